@@ -20,13 +20,348 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// Severity is the severity of an issue, sev1 being the most severe
+// +kubebuilder:validation:Enum=sev1;sev2;sev3;sev4
+type Severity string
+
+const (
+	SeveritySev1 Severity = "sev1"
+	SeveritySev2 Severity = "sev2"
+	SeveritySev3 Severity = "sev3"
+	SeveritySev4 Severity = "sev4"
+)
+
+// Severities are the supported issue severities
+var Severities = []Severity{SeveritySev1, SeveritySev2, SeveritySev3, SeveritySev4}
+
 // GithubIssueSpec defines the desired state of GithubIssue
 type GithubIssueSpec struct {
-	Repo        string `json:"repo"`
+	// Repo is the URL of the repository of the issue, it defaults to the repo of the
+	// namespace's defaults ConfigMap when omitted
+	// +optional
+	Repo        string `json:"repo,omitempty"`
 	Title       string `json:"title"`
 	Description string `json:"description"`
+
+	// Bodies holds the description in several languages keyed by locale, e.g. "en" or
+	// "pt-BR". The body of DefaultLocale is posted in place of the description.
+	// +optional
+	Bodies map[string]string `json:"bodies,omitempty"`
+
+	// DefaultLocale is the locale of Bodies posted as the issue body, required with Bodies
+	// +optional
+	DefaultLocale string `json:"defaultLocale,omitempty"`
+
+	// AppendLocales appends the bodies of the other locales to the issue body, each in a
+	// collapsible <details> section
+	// +optional
+	AppendLocales bool `json:"appendLocales,omitempty"`
+
+	// InitialComments are posted in order on the issue once it is created, e.g. a runbook or
+	// escalation contacts. They aren't maintained afterward, changing them has no effect on
+	// existing issues.
+	// +optional
+	InitialComments []string `json:"initialComments,omitempty"`
+
+	// APIBaseURL overrides the GitHub API base URL, e.g. an API gateway proxying GitHub
+	// under a path prefix such as https://gateway.example.com/github/api/v3
+	// +optional
+	APIBaseURL string `json:"apiBaseURL,omitempty"`
+
+	// IssueForm names an issue form of the repository under .github/ISSUE_TEMPLATE, e.g.
+	// bug_report.yml. The description must answer every required field of the form under a
+	// "### <label>" heading, as GitHub renders form answers.
+	// +optional
+	IssueForm string `json:"issueForm,omitempty"`
+
+	// LinkedResource references a cluster resource whose health drives the issue state:
+	// the issue is opened while the resource is unhealthy and closed once it is healthy
+	// +optional
+	LinkedResource *LinkedResource `json:"linkedResource,omitempty"`
+
+	// UpdatePolicy controls how description changes reach the issue: EditBody edits the
+	// issue body, AppendComment keeps the original body and posts every new description
+	// as a comment
+	// +kubebuilder:validation:Enum=EditBody;AppendComment
+	// +kubebuilder:default=EditBody
+	// +optional
+	UpdatePolicy UpdatePolicy `json:"updatePolicy,omitempty"`
+
+	// ManagedSection makes the operator own only the section of the body between the
+	// <!-- operator:start --> and <!-- operator:end --> markers, leaving the rest to humans
+	// +optional
+	ManagedSection bool `json:"managedSection,omitempty"`
+
+	// ExternalEditPolicy controls what happens to a body edited on GitHub since the operator
+	// last wrote it: Overwrite restores the description, Preserve leaves the edit in place
+	// +kubebuilder:validation:Enum=Overwrite;Preserve
+	// +kubebuilder:default=Overwrite
+	// +optional
+	ExternalEditPolicy ExternalEditPolicy `json:"externalEditPolicy,omitempty"`
+
+	// BodyFormat is how the description is rendered on GitHub: markdown as is, or
+	// plaintext wrapped in a code fence so it isn't interpreted as markdown
+	// +kubebuilder:validation:Enum=markdown;plaintext
+	// +kubebuilder:default=markdown
+	// +optional
+	BodyFormat BodyFormat `json:"bodyFormat,omitempty"`
+
+	// FrontMatter parses the YAML front-matter at the start of the description, setting
+	// its labels, assignees and milestone on the issue and stripping it from the body
+	// +optional
+	FrontMatter bool `json:"frontMatter,omitempty"`
+
+	// Labels are added to the issue, they also pick the body template configured for them.
+	// They may be templates rendered with .Namespace and .Name, e.g. "ns/{{ .Namespace }}".
+	// +optional
+	Labels []string `json:"labels,omitempty"`
+
+	// Assignees are the GitHub logins the issue is assigned to
+	// +optional
+	Assignees []string `json:"assignees,omitempty"`
+
+	// AssignFromTeam is an "org/team-slug" GitHub team the issue is assigned to a member of,
+	// the member holding the fewest open issues. The member stays assigned while they're in
+	// the team. The token needs the read:org scope to list the team.
+	// +optional
+	AssignFromTeam string `json:"assignFromTeam,omitempty"`
+
+	// BlockedBy lists the numbers of issues in the same repository that block this issue,
+	// each is rendered as a "Blocked by #N" line in the issue body
+	// +optional
+	BlockedBy []int `json:"blockedBy,omitempty"`
+
+	// TrackPR appends a "Tracked by PR #N" line to the body and adds the has-pr label while
+	// the issue is linked to a pull request
+	// +optional
+	TrackPR bool `json:"trackPR,omitempty"`
+
+	// ApprovalLabel is the label kept on the issue while it awaits approval, e.g.
+	// awaiting-approval. It is removed once the GithubIssue is annotated with
+	// issue.core.github.io/approved=true, and added back if the annotation is removed.
+	// +optional
+	ApprovalLabel string `json:"approvalLabel,omitempty"`
+
+	// Footer is a Go template appended to the issue body once the issue is created, rendered
+	// with .IssueNumber and .IssueURL, e.g. "Tracked as #{{ .IssueNumber }}"
+	// +optional
+	Footer string `json:"footer,omitempty"`
+
+	// NotifyURL is posted a JSON payload with the repo, number, URL and title of the issue
+	// when it is created and when it is closed, e.g. a Slack incoming webhook
+	// +optional
+	NotifyURL string `json:"notifyURL,omitempty"`
+
+	// Attachments are links, e.g. dashboards or runbooks, rendered as a section at the end
+	// of the issue body
+	// +optional
+	Attachments []Attachment `json:"attachments,omitempty"`
+
+	// LabelBlocked adds the "blocked" label to the issue while any of the BlockedBy issues is open
+	// +optional
+	LabelBlocked bool `json:"labelBlocked,omitempty"`
+
+	// Category of the issue (e.g. bug, feature, chore), the operator attaches the label
+	// configured for it
+	// +optional
+	Category string `json:"category,omitempty"`
+
+	// Severity of the issue, the operator attaches the label configured for it and prepends
+	// the configured emoji to the title
+	// +optional
+	Severity Severity `json:"severity,omitempty"`
+
+	// SecurityAdvisory creates a draft repository security advisory instead of a public issue
+	// +optional
+	SecurityAdvisory bool `json:"securityAdvisory,omitempty"`
+
+	// AdvisorySeverity is the severity of the security advisory
+	// +kubebuilder:validation:Enum=critical;high;medium;low
+	// +optional
+	AdvisorySeverity string `json:"advisorySeverity,omitempty"`
+
+	// AdvisorySummary is the summary of the security advisory, defaults to the title
+	// +optional
+	AdvisorySummary string `json:"advisorySummary,omitempty"`
+
+	// DuplicateOf is the number of an issue in the same repository this issue duplicates,
+	// the issue is closed as a duplicate and reopened once DuplicateOf is removed
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	DuplicateOf int `json:"duplicateOf,omitempty"`
+
+	// SupersededBy references the issue succeeding this one, e.g. after the repository was
+	// transferred or the GithubIssue migrated. The issue is closed with a "Superseded by"
+	// comment linking its successor.
+	// +optional
+	SupersededBy *IssueReference `json:"supersededBy,omitempty"`
+
+	// TrackingIssue is the number of an issue in the same repository whose body keeps a
+	// checklist item for this issue, checked off once this issue is closed
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	TrackingIssue int `json:"trackingIssue,omitempty"`
+
+	// AllowReopen lets the operator reopen a closed issue, e.g. once its linked resource is
+	// unhealthy again. When false, a closed issue still gets spec changes but stays closed.
+	// +kubebuilder:default=true
+	// +optional
+	AllowReopen *bool `json:"allowReopen,omitempty"`
+
+	// ReopenWithin is how long after the operator closed the issue of a recovered linked
+	// resource the issue is reopened when the resource fails again. Past it a new issue is
+	// created instead, the closed one keeping the history of the earlier failure. Closed
+	// issues are always reopened when unset.
+	// +optional
+	ReopenWithin *metav1.Duration `json:"reopenWithin,omitempty"`
+
+	// Milestone is the title of the milestone the issue is attached to, taking precedence
+	// over the milestone number of the front-matter
+	// +optional
+	Milestone string `json:"milestone,omitempty"`
+
+	// CreateMilestoneIfMissing creates the milestone when the repo doesn't have it yet,
+	// rather than failing to attach it
+	// +optional
+	CreateMilestoneIfMissing bool `json:"createMilestoneIfMissing,omitempty"`
+
+	// CloseOnMilestoneComplete closes the issue once its milestone is closed on GitHub
+	// +optional
+	CloseOnMilestoneComplete bool `json:"closeOnMilestoneComplete,omitempty"`
+
+	// RetentionAfterClose deletes the GithubIssue once its issue has been closed for this
+	// long, keeping the cluster tidy of transient issues. The closed issue is left as is.
+	// +optional
+	RetentionAfterClose *metav1.Duration `json:"retentionAfterClose,omitempty"`
+
+	// SkipFinalizer never adds the finalizer, so deleting the GithubIssue is immediate and
+	// leaves the GitHub issue untouched
+	// +optional
+	SkipFinalizer bool `json:"skipFinalizer,omitempty"`
+
+	// LockOnClose locks the issue once the operator closes it
+	// +optional
+	LockOnClose bool `json:"lockOnClose,omitempty"`
+
+	// LockReason is the reason the issue is locked with when LockOnClose is set
+	// +kubebuilder:validation:Enum=off-topic;too heated;resolved;spam
+	// +kubebuilder:default=resolved
+	// +optional
+	LockReason string `json:"lockReason,omitempty"`
+
+	// ClosedBy is the commit SHA or pull request URL that resolved the issue, recorded in a
+	// comment when the operator closes it
+	// +optional
+	ClosedBy string `json:"closedBy,omitempty"`
+
+	// CreateWindow defers creating the issue until the time of day is within the window,
+	// e.g. business hours for non-urgent issues. Existing issues are updated at any time.
+	// +optional
+	CreateWindow *CreateWindow `json:"createWindow,omitempty"`
+
+	// CloseAt is when the operator closes the issue, a time already past closes it right away
+	// +optional
+	CloseAt *metav1.Time `json:"closeAt,omitempty"`
+
+	// SLAMaxAge is how long the issue may stay open before the SLABreached condition is set
+	// +optional
+	SLAMaxAge *metav1.Duration `json:"slaMaxAge,omitempty"`
+}
+
+// IssueReference identifies a GitHub issue
+type IssueReference struct {
+	// Repo is the URL of the repository of the issue, defaults to the repository of this issue
+	// +optional
+	Repo string `json:"repo,omitempty"`
+
+	// Number is the number of the issue
+	// +kubebuilder:validation:Minimum=1
+	Number int `json:"number"`
+}
+
+// Attachment is a link rendered in the attachments section of the issue body
+type Attachment struct {
+	// Title is the text of the link
+	// +kubebuilder:validation:MinLength=1
+	Title string `json:"title"`
+
+	// URL is the https URL the link points to
+	URL string `json:"url"`
 }
 
+// CreateWindow is a daily window of time, a window ending before it starts wraps past midnight
+type CreateWindow struct {
+	// Start is the time of day the window opens, as HH:MM
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	Start string `json:"start"`
+
+	// End is the time of day the window closes, as HH:MM
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	End string `json:"end"`
+
+	// TimeZone is the IANA time zone of Start and End
+	// +kubebuilder:default=UTC
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+// LinkedResource identifies a cluster resource and the condition reporting its health
+type LinkedResource struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+
+	// Namespace of the resource, it must be the namespace of the GithubIssue when set
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// ConditionType is the condition on the resource that reports its health,
+	// the resource is healthy only while this condition is "True"
+	// +kubebuilder:default=Ready
+	// +optional
+	ConditionType string `json:"conditionType,omitempty"`
+}
+
+// PendingComment is a comment to post once the issue is in the state it's posted along with
+type PendingComment struct {
+	// Body of the comment, tagged with a marker telling whether it was posted
+	Body string `json:"body"`
+
+	// State the issue changes to, the comment is dropped if the issue isn't in it
+	// +kubebuilder:validation:Enum=open;closed
+	State string `json:"state"`
+}
+
+// UpdatePolicy controls how description changes reach the GitHub issue
+type UpdatePolicy string
+
+const (
+	// UpdatePolicyEditBody edits the issue body to match the description
+	UpdatePolicyEditBody UpdatePolicy = "EditBody"
+	// UpdatePolicyAppendComment posts every new description as a comment
+	UpdatePolicyAppendComment UpdatePolicy = "AppendComment"
+)
+
+// ExternalEditPolicy controls what happens to an issue body edited on GitHub
+type ExternalEditPolicy string
+
+const (
+	// ExternalEditPolicyOverwrite restores the description over the external edit
+	ExternalEditPolicyOverwrite ExternalEditPolicy = "Overwrite"
+	// ExternalEditPolicyPreserve leaves the externally edited body in place
+	ExternalEditPolicyPreserve ExternalEditPolicy = "Preserve"
+)
+
+// BodyFormat is how the description is rendered on GitHub
+type BodyFormat string
+
+const (
+	// BodyFormatMarkdown sends the description as is
+	BodyFormatMarkdown BodyFormat = "markdown"
+	// BodyFormatPlaintext wraps the description in a code fence
+	BodyFormatPlaintext BodyFormat = "plaintext"
+)
+
 // GithubIssueStatus defines the observed state of GithubIssue
 type GithubIssueStatus struct {
 	// +optional
@@ -40,6 +375,84 @@ type GithubIssueStatus struct {
 
 	// +optional
 	TokenRequired bool `json:"TokenRequired,omitempty"`
+
+	// Assignees are the logins the GitHub issue is assigned to
+	// +optional
+	Assignees []string `json:"assignees,omitempty"`
+
+	// ManagedAssignees are the logins the operator assigned the issue to, they're unassigned
+	// once the spec drops them all
+	// +optional
+	ManagedAssignees []string `json:"managedAssignees,omitempty"`
+
+	// AdvisoryID is the GHSA ID of the security advisory created instead of an issue
+	// +optional
+	AdvisoryID string `json:"advisoryID,omitempty"`
+
+	// ContentHash is the hash of the description last posted as a comment with the
+	// AppendComment update policy
+	// +optional
+	ContentHash string `json:"contentHash,omitempty"`
+
+	// BodyHash is the hash of the issue body the operator last wrote, a live body
+	// matching neither it nor the description was edited on GitHub
+	// +optional
+	BodyHash string `json:"bodyHash,omitempty"`
+
+	// DiscussionURL is the URL of the discussion the issue was converted to on GitHub
+	// +optional
+	DiscussionURL string `json:"discussionURL,omitempty"`
+
+	// NotifiedState is the issue state the notify URL was last notified of
+	// +optional
+	NotifiedState string `json:"notifiedState,omitempty"`
+
+	// RateLimitRemaining is the number of GitHub requests the token had left as of the
+	// latest reconcile, unset until a request was made. A token out of requests reports 0.
+	// +optional
+	RateLimitRemaining *int32 `json:"rateLimitRemaining,omitempty"`
+
+	// RateLimitResetTime is when the GitHub rate limit of the token resets
+	// +optional
+	RateLimitResetTime *metav1.Time `json:"rateLimitResetTime,omitempty"`
+
+	// LastStateTransition is when the operator last opened or closed the issue
+	// +optional
+	LastStateTransition *metav1.Time `json:"lastStateTransition,omitempty"`
+
+	// LastClosedAt is when the operator last closed the issue because its linked resource
+	// recovered
+	// +optional
+	LastClosedAt *metav1.Time `json:"lastClosedAt,omitempty"`
+
+	// PreviousIssueNumber is the number of the closed issue the current issue replaced, once
+	// the linked resource failed again past ReopenWithin
+	// +optional
+	PreviousIssueNumber int32 `json:"previousIssueNumber,omitempty"`
+
+	// InitialCommentsPending is set when the issue is created and cleared once all of its
+	// initial comments are posted
+	// +optional
+	InitialCommentsPending bool `json:"initialCommentsPending,omitempty"`
+
+	// PendingComment is the comment to post along with a change of the issue state, recorded
+	// before the state changes and cleared once posted, so it's still posted when the reconcile
+	// fails in between
+	// +optional
+	PendingComment *PendingComment `json:"pendingComment,omitempty"`
+
+	// LastEditSummary is what the last edit of the issue by the operator changed, e.g.
+	// "title changed; body +3/-1 lines"
+	// +optional
+	LastEditSummary string `json:"lastEditSummary,omitempty"`
+
+	// CommentCount is the number of comments on the issue
+	// +optional
+	CommentCount int32 `json:"commentCount,omitempty"`
+
+	// OperatorVersion is the version of the operator that last reconciled the issue
+	// +optional
+	OperatorVersion string `json:"operatorVersion,omitempty"`
 }
 
 // +kubebuilder:object:root=true
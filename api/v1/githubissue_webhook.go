@@ -17,6 +17,16 @@ limitations under the License.
 package v1
 
 import (
+	"context"
+	"fmt"
+	"github.com/oshribelay/github-issue-operator/internal/footer"
+	"github.com/oshribelay/github-issue-operator/internal/frontmatter"
+	"github.com/oshribelay/github-issue-operator/internal/issueform"
+	"github.com/oshribelay/github-issue-operator/internal/labeltemplate"
+	"github.com/oshribelay/github-issue-operator/internal/mdlint"
+	"github.com/oshribelay/github-issue-operator/internal/repourl"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -24,14 +34,126 @@ import (
 	"net/url"
 	"regexp"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
 )
 
 // log is for logging in this package.
 var githubissuelog = logf.Log.WithName("githubissue-resource")
 
+// WebhookOptions configures the optional behavior of the GithubIssue webhooks
+// +kubebuilder:object:generate=false
+type WebhookOptions struct {
+	// TruncateBody skips the description length check, since the controller
+	// truncates over-long bodies itself
+	TruncateBody bool
+
+	// ScanSecrets rejects descriptions containing what looks like a credential
+	ScanSecrets bool
+
+	// Categories are the issue categories the operator has labels configured for
+	Categories []string
+
+	// ControlCharacters is what the webhooks do with control characters in the description
+	ControlCharacters ControlCharacterPolicy
+
+	// DefaultsConfigMap is the name of the ConfigMap a namespace can hold its default
+	// repo in, under the DefaultRepoKey key
+	DefaultsConfigMap string
+
+	// Reader reads the defaults ConfigMap, defaulting is skipped without one
+	Reader client.Reader
+
+	// RepoChecker checks at creation that the repo exists and is accessible, the check
+	// is skipped without one
+	RepoChecker RepoChecker
+
+	// RepoCheckTimeout bounds the repo and issue form checks so admission stays fast, the
+	// GithubIssue is admitted with a warning when a check doesn't complete in time
+	RepoCheckTimeout time.Duration
+
+	// IssueForms fetches the issue forms descriptions are checked against, the check is
+	// skipped without it
+	IssueForms *issueform.Cache
+
+	// LintMarkdown warns about structural markdown errors in the description, e.g.
+	// unclosed code fences, without rejecting it
+	LintMarkdown bool
+
+	// LinkedKinds are the kinds a linked resource can be of, any kind is admitted when empty
+	LinkedKinds []schema.GroupKind
+
+	// AllowedAPIHosts are the hosts an API base URL override may point at besides
+	// api.github.com, since the operator sends its tokens there
+	AllowedAPIHosts []string
+
+	// AllowedNotifyHosts are the hosts a notify URL may point at, none is allowed when empty
+	AllowedNotifyHosts []string
+}
+
+// RepoChecker probes whether a repository exists and the token it uses can access it
+// +kubebuilder:object:generate=false
+type RepoChecker interface {
+	RepoExists(ctx context.Context, owner, repo string) (bool, error)
+}
+
+// ControlCharacterPolicy is what the webhooks do with the control characters of a description,
+// other than newlines, carriage returns and tabs
+// +kubebuilder:object:generate=false
+type ControlCharacterPolicy string
+
+const (
+	// ControlCharactersAllow leaves the control characters in the description
+	ControlCharactersAllow ControlCharacterPolicy = "allow"
+	// ControlCharactersReject rejects descriptions containing control characters
+	ControlCharactersReject ControlCharacterPolicy = "reject"
+	// ControlCharactersStrip removes the control characters from the description
+	ControlCharactersStrip ControlCharacterPolicy = "strip"
+)
+
+// DefaultRepoKey is the key of the default repo in the namespace defaults ConfigMap
+const DefaultRepoKey = "repo"
+
+// secretPattern describes a credential format the description is scanned for
+type secretPattern struct {
+	name string
+	re   *regexp.Regexp
+}
+
+// secretPatterns are the credential formats rejected when ScanSecrets is enabled
+var secretPatterns = []secretPattern{
+	{name: "GitHub token", re: regexp.MustCompile(`gh[pousr]_[A-Za-z0-9]{36}`)},
+	{name: "GitHub fine-grained token", re: regexp.MustCompile(`github_pat_[A-Za-z0-9_]{82}`)},
+	{name: "AWS access key ID", re: regexp.MustCompile(`(AKIA|ASIA)[0-9A-Z]{16}`)},
+}
+
+// loginPattern matches a GitHub login
+var loginPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9-]{0,38}$`)
+
+// teamSlugPattern matches the slugs GitHub derives from team names
+var teamSlugPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// commitSHAPattern matches a full or abbreviated commit SHA
+var commitSHAPattern = regexp.MustCompile(`^[0-9a-f]{7,40}$`)
+
+// pullRequestPathPattern matches the path of a pull request URL
+var pullRequestPathPattern = regexp.MustCompile(`^/[^/]+/[^/]+/pull/[1-9][0-9]*$`)
+
+// webhookOptions holds the options the webhooks were configured with
+var webhookOptions WebhookOptions
+
+// SetWebhookOptions configures the GithubIssue webhooks, it should be called before the manager starts
+func SetWebhookOptions(opts WebhookOptions) {
+	webhookOptions = opts
+}
+
 // SetupWebhookWithManager will setup the manager to manage the webhooks
 func (r *GithubIssue) SetupWebhookWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewWebhookManagedBy(mgr).
@@ -39,6 +161,9 @@ func (r *GithubIssue) SetupWebhookWithManager(mgr ctrl.Manager) error {
 		Complete()
 }
 
+// The webhooks match resources=githubissues only, which leaves out the status subresource:
+// the controller's status writes never go through them, even while they are unavailable. Its
+// metadata writes, e.g. finalizers and annotations, do go through both webhooks.
 // +kubebuilder:webhook:path=/mutate-issue-core-github-io-v1-githubissue,mutating=true,failurePolicy=fail,sideEffects=None,groups=issue.core.github.io,resources=githubissues,verbs=create;update,versions=v1,name=mgithubissue.kb.io,admissionReviewVersions=v1
 
 var _ webhook.Defaulter = &GithubIssue{}
@@ -46,6 +171,56 @@ var _ webhook.Defaulter = &GithubIssue{}
 // Default implements webhook.Defaulter so a webhook will be registered for the type
 func (r *GithubIssue) Default() {
 	githubissuelog.Info("default", "name", r.Name)
+
+	if r.Spec.Repo == "" {
+		repo, err := namespaceDefaultRepo(context.Background(), r.Namespace)
+		if err != nil {
+			// validation reports the missing repo
+			githubissuelog.Error(err, "unable to read the namespace default repo", "namespace", r.Namespace)
+			return
+		}
+		r.Spec.Repo = repo
+	}
+	if webhookOptions.ControlCharacters == ControlCharactersStrip {
+		r.Spec.Description = stripControlCharacters(r.Spec.Description)
+		for locale, body := range r.Spec.Bodies {
+			r.Spec.Bodies[locale] = stripControlCharacters(body)
+		}
+		for i, comment := range r.Spec.InitialComments {
+			r.Spec.InitialComments[i] = stripControlCharacters(comment)
+		}
+	}
+}
+
+// stripControlCharacters removes the control characters from a body
+func stripControlCharacters(body string) string {
+	return strings.Map(func(c rune) rune {
+		if isControlCharacter(c) {
+			return -1
+		}
+		return c
+	}, body)
+}
+
+// isControlCharacter reports whether the character is a control character other than a
+// newline, a carriage return or a tab. Carriage returns are left to the controller, which
+// normalizes CRLF line endings.
+func isControlCharacter(c rune) bool {
+	return unicode.IsControl(c) && c != '\n' && c != '\r' && c != '\t'
+}
+
+// namespaceDefaultRepo returns the default repo of the namespace, or an empty string when
+// the namespace has no defaults ConfigMap
+func namespaceDefaultRepo(ctx context.Context, namespace string) (string, error) {
+	if webhookOptions.Reader == nil || webhookOptions.DefaultsConfigMap == "" {
+		return "", nil
+	}
+	configMap := &corev1.ConfigMap{}
+	key := client.ObjectKey{Namespace: namespace, Name: webhookOptions.DefaultsConfigMap}
+	if err := webhookOptions.Reader.Get(ctx, key, configMap); err != nil {
+		return "", client.IgnoreNotFound(err)
+	}
+	return configMap.Data[DefaultRepoKey], nil
 }
 
 // NOTE: The 'path' attribute must follow a specific pattern and should not be modified directly here.
@@ -57,6 +232,12 @@ var _ webhook.Validator = &GithubIssue{}
 // isValidRepoUrl validates the GitHub repository URL format.
 func validateRepoURL(repoUrl string) *field.Error {
 	fldPath := field.NewPath("spec").Child("repo")
+	// the repo is only missing when the namespace has no default to inherit
+	if repoUrl == "" {
+		return field.Required(fldPath, fmt.Sprintf(
+			"repo is required unless the namespace sets a default in the %q key of the %s ConfigMap",
+			DefaultRepoKey, webhookOptions.DefaultsConfigMap))
+	}
 	// check if it is a proper URL
 	parsedURL, err := url.Parse(repoUrl)
 	if err != nil {
@@ -75,7 +256,34 @@ func validateRepoURL(repoUrl string) *field.Error {
 	if !re.MatchString(parsedURL.Path) {
 		return field.Invalid(fldPath, repoUrl, "repository URL must be in the format 'https://github.com/{owner}/{repo}'")
 	}
+	// extract the owner and repo the way the controller does
+	if _, _, err := repourl.Parse(repoUrl); err != nil {
+		return field.Invalid(fldPath, repoUrl, err.Error())
+	}
+
+	return nil
+}
 
+// validateAPIBaseURL checks that the API base URL override is a well-formed https URL on an
+// allowed host
+func validateAPIBaseURL(apiBaseURL string) *field.Error {
+	if apiBaseURL == "" {
+		return nil
+	}
+	fldPath := field.NewPath("spec").Child("apiBaseURL")
+	parsedURL, err := url.Parse(apiBaseURL)
+	if err != nil {
+		return field.Invalid(fldPath, apiBaseURL, "Invalid url format")
+	}
+	if parsedURL.Scheme != "https" || parsedURL.Host == "" {
+		return field.Invalid(fldPath, apiBaseURL, "API base URL must be an https URL with a host")
+	}
+	if parsedURL.RawQuery != "" || parsedURL.Fragment != "" || parsedURL.User != nil {
+		return field.Invalid(fldPath, apiBaseURL, "API base URL can't have credentials, a query or a fragment")
+	}
+	if !repourl.APIHostAllowed(apiBaseURL, webhookOptions.AllowedAPIHosts) {
+		return field.Forbidden(fldPath, fmt.Sprintf("host %s isn't one of the API hosts the operator allows", parsedURL.Host))
+	}
 	return nil
 }
 
@@ -87,24 +295,512 @@ func validateTitle(title string) *field.Error {
 	return nil
 }
 
-func validateDescription(description string) *field.Error {
-	if len(description) > 256 {
-		return field.Invalid(field.NewPath("spec").Child("description"), description, "description must not be longer than 256 characters")
+// body is a body posted to GitHub, the description or the body of a locale
+type body struct {
+	path *field.Path
+	// name names the body in messages
+	name string
+	text string
+}
+
+// bodies returns the description and the bodies of every locale, sorted, so all of them are
+// checked alike
+func bodies(spec GithubIssueSpec) []body {
+	all := []body{{path: field.NewPath("spec").Child("description"), name: "description", text: spec.Description}}
+	locales := make([]string, 0, len(spec.Bodies))
+	for locale := range spec.Bodies {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	for _, locale := range locales {
+		all = append(all, body{
+			path: field.NewPath("spec").Child("bodies").Key(locale),
+			name: "body of " + locale,
+			text: spec.Bodies[locale],
+		})
+	}
+	return all
+}
+
+// MaxDescriptionLength is the length in bytes the webhook admits for the description and the
+// body of every locale, unless bodies are truncated by the controller
+const MaxDescriptionLength = 256
+
+// MaxTitleLength is the length in characters GitHub accepts for the title of an issue
+const MaxTitleLength = 256
+
+// initialComments returns the initial comments as bodies, they are posted on the issue alike
+func initialComments(spec GithubIssueSpec) []body {
+	all := make([]body, 0, len(spec.InitialComments))
+	for i, comment := range spec.InitialComments {
+		all = append(all, body{
+			path: field.NewPath("spec").Child("initialComments").Index(i),
+			name: fmt.Sprintf("initial comment %d", i),
+			text: comment,
+		})
+	}
+	return all
+}
+
+func validateDescription(b body) *field.Error {
+	if len(b.text) > MaxDescriptionLength {
+		return field.Invalid(b.path, b.text, fmt.Sprintf("%s must not be longer than %d characters", b.name, MaxDescriptionLength))
 	}
 	return nil
 }
 
+// validateInitialComments checks that no initial comment is blank, GitHub refuses empty comments
+func validateInitialComments(comments []string) field.ErrorList {
+	var allErrs field.ErrorList
+	fldPath := field.NewPath("spec").Child("initialComments")
+	for i, comment := range comments {
+		if strings.TrimSpace(comment) == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Index(i), "initial comments must not be blank"))
+		}
+	}
+	return allErrs
+}
+
+// localePattern matches a BCP 47 language tag, e.g. "en", "pt-BR" or "zh-Hant-TW"
+var localePattern = regexp.MustCompile(`^[a-z]{2,3}(-[A-Za-z0-9]{2,8})*$`)
+
+// validateBodies checks that the bodies are keyed by locales, one of them the default locale
+// replacing the description
+func validateBodies(spec GithubIssueSpec) field.ErrorList {
+	var allErrs field.ErrorList
+	fldPath := field.NewPath("spec")
+	if len(spec.Bodies) == 0 {
+		if spec.DefaultLocale != "" {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("defaultLocale"), "requires bodies"))
+		}
+		if spec.AppendLocales {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("appendLocales"), "requires bodies"))
+		}
+		return allErrs
+	}
+
+	locales := make([]string, 0, len(spec.Bodies))
+	for locale := range spec.Bodies {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	for _, locale := range locales {
+		if !localePattern.MatchString(locale) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("bodies").Key(locale), locale,
+				"must be a locale such as en or pt-BR"))
+		}
+	}
+	if spec.DefaultLocale == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("defaultLocale"), "the locale of the body to post is required with bodies"))
+	} else if _, ok := spec.Bodies[spec.DefaultLocale]; !ok {
+		allErrs = append(allErrs, field.NotFound(fldPath.Child("defaultLocale"), spec.DefaultLocale))
+	}
+	if spec.Description != "" {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("description"),
+			"the body of the default locale is the description, leave it empty with bodies"))
+	}
+	return allErrs
+}
+
+// defaultBody returns the body posted for the issue, the description or the body of the
+// default locale
+func defaultBody(spec GithubIssueSpec) string {
+	if len(spec.Bodies) > 0 {
+		return spec.Bodies[spec.DefaultLocale]
+	}
+	return spec.Description
+}
+
+// validateNoSecrets rejects a body containing credentials, the error points at the location
+// of the match without echoing it back
+func validateNoSecrets(b body) field.ErrorList {
+	var allErrs field.ErrorList
+	for _, pattern := range secretPatterns {
+		for _, loc := range pattern.re.FindAllStringIndex(b.text, -1) {
+			allErrs = append(allErrs, field.Forbidden(b.path, fmt.Sprintf(
+				"%s contains what looks like a %s at characters %d-%d (%s), remove it before creating the issue",
+				b.name, pattern.name, loc[0], loc[1], redact(b.text[loc[0]:loc[1]]))))
+		}
+	}
+	return allErrs
+}
+
+// validateNoControlCharacters rejects a body containing control characters, listing the
+// positions of the characters
+func validateNoControlCharacters(b body) *field.Error {
+	var positions []string
+	for i, c := range []rune(b.text) {
+		if isControlCharacter(c) {
+			positions = append(positions, fmt.Sprintf("%d (%U)", i, c))
+		}
+	}
+	if len(positions) == 0 {
+		return nil
+	}
+	return field.Invalid(b.path, "<"+b.name+">", fmt.Sprintf(
+		"%s contains control characters at characters %s, remove them or let the operator strip them",
+		b.name, strings.Join(positions, ", ")))
+}
+
+// redact hides all but the first 4 characters of a matched secret
+func redact(secret string) string {
+	return secret[:4] + strings.Repeat("*", len(secret)-4)
+}
+
+// validateCategory checks that the category is one of the configured ones
+func validateCategory(category string) *field.Error {
+	if category == "" {
+		return nil
+	}
+	for _, c := range webhookOptions.Categories {
+		if c == category {
+			return nil
+		}
+	}
+	return field.NotSupported(field.NewPath("spec").Child("category"), category, webhookOptions.Categories)
+}
+
+// validateSeverity checks that the severity is one of the supported ones
+func validateSeverity(severity Severity) *field.Error {
+	if severity == "" {
+		return nil
+	}
+	supported := make([]string, 0, len(Severities))
+	for _, s := range Severities {
+		if s == severity {
+			return nil
+		}
+		supported = append(supported, string(s))
+	}
+	return field.NotSupported(field.NewPath("spec").Child("severity"), severity, supported)
+}
+
+// validateSecurityAdvisory checks that a security advisory has a severity
+func validateSecurityAdvisory(spec GithubIssueSpec) *field.Error {
+	if spec.SecurityAdvisory && spec.AdvisorySeverity == "" {
+		return field.Required(field.NewPath("spec").Child("advisorySeverity"), "severity is required for a security advisory")
+	}
+	return nil
+}
+
+// validateRetentionAfterClose checks that the retention of the closed issue is positive
+func validateRetentionAfterClose(spec GithubIssueSpec) *field.Error {
+	retention := spec.RetentionAfterClose
+	if retention == nil || retention.Duration > 0 {
+		return nil
+	}
+	return field.Invalid(field.NewPath("spec").Child("retentionAfterClose"), retention.Duration.String(), "must be positive")
+}
+
+// validateCreateMilestone checks that a milestone to create has a title
+func validateCreateMilestone(spec GithubIssueSpec) *field.Error {
+	if spec.CreateMilestoneIfMissing && spec.Milestone == "" {
+		return field.Required(field.NewPath("spec").Child("milestone"), "the title of the milestone to create is required")
+	}
+	return nil
+}
+
+// validateLinkedResource checks that the linked resource is in the namespace of the GithubIssue
+// and of a kind the operator reads
+func validateLinkedResource(githubIssue *GithubIssue) field.ErrorList {
+	linked := githubIssue.Spec.LinkedResource
+	if linked == nil {
+		return nil
+	}
+	var allErrs field.ErrorList
+	fldPath := field.NewPath("spec").Child("linkedResource")
+	if linked.Namespace != "" && linked.Namespace != githubIssue.Namespace {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("namespace"), "must be the namespace of the GithubIssue"))
+	}
+	if len(webhookOptions.LinkedKinds) > 0 {
+		groupKind := schema.FromAPIVersionAndKind(linked.APIVersion, linked.Kind).GroupKind()
+		if !slices.Contains(webhookOptions.LinkedKinds, groupKind) {
+			allErrs = append(allErrs, field.NotSupported(fldPath.Child("kind"), groupKind.String(), groupKindNames(webhookOptions.LinkedKinds)))
+		}
+	}
+	return allErrs
+}
+
+// groupKindNames returns the names of the kinds in the "Kind.group" form
+func groupKindNames(kinds []schema.GroupKind) []string {
+	names := make([]string, 0, len(kinds))
+	for _, kind := range kinds {
+		names = append(names, kind.String())
+	}
+	return names
+}
+
+// validateReopenWithin checks that the reopen window is positive and has a linked resource
+// to follow
+func validateReopenWithin(spec GithubIssueSpec) *field.Error {
+	if spec.ReopenWithin == nil {
+		return nil
+	}
+	fldPath := field.NewPath("spec").Child("reopenWithin")
+	if spec.ReopenWithin.Duration <= 0 {
+		return field.Invalid(fldPath, spec.ReopenWithin.Duration.String(), "must be positive")
+	}
+	if spec.LinkedResource == nil {
+		return field.Forbidden(fldPath, "only issues following a linked resource are reopened")
+	}
+	return nil
+}
+
+// issueFormPattern matches the file name of an issue form
+var issueFormPattern = regexp.MustCompile(`^[A-Za-z0-9._-]+\.ya?ml$`)
+
+// validateIssueFormName checks that the issue form is the YAML file name of a form
+func validateIssueFormName(name string) *field.Error {
+	if name == "" || issueFormPattern.MatchString(name) {
+		return nil
+	}
+	return field.Invalid(field.NewPath("spec").Child("issueForm"), name,
+		"must be the file name of an issue form under "+issueform.Dir+", e.g. bug_report.yml")
+}
+
+// validateCreateWindow checks that the create window has a known time zone and isn't empty
+func validateCreateWindow(window *CreateWindow) field.ErrorList {
+	var allErrs field.ErrorList
+	if window == nil {
+		return allErrs
+	}
+
+	fldPath := field.NewPath("spec").Child("createWindow")
+	if window.Start == window.End {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("end"), window.End, "the window must not end when it starts"))
+	}
+	if _, err := time.LoadLocation(window.TimeZone); err != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("timeZone"), window.TimeZone, "unknown time zone"))
+	}
+	return allErrs
+}
+
+// validateAssignees checks that every assignee at the path looks like a GitHub login: at most
+// 39 alphanumeric characters or hyphens, not starting with a hyphen
+func validateAssignees(fldPath *field.Path, assignees []string) field.ErrorList {
+	var allErrs field.ErrorList
+	for i, login := range assignees {
+		if !loginPattern.MatchString(login) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i), login,
+				"must be 1-39 alphanumeric characters or hyphens and can't start with a hyphen"))
+		}
+	}
+	return allErrs
+}
+
+// validateAssignFromTeam checks that the team is an "org/team-slug" reference
+func validateAssignFromTeam(team string) *field.Error {
+	if team == "" {
+		return nil
+	}
+	org, slug, found := strings.Cut(team, "/")
+	if !found || !loginPattern.MatchString(org) || !teamSlugPattern.MatchString(slug) {
+		return field.Invalid(field.NewPath("spec").Child("assignFromTeam"), team,
+			"must be in the format 'org/team-slug'")
+	}
+	return nil
+}
+
+// validateFrontMatter checks that the front-matter of the description parses and that its
+// assignees look like GitHub logins
+func validateFrontMatter(spec GithubIssueSpec) field.ErrorList {
+	if !spec.FrontMatter {
+		return nil
+	}
+	fldPath := field.NewPath("spec").Child("description")
+	metadata, _, err := frontmatter.Parse(spec.Description)
+	if err != nil {
+		return field.ErrorList{field.Invalid(fldPath, "<front-matter>", err.Error())}
+	}
+	return validateAssignees(fldPath.Key("front-matter").Child("assignees"), metadata.Assignees)
+}
+
+// validateBlockedBy checks that every blocking issue number is positive
+func validateBlockedBy(blockedBy []int) field.ErrorList {
+	var allErrs field.ErrorList
+	fldPath := field.NewPath("spec").Child("blockedBy")
+	for i, number := range blockedBy {
+		if number < 1 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i), number, "issue number must be positive"))
+		}
+	}
+	return allErrs
+}
+
+// validateSupersededBy checks that the successor issue has a valid repository and isn't
+// combined with DuplicateOf, which closes the issue differently
+func validateSupersededBy(spec GithubIssueSpec) field.ErrorList {
+	var allErrs field.ErrorList
+	if spec.SupersededBy == nil {
+		return allErrs
+	}
+
+	fldPath := field.NewPath("spec").Child("supersededBy")
+	if spec.DuplicateOf > 0 {
+		allErrs = append(allErrs, field.Forbidden(fldPath, "an issue can't be both superseded and a duplicate"))
+	}
+	if spec.SupersededBy.Repo != "" {
+		if _, _, err := repourl.Parse(spec.SupersededBy.Repo); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("repo"), spec.SupersededBy.Repo, err.Error()))
+		}
+	}
+	if spec.SupersededBy.Number < 1 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("number"), spec.SupersededBy.Number, "issue number must be positive"))
+	}
+	return allErrs
+}
+
+// validateAttachments checks that every attachment links to an https URL
+func validateAttachments(attachments []Attachment) field.ErrorList {
+	var allErrs field.ErrorList
+	fldPath := field.NewPath("spec").Child("attachments")
+	for i, attachment := range attachments {
+		u, err := url.Parse(attachment.URL)
+		if err != nil || u.Scheme != "https" || u.Host == "" {
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i).Child("url"), attachment.URL, "attachment URL must be an https URL"))
+		}
+	}
+	return allErrs
+}
+
+// validateLabels checks that the labels render with the metadata of the GithubIssue, which
+// can't change, into labels GitHub accepts
+func validateLabels(githubIssue *GithubIssue) *field.Error {
+	data := labeltemplate.Data{Namespace: githubIssue.Namespace, Name: githubIssue.Name}
+	if _, err := labeltemplate.Render(githubIssue.Spec.Labels, data); err != nil {
+		return field.Invalid(field.NewPath("spec").Child("labels"), githubIssue.Spec.Labels, err.Error())
+	}
+	return nil
+}
+
+// validateApprovalLabel checks that the approval label isn't among the labels, which are
+// kept on the issue and would add it back once approved
+func validateApprovalLabel(spec GithubIssueSpec) *field.Error {
+	for _, label := range spec.Labels {
+		if spec.ApprovalLabel != "" && label == spec.ApprovalLabel {
+			return field.Invalid(field.NewPath("spec").Child("approvalLabel"), spec.ApprovalLabel,
+				"the approval label must not be listed in labels")
+		}
+	}
+	return nil
+}
+
+// validateFooter checks that the footer template renders with the fields of an issue
+func validateFooter(footerTemplate string) *field.Error {
+	if err := footer.Validate(footerTemplate); err != nil {
+		return field.Invalid(field.NewPath("spec").Child("footer"), footerTemplate, err.Error())
+	}
+	return nil
+}
+
+// validateNotifyURL checks that the notify URL is an https URL on one of the notify hosts
+// the operator allows
+func validateNotifyURL(notifyURL string) *field.Error {
+	if notifyURL == "" {
+		return nil
+	}
+	u, err := url.Parse(notifyURL)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return field.Invalid(field.NewPath("spec").Child("notifyURL"), notifyURL, "notify URL must be an https URL")
+	}
+	if !repourl.HostAllowed(notifyURL, webhookOptions.AllowedNotifyHosts) {
+		return field.Forbidden(field.NewPath("spec").Child("notifyURL"),
+			fmt.Sprintf("host %s isn't one of the notify hosts the operator allows", u.Host))
+	}
+	return nil
+}
+
+// validateClosedBy checks that the closing reference is a commit SHA or a pull request URL
+func validateClosedBy(closedBy string) *field.Error {
+	if closedBy == "" || commitSHAPattern.MatchString(closedBy) {
+		return nil
+	}
+	u, err := url.Parse(closedBy)
+	if err != nil || u.Scheme != "https" || u.Host == "" || !pullRequestPathPattern.MatchString(u.Path) {
+		return field.Invalid(field.NewPath("spec").Child("closedBy"), closedBy,
+			"must be a commit SHA or a pull request URL like https://github.com/owner/repo/pull/1")
+	}
+	return nil
+}
+
+// validateGithubIssue validates the spec, reporting every invalid field at once in a single
+// Invalid error rather than stopping at the first one
 func validateGithubIssue(githubIssue *GithubIssue) error {
 	var allErrs field.ErrorList
 	if err := validateTitle(githubIssue.Spec.Title); err != nil {
 		allErrs = append(allErrs, err)
 	}
-	if err := validateDescription(githubIssue.Spec.Description); err != nil {
-		allErrs = append(allErrs, err)
+	if !webhookOptions.TruncateBody {
+		for _, b := range bodies(githubIssue.Spec) {
+			if err := validateDescription(b); err != nil {
+				allErrs = append(allErrs, err)
+			}
+		}
 	}
 	if err := validateRepoURL(githubIssue.Spec.Repo); err != nil {
 		allErrs = append(allErrs, err)
 	}
+	if err := validateAPIBaseURL(githubIssue.Spec.APIBaseURL); err != nil {
+		allErrs = append(allErrs, err)
+	}
+	allErrs = append(allErrs, validateBodies(githubIssue.Spec)...)
+	allErrs = append(allErrs, validateInitialComments(githubIssue.Spec.InitialComments)...)
+	allErrs = append(allErrs, validateAssignees(field.NewPath("spec").Child("assignees"), githubIssue.Spec.Assignees)...)
+	if err := validateAssignFromTeam(githubIssue.Spec.AssignFromTeam); err != nil {
+		allErrs = append(allErrs, err)
+	}
+	allErrs = append(allErrs, validateFrontMatter(githubIssue.Spec)...)
+	allErrs = append(allErrs, validateBlockedBy(githubIssue.Spec.BlockedBy)...)
+	allErrs = append(allErrs, validateAttachments(githubIssue.Spec.Attachments)...)
+	if err := validateLabels(githubIssue); err != nil {
+		allErrs = append(allErrs, err)
+	}
+	if err := validateApprovalLabel(githubIssue.Spec); err != nil {
+		allErrs = append(allErrs, err)
+	}
+	if err := validateFooter(githubIssue.Spec.Footer); err != nil {
+		allErrs = append(allErrs, err)
+	}
+	if err := validateNotifyURL(githubIssue.Spec.NotifyURL); err != nil {
+		allErrs = append(allErrs, err)
+	}
+	if err := validateClosedBy(githubIssue.Spec.ClosedBy); err != nil {
+		allErrs = append(allErrs, err)
+	}
+	allErrs = append(allErrs, validateSupersededBy(githubIssue.Spec)...)
+	if err := validateCategory(githubIssue.Spec.Category); err != nil {
+		allErrs = append(allErrs, err)
+	}
+	if err := validateSeverity(githubIssue.Spec.Severity); err != nil {
+		allErrs = append(allErrs, err)
+	}
+	if err := validateSecurityAdvisory(githubIssue.Spec); err != nil {
+		allErrs = append(allErrs, err)
+	}
+	if err := validateIssueFormName(githubIssue.Spec.IssueForm); err != nil {
+		allErrs = append(allErrs, err)
+	}
+	allErrs = append(allErrs, validateLinkedResource(githubIssue)...)
+	if err := validateReopenWithin(githubIssue.Spec); err != nil {
+		allErrs = append(allErrs, err)
+	}
+	if err := validateCreateMilestone(githubIssue.Spec); err != nil {
+		allErrs = append(allErrs, err)
+	}
+	if err := validateRetentionAfterClose(githubIssue.Spec); err != nil {
+		allErrs = append(allErrs, err)
+	}
+	allErrs = append(allErrs, validateCreateWindow(githubIssue.Spec.CreateWindow)...)
+	for _, b := range append(bodies(githubIssue.Spec), initialComments(githubIssue.Spec)...) {
+		if webhookOptions.ScanSecrets {
+			allErrs = append(allErrs, validateNoSecrets(b)...)
+		}
+		if webhookOptions.ControlCharacters == ControlCharactersReject {
+			if err := validateNoControlCharacters(b); err != nil {
+				allErrs = append(allErrs, err)
+			}
+		}
+	}
 
 	if len(allErrs) == 0 {
 		return nil
@@ -125,18 +821,125 @@ func (r *GithubIssue) ValidateCreate() (admission.Warnings, error) {
 		return nil, err
 	}
 
+	warnings, err := validateRepoExists(r)
+	if err != nil {
+		return warnings, err
+	}
+	formWarnings, err := validateIssueForm(r)
+	return append(append(warnings, formWarnings...), lintDescription(r)...), err
+}
+
+// validateRepoExists rejects a GithubIssue whose repo doesn't exist or isn't accessible with
+// the admission token. Failing checks, e.g. timeouts, only warn rather than hold admission back
+// on GitHub being unavailable. Repos behind an API base URL override aren't checked, the
+// admission token is for github.com.
+func validateRepoExists(githubIssue *GithubIssue) (admission.Warnings, error) {
+	if webhookOptions.RepoChecker == nil || githubIssue.Spec.APIBaseURL != "" {
+		return nil, nil
+	}
+	owner, repo, err := repourl.Parse(githubIssue.Spec.Repo)
+	if err != nil {
+		return nil, nil
+	}
+
+	ctx := context.Background()
+	if webhookOptions.RepoCheckTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, webhookOptions.RepoCheckTimeout)
+		defer cancel()
+	}
+	exists, err := webhookOptions.RepoChecker.RepoExists(ctx, owner, repo)
+	if err != nil {
+		githubissuelog.Error(err, "unable to check the repo exists", "owner", owner, "repo", repo)
+		return admission.Warnings{fmt.Sprintf("unable to check that %s/%s exists: %v", owner, repo, err)}, nil
+	}
+	if !exists {
+		return nil, apierrors.NewInvalid(
+			schema.GroupKind{Group: GroupVersion.Group, Kind: "GithubIssue"},
+			githubIssue.Name,
+			field.ErrorList{field.Invalid(field.NewPath("spec").Child("repo"), githubIssue.Spec.Repo,
+				"the repository does not exist or is not accessible with the admission token")},
+		)
+	}
 	return nil, nil
 }
 
+// validateIssueForm checks that the description answers every required field of the issue
+// form. The GithubIssue is admitted with a warning when the form can't be fetched in time.
+func validateIssueForm(githubIssue *GithubIssue) (admission.Warnings, error) {
+	name := githubIssue.Spec.IssueForm
+	if webhookOptions.IssueForms == nil || name == "" || githubIssue.Spec.APIBaseURL != "" {
+		return nil, nil
+	}
+	owner, repo, err := repourl.Parse(githubIssue.Spec.Repo)
+	if err != nil {
+		return nil, nil
+	}
+
+	ctx := context.Background()
+	if webhookOptions.RepoCheckTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, webhookOptions.RepoCheckTimeout)
+		defer cancel()
+	}
+	form, err := webhookOptions.IssueForms.Form(ctx, owner, repo, name)
+	if err != nil {
+		githubissuelog.Error(err, "unable to fetch the issue form", "owner", owner, "repo", repo, "form", name)
+		return admission.Warnings{fmt.Sprintf("unable to check the description against issue form %s: %v", name, err)}, nil
+	}
+
+	var allErrs field.ErrorList
+	if form == nil {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("spec").Child("issueForm"), name,
+			fmt.Sprintf("%s/%s has no such issue form", owner, repo)))
+	} else {
+		for _, label := range issueform.Missing(defaultBody(githubIssue.Spec), form.RequiredLabels()) {
+			allErrs = append(allErrs, field.Required(field.NewPath("spec").Child("description"),
+				fmt.Sprintf("issue form %s requires an answer under a \"### %s\" heading", name, label)))
+		}
+	}
+	if len(allErrs) == 0 {
+		return nil, nil
+	}
+	return nil, apierrors.NewInvalid(
+		schema.GroupKind{Group: GroupVersion.Group, Kind: "GithubIssue"},
+		githubIssue.Name,
+		allErrs,
+	)
+}
+
 // ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
 func (r *GithubIssue) ValidateUpdate(old runtime.Object) (admission.Warnings, error) {
 	githubissuelog.Info("validate update", "name", r.Name)
 
+	// metadata-only updates, e.g. the controller managing finalizers and annotations,
+	// aren't held back by a spec that predates the current validation rules. They are still
+	// admitted by the webhooks, so unlike status writes they fail while those are unavailable.
+	if oldIssue, ok := old.(*GithubIssue); ok && equality.Semantic.DeepEqual(oldIssue.Spec, r.Spec) {
+		return nil, nil
+	}
+
 	if err := validateGithubIssue(r); err != nil {
 		return nil, err
 	}
 
-	return nil, nil
+	warnings, err := validateIssueForm(r)
+	return append(warnings, lintDescription(r)...), err
+}
+
+// lintDescription warns about the structural markdown errors of the description and of the
+// body of every locale, each warning naming the field it was found in
+func lintDescription(githubIssue *GithubIssue) admission.Warnings {
+	if !webhookOptions.LintMarkdown {
+		return nil
+	}
+	var warnings admission.Warnings
+	for _, b := range bodies(githubIssue.Spec) {
+		for _, problem := range mdlint.Check(b.text) {
+			warnings = append(warnings, b.path.String()+" "+problem.String())
+		}
+	}
+	return warnings
 }
 
 // ValidateDelete implements webhook.Validator so a webhook will be registered for the type
@@ -17,9 +17,68 @@ limitations under the License.
 package v1
 
 import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
 	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/oshribelay/github-issue-operator/internal/issueform"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/yaml"
 )
 
+// stubRepoChecker answers repo checks with exists or err, after delay unless the context is done first
+type stubRepoChecker struct {
+	exists  bool
+	err     error
+	delay   time.Duration
+	checked []string
+}
+
+func (c *stubRepoChecker) RepoExists(ctx context.Context, owner, repo string) (bool, error) {
+	c.checked = append(c.checked, owner+"/"+repo)
+	select {
+	case <-time.After(c.delay):
+		return c.exists, c.err
+	case <-ctx.Done():
+		return false, ctx.Err()
+	}
+}
+
+// stubFormFetcher serves the issue forms it holds, keyed by owner/repo/name, or fails with err
+type stubFormFetcher struct {
+	forms map[string]string
+	err   error
+}
+
+func (f *stubFormFetcher) IssueForm(_ context.Context, owner, repo, name string) ([]byte, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	form, ok := f.forms[owner+"/"+repo+"/"+name]
+	if !ok {
+		return nil, nil
+	}
+	return []byte(form), nil
+}
+
+func newTestIssue(spec GithubIssueSpec) *GithubIssue {
+	return &GithubIssue{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-issue", Namespace: "default"},
+		Spec:       spec,
+	}
+}
+
 var _ = Describe("GithubIssue Webhook", func() {
 
 	Context("When creating GithubIssue under Defaulting Webhook", func() {
@@ -42,4 +101,1276 @@ var _ = Describe("GithubIssue Webhook", func() {
 		})
 	})
 
+	Context("When the operator truncates long bodies", func() {
+		longBody := GithubIssueSpec{
+			Repo:        "https://github.com/owner/repo",
+			Title:       "Test Title",
+			Description: strings.Repeat("a", 257),
+		}
+
+		AfterEach(func() {
+			SetWebhookOptions(WebhookOptions{})
+		})
+
+		It("Should deny a long description by default", func() {
+			_, err := newTestIssue(longBody).ValidateCreate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("description must not be longer than 256 characters"))
+		})
+
+		It("Should deny a long body of any locale by default", func() {
+			_, err := newTestIssue(GithubIssueSpec{
+				Repo:          "https://github.com/owner/repo",
+				Title:         "Test Title",
+				Bodies:        map[string]string{"en": "Disk full", "ja": strings.Repeat("a", 257)},
+				DefaultLocale: "en",
+			}).ValidateCreate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.bodies[ja]: Invalid value"))
+			Expect(err.Error()).To(ContainSubstring("body of ja must not be longer than 256 characters"))
+		})
+
+		It("Should admit a long description when truncation is enabled", func() {
+			SetWebhookOptions(WebhookOptions{TruncateBody: true})
+			_, err := newTestIssue(longBody).ValidateCreate()
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Context("When validating blocking issues", func() {
+		It("Should deny non-positive issue numbers", func() {
+			_, err := newTestIssue(GithubIssueSpec{
+				Repo:      "https://github.com/owner/repo",
+				Title:     "Test Title",
+				BlockedBy: []int{4, 0},
+			}).ValidateCreate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.blockedBy[1]"))
+		})
+
+		It("Should admit positive issue numbers", func() {
+			_, err := newTestIssue(GithubIssueSpec{
+				Repo:      "https://github.com/owner/repo",
+				Title:     "Test Title",
+				BlockedBy: []int{4, 9},
+			}).ValidateCreate()
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Context("When scanning descriptions for secrets", func() {
+		token := "ghp_" + strings.Repeat("A", 36)
+
+		AfterEach(func() {
+			SetWebhookOptions(WebhookOptions{})
+		})
+
+		It("Should deny a description containing a GitHub token, without echoing it", func() {
+			SetWebhookOptions(WebhookOptions{ScanSecrets: true})
+			_, err := newTestIssue(GithubIssueSpec{
+				Repo:        "https://github.com/owner/repo",
+				Title:       "Test Title",
+				Description: "token: " + token,
+			}).ValidateCreate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("GitHub token at characters 7-47"))
+			Expect(err.Error()).NotTo(ContainSubstring(token))
+		})
+
+		It("Should admit a clean description", func() {
+			SetWebhookOptions(WebhookOptions{ScanSecrets: true})
+			_, err := newTestIssue(GithubIssueSpec{
+				Repo:        "https://github.com/owner/repo",
+				Title:       "Test Title",
+				Description: "nothing to see here",
+			}).ValidateCreate()
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should not scan when disabled", func() {
+			_, err := newTestIssue(GithubIssueSpec{
+				Repo:        "https://github.com/owner/repo",
+				Title:       "Test Title",
+				Description: token,
+			}).ValidateCreate()
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should deny a token hidden in the body of a locale other than the default", func() {
+			SetWebhookOptions(WebhookOptions{ScanSecrets: true})
+			_, err := newTestIssue(GithubIssueSpec{
+				Repo:          "https://github.com/owner/repo",
+				Title:         "Test Title",
+				Bodies:        map[string]string{"en": "Disk full", "fr": "Disque plein, jeton " + token},
+				DefaultLocale: "en",
+			}).ValidateCreate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.bodies[fr]: Forbidden: body of fr contains what looks like a GitHub token"))
+			Expect(err.Error()).NotTo(ContainSubstring(token))
+		})
+	})
+
+	Context("When validating the category", func() {
+		BeforeEach(func() {
+			SetWebhookOptions(WebhookOptions{Categories: []string{"bug", "feature", "chore"}})
+		})
+
+		AfterEach(func() {
+			SetWebhookOptions(WebhookOptions{})
+		})
+
+		It("Should admit a configured category", func() {
+			_, err := newTestIssue(GithubIssueSpec{
+				Repo:     "https://github.com/owner/repo",
+				Title:    "Test Title",
+				Category: "bug",
+			}).ValidateCreate()
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should deny a category that isn't configured", func() {
+			_, err := newTestIssue(GithubIssueSpec{
+				Repo:     "https://github.com/owner/repo",
+				Title:    "Test Title",
+				Category: "question",
+			}).ValidateCreate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(`spec.category: Unsupported value: "question"`))
+		})
+	})
+
+	Context("When the description contains control characters", func() {
+		spec := GithubIssueSpec{
+			Repo:        "https://github.com/owner/repo",
+			Title:       "Test Title",
+			Description: "line\x00one\r\n\tline\x1btwo\n",
+		}
+
+		AfterEach(func() {
+			SetWebhookOptions(WebhookOptions{})
+		})
+
+		It("Should admit them by default", func() {
+			_, err := newTestIssue(spec).ValidateCreate()
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should reject them listing their positions", func() {
+			SetWebhookOptions(WebhookOptions{ControlCharacters: ControlCharactersReject})
+			_, err := newTestIssue(spec).ValidateCreate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.description"))
+			Expect(err.Error()).To(ContainSubstring("at characters 4 (U+0000), 15 (U+001B)"))
+		})
+
+		It("Should admit newlines, carriage returns and tabs when rejecting", func() {
+			SetWebhookOptions(WebhookOptions{ControlCharacters: ControlCharactersReject})
+			_, err := newTestIssue(GithubIssueSpec{
+				Repo:        "https://github.com/owner/repo",
+				Title:       "Test Title",
+				Description: "line one\r\n\tline two\n",
+			}).ValidateCreate()
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should strip them when defaulting", func() {
+			SetWebhookOptions(WebhookOptions{ControlCharacters: ControlCharactersStrip})
+			githubIssue := newTestIssue(spec)
+			githubIssue.Default()
+			Expect(githubIssue.Spec.Description).To(Equal("lineone\r\n\tlinetwo\n"))
+		})
+
+		It("Should check the bodies of every locale alike", func() {
+			localized := GithubIssueSpec{
+				Repo:          "https://github.com/owner/repo",
+				Title:         "Test Title",
+				Bodies:        map[string]string{"en": "Disk full", "de": "Festplatte\x00voll"},
+				DefaultLocale: "en",
+			}
+			SetWebhookOptions(WebhookOptions{ControlCharacters: ControlCharactersReject})
+			_, err := newTestIssue(localized).ValidateCreate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.bodies[de]"))
+
+			SetWebhookOptions(WebhookOptions{ControlCharacters: ControlCharactersStrip})
+			githubIssue := newTestIssue(localized)
+			githubIssue.Default()
+			Expect(githubIssue.Spec.Bodies).To(HaveKeyWithValue("de", "Festplattevoll"))
+		})
+	})
+
+	Context("When validating the footer", func() {
+		It("Should deny a footer that isn't a valid template", func() {
+			_, err := newTestIssue(GithubIssueSpec{
+				Repo:   "https://github.com/owner/repo",
+				Title:  "Test Title",
+				Footer: "Tracked as #{{ .IssueNumber }",
+			}).ValidateCreate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.footer"))
+		})
+
+		It("Should deny a footer using a field the issue doesn't have", func() {
+			_, err := newTestIssue(GithubIssueSpec{
+				Repo:   "https://github.com/owner/repo",
+				Title:  "Test Title",
+				Footer: "Tracked as {{ .Foo }}",
+			}).ValidateCreate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.footer"))
+			Expect(err.Error()).To(ContainSubstring("can't evaluate field Foo"))
+		})
+
+		It("Should admit a footer using the fields of the issue", func() {
+			_, err := newTestIssue(GithubIssueSpec{
+				Repo:   "https://github.com/owner/repo",
+				Title:  "Test Title",
+				Footer: "Tracked as #{{ .IssueNumber }} at {{ .IssueURL }}",
+			}).ValidateCreate()
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Context("When validating the notify URL", func() {
+		BeforeEach(func() {
+			SetWebhookOptions(WebhookOptions{AllowedNotifyHosts: []string{"hooks.example.com"}})
+		})
+
+		AfterEach(func() {
+			SetWebhookOptions(WebhookOptions{})
+		})
+
+		It("Should deny a notify URL that isn't https", func() {
+			_, err := newTestIssue(GithubIssueSpec{
+				Repo:      "https://github.com/owner/repo",
+				Title:     "Test Title",
+				NotifyURL: "http://hooks.example.com/issues",
+			}).ValidateCreate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.notifyURL"))
+		})
+
+		It("Should admit an https notify URL", func() {
+			_, err := newTestIssue(GithubIssueSpec{
+				Repo:      "https://github.com/owner/repo",
+				Title:     "Test Title",
+				NotifyURL: "https://hooks.example.com/issues",
+			}).ValidateCreate()
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should deny a notify URL on a host that isn't allowed", func() {
+			_, err := newTestIssue(GithubIssueSpec{
+				Repo:      "https://github.com/owner/repo",
+				Title:     "Test Title",
+				NotifyURL: "https://kubernetes.default.svc/api",
+			}).ValidateCreate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.notifyURL: Forbidden: host kubernetes.default.svc isn't one of the notify hosts"))
+		})
+	})
+
+	Context("When validating templated labels", func() {
+		It("Should admit labels rendering with the metadata of the GithubIssue", func() {
+			_, err := newTestIssue(GithubIssueSpec{
+				Repo:   "https://github.com/owner/repo",
+				Title:  "Test Title",
+				Labels: []string{"bug", "ns/{{ .Namespace }}", "cr/{{ .Name }}"},
+			}).ValidateCreate()
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should deny labels that don't render into labels GitHub accepts", func() {
+			for _, label := range []string{"ns/{{ .Namespace", "{{ .Cluster }}", "{{ .Name }}-" + strings.Repeat("x", 40)} {
+				_, err := newTestIssue(GithubIssueSpec{
+					Repo:   "https://github.com/owner/repo",
+					Title:  "Test Title",
+					Labels: []string{label},
+				}).ValidateCreate()
+				Expect(err).To(HaveOccurred(), label)
+				Expect(err.Error()).To(ContainSubstring("spec.labels: Invalid value"))
+			}
+		})
+	})
+
+	Context("When validating the approval label", func() {
+		It("Should deny an approval label listed in labels", func() {
+			_, err := newTestIssue(GithubIssueSpec{
+				Repo:          "https://github.com/owner/repo",
+				Title:         "Test Title",
+				Labels:        []string{"bug", "awaiting-approval"},
+				ApprovalLabel: "awaiting-approval",
+			}).ValidateCreate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.approvalLabel"))
+		})
+
+		It("Should admit an approval label apart from the labels", func() {
+			_, err := newTestIssue(GithubIssueSpec{
+				Repo:          "https://github.com/owner/repo",
+				Title:         "Test Title",
+				Labels:        []string{"bug"},
+				ApprovalLabel: "awaiting-approval",
+			}).ValidateCreate()
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Context("When validating the closing reference", func() {
+		It("Should admit a commit SHA and a pull request URL", func() {
+			for _, closedBy := range []string{"1a2b3c4", "https://github.com/owner/repo/pull/7"} {
+				_, err := newTestIssue(GithubIssueSpec{
+					Repo:     "https://github.com/owner/repo",
+					Title:    "Test Title",
+					ClosedBy: closedBy,
+				}).ValidateCreate()
+				Expect(err).NotTo(HaveOccurred())
+			}
+		})
+
+		It("Should deny a reference that is neither a commit SHA nor a pull request URL", func() {
+			for _, closedBy := range []string{"main", "1A2B3C4", "https://github.com/owner/repo/issues/7", "http://github.com/owner/repo/pull/7"} {
+				_, err := newTestIssue(GithubIssueSpec{
+					Repo:     "https://github.com/owner/repo",
+					Title:    "Test Title",
+					ClosedBy: closedBy,
+				}).ValidateCreate()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("spec.closedBy"))
+			}
+		})
+	})
+
+	Context("When validating the severity", func() {
+		It("Should admit a supported severity", func() {
+			_, err := newTestIssue(GithubIssueSpec{
+				Repo:     "https://github.com/owner/repo",
+				Title:    "Test Title",
+				Severity: SeveritySev1,
+			}).ValidateCreate()
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should deny an unknown severity", func() {
+			_, err := newTestIssue(GithubIssueSpec{
+				Repo:     "https://github.com/owner/repo",
+				Title:    "Test Title",
+				Severity: "sev0",
+			}).ValidateCreate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(`spec.severity: Unsupported value: "sev0"`))
+		})
+	})
+
+	Context("When validating the repository URL", func() {
+		It("Should deny a URL passing the format check the owner and repo can't be extracted from", func() {
+			for _, repo := range []string{
+				"https://user@github.com/owner/repo",
+				"https://github.com/owner/repo?tab=issues",
+			} {
+				_, err := newTestIssue(GithubIssueSpec{Repo: repo, Title: "Test Title"}).ValidateCreate()
+				Expect(err).To(HaveOccurred(), repo)
+				Expect(err.Error()).To(ContainSubstring("spec.repo"))
+			}
+		})
+
+		It("Should deny a URL with extra path segments", func() {
+			_, err := newTestIssue(GithubIssueSpec{
+				Repo:  "https://github.com/owner/repo/extra",
+				Title: "Test Title",
+			}).ValidateCreate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("repository URL must be in the format"))
+		})
+	})
+
+	Context("When validating a security advisory", func() {
+		It("Should deny an advisory without a severity", func() {
+			_, err := newTestIssue(GithubIssueSpec{
+				Repo:             "https://github.com/owner/repo",
+				Title:            "Test Title",
+				SecurityAdvisory: true,
+			}).ValidateCreate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.advisorySeverity: Required value"))
+		})
+
+		It("Should admit an advisory with a severity", func() {
+			_, err := newTestIssue(GithubIssueSpec{
+				Repo:             "https://github.com/owner/repo",
+				Title:            "Test Title",
+				SecurityAdvisory: true,
+				AdvisorySeverity: "high",
+			}).ValidateCreate()
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Context("When validating the linked resource", func() {
+		AfterEach(func() {
+			SetWebhookOptions(WebhookOptions{})
+		})
+
+		It("Should admit a resource of an allowed kind in the namespace of the GithubIssue", func() {
+			SetWebhookOptions(WebhookOptions{LinkedKinds: []schema.GroupKind{{Group: "apps", Kind: "Deployment"}}})
+			_, err := newTestIssue(GithubIssueSpec{
+				Repo:           "https://github.com/owner/repo",
+				Title:          "Test Title",
+				LinkedResource: &LinkedResource{APIVersion: "apps/v1", Kind: "Deployment", Name: "web", Namespace: "default"},
+			}).ValidateCreate()
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should deny a resource in another namespace", func() {
+			_, err := newTestIssue(GithubIssueSpec{
+				Repo:           "https://github.com/owner/repo",
+				Title:          "Test Title",
+				LinkedResource: &LinkedResource{APIVersion: "apps/v1", Kind: "Deployment", Name: "web", Namespace: "kube-system"},
+			}).ValidateCreate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.linkedResource.namespace: Forbidden"))
+		})
+
+		It("Should deny kinds the operator doesn't read", func() {
+			SetWebhookOptions(WebhookOptions{LinkedKinds: []schema.GroupKind{{Group: "apps", Kind: "Deployment"}}})
+			_, err := newTestIssue(GithubIssueSpec{
+				Repo:           "https://github.com/owner/repo",
+				Title:          "Test Title",
+				LinkedResource: &LinkedResource{APIVersion: "v1", Kind: "Secret", Name: "token"},
+			}).ValidateCreate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.linkedResource.kind: Unsupported value"))
+		})
+	})
+
+	Context("When validating the reopen window", func() {
+		linkedResource := &LinkedResource{APIVersion: "apps/v1", Kind: "Deployment", Name: "web"}
+
+		It("Should admit a window of an issue following a linked resource", func() {
+			_, err := newTestIssue(GithubIssueSpec{
+				Repo:           "https://github.com/owner/repo",
+				Title:          "Test Title",
+				LinkedResource: linkedResource,
+				ReopenWithin:   &metav1.Duration{Duration: 24 * time.Hour},
+			}).ValidateCreate()
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should deny a window that isn't positive", func() {
+			_, err := newTestIssue(GithubIssueSpec{
+				Repo:           "https://github.com/owner/repo",
+				Title:          "Test Title",
+				LinkedResource: linkedResource,
+				ReopenWithin:   &metav1.Duration{},
+			}).ValidateCreate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.reopenWithin: Invalid value"))
+		})
+
+		It("Should deny a window without a linked resource", func() {
+			_, err := newTestIssue(GithubIssueSpec{
+				Repo:         "https://github.com/owner/repo",
+				Title:        "Test Title",
+				ReopenWithin: &metav1.Duration{Duration: time.Hour},
+			}).ValidateCreate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.reopenWithin: Forbidden"))
+		})
+	})
+
+	Context("When validating the retention after close", func() {
+		It("Should admit a positive retention", func() {
+			_, err := newTestIssue(GithubIssueSpec{
+				Repo:                "https://github.com/owner/repo",
+				Title:               "Test Title",
+				RetentionAfterClose: &metav1.Duration{Duration: 30 * 24 * time.Hour},
+			}).ValidateCreate()
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should deny a retention that isn't positive", func() {
+			_, err := newTestIssue(GithubIssueSpec{
+				Repo:                "https://github.com/owner/repo",
+				Title:               "Test Title",
+				RetentionAfterClose: &metav1.Duration{Duration: -time.Hour},
+			}).ValidateCreate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.retentionAfterClose: Invalid value"))
+		})
+	})
+
+	Context("When validating the initial comments", func() {
+		AfterEach(func() {
+			SetWebhookOptions(WebhookOptions{})
+		})
+
+		It("Should admit initial comments", func() {
+			_, err := newTestIssue(GithubIssueSpec{
+				Repo:            "https://github.com/owner/repo",
+				Title:           "Test Title",
+				InitialComments: []string{"Runbook: https://runbooks.example.com/disk", "Escalate to @oncall"},
+			}).ValidateCreate()
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should deny blank initial comments", func() {
+			_, err := newTestIssue(GithubIssueSpec{
+				Repo:            "https://github.com/owner/repo",
+				Title:           "Test Title",
+				InitialComments: []string{"Runbook", " \n"},
+			}).ValidateCreate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.initialComments[1]: Required value"))
+		})
+
+		It("Should scan them for secrets", func() {
+			token := "ghp_" + strings.Repeat("A", 36)
+			SetWebhookOptions(WebhookOptions{ScanSecrets: true})
+			_, err := newTestIssue(GithubIssueSpec{
+				Repo:            "https://github.com/owner/repo",
+				Title:           "Test Title",
+				InitialComments: []string{"Runbook", "token: " + token},
+			}).ValidateCreate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.initialComments[1]: Forbidden: initial comment 1 contains what looks like a GitHub token"))
+			Expect(err.Error()).NotTo(ContainSubstring(token))
+		})
+
+		It("Should reject or strip their control characters", func() {
+			spec := GithubIssueSpec{
+				Repo:            "https://github.com/owner/repo",
+				Title:           "Test Title",
+				InitialComments: []string{"Runbook\x1b[31m", "Escalate"},
+			}
+
+			SetWebhookOptions(WebhookOptions{ControlCharacters: ControlCharactersReject})
+			_, err := newTestIssue(spec).ValidateCreate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.initialComments[0]"))
+
+			SetWebhookOptions(WebhookOptions{ControlCharacters: ControlCharactersStrip})
+			githubIssue := newTestIssue(spec)
+			githubIssue.Default()
+			Expect(githubIssue.Spec.InitialComments).To(Equal([]string{"Runbook[31m", "Escalate"}))
+		})
+	})
+
+	Context("When validating bodies in several languages", func() {
+		bodies := map[string]string{"en": "Disk full", "pt-BR": "Disco cheio"}
+
+		It("Should admit bodies keyed by locale with a default locale", func() {
+			_, err := newTestIssue(GithubIssueSpec{
+				Repo:          "https://github.com/owner/repo",
+				Title:         "Test Title",
+				Bodies:        bodies,
+				DefaultLocale: "pt-BR",
+				AppendLocales: true,
+			}).ValidateCreate()
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should deny keys that aren't locales", func() {
+			_, err := newTestIssue(GithubIssueSpec{
+				Repo:          "https://github.com/owner/repo",
+				Title:         "Test Title",
+				Bodies:        map[string]string{"en": "Disk full", "English": "Disk full", "pt_BR": "Disco cheio"},
+				DefaultLocale: "en",
+			}).ValidateCreate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.bodies[English]: Invalid value"))
+			Expect(err.Error()).To(ContainSubstring("spec.bodies[pt_BR]: Invalid value"))
+		})
+
+		It("Should deny a default locale without a body", func() {
+			_, err := newTestIssue(GithubIssueSpec{
+				Repo:   "https://github.com/owner/repo",
+				Title:  "Test Title",
+				Bodies: bodies,
+			}).ValidateCreate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.defaultLocale: Required value"))
+
+			_, err = newTestIssue(GithubIssueSpec{
+				Repo:          "https://github.com/owner/repo",
+				Title:         "Test Title",
+				Bodies:        bodies,
+				DefaultLocale: "fr",
+			}).ValidateCreate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.defaultLocale: Not found"))
+		})
+
+		It("Should deny a description along with bodies", func() {
+			_, err := newTestIssue(GithubIssueSpec{
+				Repo:          "https://github.com/owner/repo",
+				Title:         "Test Title",
+				Description:   "Disk full",
+				Bodies:        bodies,
+				DefaultLocale: "en",
+			}).ValidateCreate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.description: Forbidden"))
+		})
+
+		It("Should deny locale settings without bodies", func() {
+			_, err := newTestIssue(GithubIssueSpec{
+				Repo:          "https://github.com/owner/repo",
+				Title:         "Test Title",
+				DefaultLocale: "en",
+				AppendLocales: true,
+			}).ValidateCreate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.defaultLocale: Forbidden"))
+			Expect(err.Error()).To(ContainSubstring("spec.appendLocales: Forbidden"))
+		})
+	})
+
+	Context("When validating the milestone", func() {
+		It("Should admit creating a milestone with a title", func() {
+			_, err := newTestIssue(GithubIssueSpec{
+				Repo:                     "https://github.com/owner/repo",
+				Title:                    "Test Title",
+				Milestone:                "v2.0",
+				CreateMilestoneIfMissing: true,
+			}).ValidateCreate()
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should deny creating a milestone without a title", func() {
+			_, err := newTestIssue(GithubIssueSpec{
+				Repo:                     "https://github.com/owner/repo",
+				Title:                    "Test Title",
+				CreateMilestoneIfMissing: true,
+			}).ValidateCreate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.milestone: Required value"))
+		})
+	})
+
+	Context("When validating assignees", func() {
+		It("Should admit valid GitHub logins", func() {
+			_, err := newTestIssue(GithubIssueSpec{
+				Repo:      "https://github.com/owner/repo",
+				Title:     "Test Title",
+				Assignees: []string{"octocat", "some-user", "a1", strings.Repeat("a", 39)},
+			}).ValidateCreate()
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should deny malformed logins", func() {
+			_, err := newTestIssue(GithubIssueSpec{
+				Repo:      "https://github.com/owner/repo",
+				Title:     "Test Title",
+				Assignees: []string{"octocat", "-leading", "under_score", "", strings.Repeat("a", 40)},
+			}).ValidateCreate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).NotTo(ContainSubstring("spec.assignees[0]"))
+			Expect(err.Error()).To(ContainSubstring(`spec.assignees[1]: Invalid value: "-leading"`))
+			Expect(err.Error()).To(ContainSubstring(`spec.assignees[2]: Invalid value: "under_score"`))
+			Expect(err.Error()).To(ContainSubstring("spec.assignees[3]"))
+			Expect(err.Error()).To(ContainSubstring("spec.assignees[4]"))
+		})
+	})
+
+	Context("When assigning from a team", func() {
+		It("Should admit an org/team-slug reference", func() {
+			_, err := newTestIssue(GithubIssueSpec{
+				Repo:           "https://github.com/owner/repo",
+				Title:          "Test Title",
+				AssignFromTeam: "my-org/on-call_team",
+			}).ValidateCreate()
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should deny malformed team references", func() {
+			for _, team := range []string{"on-call", "my-org/", "/on-call", "my-org/on-call/x", "my-org/On Call"} {
+				_, err := newTestIssue(GithubIssueSpec{
+					Repo:           "https://github.com/owner/repo",
+					Title:          "Test Title",
+					AssignFromTeam: team,
+				}).ValidateCreate()
+				Expect(err).To(HaveOccurred(), team)
+				Expect(err.Error()).To(ContainSubstring("spec.assignFromTeam: Invalid value"))
+			}
+		})
+	})
+
+	Context("When validating front-matter", func() {
+		It("Should admit well-formed front-matter", func() {
+			_, err := newTestIssue(GithubIssueSpec{
+				Repo:        "https://github.com/owner/repo",
+				Title:       "Test Title",
+				Description: "---\nlabels: [incident]\n---\nThe body",
+				FrontMatter: true,
+			}).ValidateCreate()
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should deny malformed front-matter", func() {
+			_, err := newTestIssue(GithubIssueSpec{
+				Repo:        "https://github.com/owner/repo",
+				Title:       "Test Title",
+				Description: "---\nlabels: [incident\nThe body",
+				FrontMatter: true,
+			}).ValidateCreate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("invalid front-matter"))
+		})
+
+		It("Should deny front-matter assignees that aren't GitHub logins", func() {
+			_, err := newTestIssue(GithubIssueSpec{
+				Repo:        "https://github.com/owner/repo",
+				Title:       "Test Title",
+				Description: "---\nassignees: [octocat, -bad]\n---\nThe body",
+				FrontMatter: true,
+			}).ValidateCreate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.description[front-matter].assignees[1]: Invalid value"))
+		})
+
+		It("Should ignore front-matter unless parsing is enabled", func() {
+			_, err := newTestIssue(GithubIssueSpec{
+				Repo:        "https://github.com/owner/repo",
+				Title:       "Test Title",
+				Description: "---\nlabels: [incident\nThe body",
+			}).ValidateCreate()
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Context("When the controller writes the status", func() {
+		It("Should register neither webhook for the status subresource", func() {
+			manifests, err := os.ReadFile(filepath.Join("..", "..", "config", "webhook", "manifests.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+
+			resources := map[string][]string{}
+			for _, doc := range strings.Split(string(manifests), "\n---\n") {
+				typeMeta := &metav1.TypeMeta{}
+				Expect(yaml.Unmarshal([]byte(doc), typeMeta)).To(Succeed())
+				switch typeMeta.Kind {
+				case "MutatingWebhookConfiguration":
+					config := &admissionregistrationv1.MutatingWebhookConfiguration{}
+					Expect(yaml.Unmarshal([]byte(doc), config)).To(Succeed())
+					for _, webhook := range config.Webhooks {
+						for _, rule := range webhook.Rules {
+							resources[typeMeta.Kind] = append(resources[typeMeta.Kind], rule.Resources...)
+						}
+					}
+				case "ValidatingWebhookConfiguration":
+					config := &admissionregistrationv1.ValidatingWebhookConfiguration{}
+					Expect(yaml.Unmarshal([]byte(doc), config)).To(Succeed())
+					for _, webhook := range config.Webhooks {
+						for _, rule := range webhook.Rules {
+							resources[typeMeta.Kind] = append(resources[typeMeta.Kind], rule.Resources...)
+						}
+					}
+				}
+			}
+			Expect(resources).To(Equal(map[string][]string{
+				"MutatingWebhookConfiguration":   {"githubissues"},
+				"ValidatingWebhookConfiguration": {"githubissues"},
+			}))
+		})
+
+		It("Should admit a metadata-only update of a spec that no longer validates", func() {
+			oldIssue := newTestIssue(GithubIssueSpec{
+				Repo:  "https://github.com/owner/repo/extra",
+				Title: "Test Title",
+			})
+			oldIssue.Finalizers = []string{"finalizer"}
+			_, err := oldIssue.ValidateCreate()
+			Expect(err).To(HaveOccurred())
+
+			newIssue := oldIssue.DeepCopy()
+			newIssue.Finalizers = nil
+			newIssue.Status.IssueNumber = 4
+
+			_, err = newIssue.ValidateUpdate(oldIssue)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should still validate spec updates", func() {
+			oldIssue := newTestIssue(GithubIssueSpec{
+				Repo:  "https://github.com/owner/repo",
+				Title: "Test Title",
+			})
+			newIssue := oldIssue.DeepCopy()
+			newIssue.Spec.Repo = "not-a-url"
+
+			_, err := newIssue.ValidateUpdate(oldIssue)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("Should write the status of a GithubIssue whose spec no longer validates", func() {
+			SetWebhookOptions(WebhookOptions{Categories: []string{"bug"}})
+			DeferCleanup(SetWebhookOptions, WebhookOptions{})
+
+			issue := &GithubIssue{
+				ObjectMeta: metav1.ObjectMeta{Name: "status-only-update", Namespace: "default"},
+				Spec: GithubIssueSpec{
+					Repo:     "https://github.com/owner/repo",
+					Title:    "Test Title",
+					Category: "bug",
+				},
+			}
+			Expect(k8sClient.Create(ctx, issue)).To(Succeed())
+			DeferCleanup(k8sClient.Delete, ctx, issue)
+
+			By("dropping the category the spec uses")
+			SetWebhookOptions(WebhookOptions{})
+
+			specUpdate := issue.DeepCopy()
+			specUpdate.Spec.Title = "Other Title"
+			err := k8sClient.Update(ctx, specUpdate)
+			Expect(err).To(MatchError(ContainSubstring("spec.category")))
+
+			By("writing the status through the status subresource")
+			issue.Status.IssueNumber = 4
+			Expect(k8sClient.Status().Update(ctx, issue)).To(Succeed())
+
+			fetched := &GithubIssue{}
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(issue), fetched)).To(Succeed())
+			Expect(fetched.Status.IssueNumber).To(Equal(int32(4)))
+			Expect(fetched.Spec.Category).To(Equal("bug"))
+		})
+	})
+
+	Context("When overriding the API base URL", func() {
+		BeforeEach(func() {
+			SetWebhookOptions(WebhookOptions{AllowedAPIHosts: []string{"gateway.example.com"}})
+		})
+
+		AfterEach(func() {
+			SetWebhookOptions(WebhookOptions{})
+		})
+
+		It("Should admit an https URL with a path prefix", func() {
+			_, err := newTestIssue(GithubIssueSpec{
+				Repo:       "https://github.com/owner/repo",
+				Title:      "Test Title",
+				APIBaseURL: "https://gateway.example.com/github/api/v3",
+			}).ValidateCreate()
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should deny a URL that isn't https", func() {
+			_, err := newTestIssue(GithubIssueSpec{
+				Repo:       "https://github.com/owner/repo",
+				Title:      "Test Title",
+				APIBaseURL: "http://gateway.example.com/github",
+			}).ValidateCreate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.apiBaseURL"))
+		})
+
+		It("Should deny a URL with a query", func() {
+			_, err := newTestIssue(GithubIssueSpec{
+				Repo:       "https://github.com/owner/repo",
+				Title:      "Test Title",
+				APIBaseURL: "https://gateway.example.com/github?token=x",
+			}).ValidateCreate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.apiBaseURL"))
+		})
+
+		It("Should deny a host that isn't allowed, which would be sent the token", func() {
+			_, err := newTestIssue(GithubIssueSpec{
+				Repo:       "https://github.com/owner/repo",
+				Title:      "Test Title",
+				APIBaseURL: "https://attacker.example.net/api/v3",
+			}).ValidateCreate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.apiBaseURL: Forbidden"))
+
+			SetWebhookOptions(WebhookOptions{})
+			_, err = newTestIssue(GithubIssueSpec{
+				Repo:       "https://github.com/owner/repo",
+				Title:      "Test Title",
+				APIBaseURL: "https://gateway.example.com/github/api/v3",
+			}).ValidateCreate()
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("Should admit api.github.com without an allowlist", func() {
+			SetWebhookOptions(WebhookOptions{})
+			_, err := newTestIssue(GithubIssueSpec{
+				Repo:       "https://github.com/owner/repo",
+				Title:      "Test Title",
+				APIBaseURL: "https://api.github.com/",
+			}).ValidateCreate()
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Context("When several fields are invalid", func() {
+		// fieldsOf returns the fields of the causes reported by an Invalid error
+		fieldsOf := func(err error) []string {
+			var statusErr *apierrors.StatusError
+			Expect(errors.As(err, &statusErr)).To(BeTrue())
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+
+			var fields []string
+			for _, cause := range statusErr.Status().Details.Causes {
+				fields = append(fields, cause.Field)
+			}
+			return fields
+		}
+
+		It("Should report every invalid field of a new issue at once", func() {
+			_, err := newTestIssue(GithubIssueSpec{
+				Repo:  "http://github.com/owner/repo",
+				Title: "",
+			}).ValidateCreate()
+			Expect(err).To(HaveOccurred())
+			Expect(fieldsOf(err)).To(ConsistOf("spec.title", "spec.repo"))
+			Expect(err.Error()).To(ContainSubstring("title must not be empty"))
+			Expect(err.Error()).To(ContainSubstring("repository url should start with https"))
+		})
+
+		It("Should report every invalid field of an update at once", func() {
+			oldIssue := newTestIssue(GithubIssueSpec{
+				Repo:  "https://github.com/owner/repo",
+				Title: "Test Title",
+			})
+			newIssue := oldIssue.DeepCopy()
+			newIssue.Spec.Title = ""
+			newIssue.Spec.Repo = "https://gitlab.com/owner/repo"
+			newIssue.Spec.BlockedBy = []int{0}
+
+			_, err := newIssue.ValidateUpdate(oldIssue)
+			Expect(err).To(HaveOccurred())
+			Expect(fieldsOf(err)).To(ConsistOf("spec.title", "spec.repo", "spec.blockedBy[0]"))
+			Expect(err.Error()).To(ContainSubstring("title must not be empty"))
+			Expect(err.Error()).To(ContainSubstring("the host name of the repository should be github.com"))
+			Expect(err.Error()).To(ContainSubstring("issue number must be positive"))
+		})
+	})
+
+	Context("When validating the create window", func() {
+		It("Should admit a window in a known time zone", func() {
+			_, err := newTestIssue(GithubIssueSpec{
+				Repo:         "https://github.com/owner/repo",
+				Title:        "Test Title",
+				CreateWindow: &CreateWindow{Start: "09:00", End: "17:00", TimeZone: "America/New_York"},
+			}).ValidateCreate()
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should deny an unknown time zone and an empty window", func() {
+			_, err := newTestIssue(GithubIssueSpec{
+				Repo:         "https://github.com/owner/repo",
+				Title:        "Test Title",
+				CreateWindow: &CreateWindow{Start: "09:00", End: "09:00", TimeZone: "Mars/Olympus"},
+			}).ValidateCreate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.createWindow.timeZone"))
+			Expect(err.Error()).To(ContainSubstring("spec.createWindow.end"))
+		})
+	})
+
+	Context("When validating attachments", func() {
+		It("Should admit https links", func() {
+			_, err := newTestIssue(GithubIssueSpec{
+				Repo:        "https://github.com/owner/repo",
+				Title:       "Test Title",
+				Attachments: []Attachment{{Title: "Runbook", URL: "https://runbooks.example.com/api"}},
+			}).ValidateCreate()
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should deny links that aren't https", func() {
+			_, err := newTestIssue(GithubIssueSpec{
+				Repo:  "https://github.com/owner/repo",
+				Title: "Test Title",
+				Attachments: []Attachment{
+					{Title: "Runbook", URL: "https://runbooks.example.com/api"},
+					{Title: "Grafana", URL: "http://grafana.example.com"},
+					{Title: "Script", URL: "javascript:alert(1)"},
+				},
+			}).ValidateCreate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.attachments[1].url"))
+			Expect(err.Error()).To(ContainSubstring("spec.attachments[2].url"))
+			Expect(err.Error()).NotTo(ContainSubstring("spec.attachments[0].url"))
+		})
+	})
+
+	Context("When validating the successor of a superseded issue", func() {
+		It("Should admit a successor in another repository", func() {
+			_, err := newTestIssue(GithubIssueSpec{
+				Repo:         "https://github.com/owner/repo",
+				Title:        "Test Title",
+				SupersededBy: &IssueReference{Repo: "https://github.com/owner/new-repo", Number: 3},
+			}).ValidateCreate()
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should deny a successor combined with DuplicateOf or in an invalid repository", func() {
+			_, err := newTestIssue(GithubIssueSpec{
+				Repo:         "https://github.com/owner/repo",
+				Title:        "Test Title",
+				DuplicateOf:  2,
+				SupersededBy: &IssueReference{Repo: "https://gitlab.com/owner/repo", Number: 3},
+			}).ValidateCreate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.supersededBy: Forbidden"))
+			Expect(err.Error()).To(ContainSubstring("spec.supersededBy.repo"))
+		})
+	})
+
+	Context("When the namespace sets a default repo", func() {
+		defaults := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "github-issue-defaults", Namespace: "default"},
+			Data:       map[string]string{DefaultRepoKey: "https://github.com/owner/default-repo"},
+		}
+
+		AfterEach(func() {
+			SetWebhookOptions(WebhookOptions{})
+		})
+
+		It("Should inherit the default repo when the spec omits it", func() {
+			SetWebhookOptions(WebhookOptions{
+				DefaultsConfigMap: "github-issue-defaults",
+				Reader:            fake.NewClientBuilder().WithObjects(defaults).Build(),
+			})
+			githubIssue := newTestIssue(GithubIssueSpec{Title: "Test Title"})
+			githubIssue.Default()
+			Expect(githubIssue.Spec.Repo).To(Equal("https://github.com/owner/default-repo"))
+
+			_, err := githubIssue.ValidateCreate()
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should keep the repo set in the spec", func() {
+			SetWebhookOptions(WebhookOptions{
+				DefaultsConfigMap: "github-issue-defaults",
+				Reader:            fake.NewClientBuilder().WithObjects(defaults).Build(),
+			})
+			githubIssue := newTestIssue(GithubIssueSpec{Repo: "https://github.com/owner/repo", Title: "Test Title"})
+			githubIssue.Default()
+			Expect(githubIssue.Spec.Repo).To(Equal("https://github.com/owner/repo"))
+		})
+
+		It("Should deny a missing repo when the namespace has no default", func() {
+			SetWebhookOptions(WebhookOptions{
+				DefaultsConfigMap: "github-issue-defaults",
+				Reader:            fake.NewClientBuilder().Build(),
+			})
+			githubIssue := newTestIssue(GithubIssueSpec{Title: "Test Title"})
+			githubIssue.Default()
+			Expect(githubIssue.Spec.Repo).To(BeEmpty())
+
+			_, err := githubIssue.ValidateCreate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.repo: Required value"))
+			Expect(err.Error()).To(ContainSubstring("github-issue-defaults"))
+		})
+	})
+
+	Context("When checking the repo exists at admission", func() {
+		AfterEach(func() {
+			SetWebhookOptions(WebhookOptions{})
+		})
+
+		It("Should admit a repo the admission token can access", func() {
+			checker := &stubRepoChecker{exists: true}
+			SetWebhookOptions(WebhookOptions{RepoChecker: checker})
+			warnings, err := newTestIssue(GithubIssueSpec{Repo: "https://github.com/owner/repo", Title: "Test Title"}).ValidateCreate()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(BeEmpty())
+			Expect(checker.checked).To(Equal([]string{"owner/repo"}))
+		})
+
+		It("Should deny a repo that doesn't exist or isn't accessible", func() {
+			SetWebhookOptions(WebhookOptions{RepoChecker: &stubRepoChecker{}})
+			_, err := newTestIssue(GithubIssueSpec{Repo: "https://github.com/owner/missing", Title: "Test Title"}).ValidateCreate()
+			Expect(err).To(HaveOccurred())
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.repo: Invalid value"))
+			Expect(err.Error()).To(ContainSubstring("not accessible with the admission token"))
+		})
+
+		It("Should admit with a warning when the check fails", func() {
+			SetWebhookOptions(WebhookOptions{RepoChecker: &stubRepoChecker{err: errors.New("bad gateway")}})
+			warnings, err := newTestIssue(GithubIssueSpec{Repo: "https://github.com/owner/repo", Title: "Test Title"}).ValidateCreate()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(ConsistOf(ContainSubstring("bad gateway")))
+		})
+
+		It("Should admit with a warning when the check times out", func() {
+			SetWebhookOptions(WebhookOptions{
+				RepoChecker:      &stubRepoChecker{exists: true, delay: time.Minute},
+				RepoCheckTimeout: 10 * time.Millisecond,
+			})
+			start := time.Now()
+			warnings, err := newTestIssue(GithubIssueSpec{Repo: "https://github.com/owner/repo", Title: "Test Title"}).ValidateCreate()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(ConsistOf(ContainSubstring("deadline exceeded")))
+			Expect(time.Since(start)).To(BeNumerically("<", time.Second))
+		})
+
+		It("Should not check the repo on updates or behind an API base URL override", func() {
+			checker := &stubRepoChecker{}
+			SetWebhookOptions(WebhookOptions{RepoChecker: checker, AllowedAPIHosts: []string{"github.example.com"}})
+			githubIssue := newTestIssue(GithubIssueSpec{Repo: "https://github.com/owner/repo", Title: "Test Title"})
+			_, err := githubIssue.ValidateUpdate(newTestIssue(GithubIssueSpec{Repo: "https://github.com/owner/repo", Title: "Old Title"}))
+			Expect(err).NotTo(HaveOccurred())
+
+			githubIssue.Spec.APIBaseURL = "https://github.example.com/api/v3/"
+			_, err = githubIssue.ValidateCreate()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(checker.checked).To(BeEmpty())
+		})
+	})
+
+	Context("When checking the description against an issue form", func() {
+		const bugReport = `
+name: Bug report
+body:
+  - type: textarea
+    attributes:
+      label: What happened?
+    validations:
+      required: true
+  - type: input
+    attributes:
+      label: Version
+    validations:
+      required: true
+  - type: textarea
+    attributes:
+      label: Logs
+`
+		formSpec := func(description string) GithubIssueSpec {
+			return GithubIssueSpec{
+				Repo:        "https://github.com/owner/repo",
+				Title:       "Test Title",
+				Description: description,
+				IssueForm:   "bug.yml",
+			}
+		}
+
+		BeforeEach(func() {
+			fetcher := &stubFormFetcher{forms: map[string]string{"owner/repo/bug.yml": bugReport}}
+			SetWebhookOptions(WebhookOptions{IssueForms: issueform.NewCache(fetcher, time.Minute)})
+		})
+
+		AfterEach(func() {
+			SetWebhookOptions(WebhookOptions{})
+		})
+
+		It("Should admit a description answering every required field", func() {
+			warnings, err := newTestIssue(formSpec("### What happened?\n\nIt crashed.\n\n### Version\n\n1.2.0")).ValidateCreate()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(BeEmpty())
+		})
+
+		It("Should deny a description missing required fields, naming each", func() {
+			_, err := newTestIssue(formSpec("### What happened?\n\nIt crashed.\n\n### Logs\n\nnone")).ValidateCreate()
+			Expect(err).To(HaveOccurred())
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring(`spec.description: Required value: issue form bug.yml requires an answer under a "### Version" heading`))
+			Expect(err.Error()).NotTo(ContainSubstring("What happened?"))
+		})
+
+		It("Should deny a required field left without a response", func() {
+			_, err := newTestIssue(formSpec("### What happened?\n\n_No response_\n\n### Version\n\n1.2.0")).ValidateCreate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(`"### What happened?" heading`))
+		})
+
+		It("Should check the description again when it is updated", func() {
+			old := newTestIssue(formSpec("### What happened?\n\nIt crashed.\n\n### Version\n\n1.2.0"))
+			_, err := newTestIssue(formSpec("It crashed.")).ValidateUpdate(old)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(`"### Version" heading`))
+		})
+
+		It("Should deny a form the repository lacks", func() {
+			spec := formSpec("It crashed.")
+			spec.IssueForm = "missing.yml"
+			_, err := newTestIssue(spec).ValidateCreate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.issueForm: Invalid value"))
+			Expect(err.Error()).To(ContainSubstring("owner/repo has no such issue form"))
+		})
+
+		It("Should deny a form name that isn't a YAML file name", func() {
+			spec := formSpec("It crashed.")
+			spec.IssueForm = "../config.yml"
+			_, err := newTestIssue(spec).ValidateCreate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("must be the file name of an issue form"))
+		})
+
+		It("Should admit with a warning when the form can't be fetched", func() {
+			fetcher := &stubFormFetcher{err: errors.New("bad gateway")}
+			SetWebhookOptions(WebhookOptions{IssueForms: issueform.NewCache(fetcher, time.Minute)})
+			warnings, err := newTestIssue(formSpec("It crashed.")).ValidateCreate()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(ConsistOf(ContainSubstring("bad gateway")))
+		})
+	})
+
+	Context("When linting the markdown of descriptions", func() {
+		AfterEach(func() {
+			SetWebhookOptions(WebhookOptions{})
+		})
+
+		It("Should admit a malformed code fence with a warning", func() {
+			SetWebhookOptions(WebhookOptions{LintMarkdown: true})
+			warnings, err := newTestIssue(GithubIssueSpec{
+				Repo:        "https://github.com/owner/repo",
+				Title:       "Test Title",
+				Description: "## Logs\n\n```\npanic: boom\n",
+			}).ValidateCreate()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(ConsistOf("spec.description line 3: code fence ``` is never closed, " +
+				"the rest of the description renders as code"))
+		})
+
+		It("Should lint the body of every locale, naming the locale", func() {
+			SetWebhookOptions(WebhookOptions{LintMarkdown: true})
+			warnings, err := newTestIssue(GithubIssueSpec{
+				Repo:          "https://github.com/owner/repo",
+				Title:         "Test Title",
+				Bodies:        map[string]string{"en": "## Logs\n\n```\npanic: boom\n```\n", "fr": "## Journaux\n\n```\npanic: boom\n"},
+				DefaultLocale: "en",
+				AppendLocales: true,
+			}).ValidateCreate()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(ConsistOf(HavePrefix("spec.bodies[fr] line 3: code fence ``` is never closed")))
+		})
+
+		It("Should not warn about clean markdown", func() {
+			SetWebhookOptions(WebhookOptions{LintMarkdown: true})
+			warnings, err := newTestIssue(GithubIssueSpec{
+				Repo:        "https://github.com/owner/repo",
+				Title:       "Test Title",
+				Description: "## Logs\n\n```\npanic: boom\n```\n\n| Pod | Restarts |\n| --- | --- |\n| web-1 | 3 |\n",
+			}).ValidateCreate()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(BeEmpty())
+		})
+
+		It("Should warn on updates changing the description", func() {
+			SetWebhookOptions(WebhookOptions{LintMarkdown: true})
+			old := newTestIssue(GithubIssueSpec{Repo: "https://github.com/owner/repo", Title: "Test Title"})
+			updated := old.DeepCopy()
+			updated.Spec.Description = "| Pod | Node | Restarts |\n| --- | --- |\n"
+			warnings, err := updated.ValidateUpdate(old)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(ConsistOf(ContainSubstring("table delimiter row has 2 columns")))
+		})
+
+		It("Should not lint when disabled", func() {
+			warnings, err := newTestIssue(GithubIssueSpec{
+				Repo:        "https://github.com/owner/repo",
+				Title:       "Test Title",
+				Description: "```\nunclosed",
+			}).ValidateCreate()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(BeEmpty())
+		})
+	})
 })
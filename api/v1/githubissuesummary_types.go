@@ -0,0 +1,146 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GithubIssueSummaryName is the name of the cluster-wide summary maintained by the operator
+const GithubIssueSummaryName = "cluster"
+
+// ManagedIssue states reported by the summary
+const (
+	IssueStateOpen    = "open"
+	IssueStateClosed  = "closed"
+	IssueStatePending = "pending"
+)
+
+// DefaultSummaryMaxIssues is how many issues the summary lists when maxIssues isn't set
+const DefaultSummaryMaxIssues = 100
+
+// GithubIssueSummarySpec defines the desired state of GithubIssueSummary, which GithubIssues
+// it counts and how many of them it lists
+type GithubIssueSummarySpec struct {
+	// Namespaces only counts the GithubIssues of these namespaces, every namespace when empty
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
+
+	// Repos only counts the GithubIssues of these repository URLs, every repository when empty
+	// +optional
+	Repos []string `json:"repos,omitempty"`
+
+	// States only counts the issues in these states, every state when empty
+	// +kubebuilder:validation:items:Enum=open;closed;pending
+	// +optional
+	States []string `json:"states,omitempty"`
+
+	// MaxIssues caps how many issues are listed across the repos, keeping the summary's size
+	// bounded whatever the number of GithubIssues. It isn't a page size: the issues past it
+	// are counted but listed nowhere, the repos cut short are marked truncated. Zero lists
+	// none, only counting them.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=1000
+	// +kubebuilder:default=100
+	// +optional
+	MaxIssues *int32 `json:"maxIssues,omitempty"`
+}
+
+// ManagedIssue references a GithubIssue and the state of its GitHub issue
+type ManagedIssue struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+
+	// +optional
+	IssueNumber int32 `json:"issueNumber,omitempty"`
+
+	// State is open, closed or pending when the issue wasn't created yet
+	State string `json:"state"`
+}
+
+// RepoSummary counts the managed issues of a repository per state
+type RepoSummary struct {
+	Repo    string `json:"repo"`
+	Open    int32  `json:"open"`
+	Closed  int32  `json:"closed"`
+	Pending int32  `json:"pending"`
+
+	// +optional
+	Issues []ManagedIssue `json:"issues,omitempty"`
+
+	// Truncated is set when maxIssues cut the issues short, the counts still cover them all
+	// +optional
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// GithubIssueSummaryStatus defines the observed state of GithubIssueSummary
+type GithubIssueSummaryStatus struct {
+	// +optional
+	Total int32 `json:"total,omitempty"`
+
+	// +optional
+	Open int32 `json:"open,omitempty"`
+
+	// +optional
+	Closed int32 `json:"closed,omitempty"`
+
+	// +optional
+	Pending int32 `json:"pending,omitempty"`
+
+	// Omitted counts the issues maxIssues left out of the repo lists
+	// +optional
+	Omitted int32 `json:"omitted,omitempty"`
+
+	// +optional
+	Repos []RepoSummary `json:"repos,omitempty"`
+
+	// +optional
+	LastUpdated metav1.Time `json:"lastUpdated,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:printcolumn:name="Total",type=integer,JSONPath=`.status.total`
+// +kubebuilder:printcolumn:name="Open",type=integer,JSONPath=`.status.open`
+// +kubebuilder:printcolumn:name="Closed",type=integer,JSONPath=`.status.closed`
+// +kubebuilder:printcolumn:name="Pending",type=integer,JSONPath=`.status.pending`
+// +kubebuilder:printcolumn:name="Omitted",type=integer,JSONPath=`.status.omitted`,priority=1
+// +kubebuilder:printcolumn:name="Updated",type=date,JSONPath=`.status.lastUpdated`
+
+// GithubIssueSummary is the Schema for the githubissuesummaries API, it aggregates
+// the issues managed by the operator across all namespaces
+type GithubIssueSummary struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   GithubIssueSummarySpec   `json:"spec,omitempty"`
+	Status GithubIssueSummaryStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// GithubIssueSummaryList contains a list of GithubIssueSummary
+type GithubIssueSummaryList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []GithubIssueSummary `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&GithubIssueSummary{}, &GithubIssueSummaryList{})
+}
@@ -25,12 +25,42 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Attachment) DeepCopyInto(out *Attachment) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Attachment.
+func (in *Attachment) DeepCopy() *Attachment {
+	if in == nil {
+		return nil
+	}
+	out := new(Attachment)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CreateWindow) DeepCopyInto(out *CreateWindow) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CreateWindow.
+func (in *CreateWindow) DeepCopy() *CreateWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(CreateWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GithubIssue) DeepCopyInto(out *GithubIssue) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	in.Status.DeepCopyInto(&out.Status)
 }
 
@@ -87,6 +117,77 @@ func (in *GithubIssueList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GithubIssueSpec) DeepCopyInto(out *GithubIssueSpec) {
 	*out = *in
+	if in.Bodies != nil {
+		in, out := &in.Bodies, &out.Bodies
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.InitialComments != nil {
+		in, out := &in.InitialComments, &out.InitialComments
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LinkedResource != nil {
+		in, out := &in.LinkedResource, &out.LinkedResource
+		*out = new(LinkedResource)
+		**out = **in
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Assignees != nil {
+		in, out := &in.Assignees, &out.Assignees
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.BlockedBy != nil {
+		in, out := &in.BlockedBy, &out.BlockedBy
+		*out = make([]int, len(*in))
+		copy(*out, *in)
+	}
+	if in.Attachments != nil {
+		in, out := &in.Attachments, &out.Attachments
+		*out = make([]Attachment, len(*in))
+		copy(*out, *in)
+	}
+	if in.SupersededBy != nil {
+		in, out := &in.SupersededBy, &out.SupersededBy
+		*out = new(IssueReference)
+		**out = **in
+	}
+	if in.AllowReopen != nil {
+		in, out := &in.AllowReopen, &out.AllowReopen
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ReopenWithin != nil {
+		in, out := &in.ReopenWithin, &out.ReopenWithin
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.RetentionAfterClose != nil {
+		in, out := &in.RetentionAfterClose, &out.RetentionAfterClose
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.CreateWindow != nil {
+		in, out := &in.CreateWindow, &out.CreateWindow
+		*out = new(CreateWindow)
+		**out = **in
+	}
+	if in.CloseAt != nil {
+		in, out := &in.CloseAt, &out.CloseAt
+		*out = (*in).DeepCopy()
+	}
+	if in.SLAMaxAge != nil {
+		in, out := &in.SLAMaxAge, &out.SLAMaxAge
+		*out = new(metav1.Duration)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GithubIssueSpec.
@@ -110,6 +211,38 @@ func (in *GithubIssueStatus) DeepCopyInto(out *GithubIssueStatus) {
 		}
 	}
 	in.LastUpdated.DeepCopyInto(&out.LastUpdated)
+	if in.Assignees != nil {
+		in, out := &in.Assignees, &out.Assignees
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ManagedAssignees != nil {
+		in, out := &in.ManagedAssignees, &out.ManagedAssignees
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RateLimitRemaining != nil {
+		in, out := &in.RateLimitRemaining, &out.RateLimitRemaining
+		*out = new(int32)
+		**out = **in
+	}
+	if in.RateLimitResetTime != nil {
+		in, out := &in.RateLimitResetTime, &out.RateLimitResetTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastStateTransition != nil {
+		in, out := &in.LastStateTransition, &out.LastStateTransition
+		*out = (*in).DeepCopy()
+	}
+	if in.LastClosedAt != nil {
+		in, out := &in.LastClosedAt, &out.LastClosedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.PendingComment != nil {
+		in, out := &in.PendingComment, &out.PendingComment
+		*out = new(PendingComment)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GithubIssueStatus.
@@ -121,3 +254,200 @@ func (in *GithubIssueStatus) DeepCopy() *GithubIssueStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GithubIssueSummary) DeepCopyInto(out *GithubIssueSummary) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GithubIssueSummary.
+func (in *GithubIssueSummary) DeepCopy() *GithubIssueSummary {
+	if in == nil {
+		return nil
+	}
+	out := new(GithubIssueSummary)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GithubIssueSummary) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GithubIssueSummaryList) DeepCopyInto(out *GithubIssueSummaryList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]GithubIssueSummary, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GithubIssueSummaryList.
+func (in *GithubIssueSummaryList) DeepCopy() *GithubIssueSummaryList {
+	if in == nil {
+		return nil
+	}
+	out := new(GithubIssueSummaryList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GithubIssueSummaryList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GithubIssueSummarySpec) DeepCopyInto(out *GithubIssueSummarySpec) {
+	*out = *in
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Repos != nil {
+		in, out := &in.Repos, &out.Repos
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.States != nil {
+		in, out := &in.States, &out.States
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MaxIssues != nil {
+		in, out := &in.MaxIssues, &out.MaxIssues
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GithubIssueSummarySpec.
+func (in *GithubIssueSummarySpec) DeepCopy() *GithubIssueSummarySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GithubIssueSummarySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GithubIssueSummaryStatus) DeepCopyInto(out *GithubIssueSummaryStatus) {
+	*out = *in
+	if in.Repos != nil {
+		in, out := &in.Repos, &out.Repos
+		*out = make([]RepoSummary, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.LastUpdated.DeepCopyInto(&out.LastUpdated)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GithubIssueSummaryStatus.
+func (in *GithubIssueSummaryStatus) DeepCopy() *GithubIssueSummaryStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(GithubIssueSummaryStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IssueReference) DeepCopyInto(out *IssueReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IssueReference.
+func (in *IssueReference) DeepCopy() *IssueReference {
+	if in == nil {
+		return nil
+	}
+	out := new(IssueReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LinkedResource) DeepCopyInto(out *LinkedResource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LinkedResource.
+func (in *LinkedResource) DeepCopy() *LinkedResource {
+	if in == nil {
+		return nil
+	}
+	out := new(LinkedResource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManagedIssue) DeepCopyInto(out *ManagedIssue) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagedIssue.
+func (in *ManagedIssue) DeepCopy() *ManagedIssue {
+	if in == nil {
+		return nil
+	}
+	out := new(ManagedIssue)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PendingComment) DeepCopyInto(out *PendingComment) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PendingComment.
+func (in *PendingComment) DeepCopy() *PendingComment {
+	if in == nil {
+		return nil
+	}
+	out := new(PendingComment)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RepoSummary) DeepCopyInto(out *RepoSummary) {
+	*out = *in
+	if in.Issues != nil {
+		in, out := &in.Issues, &out.Issues
+		*out = make([]ManagedIssue, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RepoSummary.
+func (in *RepoSummary) DeepCopy() *RepoSummary {
+	if in == nil {
+		return nil
+	}
+	out := new(RepoSummary)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -17,18 +17,30 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"crypto/tls"
 	"flag"
+	"fmt"
+	"net"
 	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"text/template"
 	"time"
 
+	"golang.org/x/time/rate"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/selection"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -40,6 +52,15 @@ import (
 
 	issuev1 "github.com/oshribelay/github-issue-operator/api/v1"
 	"github.com/oshribelay/github-issue-operator/internal/controller"
+	"github.com/oshribelay/github-issue-operator/internal/controller/linked"
+	"github.com/oshribelay/github-issue-operator/internal/controller/notify"
+	"github.com/oshribelay/github-issue-operator/internal/controller/resources"
+	"github.com/oshribelay/github-issue-operator/internal/controller/status"
+	"github.com/oshribelay/github-issue-operator/internal/controller/templates"
+	"github.com/oshribelay/github-issue-operator/internal/controller/tokenfile"
+	"github.com/oshribelay/github-issue-operator/internal/controller/utils"
+	"github.com/oshribelay/github-issue-operator/internal/issueform"
+	"github.com/oshribelay/github-issue-operator/internal/repourl"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -58,9 +79,62 @@ func init() {
 func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
+	var leaderElectionID string
+	var maintainSummary bool
 	var probeAddr string
 	var secureMetrics bool
 	var enableHTTP2 bool
+	var seedTokenEnv string
+	var truncateBody bool
+	var userAgentSuffix string
+	var scanSecrets bool
+	var lintMarkdown bool
+	var controlCharacters string
+	var categoryLabelsFlag string
+	var severityLabelsFlag string
+	var severityEmojisFlag string
+	var adoptOnlyOwned bool
+	var titleMatch string
+	var tokenKey string
+	var tokenSecretPattern string
+	var atRiskWithin time.Duration
+	var minStateChangeInterval time.Duration
+	var updateDelay time.Duration
+	var maxCreationsPerMinute int
+	var eventBridgeReason string
+	var eventBridgeNamespace string
+	var eventBridgeRepo string
+	var eventBridgeCooldown time.Duration
+	var alertmanagerBindAddress string
+	var alertmanagerNamespace string
+	var alertmanagerRepo string
+	var alertmanagerTokenSecret string
+	var alertmanagerTLSCert string
+	var alertmanagerTLSKey string
+	var labelSelectorFlag string
+	var tokenFilePath string
+	var freezeConfigMapFlag string
+	var secretSweepInterval time.Duration
+	var labelTemplatesFile string
+	var missingTokenRequeue time.Duration
+	var conflictRequeue time.Duration
+	var createDedupeWindow time.Duration
+	var repoProbeInterval time.Duration
+	var allowedAPIHostsFlag string
+	var allowedNotifyHostsFlag string
+	var linkedKindsFlag string
+	var githubPageSize int
+	var maxConcurrentReconciles int
+	var titlePrefixFlag string
+	var reuseGithubClients bool
+	var maxAssignedIssues int
+	var versionLabel bool
+	var disabledConditionsFlag string
+	var clusterName string
+	var defaultsConfigMap string
+	var admissionTokenEnv string
+	var repoCheckTimeout time.Duration
+	var issueFormTTL time.Duration
 	var tlsOpts []func(*tls.Config)
 	syncPeriod := time.Duration(1) * time.Minute
 	log := ctrl.Log.WithName("controllers").WithName("github-issue-operator")
@@ -71,10 +145,155 @@ func main() {
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.StringVar(&leaderElectionID, "leader-election-id", "",
+		"Name of the leader election lease. Defaults to one derived from --label-selector, so every shard "+
+			"elects its own leader.")
+	flag.BoolVar(&maintainSummary, "maintain-summary", true,
+		"Maintain the cluster-wide GithubIssueSummary, which counts every GithubIssue whatever the "+
+			"--label-selector. Defaults to false with a --label-selector, enable it on a single shard.")
 	flag.BoolVar(&secureMetrics, "metrics-secure", true,
 		"If set, the metrics endpoint is served securely via HTTPS. Use --metrics-secure=false to use HTTP instead.")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.StringVar(&seedTokenEnv, "seed-token-from-env", "",
+		"If set, newly created token secrets are pre-populated from this operator environment variable. "+
+			"Intended for dev clusters; leave empty in production so tokens are entered manually.")
+	flag.BoolVar(&truncateBody, "truncate-body", false,
+		"If set, issue bodies longer than GitHub's limit are truncated instead of being rejected by the webhook.")
+	flag.StringVar(&userAgentSuffix, "user-agent-suffix", "",
+		"Appended to the github-issue-operator/<version> User-Agent sent with GitHub requests.")
+	flag.BoolVar(&scanSecrets, "scan-secrets", false,
+		"If set, the webhook rejects issue descriptions containing what looks like a GitHub token or AWS key.")
+	flag.StringVar(&controlCharacters, "control-characters", string(issuev1.ControlCharactersAllow),
+		"What the webhooks do with control characters other than newlines and tabs in issue descriptions: "+
+			"allow, reject (listing their positions) or strip.")
+	flag.StringVar(&categoryLabelsFlag, "category-labels", "bug=bug,feature=enhancement,chore=chore",
+		"Comma separated category=label pairs, mapping the issue categories to the labels attached for them.")
+	flag.StringVar(&severityLabelsFlag, "severity-labels", "sev1=sev1,sev2=sev2,sev3=sev3,sev4=sev4",
+		"Comma separated severity=label pairs, mapping the issue severities to the labels attached for them.")
+	flag.StringVar(&severityEmojisFlag, "severity-emojis", "",
+		"Comma separated severity=emoji pairs, e.g. \"sev1=🔥\", the emoji is prepended to the title of "+
+			"issues of the severity. No emoji is added by default.")
+	flag.BoolVar(&adoptOnlyOwned, "adopt-only-owned", false,
+		"If set, issues created by the operator are tagged with an invisible marker and existing issues "+
+			"without it are never adopted, even when their title matches.")
+	flag.StringVar(&titleMatch, "title-match", string(resources.TitleMatchExact),
+		"How existing issues are matched by title: exact, prefix (titles starting with the GithubIssue title) or "+
+			"contains (titles containing it). The loose strategies find titles suffixed by bots but may adopt an "+
+			"unrelated issue whose title happens to match, an exact match is always preferred. They only apply "+
+			"to adopting an issue, the issue closed on deletion is always matched exactly.")
+	flag.StringVar(&tokenKey, "token-secret-key", resources.DefaultTokenKey,
+		"Key of the GitHub token in the token secrets. When it is missing or empty, the first key "+
+			"(in alphabetical order) whose value looks like a GitHub token is used instead.")
+	flag.StringVar(&tokenSecretPattern, "token-secret-pattern", resources.DefaultTokenSecretPattern,
+		"Template naming the token secret of a GithubIssue from its .Name and .Namespace, e.g. \"{{ .Name }}-gh\", "+
+			"used both to create and to look up the secrets.")
+	flag.DurationVar(&atRiskWithin, "at-risk-within", 0,
+		"If set, open issues whose milestone is due within this duration get the at-risk label.")
+	flag.DurationVar(&minStateChangeInterval, "min-state-change-interval", 0,
+		"If set, an issue isn't opened or closed again within this duration of its last state change, "+
+			"so a flapping linked resource doesn't flap the issue.")
+	flag.DurationVar(&secretSweepInterval, "secret-sweep-interval", time.Hour,
+		"How often token secrets left behind by deleted GithubIssues are cleaned up, 0 disables the sweep.")
+	flag.StringVar(&labelTemplatesFile, "label-templates", "",
+		"Path of a YAML list of {label, priority, template} entries. Issues carrying one of the labels get "+
+			"their body rendered with its Go template, the highest priority wins when several labels match.")
+	flag.BoolVar(&versionLabel, "version-label", false,
+		"If set, issues are labeled operator-version:<version> with the operator version managing them.")
+	flag.IntVar(&maxAssignedIssues, "max-assigned-issues", 0,
+		"If set, issues whose assignees already hold this many open issues across the GithubIssues "+
+			"get the AssigneesOverloaded warning condition. The assignment isn't blocked.")
+	flag.StringVar(&disabledConditionsFlag, "disabled-conditions", "",
+		"Comma separated status condition types left out of the GithubIssue status, e.g. \"HasPR\". "+
+			"All conditions are reported by default, those the operator keeps its state in can't be disabled.")
+	flag.StringVar(&defaultsConfigMap, "defaults-configmap", "github-issue-defaults",
+		"Name of the ConfigMap a namespace can set its default repo in, under the \"repo\" key. "+
+			"GithubIssues omitting .spec.repo inherit it.")
+	flag.BoolVar(&reuseGithubClients, "reuse-github-clients", false,
+		"If set, the GitHub client of a token is kept across reconciles, reusing its connections. "+
+			"Clients are evicted when their token is rotated.")
+	flag.DurationVar(&missingTokenRequeue, "missing-token-requeue", time.Minute,
+		"How long to wait before retrying an issue whose token secret is still empty.")
+	flag.DurationVar(&conflictRequeue, "conflict-requeue", 5*time.Second,
+		"How long to wait before retrying an issue after a conflicting update.")
+	flag.IntVar(&githubPageSize, "github-page-size", 30,
+		"How many issues are listed per GitHub request, up to 100. Larger pages take fewer requests "+
+			"in repos with many issues.")
+	flag.IntVar(&maxConcurrentReconciles, "max-concurrent-reconciles", 1,
+		"How many GithubIssues are reconciled at once. Issues created for the same repository and title "+
+			"by concurrent reconciles are deduplicated within --create-dedupe-window.")
+	flag.DurationVar(&createDedupeWindow, "create-dedupe-window", 30*time.Second,
+		"How long an issue created for a repository and title is reused by concurrent reconciles creating "+
+			"the same one, covering the delay before GitHub lists new issues.")
+	flag.StringVar(&allowedAPIHostsFlag, "allowed-api-hosts", "",
+		"Comma-separated hosts, e.g. github.example.com, an apiBaseURL may point at besides api.github.com. "+
+			"The operator's tokens are sent to the API base URL, GithubIssues pointing elsewhere are refused.")
+	flag.StringVar(&allowedNotifyHostsFlag, "allowed-notify-hosts", "",
+		"Comma-separated hosts, e.g. hooks.example.com, a notifyURL may point at. GithubIssues notifying "+
+			"other hosts are refused, and no notification is sent without any.")
+	flag.StringVar(&linkedKindsFlag, "linked-resource-kinds", "",
+		"Comma-separated kinds in the Kind.group form, e.g. Certificate.cert-manager.io, GithubIssues can link to "+
+			"besides Deployments, StatefulSets, DaemonSets, Jobs and Pods. The operator must be granted reading them.")
+	flag.DurationVar(&repoProbeInterval, "repo-probe-interval", 5*time.Minute,
+		"How often each repository is probed for the RepoReachable condition, shared by the GithubIssues "+
+			"targeting it. Zero disables the probe.")
+	flag.StringVar(&titlePrefixFlag, "title-prefix", "",
+		"Go template prepended to the issue titles, rendered with .Cluster and .Namespace, "+
+			"e.g. \"[{{ .Cluster }}/{{ .Namespace }}] \".")
+	flag.StringVar(&clusterName, "cluster-name", "",
+		"Name of the cluster, available as .Cluster in --title-prefix.")
+	flag.StringVar(&admissionTokenEnv, "admission-token-from-env", "",
+		"If set, the webhook rejects new GithubIssues whose repo doesn't exist or isn't accessible with the "+
+			"GitHub token in this operator environment variable. Disabled by default.")
+	flag.DurationVar(&repoCheckTimeout, "admission-repo-check-timeout", 2*time.Second,
+		"How long the webhook waits for GitHub when checking the repo exists, GithubIssues are admitted "+
+			"with a warning when the check doesn't complete in time.")
+	flag.DurationVar(&issueFormTTL, "admission-issue-form-ttl", 10*time.Minute,
+		"How long the webhook caches the issue forms of the repos, which descriptions of GithubIssues naming "+
+			"a form are checked against. Requires --admission-token-from-env.")
+	flag.BoolVar(&lintMarkdown, "admission-lint-markdown", false,
+		"If set, the webhook warns about malformed markdown in descriptions, such as unclosed code fences "+
+			"and tables whose rows don't match their header. GithubIssues are admitted regardless.")
+	flag.DurationVar(&updateDelay, "update-delay", 0,
+		"How long reconciles of GithubIssues that already have an issue are delayed when they're added or "+
+			"changed, so the issues of new GithubIssues are created first during large applies. 0 disables the delay.")
+	flag.StringVar(&freezeConfigMapFlag, "freeze-configmap", "",
+		"Namespace/name of the ConfigMap holding a change freeze window, as RFC 3339 \"start\" and \"end\" keys "+
+			"and an optional \"reason\". Issues aren't created, edited or closed during the freeze. Disabled by default.")
+	flag.IntVar(&maxCreationsPerMinute, "max-creations-per-minute", 0,
+		"How many issues the operator creates per minute at most, protecting the repos from a runaway apply. "+
+			"Creations over the rate are retried once it allows them. 0 disables the limit.")
+	flag.StringVar(&eventBridgeReason, "event-bridge-reason", "",
+		"Regular expression matching the reasons of the Warning Events a GithubIssue is created for, one per "+
+			"involved object and reason. Disabled by default.")
+	flag.StringVar(&eventBridgeNamespace, "event-bridge-namespace", "",
+		"Namespace the GithubIssues of bridged Events are created in, required with --event-bridge-reason.")
+	flag.StringVar(&eventBridgeRepo, "event-bridge-repo", "",
+		"Repository URL of the GithubIssues of bridged Events, required with --event-bridge-reason.")
+	flag.DurationVar(&eventBridgeCooldown, "event-bridge-cooldown", time.Hour,
+		"How long after bridging an Event its GithubIssue is left alone by recurring Events.")
+	flag.StringVar(&alertmanagerBindAddress, "alertmanager-bind-address", "",
+		"Address the Alertmanager webhook receiver serves /alerts on, e.g. \"127.0.0.1:9095\". Firing alerts create a "+
+			"GithubIssue and resolved alerts delete it, closing the issue. Disabled by default.")
+	flag.StringVar(&alertmanagerNamespace, "alertmanager-namespace", "",
+		"Namespace the GithubIssues of alerts are created in, required with --alertmanager-bind-address.")
+	flag.StringVar(&alertmanagerRepo, "alertmanager-repo", "",
+		"Repository URL of the GithubIssues of alerts, required with --alertmanager-bind-address.")
+	flag.StringVar(&alertmanagerTokenSecret, "alertmanager-token-secret", "",
+		"Secret in the --alertmanager-namespace holding under its \"token\" key the bearer token Alertmanager "+
+			"must send, required with --alertmanager-bind-address.")
+	flag.StringVar(&alertmanagerTLSCert, "alertmanager-tls-cert", "",
+		"Certificate the Alertmanager webhook is served over TLS with. Without it the receiver must bind to "+
+			"localhost, for an Alertmanager sidecar.")
+	flag.StringVar(&alertmanagerTLSKey, "alertmanager-tls-key", "",
+		"Private key of --alertmanager-tls-cert.")
+	flag.StringVar(&labelSelectorFlag, "label-selector", "",
+		"Label selector restricting the GithubIssues this instance reconciles, e.g. \"shard=a\", so several "+
+			"operator deployments can divide the GithubIssues between them. All GithubIssues are reconciled by default. "+
+			"The GithubIssues created from events and alerts are given labels matching it.")
+	flag.StringVar(&tokenFilePath, "token-file", "",
+		"Path of a file holding the GitHub token of every GithubIssue, e.g. a mounted secret, used in place of "+
+			"the per-GithubIssue token secrets. The token is read again whenever the file changes.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -83,6 +302,213 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	categoryLabels, err := utils.ParseKeyValues(categoryLabelsFlag)
+	if err != nil {
+		setupLog.Error(err, "invalid --category-labels")
+		os.Exit(1)
+	}
+	severityLabels, err := utils.ParseKeyValues(severityLabelsFlag)
+	if err != nil {
+		setupLog.Error(err, "invalid --severity-labels")
+		os.Exit(1)
+	}
+	severityEmojis, err := utils.ParseKeyValues(severityEmojisFlag)
+	if err != nil {
+		setupLog.Error(err, "invalid --severity-emojis")
+		os.Exit(1)
+	}
+	if err := status.SetDisabledConditions(utils.ParseList(disabledConditionsFlag)); err != nil {
+		setupLog.Error(err, "invalid --disabled-conditions")
+		os.Exit(1)
+	}
+	switch issuev1.ControlCharacterPolicy(controlCharacters) {
+	case issuev1.ControlCharactersAllow, issuev1.ControlCharactersReject, issuev1.ControlCharactersStrip:
+	default:
+		setupLog.Error(fmt.Errorf("must be allow, reject or strip, got %q", controlCharacters), "invalid --control-characters")
+		os.Exit(1)
+	}
+	switch resources.TitleMatch(titleMatch) {
+	case resources.TitleMatchExact, resources.TitleMatchPrefix, resources.TitleMatchContains:
+	default:
+		setupLog.Error(fmt.Errorf("must be exact, prefix or contains, got %q", titleMatch), "invalid --title-match")
+		os.Exit(1)
+	}
+	if missingTokenRequeue <= 0 {
+		setupLog.Error(fmt.Errorf("must be positive, got %s", missingTokenRequeue), "invalid --missing-token-requeue")
+		os.Exit(1)
+	}
+	if minStateChangeInterval < 0 {
+		setupLog.Error(fmt.Errorf("must not be negative, got %s", minStateChangeInterval), "invalid --min-state-change-interval")
+		os.Exit(1)
+	}
+	if issueFormTTL < 0 {
+		setupLog.Error(fmt.Errorf("must not be negative, got %s", issueFormTTL), "invalid --admission-issue-form-ttl")
+		os.Exit(1)
+	}
+	if repoCheckTimeout <= 0 {
+		setupLog.Error(fmt.Errorf("must be positive, got %s", repoCheckTimeout), "invalid --admission-repo-check-timeout")
+		os.Exit(1)
+	}
+	var freezeConfigMap client.ObjectKey
+	if freezeConfigMapFlag != "" {
+		namespace, name, found := strings.Cut(freezeConfigMapFlag, "/")
+		if !found || namespace == "" || name == "" {
+			setupLog.Error(fmt.Errorf("must be namespace/name, got %q", freezeConfigMapFlag), "invalid --freeze-configmap")
+			os.Exit(1)
+		}
+		freezeConfigMap = client.ObjectKey{Namespace: namespace, Name: name}
+	}
+	var eventBridgeReasonPattern *regexp.Regexp
+	if eventBridgeReason != "" {
+		if eventBridgeReasonPattern, err = regexp.Compile(eventBridgeReason); err != nil {
+			setupLog.Error(err, "invalid --event-bridge-reason")
+			os.Exit(1)
+		}
+		if eventBridgeNamespace == "" {
+			setupLog.Error(fmt.Errorf("required with --event-bridge-reason"), "invalid --event-bridge-namespace")
+			os.Exit(1)
+		}
+		if _, _, err := repourl.Parse(eventBridgeRepo); err != nil {
+			setupLog.Error(err, "invalid --event-bridge-repo")
+			os.Exit(1)
+		}
+	}
+	if eventBridgeCooldown < 0 {
+		setupLog.Error(fmt.Errorf("must not be negative, got %s", eventBridgeCooldown), "invalid --event-bridge-cooldown")
+		os.Exit(1)
+	}
+	if alertmanagerBindAddress != "" {
+		if alertmanagerNamespace == "" {
+			setupLog.Error(fmt.Errorf("required with --alertmanager-bind-address"), "invalid --alertmanager-namespace")
+			os.Exit(1)
+		}
+		if _, _, err := repourl.Parse(alertmanagerRepo); err != nil {
+			setupLog.Error(err, "invalid --alertmanager-repo")
+			os.Exit(1)
+		}
+		if alertmanagerTokenSecret == "" {
+			setupLog.Error(fmt.Errorf("required with --alertmanager-bind-address"), "invalid --alertmanager-token-secret")
+			os.Exit(1)
+		}
+		if (alertmanagerTLSCert == "") != (alertmanagerTLSKey == "") {
+			setupLog.Error(fmt.Errorf("--alertmanager-tls-cert and --alertmanager-tls-key go together"), "invalid --alertmanager-tls-key")
+			os.Exit(1)
+		}
+		// without TLS the bearer token would cross the network in the clear
+		if host, _, err := net.SplitHostPort(alertmanagerBindAddress); alertmanagerTLSCert == "" && (err != nil || !isLoopback(host)) {
+			setupLog.Error(fmt.Errorf("must bind to localhost without --alertmanager-tls-cert, got %q", alertmanagerBindAddress),
+				"invalid --alertmanager-bind-address")
+			os.Exit(1)
+		}
+	}
+	tokenSecretNames, err := resources.ParseTokenSecretNames(tokenSecretPattern)
+	if err != nil {
+		setupLog.Error(err, "invalid --token-secret-pattern")
+		os.Exit(1)
+	}
+	var labelSelector labels.Selector
+	if labelSelectorFlag != "" {
+		if labelSelector, err = labels.Parse(labelSelectorFlag); err != nil {
+			setupLog.Error(err, "invalid --label-selector")
+			os.Exit(1)
+		}
+	}
+	if leaderElectionID == "" {
+		leaderElectionID = defaultLeaderElectionID(labelSelector)
+	}
+	// the GithubIssues created from events and alerts carry the labels the selector requires,
+	// otherwise no instance would reconcile them
+	var createdLabels map[string]string
+	if eventBridgeReasonPattern != nil || alertmanagerBindAddress != "" {
+		if createdLabels, err = selectorLabels(labelSelector); err != nil {
+			setupLog.Error(err, "invalid --label-selector for the GithubIssues created from events and alerts")
+			os.Exit(1)
+		}
+	}
+	// shards maintaining the same summary would fight over it, leave it to the one enabling it
+	if labelSelector != nil && !flagSet("maintain-summary") {
+		maintainSummary = false
+	}
+	var tokenFile *tokenfile.Source
+	if tokenFilePath != "" {
+		if tokenFile, err = tokenfile.Load(tokenFilePath, ctrl.Log.WithName("token-file")); err != nil {
+			setupLog.Error(err, "invalid --token-file")
+			os.Exit(1)
+		}
+	}
+	if maxCreationsPerMinute < 0 {
+		setupLog.Error(fmt.Errorf("must not be negative, got %d", maxCreationsPerMinute), "invalid --max-creations-per-minute")
+		os.Exit(1)
+	}
+	var creationLimiter *rate.Limiter
+	if maxCreationsPerMinute > 0 {
+		creationLimiter = rate.NewLimiter(rate.Every(time.Minute/time.Duration(maxCreationsPerMinute)), maxCreationsPerMinute)
+	}
+	if updateDelay < 0 {
+		setupLog.Error(fmt.Errorf("must not be negative, got %s", updateDelay), "invalid --update-delay")
+		os.Exit(1)
+	}
+	if maxAssignedIssues < 0 {
+		setupLog.Error(fmt.Errorf("must not be negative, got %d", maxAssignedIssues), "invalid --max-assigned-issues")
+		os.Exit(1)
+	}
+	if githubPageSize < 1 || githubPageSize > 100 {
+		setupLog.Error(fmt.Errorf("must be within 1-100, got %d", githubPageSize), "invalid --github-page-size")
+		os.Exit(1)
+	}
+	if createDedupeWindow <= 0 {
+		setupLog.Error(fmt.Errorf("must be positive, got %s", createDedupeWindow), "invalid --create-dedupe-window")
+		os.Exit(1)
+	}
+	allowedAPIHosts, err := repourl.ParseHosts(allowedAPIHostsFlag)
+	if err != nil {
+		setupLog.Error(err, "invalid --allowed-api-hosts")
+		os.Exit(1)
+	}
+	allowedNotifyHosts, err := repourl.ParseHosts(allowedNotifyHostsFlag)
+	if err != nil {
+		setupLog.Error(err, "invalid --allowed-notify-hosts")
+		os.Exit(1)
+	}
+	extraLinkedKinds, err := linked.ParseKinds(linkedKindsFlag)
+	if err != nil {
+		setupLog.Error(err, "invalid --linked-resource-kinds")
+		os.Exit(1)
+	}
+	linkedKinds := append(slices.Clone(linked.DefaultKinds), extraLinkedKinds...)
+	if repoProbeInterval < 0 {
+		setupLog.Error(fmt.Errorf("must not be negative, got %s", repoProbeInterval), "invalid --repo-probe-interval")
+		os.Exit(1)
+	}
+	if conflictRequeue <= 0 {
+		setupLog.Error(fmt.Errorf("must be positive, got %s", conflictRequeue), "invalid --conflict-requeue")
+		os.Exit(1)
+	}
+	var titlePrefix *template.Template
+	if titlePrefixFlag != "" {
+		if titlePrefix, err = template.New("title-prefix").Option("missingkey=error").Parse(titlePrefixFlag); err != nil {
+			setupLog.Error(err, "invalid --title-prefix")
+			os.Exit(1)
+		}
+	}
+	var labelTemplates *templates.Set
+	if labelTemplatesFile != "" {
+		data, err := os.ReadFile(labelTemplatesFile)
+		if err != nil {
+			setupLog.Error(err, "unable to read --label-templates")
+			os.Exit(1)
+		}
+		if labelTemplates, err = templates.Load(data); err != nil {
+			setupLog.Error(err, "invalid --label-templates")
+			os.Exit(1)
+		}
+	}
+	categories := make([]string, 0, len(categoryLabels))
+	for category := range categoryLabels {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+
 	// if the enable-http2 flag is false (the default), http/2 should be disabled
 	// due to its vulnerabilities. More specifically, disabling http/2 will
 	// prevent from being vulnerable to the HTTP/2 Stream Cancellation and
@@ -132,7 +558,7 @@ func main() {
 		WebhookServer:          webhookServer,
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
-		LeaderElectionID:       "0141f561.core.github.io",
+		LeaderElectionID:       leaderElectionID,
 		Cache: cache.Options{
 			SyncPeriod: &syncPeriod,
 		},
@@ -154,15 +580,136 @@ func main() {
 	}
 
 	if err = (&controller.GithubIssueReconciler{
-		Client:       mgr.GetClient(),
-		GithubClient: nil,
-		Scheme:       mgr.GetScheme(),
-		Log:          log,
+		Client:                  mgr.GetClient(),
+		Scheme:                  mgr.GetScheme(),
+		Log:                     log,
+		SeedTokenEnv:            seedTokenEnv,
+		TruncateBody:            truncateBody,
+		UserAgent:               resources.UserAgent(userAgentSuffix),
+		CategoryLabels:          categoryLabels,
+		SeverityLabels:          severityLabels,
+		SeverityEmojis:          severityEmojis,
+		AdoptOnlyOwned:          adoptOnlyOwned,
+		TitleMatch:              resources.TitleMatch(titleMatch),
+		Selector:                labelSelector,
+		TokenFile:               tokenFile,
+		TokenKey:                tokenKey,
+		TokenSecretNames:        tokenSecretNames,
+		AtRiskWithin:            atRiskWithin,
+		MinStateChangeInterval:  minStateChangeInterval,
+		UpdateDelay:             updateDelay,
+		CreationLimiter:         creationLimiter,
+		FreezeConfigMap:         freezeConfigMap,
+		APIReader:               mgr.GetAPIReader(),
+		LabelTemplates:          labelTemplates,
+		MissingTokenRequeue:     missingTokenRequeue,
+		ConflictRequeue:         conflictRequeue,
+		CreateDedupeWindow:      createDedupeWindow,
+		MaxConcurrentReconciles: maxConcurrentReconciles,
+		RepoProbeInterval:       repoProbeInterval,
+		AllowedAPIHosts:         allowedAPIHosts,
+		Notifier:                &notify.Notifier{AllowedHosts: allowedNotifyHosts},
+		LinkedKinds:             linkedKinds,
+		PageSize:                githubPageSize,
+		TitlePrefix:             titlePrefix,
+		ClusterName:             clusterName,
+		ReuseGithubClients:      reuseGithubClients,
+		MaxAssignedIssues:       maxAssignedIssues,
+		VersionLabel:            versionLabel,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "GithubIssue")
 		os.Exit(1)
 	}
+	if maintainSummary {
+		if err = (&controller.GithubIssueSummaryReconciler{
+			Client: mgr.GetClient(),
+			Scheme: mgr.GetScheme(),
+			Log:    ctrl.Log.WithName("controllers").WithName("github-issue-summary"),
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "GithubIssueSummary")
+			os.Exit(1)
+		}
+	}
+	if eventBridgeReasonPattern != nil {
+		if err = (&controller.EventBridgeReconciler{
+			Client:    mgr.GetClient(),
+			Log:       ctrl.Log.WithName("controllers").WithName("event-bridge"),
+			Reason:    eventBridgeReasonPattern,
+			Namespace: eventBridgeNamespace,
+			Repo:      eventBridgeRepo,
+			Labels:    createdLabels,
+			Cooldown:  eventBridgeCooldown,
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "EventBridge")
+			os.Exit(1)
+		}
+	}
+	if alertmanagerBindAddress != "" {
+		if err = mgr.Add(&controller.AlertmanagerReceiver{
+			Client:      mgr.GetClient(),
+			Log:         ctrl.Log.WithName("alertmanager-receiver"),
+			Addr:        alertmanagerBindAddress,
+			Namespace:   alertmanagerNamespace,
+			Repo:        alertmanagerRepo,
+			Labels:      createdLabels,
+			TokenSecret: client.ObjectKey{Namespace: alertmanagerNamespace, Name: alertmanagerTokenSecret},
+			CertFile:    alertmanagerTLSCert,
+			KeyFile:     alertmanagerTLSKey,
+		}); err != nil {
+			setupLog.Error(err, "unable to add the Alertmanager receiver")
+			os.Exit(1)
+		}
+	}
+	// warn early about a seed token lacking the scopes the operator needs
+	if seedToken := os.Getenv(seedTokenEnv); seedTokenEnv != "" && seedToken != "" {
+		checkTokenScopes(resources.NewGithubClient(seedToken, resources.WithUserAgent(resources.UserAgent(userAgentSuffix))))
+	}
+	if tokenFile != nil {
+		if err = mgr.Add(tokenFile); err != nil {
+			setupLog.Error(err, "unable to watch the token file")
+			os.Exit(1)
+		}
+	}
+	if secretSweepInterval > 0 {
+		if err = mgr.Add(&controller.TokenSecretSweeper{
+			Client:   mgr.GetClient(),
+			Log:      ctrl.Log.WithName("controllers").WithName("token-secret-sweeper"),
+			Interval: secretSweepInterval,
+			Names:    tokenSecretNames,
+		}); err != nil {
+			setupLog.Error(err, "unable to add token secret sweeper")
+			os.Exit(1)
+		}
+	}
 	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+		var repoChecker issuev1.RepoChecker
+		var issueForms *issueform.Cache
+		if admissionTokenEnv != "" {
+			admissionToken := os.Getenv(admissionTokenEnv)
+			if admissionToken == "" {
+				setupLog.Error(fmt.Errorf("environment variable %s is empty", admissionTokenEnv), "invalid --admission-token-from-env")
+				os.Exit(1)
+			}
+			admissionClient := resources.NewGithubClient(admissionToken, resources.WithUserAgent(resources.UserAgent(userAgentSuffix)))
+			repoChecker = admissionClient
+			issueForms = issueform.NewCache(admissionClient, issueFormTTL)
+		}
+		issuev1.SetWebhookOptions(issuev1.WebhookOptions{
+			TruncateBody:      truncateBody,
+			ScanSecrets:       scanSecrets,
+			Categories:        categories,
+			ControlCharacters: issuev1.ControlCharacterPolicy(controlCharacters),
+			// read directly rather than through the cache, which would watch every ConfigMap
+			DefaultsConfigMap:  defaultsConfigMap,
+			Reader:             mgr.GetAPIReader(),
+			RepoChecker:        repoChecker,
+			RepoCheckTimeout:   repoCheckTimeout,
+			IssueForms:         issueForms,
+			LintMarkdown:       lintMarkdown,
+			AllowedAPIHosts:    allowedAPIHosts,
+			AllowedNotifyHosts: allowedNotifyHosts,
+			LinkedKinds:        linkedKinds,
+		})
 		if err = (&issuev1.GithubIssue{}).SetupWebhookWithManager(mgr); err != nil {
 			setupLog.Error(err, "unable to create webhook", "webhook", "GithubIssue")
 			os.Exit(1)
@@ -185,3 +732,69 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// defaultLeaderElectionID returns the name of the leader election lease of the instance, one
+// per label selector so that shards don't compete for a single lease
+func defaultLeaderElectionID(selector labels.Selector) string {
+	const id = "0141f561.core.github.io"
+	if selector == nil {
+		return id
+	}
+	return utils.ContentHash(selector.String()) + "." + id
+}
+
+// selectorLabels returns labels matching the selector, the ones of its equality and set
+// requirements. It fails for selectors no labels the operator could pick match, e.g. "shard".
+func selectorLabels(selector labels.Selector) (map[string]string, error) {
+	if selector == nil {
+		return nil, nil
+	}
+	set := labels.Set{}
+	requirements, _ := selector.Requirements()
+	for _, requirement := range requirements {
+		switch requirement.Operator() {
+		case selection.Equals, selection.DoubleEquals, selection.In:
+			set[requirement.Key()] = requirement.Values().List()[0]
+		}
+	}
+	if !selector.Matches(set) {
+		return nil, fmt.Errorf("no labels the operator could set match %q", selector)
+	}
+	return set, nil
+}
+
+// flagSet reports whether the flag was given on the command line
+func flagSet(name string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+// isLoopback reports whether the host of a bind address is localhost
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// checkTokenScopes logs a warning when the token lacks the scopes the operator needs
+func checkTokenScopes(githubClient *resources.GithubClient) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	missing, ok, err := githubClient.CheckScopes(ctx)
+	switch {
+	case err != nil:
+		setupLog.Error(err, "unable to check the seed token scopes")
+	case !ok:
+		setupLog.Info("seed token scopes unknown, fine-grained tokens need read and write access to issues")
+	case len(missing) > 0:
+		setupLog.Info("WARNING: seed token is missing required scopes, GitHub will refuse requests", "missing", missing)
+	}
+}
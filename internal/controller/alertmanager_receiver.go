@@ -0,0 +1,308 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	issuev1 "github.com/oshribelay/github-issue-operator/api/v1"
+	"github.com/oshribelay/github-issue-operator/internal/controller/utils"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// AlertFingerprintLabel holds the fingerprint of the alert a GithubIssue was created for
+const AlertFingerprintLabel = "issue.core.github.io/alert-fingerprint"
+
+// fingerprintPattern matches the fingerprints Alertmanager computes for alerts
+var fingerprintPattern = regexp.MustCompile(`^[0-9a-f]{1,63}$`)
+
+// AlertmanagerPayload is the body of the Alertmanager webhook, only the parts the receiver uses
+type AlertmanagerPayload struct {
+	Version string  `json:"version"`
+	Status  string  `json:"status"`
+	Alerts  []Alert `json:"alerts"`
+}
+
+// Alert is an alert of the Alertmanager webhook payload
+type Alert struct {
+	Status       string            `json:"status"`
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations"`
+	StartsAt     time.Time         `json:"startsAt"`
+	GeneratorURL string            `json:"generatorURL"`
+	Fingerprint  string            `json:"fingerprint"`
+}
+
+// AlertmanagerReceiver receives Alertmanager webhooks, creating a GithubIssue for every firing
+// alert and deleting it once the alert is resolved, which closes its issue
+type AlertmanagerReceiver struct {
+	Client client.Client
+	Log    logr.Logger
+
+	// Addr is the address the receiver listens on
+	Addr string
+
+	// Namespace is where the GithubIssues are created
+	Namespace string
+
+	// Repo is the repository URL of the GithubIssues
+	Repo string
+
+	// Labels are added to the GithubIssues created, e.g. the ones the label selector of a
+	// sharded instance requires so that it reconciles them
+	Labels map[string]string
+
+	// TokenSecret holds, under its "token" key, the bearer token Alertmanager must send.
+	// Requests without it are rejected, the Secret being read every request so it can rotate.
+	TokenSecret client.ObjectKey
+
+	// CertFile and KeyFile serve the webhook over TLS when set, otherwise it should only
+	// listen on localhost for an Alertmanager sidecar
+	CertFile string
+	KeyFile  string
+}
+
+// AlertmanagerTokenKey is the key of the bearer token in the receiver's token secret
+const AlertmanagerTokenKey = "token"
+
+// Start serves the webhook until the context is done, it implements manager.Runnable
+func (a *AlertmanagerReceiver) Start(ctx context.Context) error {
+	mux := http.NewServeMux()
+	mux.Handle("/alerts", a)
+	server := &http.Server{Addr: a.Addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	errs := make(chan error, 1)
+	go func() {
+		a.Log.Info("serving the Alertmanager webhook", "addr", a.Addr, "tls", a.CertFile != "")
+		if a.CertFile != "" {
+			errs <- server.ListenAndServeTLS(a.CertFile, a.KeyFile)
+			return
+		}
+		errs <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		return server.Shutdown(shutdownCtx)
+	}
+}
+
+// NeedLeaderElection makes every replica serve the webhook, the GithubIssues of an alert are
+// named after its fingerprint so replicas receiving the same alert don't duplicate them
+func (a *AlertmanagerReceiver) NeedLeaderElection() bool {
+	return false
+}
+
+// ServeHTTP handles an Alertmanager webhook. It fails with 500 when a GithubIssue can't be
+// created or deleted, so Alertmanager retries the notification.
+func (a *AlertmanagerReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	authorized, err := a.authorized(req)
+	if err != nil {
+		a.Log.Error(err, "unable to read the token secret", "secret", a.TokenSecret)
+		http.Error(w, "unable to authenticate the request", http.StatusInternalServerError)
+		return
+	}
+	if !authorized {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	payload := &AlertmanagerPayload{}
+	if err := json.NewDecoder(http.MaxBytesReader(w, req.Body, 1<<20)).Decode(payload); err != nil {
+		http.Error(w, fmt.Sprintf("invalid Alertmanager payload: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	var errs []error
+	for _, alert := range payload.Alerts {
+		if err := a.sync(req.Context(), alert); err != nil {
+			a.Log.Error(err, "unable to sync the GithubIssue of an alert", "alert", alert.Labels["alertname"])
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		http.Error(w, errors.Join(errs...).Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// authorized reports whether the request carries the bearer token of the token secret,
+// compared in constant time. No request is authorized while the secret holds no token.
+func (a *AlertmanagerReceiver) authorized(req *http.Request) (bool, error) {
+	secret := &corev1.Secret{}
+	if err := a.Client.Get(req.Context(), a.TokenSecret, secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			a.Log.Info("token secret not found, rejecting every request", "secret", a.TokenSecret)
+			return false, nil
+		}
+		return false, err
+	}
+	token := bytes.TrimSpace(secret.Data[AlertmanagerTokenKey])
+	sent, found := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+	if len(token) == 0 || !found {
+		return false, nil
+	}
+	return subtle.ConstantTimeCompare([]byte(sent), token) == 1, nil
+}
+
+// sync creates the GithubIssue of a firing alert, or deletes the one of a resolved alert
+func (a *AlertmanagerReceiver) sync(ctx context.Context, alert Alert) error {
+	fingerprint := alertFingerprint(alert)
+	existing := &issuev1.GithubIssueList{}
+	if err := a.Client.List(ctx, existing, client.InNamespace(a.Namespace),
+		client.MatchingLabels{AlertFingerprintLabel: fingerprint}); err != nil {
+		return err
+	}
+
+	if alert.Status == "resolved" {
+		for i := range existing.Items {
+			githubIssue := &existing.Items[i]
+			if err := a.Client.Delete(ctx, githubIssue); client.IgnoreNotFound(err) != nil {
+				return err
+			}
+			a.Log.Info("alert resolved, deleted its GithubIssue", "githubissue", githubIssue.Name)
+		}
+		return nil
+	}
+
+	// a GithubIssue still closing the issue of an earlier firing is replaced on the next notification
+	if len(existing.Items) > 0 {
+		return nil
+	}
+	labels := maps.Clone(a.Labels)
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[AlertFingerprintLabel] = fingerprint
+	githubIssue := &issuev1.GithubIssue{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "alert-" + fingerprint,
+			Namespace: a.Namespace,
+			Labels:    labels,
+		},
+		Spec: issuev1.GithubIssueSpec{
+			Repo:        a.Repo,
+			Title:       alertTitle(alert),
+			Description: alertDescription(alert),
+		},
+	}
+	if err := a.Client.Create(ctx, githubIssue); err != nil {
+		if apierrors.IsAlreadyExists(err) {
+			return nil
+		}
+		return err
+	}
+	a.Log.Info("alert firing, created its GithubIssue", "githubissue", githubIssue.Name)
+	return nil
+}
+
+// alertFingerprint returns the fingerprint of the alert, computed from its labels when
+// Alertmanager didn't send a usable one. The labels are hashed as JSON, whose keys are
+// sorted and whose values are quoted, so no two label sets share an encoding.
+func alertFingerprint(alert Alert) string {
+	if fingerprintPattern.MatchString(alert.Fingerprint) {
+		return alert.Fingerprint
+	}
+	labels, _ := json.Marshal(alert.Labels)
+	return utils.ContentHash(string(labels))
+}
+
+// alertTitle returns the title of the issue of an alert, its name and summary cut down to
+// GitHub's title limit
+func alertTitle(alert Alert) string {
+	title := alert.Labels["alertname"]
+	if title == "" {
+		title = "Alert"
+	}
+	if summary := alert.Annotations["summary"]; summary != "" {
+		title = fmt.Sprintf("%s: %s", title, summary)
+	}
+	title, _ = utils.TruncateBytes(title, issuev1.MaxTitleLength)
+	return title
+}
+
+// alertDescription returns the description of the issue of an alert, with its annotations,
+// labels and source link. It fits the webhook's description limit: the labels, then the source
+// link, are left out of descriptions that would exceed it, which are truncated as a last resort.
+func alertDescription(alert Alert) string {
+	var header strings.Builder
+	if description := alert.Annotations["description"]; description != "" {
+		header.WriteString(description + "\n\n")
+	}
+	if !alert.StartsAt.IsZero() {
+		fmt.Fprintf(&header, "Firing since %s\n\n", alert.StartsAt.UTC().Format(time.RFC3339))
+	}
+	var labels strings.Builder
+	labels.WriteString("Labels:\n")
+	for _, pair := range sortedPairs(alert.Labels) {
+		fmt.Fprintf(&labels, "- `%s`\n", pair)
+	}
+	source := ""
+	if alert.GeneratorURL != "" {
+		source = fmt.Sprintf("[Source](%s)\n", alert.GeneratorURL)
+	}
+
+	full := header.String() + labels.String()
+	if source != "" {
+		full += "\n" + source
+	}
+	for _, description := range []string{full, header.String() + source} {
+		if len(description) <= issuev1.MaxDescriptionLength {
+			return description
+		}
+	}
+	description, _ := utils.TruncateBytes(header.String(), issuev1.MaxDescriptionLength)
+	return description
+}
+
+// sortedPairs returns the labels as key=value pairs, sorted by key
+func sortedPairs(labels map[string]string) []string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, k+"="+labels[k])
+	}
+	return pairs
+}
@@ -0,0 +1,253 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	issuev1 "github.com/oshribelay/github-issue-operator/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Alertmanager receiver", func() {
+	ctx := context.Background()
+
+	var (
+		c             client.Client
+		a             *AlertmanagerReceiver
+		authorization string
+	)
+
+	payload := func(status string) string {
+		return `{
+  "version": "4",
+  "status": "` + status + `",
+  "receiver": "github",
+  "alerts": [{
+    "status": "` + status + `",
+    "labels": {"alertname": "HighErrorRate", "service": "web", "severity": "page"},
+    "annotations": {"summary": "web is failing requests", "description": "5xx ratio above 5% for 10m"},
+    "startsAt": "2024-06-01T12:00:00Z",
+    "endsAt": "0001-01-01T00:00:00Z",
+    "generatorURL": "http://prometheus/graph?g0.expr=errors",
+    "fingerprint": "3b5c8f2a9d1e4f07"
+  }]
+}`
+	}
+
+	post := func(method, body string) int {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(method, "/alerts", strings.NewReader(body))
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		a.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	alertIssues := func() []issuev1.GithubIssue {
+		list := &issuev1.GithubIssueList{}
+		Expect(c.List(ctx, list, client.InNamespace("monitoring"), client.HasLabels{AlertFingerprintLabel})).To(Succeed())
+		return list.Items
+	}
+
+	BeforeEach(func() {
+		authorization = "Bearer s3cret"
+		s := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(s)).To(Succeed())
+		Expect(issuev1.AddToScheme(s)).To(Succeed())
+		c = fake.NewClientBuilder().WithScheme(s).
+			WithObjects(&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "alertmanager-token", Namespace: "monitoring"},
+				Data:       map[string][]byte{AlertmanagerTokenKey: []byte("s3cret\n")},
+			}).
+			Build()
+		a = &AlertmanagerReceiver{
+			Client:      c,
+			Log:         logr.Discard(),
+			Namespace:   "monitoring",
+			Repo:        "https://github.com/owner/repo",
+			TokenSecret: client.ObjectKey{Namespace: "monitoring", Name: "alertmanager-token"},
+		}
+	})
+
+	It("creates a GithubIssue for a firing alert", func() {
+		Expect(post(http.MethodPost, payload("firing"))).To(Equal(http.StatusOK))
+
+		issues := alertIssues()
+		Expect(issues).To(HaveLen(1))
+		Expect(issues[0].Name).To(Equal("alert-3b5c8f2a9d1e4f07"))
+		Expect(issues[0].Labels).To(HaveKeyWithValue(AlertFingerprintLabel, "3b5c8f2a9d1e4f07"))
+		Expect(issues[0].Spec.Repo).To(Equal("https://github.com/owner/repo"))
+		Expect(issues[0].Spec.Title).To(Equal("HighErrorRate: web is failing requests"))
+		Expect(issues[0].Spec.Description).To(ContainSubstring("5xx ratio above 5% for 10m"))
+		Expect(issues[0].Spec.Description).To(ContainSubstring("`severity=page`"))
+		Expect(issues[0].Spec.Description).To(ContainSubstring("http://prometheus/graph?g0.expr=errors"))
+	})
+
+	It("keeps the description of a typical Prometheus alert within the webhook's limit", func() {
+		body := `{
+  "version": "4",
+  "status": "firing",
+  "alerts": [{
+    "status": "firing",
+    "labels": {"alertname": "KubePodCrashLooping", "container": "api", "endpoint": "http",
+      "job": "kube-state-metrics", "namespace": "payments", "pod": "api-7d9f8c6b5-x2k4q",
+      "prometheus": "monitoring/k8s", "service": "kube-state-metrics", "severity": "warning"},
+    "annotations": {"summary": "Pod is crash looping.",
+      "description": "Pod payments/api-7d9f8c6b5-x2k4q (api) is in waiting state (reason: \"CrashLoopBackOff\")."},
+    "startsAt": "2024-06-01T12:00:00Z",
+    "generatorURL": "http://prometheus-k8s-0:9090/graph?g0.expr=max_over_time%28kube_pod_container_status_waiting_reason%7Breason%3D%22CrashLoopBackOff%22%2Cjob%3D%22kube-state-metrics%22%7D%5B5m%5D%29+%3E%3D+1&g0.tab=1",
+    "fingerprint": "8c1f5d2e7a9b3c04"
+  }]
+}`
+		Expect(post(http.MethodPost, body)).To(Equal(http.StatusOK))
+
+		issues := alertIssues()
+		Expect(issues).To(HaveLen(1))
+		Expect(issues[0].Spec.Description).To(ContainSubstring("CrashLoopBackOff"))
+		Expect(len(issues[0].Spec.Description)).To(BeNumerically("<=", issuev1.MaxDescriptionLength))
+		_, err := issues[0].ValidateCreate()
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("adds the labels of the receiver to the GithubIssues it creates", func() {
+		a.Labels = map[string]string{"shard": "a"}
+		Expect(post(http.MethodPost, payload("firing"))).To(Equal(http.StatusOK))
+
+		issues := alertIssues()
+		Expect(issues).To(HaveLen(1))
+		Expect(issues[0].Labels).To(Equal(map[string]string{"shard": "a", AlertFingerprintLabel: "3b5c8f2a9d1e4f07"}))
+		Expect(a.Labels).To(Equal(map[string]string{"shard": "a"}))
+	})
+
+	It("cuts the title down to GitHub's title limit", func() {
+		alert := Alert{
+			Labels:      map[string]string{"alertname": "Noisy"},
+			Annotations: map[string]string{"summary": strings.Repeat("é", issuev1.MaxTitleLength)},
+		}
+		title := alertTitle(alert)
+		Expect(len(title)).To(BeNumerically("<=", issuev1.MaxTitleLength))
+		Expect(title).To(HavePrefix("Noisy: é"))
+		Expect(title).To(HaveSuffix("...(truncated)"))
+	})
+
+	It("truncates the description of an alert whose annotation alone exceeds the limit", func() {
+		alert := Alert{
+			Labels:      map[string]string{"alertname": "Noisy"},
+			Annotations: map[string]string{"description": strings.Repeat("é", issuev1.MaxDescriptionLength)},
+		}
+		description := alertDescription(alert)
+		Expect(len(description)).To(BeNumerically("<=", issuev1.MaxDescriptionLength))
+		Expect(description).To(HaveSuffix("...(truncated)"))
+	})
+
+	It("dedupes repeated notifications of a firing alert by its fingerprint", func() {
+		Expect(post(http.MethodPost, payload("firing"))).To(Equal(http.StatusOK))
+		Expect(post(http.MethodPost, payload("firing"))).To(Equal(http.StatusOK))
+
+		Expect(alertIssues()).To(HaveLen(1))
+	})
+
+	It("deletes the GithubIssue of a resolved alert, closing its issue", func() {
+		Expect(post(http.MethodPost, payload("firing"))).To(Equal(http.StatusOK))
+		Expect(post(http.MethodPost, payload("resolved"))).To(Equal(http.StatusOK))
+
+		Expect(alertIssues()).To(BeEmpty())
+	})
+
+	It("creates a new GithubIssue when a resolved alert fires again", func() {
+		Expect(post(http.MethodPost, payload("firing"))).To(Equal(http.StatusOK))
+		Expect(post(http.MethodPost, payload("resolved"))).To(Equal(http.StatusOK))
+		Expect(post(http.MethodPost, payload("firing"))).To(Equal(http.StatusOK))
+
+		Expect(alertIssues()).To(HaveLen(1))
+	})
+
+	It("ignores a resolved alert without a GithubIssue", func() {
+		Expect(post(http.MethodPost, payload("resolved"))).To(Equal(http.StatusOK))
+
+		Expect(alertIssues()).To(BeEmpty())
+	})
+
+	It("fingerprints alerts by their labels when Alertmanager sent none", func() {
+		body := strings.Replace(payload("firing"), `"fingerprint": "3b5c8f2a9d1e4f07"`, `"fingerprint": ""`, 1)
+		Expect(post(http.MethodPost, body)).To(Equal(http.StatusOK))
+		Expect(post(http.MethodPost, body)).To(Equal(http.StatusOK))
+
+		issues := alertIssues()
+		Expect(issues).To(HaveLen(1))
+		Expect(issues[0].Labels[AlertFingerprintLabel]).To(MatchRegexp(`^[0-9a-f]+$`))
+	})
+
+	It("keeps label values containing commas whole", func() {
+		joined := Alert{Labels: map[string]string{"alertname": "Disk", "device": "sda,mount=/data"}}
+		split := Alert{Labels: map[string]string{"alertname": "Disk", "device": "sda", "mount": "/data"}}
+		Expect(alertFingerprint(joined)).NotTo(Equal(alertFingerprint(split)))
+
+		description := alertDescription(joined)
+		Expect(description).To(ContainSubstring("- `device=sda,mount=/data`\n"))
+		Expect(description).NotTo(ContainSubstring("- `mount"))
+	})
+
+	It("rejects requests without the bearer token of the token secret", func() {
+		authorization = ""
+		Expect(post(http.MethodPost, payload("firing"))).To(Equal(http.StatusUnauthorized))
+		authorization = "Bearer wrong"
+		Expect(post(http.MethodPost, payload("firing"))).To(Equal(http.StatusUnauthorized))
+		authorization = "Basic czNjcmV0"
+		Expect(post(http.MethodPost, payload("firing"))).To(Equal(http.StatusUnauthorized))
+		Expect(alertIssues()).To(BeEmpty())
+	})
+
+	It("doesn't let unauthenticated requests delete GithubIssues", func() {
+		Expect(post(http.MethodPost, payload("firing"))).To(Equal(http.StatusOK))
+		authorization = ""
+		Expect(post(http.MethodPost, payload("resolved"))).To(Equal(http.StatusUnauthorized))
+		Expect(alertIssues()).To(HaveLen(1))
+	})
+
+	It("rejects every request while the token secret is missing or empty", func() {
+		a.TokenSecret.Name = "missing"
+		Expect(post(http.MethodPost, payload("firing"))).To(Equal(http.StatusUnauthorized))
+
+		Expect(c.Create(ctx, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "empty", Namespace: "monitoring"},
+		})).To(Succeed())
+		a.TokenSecret.Name = "empty"
+		authorization = "Bearer "
+		Expect(post(http.MethodPost, payload("firing"))).To(Equal(http.StatusUnauthorized))
+		Expect(alertIssues()).To(BeEmpty())
+	})
+
+	It("rejects malformed payloads and other methods", func() {
+		Expect(post(http.MethodPost, "{")).To(Equal(http.StatusBadRequest))
+		Expect(post(http.MethodGet, "")).To(Equal(http.StatusMethodNotAllowed))
+		Expect(alertIssues()).To(BeEmpty())
+	})
+})
@@ -0,0 +1,232 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/oshribelay/github-issue-operator/internal/controller/utils"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	issuev1 "github.com/oshribelay/github-issue-operator/api/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// EventBridgeLabel marks the GithubIssues the event bridge created
+const EventBridgeLabel = "issue.core.github.io/bridged-from-event"
+
+// EventBridgeReconciler creates a GithubIssue for the Warning Events whose reason matches,
+// one per involved object and reason
+type EventBridgeReconciler struct {
+	Client client.Client
+	Log    logr.Logger
+
+	// Reason matches the reasons of the Warning Events bridged into GithubIssues
+	Reason *regexp.Regexp
+
+	// Namespace is where the GithubIssues are created
+	Namespace string
+
+	// Repo is the repository URL of the GithubIssues
+	Repo string
+
+	// Labels are added to the GithubIssues created, e.g. the ones the label selector of a
+	// sharded instance requires so that it reconciles them
+	Labels map[string]string
+
+	// Cooldown is how long after bridging an event the GithubIssue of its object and reason is
+	// left alone, so a recurring event doesn't edit the issue every time nor recreate a
+	// GithubIssue just deleted
+	Cooldown time.Duration
+
+	// now returns the current time, overridden in tests
+	now func() time.Time
+
+	mu sync.Mutex
+	// bridged is when the events of every object and reason were last bridged, for the
+	// ones still cooling down
+	bridged map[string]time.Time
+}
+
+// +kubebuilder:rbac:groups="",resources=events,verbs=get;list;watch
+
+// Reconcile creates or refreshes the GithubIssue of a Warning Event
+func (r *EventBridgeReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := r.Log.WithValues("event", req.NamespacedName)
+
+	event := &corev1.Event{}
+	if err := r.Client.Get(ctx, req.NamespacedName, event); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if !r.matches(event) {
+		return ctrl.Result{}, nil
+	}
+
+	key := eventKey(event)
+	now := r.currentTime()
+	if wait := r.cooldownLeft(key, now); wait > 0 {
+		log.V(1).Info("event bridged recently, cooling down", "wait", wait)
+		return ctrl.Result{}, nil
+	}
+
+	githubIssue := &issuev1.GithubIssue{}
+	name := "event-" + utils.ContentHash(key)
+	err := r.Client.Get(ctx, client.ObjectKey{Namespace: r.Namespace, Name: name}, githubIssue)
+	switch {
+	case apierrors.IsNotFound(err):
+		labels := maps.Clone(r.Labels)
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		labels[EventBridgeLabel] = "true"
+		githubIssue = &issuev1.GithubIssue{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: r.Namespace,
+				Labels:    labels,
+			},
+			Spec: issuev1.GithubIssueSpec{
+				Repo:        r.Repo,
+				Title:       eventTitle(event),
+				Description: eventDescription(event),
+			},
+		}
+		if err := r.Client.Create(ctx, githubIssue); err != nil {
+			log.Error(err, "unable to create GithubIssue for event")
+			return ctrl.Result{}, err
+		}
+		log.Info("created GithubIssue for event", "githubissue", name)
+	case err != nil:
+		return ctrl.Result{}, err
+	default:
+		description := eventDescription(event)
+		if githubIssue.Spec.Description != description {
+			githubIssue.Spec.Description = description
+			if err := r.Client.Update(ctx, githubIssue); err != nil {
+				if apierrors.IsConflict(err) {
+					return ctrl.Result{Requeue: true}, nil
+				}
+				log.Error(err, "unable to update GithubIssue for event")
+				return ctrl.Result{}, err
+			}
+			log.Info("updated GithubIssue for recurring event", "githubissue", name)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.bridged == nil {
+		r.bridged = map[string]time.Time{}
+	}
+	// the keys past their cooldown are dropped, so the map doesn't grow with every object
+	// that ever had a Warning event
+	for bridgedKey, last := range r.bridged {
+		if !now.Before(last.Add(r.Cooldown)) {
+			delete(r.bridged, bridgedKey)
+		}
+	}
+	r.bridged[key] = now
+	return ctrl.Result{}, nil
+}
+
+// matches reports whether the event is a Warning whose reason matches
+func (r *EventBridgeReconciler) matches(event *corev1.Event) bool {
+	return event.Type == corev1.EventTypeWarning && r.Reason.MatchString(event.Reason)
+}
+
+// cooldownLeft returns how long until the events of the key can be bridged again
+func (r *EventBridgeReconciler) cooldownLeft(key string, now time.Time) time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	last, ok := r.bridged[key]
+	if !ok {
+		return 0
+	}
+	return max(last.Add(r.Cooldown).Sub(now), 0)
+}
+
+// currentTime returns the current time of the reconciler's clock
+func (r *EventBridgeReconciler) currentTime() time.Time {
+	if r.now != nil {
+		return r.now()
+	}
+	return time.Now()
+}
+
+// eventKey identifies the involved object and reason of an event, the events of the same
+// key share a GithubIssue
+func eventKey(event *corev1.Event) string {
+	object := event.InvolvedObject
+	return fmt.Sprintf("%s/%s/%s/%s/%s", object.APIVersion, object.Kind, object.Namespace, object.Name, event.Reason)
+}
+
+// eventTitle returns the title of the issue of an event, cut down to GitHub's title limit
+func eventTitle(event *corev1.Event) string {
+	title, _ := utils.TruncateBytes(fmt.Sprintf("%s: %s", eventObject(event), event.Reason), issuev1.MaxTitleLength)
+	return title
+}
+
+// eventDescription returns the description of the issue of an event, with its latest message.
+// The message is cut down so the description fits the webhook's description limit.
+func eventDescription(event *corev1.Event) string {
+	const format = "Kubernetes reported a %s event for %s:\n\n> %s\n\nReason: %s%s"
+	suffix := ""
+	if event.Count > 1 {
+		suffix += fmt.Sprintf(", seen %d times", event.Count)
+	}
+	if last := event.LastTimestamp; !last.IsZero() {
+		suffix += ", last at " + last.UTC().Format(time.RFC3339)
+	}
+
+	frame := fmt.Sprintf(format, event.Type, eventObject(event), "", event.Reason, suffix)
+	message, _ := utils.TruncateBytes(event.Message, max(issuev1.MaxDescriptionLength-len(frame), 0))
+	description, _ := utils.TruncateBytes(
+		fmt.Sprintf(format, event.Type, eventObject(event), message, event.Reason, suffix), issuev1.MaxDescriptionLength)
+	return description
+}
+
+// eventObject names the involved object of an event
+func eventObject(event *corev1.Event) string {
+	object := event.InvolvedObject
+	if object.Namespace == "" {
+		return fmt.Sprintf("%s %s", object.Kind, object.Name)
+	}
+	return fmt.Sprintf("%s %s/%s", object.Kind, object.Namespace, object.Name)
+}
+
+// SetupWithManager sets up the controller with the Manager, only watching the Warning Events
+// whose reason matches
+func (r *EventBridgeReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("eventbridge").
+		For(&corev1.Event{}, builder.WithPredicates(predicate.NewPredicateFuncs(func(obj client.Object) bool {
+			event, ok := obj.(*corev1.Event)
+			return ok && r.matches(event)
+		}))).
+		Complete(r)
+}
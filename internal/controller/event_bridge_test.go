@@ -0,0 +1,206 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	issuev1 "github.com/oshribelay/github-issue-operator/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Event bridge", func() {
+	ctx := context.Background()
+	start := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	var (
+		now time.Time
+		c   client.Client
+		r   *EventBridgeReconciler
+	)
+
+	warning := func(name, pod, reason string, count int32) *corev1.Event {
+		return &corev1.Event{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "apps"},
+			InvolvedObject: corev1.ObjectReference{
+				APIVersion: "v1", Kind: "Pod", Namespace: "apps", Name: pod,
+			},
+			Type:          corev1.EventTypeWarning,
+			Reason:        reason,
+			Message:       "Back-off restarting failed container",
+			Count:         count,
+			LastTimestamp: metav1.NewTime(start),
+		}
+	}
+
+	// bridge stores the event and reconciles it
+	bridge := func(event *corev1.Event) {
+		existing := &corev1.Event{}
+		if err := c.Get(ctx, client.ObjectKeyFromObject(event), existing); err == nil {
+			event.ResourceVersion = existing.ResourceVersion
+			Expect(c.Update(ctx, event)).To(Succeed())
+		} else {
+			Expect(c.Create(ctx, event)).To(Succeed())
+		}
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(event)})
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	bridgedIssues := func() []issuev1.GithubIssue {
+		list := &issuev1.GithubIssueList{}
+		Expect(c.List(ctx, list, client.InNamespace("operator"), client.HasLabels{EventBridgeLabel})).To(Succeed())
+		return list.Items
+	}
+
+	BeforeEach(func() {
+		now = start
+		s := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(s)).To(Succeed())
+		Expect(issuev1.AddToScheme(s)).To(Succeed())
+		c = fake.NewClientBuilder().WithScheme(s).Build()
+		r = &EventBridgeReconciler{
+			Client:    c,
+			Log:       logr.Discard(),
+			Reason:    regexp.MustCompile(`^(BackOff|FailedMount)$`),
+			Namespace: "operator",
+			Repo:      "https://github.com/owner/repo",
+			Cooldown:  time.Hour,
+			now:       func() time.Time { return now },
+		}
+	})
+
+	It("creates a GithubIssue for a matching Warning event", func() {
+		bridge(warning("web-1.abc", "web-1", "BackOff", 1))
+
+		issues := bridgedIssues()
+		Expect(issues).To(HaveLen(1))
+		Expect(issues[0].Spec.Repo).To(Equal("https://github.com/owner/repo"))
+		Expect(issues[0].Spec.Title).To(Equal("Pod apps/web-1: BackOff"))
+		Expect(issues[0].Spec.Description).To(ContainSubstring("> Back-off restarting failed container"))
+		Expect(issues[0].Spec.Description).To(ContainSubstring("last at 2024-06-01T12:00:00Z"))
+	})
+
+	It("adds the labels of the instance to the GithubIssues it creates", func() {
+		r.Labels = map[string]string{"shard": "a"}
+		bridge(warning("web-1.abc", "web-1", "BackOff", 1))
+
+		issues := bridgedIssues()
+		Expect(issues).To(HaveLen(1))
+		Expect(issues[0].Labels).To(Equal(map[string]string{"shard": "a", EventBridgeLabel: "true"}))
+		Expect(r.Labels).To(Equal(map[string]string{"shard": "a"}))
+	})
+
+	It("cuts the title down to GitHub's title limit", func() {
+		bridge(warning("long.abc", strings.Repeat("p", 253), "BackOff", 1))
+
+		issues := bridgedIssues()
+		Expect(issues).To(HaveLen(1))
+		Expect(len(issues[0].Spec.Title)).To(BeNumerically("<=", issuev1.MaxTitleLength))
+		Expect(issues[0].Spec.Title).To(HavePrefix("Pod apps/ppp"))
+		Expect(issues[0].Spec.Title).To(HaveSuffix("...(truncated)"))
+	})
+
+	It("ignores Normal events and reasons that don't match", func() {
+		normal := warning("web-1.normal", "web-1", "BackOff", 1)
+		normal.Type = corev1.EventTypeNormal
+		bridge(normal)
+		bridge(warning("web-1.pulled", "web-1", "FailedScheduling", 1))
+
+		Expect(bridgedIssues()).To(BeEmpty())
+	})
+
+	It("shares a GithubIssue between the events of the same object and reason", func() {
+		bridge(warning("web-1.abc", "web-1", "BackOff", 1))
+		now = start.Add(2 * time.Hour)
+		bridge(warning("web-1.def", "web-1", "BackOff", 1))
+		bridge(warning("web-1.ghi", "web-1", "FailedMount", 1))
+		bridge(warning("web-2.abc", "web-2", "BackOff", 1))
+
+		Expect(bridgedIssues()).To(HaveLen(3))
+	})
+
+	It("only refreshes the description of a recurring event after the cooldown", func() {
+		bridge(warning("web-1.abc", "web-1", "BackOff", 1))
+
+		now = start.Add(30 * time.Minute)
+		bridge(warning("web-1.abc", "web-1", "BackOff", 5))
+		issues := bridgedIssues()
+		Expect(issues).To(HaveLen(1))
+		Expect(issues[0].Spec.Description).NotTo(ContainSubstring("seen"))
+
+		now = start.Add(time.Hour)
+		bridge(warning("web-1.abc", "web-1", "BackOff", 8))
+		issues = bridgedIssues()
+		Expect(issues).To(HaveLen(1))
+		Expect(issues[0].Spec.Description).To(ContainSubstring("seen 8 times"))
+	})
+
+	It("doesn't recreate a deleted GithubIssue during the cooldown", func() {
+		bridge(warning("web-1.abc", "web-1", "BackOff", 1))
+		issues := bridgedIssues()
+		Expect(issues).To(HaveLen(1))
+		Expect(c.Delete(ctx, &issues[0])).To(Succeed())
+
+		now = start.Add(10 * time.Minute)
+		bridge(warning("web-1.abc", "web-1", "BackOff", 2))
+		Expect(bridgedIssues()).To(BeEmpty())
+
+		now = start.Add(90 * time.Minute)
+		bridge(warning("web-1.abc", "web-1", "BackOff", 3))
+		Expect(bridgedIssues()).To(HaveLen(1))
+	})
+
+	It("cuts down the message of a long event so its GithubIssue is admitted", func() {
+		r.Reason = regexp.MustCompile(`^FailedScheduling$`)
+		event := warning("checkout-7d9f8c6b5-x2k4q.17c4", "checkout-7d9f8c6b5-x2k4q", "FailedScheduling", 42)
+		event.Message = "0/12 nodes are available: 3 node(s) had untolerated taint {node-role.kubernetes.io/control-plane: }, " +
+			"4 Insufficient memory, 5 node(s) didn't match Pod's node affinity/selector. preemption: 0/12 nodes are available: " +
+			"7 No preemption victims found for incoming pod, 5 Preemption is not helpful for scheduling."
+		bridge(event)
+
+		issues := bridgedIssues()
+		Expect(issues).To(HaveLen(1))
+		Expect(issues[0].Spec.Description).To(ContainSubstring("> 0/12 nodes are available"))
+		Expect(issues[0].Spec.Description).To(HaveSuffix("Reason: FailedScheduling, seen 42 times, last at 2024-06-01T12:00:00Z"))
+		Expect(len(issues[0].Spec.Description)).To(BeNumerically("<=", issuev1.MaxDescriptionLength))
+		_, err := issues[0].ValidateCreate()
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("forgets the events past their cooldown", func() {
+		bridge(warning("web-1.abc", "web-1", "BackOff", 1))
+		bridge(warning("web-2.abc", "web-2", "BackOff", 1))
+		Expect(r.bridged).To(HaveLen(2))
+
+		now = start.Add(2 * time.Hour)
+		bridge(warning("web-3.abc", "web-3", "BackOff", 1))
+		Expect(r.bridged).To(HaveLen(1))
+		Expect(r.bridged).To(HaveKey(eventKey(warning("web-3.abc", "web-3", "BackOff", 1))))
+	})
+})
@@ -9,7 +9,12 @@ import (
 
 const githubIssueFinalizer = "finalizer.githubissue.issue.core.github.io"
 
+// EnsureFinalizer adds the finalizer closing the issue on deletion, unless the GithubIssue
+// skips it, in which case a finalizer added before is removed instead
 func EnsureFinalizer(ctx context.Context, c client.Client, githubIssue *v1.GithubIssue) error {
+	if githubIssue.Spec.SkipFinalizer {
+		return RemoveFinalizer(ctx, c, githubIssue)
+	}
 	if !controllerutil.ContainsFinalizer(githubIssue, githubIssueFinalizer) {
 		controllerutil.AddFinalizer(githubIssue, githubIssueFinalizer)
 		if err := c.Update(ctx, githubIssue); err != nil {
@@ -19,6 +24,12 @@ func EnsureFinalizer(ctx context.Context, c client.Client, githubIssue *v1.Githu
 	return nil
 }
 
+// Present reports whether the GithubIssue holds the finalizer closing its issue on deletion
+func Present(githubIssue *v1.GithubIssue) bool {
+	return controllerutil.ContainsFinalizer(githubIssue, githubIssueFinalizer)
+}
+
+// RemoveFinalizer removes the finalizer, letting the GithubIssue be deleted
 func RemoveFinalizer(ctx context.Context, c client.Client, githubIssue *v1.GithubIssue) error {
 	if controllerutil.ContainsFinalizer(githubIssue, githubIssueFinalizer) {
 		controllerutil.RemoveFinalizer(githubIssue, githubIssueFinalizer)
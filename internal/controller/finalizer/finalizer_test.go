@@ -0,0 +1,58 @@
+package finalizer
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "github.com/oshribelay/github-issue-operator/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("EnsureFinalizer", func() {
+	ctx := context.Background()
+
+	newClient := func(githubIssue *v1.GithubIssue) client.Client {
+		s := runtime.NewScheme()
+		Expect(v1.AddToScheme(s)).To(Succeed())
+		return fake.NewClientBuilder().WithScheme(s).WithObjects(githubIssue).Build()
+	}
+	finalizers := func(c client.Client, githubIssue *v1.GithubIssue) []string {
+		stored := &v1.GithubIssue{}
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(githubIssue), stored)).To(Succeed())
+		return stored.Finalizers
+	}
+
+	It("Should add the finalizer by default", func() {
+		githubIssue := &v1.GithubIssue{ObjectMeta: metav1.ObjectMeta{Name: "issue", Namespace: "default"}}
+		c := newClient(githubIssue)
+
+		Expect(EnsureFinalizer(ctx, c, githubIssue)).To(Succeed())
+		Expect(finalizers(c, githubIssue)).To(ConsistOf(githubIssueFinalizer))
+	})
+
+	It("Should not add the finalizer when skipped", func() {
+		githubIssue := &v1.GithubIssue{
+			ObjectMeta: metav1.ObjectMeta{Name: "issue", Namespace: "default"},
+			Spec:       v1.GithubIssueSpec{SkipFinalizer: true},
+		}
+		c := newClient(githubIssue)
+
+		Expect(EnsureFinalizer(ctx, c, githubIssue)).To(Succeed())
+		Expect(finalizers(c, githubIssue)).To(BeEmpty())
+	})
+
+	It("Should remove the finalizer once it is skipped", func() {
+		githubIssue := &v1.GithubIssue{
+			ObjectMeta: metav1.ObjectMeta{Name: "issue", Namespace: "default", Finalizers: []string{githubIssueFinalizer}},
+			Spec:       v1.GithubIssueSpec{SkipFinalizer: true},
+		}
+		c := newClient(githubIssue)
+
+		Expect(EnsureFinalizer(ctx, c, githubIssue)).To(Succeed())
+		Expect(finalizers(c, githubIssue)).To(BeEmpty())
+	})
+})
@@ -0,0 +1,64 @@
+package freeze
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// StartKey is the key of the freeze ConfigMap holding when the freeze starts, as RFC 3339
+	StartKey = "start"
+	// EndKey is the key of the freeze ConfigMap holding when the freeze ends, as RFC 3339
+	EndKey = "end"
+	// ReasonKey is the optional key of the freeze ConfigMap explaining the freeze
+	ReasonKey = "reason"
+)
+
+// Window is a change freeze, during which the operator doesn't edit issues
+type Window struct {
+	Start  time.Time
+	End    time.Time
+	Reason string
+}
+
+// Active reports whether now is within the freeze. During the freeze, endsIn is how long
+// until it ends.
+func (w *Window) Active(now time.Time) (active bool, endsIn time.Duration) {
+	if now.Before(w.Start) || !now.Before(w.End) {
+		return false, 0
+	}
+	return true, w.End.Sub(now)
+}
+
+// Parse reads the freeze window from the data of the freeze ConfigMap
+func Parse(data map[string]string) (*Window, error) {
+	start, err := time.Parse(time.RFC3339, data[StartKey])
+	if err != nil {
+		return nil, fmt.Errorf("invalid freeze %s: %w", StartKey, err)
+	}
+	end, err := time.Parse(time.RFC3339, data[EndKey])
+	if err != nil {
+		return nil, fmt.Errorf("invalid freeze %s: %w", EndKey, err)
+	}
+	if !end.After(start) {
+		return nil, fmt.Errorf("freeze %s %s must be after its %s %s", EndKey, data[EndKey], StartKey, data[StartKey])
+	}
+	return &Window{Start: start, End: end, Reason: data[ReasonKey]}, nil
+}
+
+// Load reads the freeze window from the ConfigMap, nil when there is no such ConfigMap
+func Load(ctx context.Context, reader client.Reader, key client.ObjectKey) (*Window, error) {
+	configMap := &corev1.ConfigMap{}
+	if err := reader.Get(ctx, key, configMap); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get freeze ConfigMap %s: %w", key, err)
+	}
+	return Parse(configMap.Data)
+}
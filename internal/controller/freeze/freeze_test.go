@@ -0,0 +1,72 @@
+package freeze
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Freeze", func() {
+	start := time.Date(2024, 12, 20, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+	data := map[string]string{
+		StartKey:  start.Format(time.RFC3339),
+		EndKey:    end.Format(time.RFC3339),
+		ReasonKey: "holidays",
+	}
+
+	It("Should parse the window", func() {
+		window, err := Parse(data)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(window.Start.Equal(start)).To(BeTrue())
+		Expect(window.End.Equal(end)).To(BeTrue())
+		Expect(window.Reason).To(Equal("holidays"))
+	})
+
+	DescribeTable("Should tell whether the freeze is active",
+		func(now time.Time, active bool, endsIn time.Duration) {
+			window, err := Parse(data)
+			Expect(err).NotTo(HaveOccurred())
+			gotActive, gotEndsIn := window.Active(now)
+			Expect(gotActive).To(Equal(active))
+			Expect(gotEndsIn).To(Equal(endsIn))
+		},
+		Entry("before the freeze", start.Add(-time.Minute), false, time.Duration(0)),
+		Entry("as the freeze starts", start, true, end.Sub(start)),
+		Entry("during the freeze", end.Add(-time.Hour), true, time.Hour),
+		Entry("as the freeze ends", end, false, time.Duration(0)),
+	)
+
+	DescribeTable("Should reject invalid windows",
+		func(start, end string) {
+			_, err := Parse(map[string]string{StartKey: start, EndKey: end})
+			Expect(err).To(HaveOccurred())
+		},
+		Entry("missing start", "", "2025-01-06T00:00:00Z"),
+		Entry("malformed end", "2024-12-20T00:00:00Z", "2025-01-06"),
+		Entry("end before start", "2025-01-06T00:00:00Z", "2024-12-20T00:00:00Z"),
+	)
+
+	It("Should load the window from the ConfigMap, nil without one", func() {
+		ctx := context.Background()
+		key := client.ObjectKey{Namespace: "operator", Name: "change-freeze"}
+		configMap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name},
+			Data:       data,
+		}
+
+		window, err := Load(ctx, fake.NewClientBuilder().Build(), key)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(window).To(BeNil())
+
+		window, err = Load(ctx, fake.NewClientBuilder().WithObjects(configMap).Build(), key)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(window.Reason).To(Equal("holidays"))
+	})
+})
@@ -18,38 +18,385 @@ package controller
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/go-logr/logr"
+	"github.com/google/go-github/v47/github"
 	"github.com/oshribelay/github-issue-operator/internal/controller/finalizer"
+	"github.com/oshribelay/github-issue-operator/internal/controller/freeze"
+	"github.com/oshribelay/github-issue-operator/internal/controller/linked"
+	"github.com/oshribelay/github-issue-operator/internal/controller/metrics"
+	"github.com/oshribelay/github-issue-operator/internal/controller/notify"
 	"github.com/oshribelay/github-issue-operator/internal/controller/resources"
 	"github.com/oshribelay/github-issue-operator/internal/controller/status"
+	"github.com/oshribelay/github-issue-operator/internal/controller/summary"
+	"github.com/oshribelay/github-issue-operator/internal/controller/templates"
+	"github.com/oshribelay/github-issue-operator/internal/controller/tokenfile"
 	"github.com/oshribelay/github-issue-operator/internal/controller/utils"
+	"github.com/oshribelay/github-issue-operator/internal/footer"
+	"github.com/oshribelay/github-issue-operator/internal/frontmatter"
+	"github.com/oshribelay/github-issue-operator/internal/labeltemplate"
+	"github.com/oshribelay/github-issue-operator/internal/repourl"
+	"golang.org/x/time/rate"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/util/workqueue"
+	"os"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
 	"time"
+	"unicode/utf8"
 
 	issuev1 "github.com/oshribelay/github-issue-operator/api/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+// defaultSecondaryRateLimitCooldown is how long to back off from a secondary rate limit GitHub
+// didn't say when to retry after
+const defaultSecondaryRateLimitCooldown = time.Minute
+
+// teamMembershipRecheck is how often the team of an issue assigned from a team is listed
+// again, reassigning the issue once its assignee left the team
+const teamMembershipRecheck = 10 * time.Minute
+
+// forbiddenRequeue is how long to wait before retrying a token lacking scopes or access, which
+// takes a human to fix. Updating the token secret reconciles the issue right away.
+const forbiddenRequeue = 10 * time.Minute
+
 // GithubIssueReconciler reconciles a GithubIssue object
 type GithubIssueReconciler struct {
-	Client       client.Client
+	Client client.Client
+	Scheme *runtime.Scheme
+	Log    logr.Logger
+
+	// SeedTokenEnv names an environment variable of the operator whose value is used to
+	// pre-populate newly created token secrets. Empty leaves the secrets blank.
+	SeedTokenEnv string
+
+	// TruncateBody truncates bodies exceeding GitHub's limit instead of failing the request,
+	// reporting it through the BodyTruncated condition.
+	TruncateBody bool
+
+	// UserAgent is sent with every GitHub request
+	UserAgent string
+
+	// CategoryLabels maps every issue category to the label attached for it
+	CategoryLabels map[string]string
+
+	// SeverityLabels maps every issue severity to the label attached for it
+	SeverityLabels map[string]string
+
+	// SeverityEmojis maps issue severities to the emoji prepended to the issue title
+	SeverityEmojis map[string]string
+
+	// AdoptOnlyOwned tags the issues the operator creates with an invisible marker and
+	// never adopts an existing issue lacking it
+	AdoptOnlyOwned bool
+
+	// TitleMatch is how existing issues are matched by title, exact when empty
+	TitleMatch resources.TitleMatch
+
+	// TokenFile is the GitHub token of every GithubIssue when set, read from a mounted file
+	// in place of the token secrets
+	TokenFile *tokenfile.Source
+
+	// Selector restricts the GithubIssues reconciled to those whose labels match, sharding
+	// them across operator instances. Nil reconciles every GithubIssue. Collisions and the
+	// open issues of assignees are looked for among the selected GithubIssues only.
+	Selector labels.Selector
+
+	// TokenSecretNames names the token secrets of GithubIssues, "<name>-token-secret" when nil
+	TokenSecretNames *resources.TokenSecretNames
+
+	// LinkedKinds are the kinds GithubIssues can link to in their namespace, linked.DefaultKinds
+	// when nil. Kinds beyond the defaults need their own RBAC.
+	LinkedKinds []schema.GroupKind
+
+	// AllowedAPIHosts are the hosts an API base URL override may point at besides
+	// api.github.com. The tokens, the seeded and file ones included, are never sent elsewhere.
+	AllowedAPIHosts []string
+
+	// TokenKey is the key of the token in the token secret, when it is missing the first
+	// key holding something that looks like a GitHub token is used. Defaults to "token".
+	TokenKey string
+
+	// AtRiskWithin adds the at-risk label to open issues whose milestone is due within
+	// this window, zero disables it
+	AtRiskWithin time.Duration
+
+	// MinStateChangeInterval is how long after the operator opened or closed an issue it
+	// waits before changing its state again, zero disables the throttling
+	MinStateChangeInterval time.Duration
+
+	// FreezeConfigMap is the ConfigMap holding the change freeze window, during which issues
+	// aren't edited, an empty name disables freezes
+	FreezeConfigMap client.ObjectKey
+
+	// APIReader reads the freeze ConfigMap directly rather than through the cache, which
+	// would watch every ConfigMap. The client is used without one.
+	APIReader client.Reader
+
+	// CreationLimiter bounds how fast the operator creates issues, protecting the repos from
+	// a runaway apply, nil doesn't limit creations
+	CreationLimiter *rate.Limiter
+
+	// UpdateDelay is how long reconciles of GithubIssues that already have an issue are
+	// delayed when they're added or changed, so the issues of new GithubIssues are created
+	// first during large applies. Zero reconciles them in the order they come.
+	UpdateDelay time.Duration
+
+	// LabelTemplates are the body templates picked by the labels of the issue
+	LabelTemplates *templates.Set
+
+	// TitlePrefix is prepended to the issue titles, rendered with ClusterName and the
+	// namespace of the GithubIssue
+	TitlePrefix *template.Template
+
+	// ClusterName identifies the cluster in the title prefix
+	ClusterName string
+
+	// MaxAssignedIssues is the number of open issues a login can be assigned across the
+	// GithubIssues before the AssigneesOverloaded condition warns, zero disables the check
+	MaxAssignedIssues int
+
+	// VersionLabel labels the issues with the operator version managing them when set
+	VersionLabel bool
+
+	// ReuseGithubClients keeps the GitHub clients of single token secrets across reconciles,
+	// reusing their connections instead of building a client every reconcile
+	ReuseGithubClients bool
+
+	// MissingTokenRequeue is how long to wait before retrying an issue whose secret holds
+	// no token, defaults to a minute
+	MissingTokenRequeue time.Duration
+
+	// ConflictRequeue is how long to wait before retrying after a conflicting update,
+	// defaults to 5 seconds
+	ConflictRequeue time.Duration
+
+	// CreateDedupeWindow is how long an issue created for a repository and title is handed to
+	// other reconciles creating the same one, which GitHub may not list yet, defaults to 30 seconds
+	CreateDedupeWindow time.Duration
+
+	// MaxConcurrentReconciles is how many GithubIssues are reconciled at once, one when zero
+	MaxConcurrentReconciles int
+
+	// RepoProbeInterval is how often each repository is probed for the RepoReachable
+	// condition, the result being shared by the GithubIssues targeting it. Zero disables the probe.
+	RepoProbeInterval time.Duration
+
+	// PageSize is how many issues are listed per GitHub request, GitHub's default of 30 when zero
+	PageSize int
+
+	// Notifier delivers the notifications of the GithubIssues with a notify URL. When nil
+	// a default notifier is used, which allows no host and so sends nothing.
+	Notifier *notify.Notifier
+
+	controller     controller.Controller
+	cache          cache.Cache
+	watchedKinds   map[schema.GroupVersionKind]bool
+	watchedKindsMu sync.Mutex
+
+	// tokenPools keeps the pools of the secrets holding multiple tokens, so the tracked
+	// rate limits survive across reconciles. A pool is dropped once its secret no longer
+	// holds multiple tokens or went unused for githubClientIdle.
+	tokenPools   map[client.ObjectKey]*cachedTokenPool
+	tokenPoolsMu sync.Mutex
+
+	// githubClients keeps the reused GitHub clients by the hash of their token and their API
+	// base URL, githubClientKeys the key each token secret last used with an API base URL so
+	// rotated tokens are evicted
+	githubClients    map[string]*cachedGithubClient
+	githubClientKeys map[githubClientUser]githubClientUse
+	githubClientsMu  sync.Mutex
+
+	// secondaryRateLimitUntil is when GitHub's secondary rate limit cools down, every
+	// reconcile is held back until then
+	secondaryRateLimitUntil time.Time
+	secondaryRateLimitMu    sync.Mutex
+
+	// serverVersions keeps the GitHub Enterprise Server version detected per API base URL
+	serverVersions   map[string]string
+	serverVersionsMu sync.Mutex
+
+	// repoProbes keeps the last probe of each repository by token secret, API base URL and
+	// repository, dropped once older than RepoProbeInterval
+	repoProbes   map[string]*repoProbe
+	repoProbesMu sync.Mutex
+
+	// recentCreates keeps the issue creations in flight or within CreateDedupeWindow by
+	// repository and title
+	recentCreates   map[string]*recentCreate
+	recentCreatesMu sync.Mutex
+
+	// now returns the current time, replaced in tests
+	now func() time.Time
+}
+
+// issueReconcile is a single reconcile of a GithubIssue, holding the GitHub client built for
+// it apart from the reconciler, which reconciles several GithubIssues at once
+type issueReconcile struct {
+	*GithubIssueReconciler
+
+	// GithubClient is the GitHub client of the token and API base URL of the GithubIssue
 	GithubClient *resources.GithubClient
-	Scheme       *runtime.Scheme
-	Log          logr.Logger
 }
 
 // +kubebuilder:rbac:groups=issue.core.github.io,resources=githubissues,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=issue.core.github.io,resources=githubissues/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=issue.core.github.io,resources=githubissues/finalizers,verbs=update
 // +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get
+// +kubebuilder:rbac:groups=apps,resources=deployments;statefulsets;daemonsets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
 func (r *GithubIssueReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	// hold every reconcile back while GitHub's secondary rate limit cools down, so the
+	// other issues don't compound it
+	if wait := r.secondaryRateLimitWait(); wait > 0 {
+		return ctrl.Result{RequeueAfter: wait}, nil
+	}
+
+	ir := &issueReconcile{GithubIssueReconciler: r}
+	result, err := ir.reconcile(ctx, req)
+	var abuseErr *github.AbuseRateLimitError
+	if errors.As(err, &abuseErr) {
+		return r.handleSecondaryRateLimit(ctx, req, abuseErr)
+	}
+	if retryAfter, ok := resources.ServiceUnavailable(err, r.currentTime()); ok {
+		return r.handleUnavailable(ctx, req, retryAfter)
+	}
+	if resources.IsNotFound(err) {
+		return ir.handleNotFound(ctx, req, err)
+	}
+	if resources.IsBodyTooLarge(err) {
+		return r.handleBodyTooLarge(ctx, req)
+	}
+	switch forbiddenErr := resources.ClassifyForbidden(err).(type) {
+	case *resources.RateLimitedError, *resources.ScopeInsufficientError, *resources.AccessDeniedError:
+		return r.handleForbidden(ctx, req, forbiddenErr)
+	}
+	return result, err
+}
+
+// handleForbidden reports why GitHub answered the reconcile with 403 through the RateLimited,
+// TokenScopeInsufficient or AccessDenied condition. A rate limited issue is requeued once the
+// rate limit resets, other 403s after forbiddenRequeue.
+func (r *GithubIssueReconciler) handleForbidden(ctx context.Context, req ctrl.Request, forbiddenErr error) (ctrl.Result, error) {
+	log := r.Log.WithValues("githubissue", req.NamespacedName)
+	log.Error(forbiddenErr, "GitHub denied the request")
+
+	requeueAfter := forbiddenRequeue
+	var rateErr *resources.RateLimitedError
+	if errors.As(forbiddenErr, &rateErr) {
+		requeueAfter = max(rateErr.Reset.Sub(r.currentTime()), time.Second)
+	}
+
+	// best-effort, the condition is cleared by the next successful reconcile
+	githubIssue := &issuev1.GithubIssue{}
+	if err := r.Client.Get(ctx, req.NamespacedName, githubIssue); err == nil {
+		if err := status.UpdateForbidden(ctx, r.Client, githubIssue, forbiddenErr); err != nil {
+			log.Error(err, "unable to update status of the denied request")
+		}
+	}
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
+
+// handleUnavailable requeues a GithubIssue after the interval GitHub asked to wait while it is
+// unavailable, instead of the default error backoff, reporting it through the GitHubUnavailable
+// condition
+func (r *GithubIssueReconciler) handleUnavailable(ctx context.Context, req ctrl.Request, retryAfter time.Duration) (ctrl.Result, error) {
+	log := r.Log.WithValues("githubissue", req.NamespacedName)
+	log.Info("GitHub is unavailable, requeueing", "retryAfter", retryAfter)
+
+	// best-effort, the condition is cleared by the next successful reconcile
+	githubIssue := &issuev1.GithubIssue{}
+	if err := r.Client.Get(ctx, req.NamespacedName, githubIssue); err == nil {
+		if err := status.UpdateGitHubUnavailable(ctx, r.Client, githubIssue, retryAfter); err != nil {
+			log.Error(err, "unable to update GitHubUnavailable status")
+		}
+	}
+	return ctrl.Result{RequeueAfter: retryAfter}, nil
+}
+
+// handleNotFound probes the repository of a GithubIssue GitHub answered with 404. A deleted
+// repository is reported through the RepoNotFound condition and not retried until the spec
+// changes, other 404s are returned to be retried.
+func (r *issueReconcile) handleNotFound(ctx context.Context, req ctrl.Request, notFoundErr error) (ctrl.Result, error) {
+	log := r.Log.WithValues("githubissue", req.NamespacedName)
+
+	githubIssue := &issuev1.GithubIssue{}
+	if err := r.Client.Get(ctx, req.NamespacedName, githubIssue); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	owner, repo, err := utils.ParseRepoUrl(githubIssue.Spec.Repo)
+	if err != nil || r.GithubClient == nil {
+		return ctrl.Result{}, notFoundErr
+	}
+	exists, err := r.GithubClient.RepoExists(ctx, owner, repo)
+	if err != nil {
+		log.Error(err, "unable to probe the repository")
+		return ctrl.Result{}, notFoundErr
+	}
+	if exists {
+		return ctrl.Result{}, notFoundErr
+	}
+
+	log.Info("repository not found, not retrying until the spec changes", "repo", githubIssue.Spec.Repo)
+	if err := status.UpdateRepoNotFound(ctx, r.Client, githubIssue); err != nil {
+		if apierrors.IsConflict(err) {
+			return ctrl.Result{RequeueAfter: r.conflictRequeue()}, nil
+		}
+		log.Error(err, "unable to update RepoNotFound status")
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// handleBodyTooLarge reports a body GitHub refused as too long through the BodyTooLarge
+// condition, not retrying the GithubIssue until the spec changes rather than looping on it
+func (r *GithubIssueReconciler) handleBodyTooLarge(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := r.Log.WithValues("githubissue", req.NamespacedName)
+
+	githubIssue := &issuev1.GithubIssue{}
+	if err := r.Client.Get(ctx, req.NamespacedName, githubIssue); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	log.Info("issue body is too long for GitHub, not retrying until the spec changes")
+	if err := status.UpdateBodyTooLarge(ctx, r.Client, githubIssue); err != nil {
+		if apierrors.IsConflict(err) {
+			return ctrl.Result{RequeueAfter: r.conflictRequeue()}, nil
+		}
+		log.Error(err, "unable to update BodyTooLarge status")
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// reconcile reconciles the GithubIssue with its GitHub issue
+func (r *issueReconcile) reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	log := r.Log.WithValues("githubissue", req.NamespacedName)
 	log.Info("Reconciling GithubIssue")
 
@@ -60,53 +407,151 @@ func (r *GithubIssueReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
-	// check if issue is marked for deletion (has DeletionTimestamp)
-	if !githubIssue.GetDeletionTimestamp().IsZero() {
-		// delete the issue from GitHub and remove it from the cluster
-		if err := status.Delete(ctx, r.Client, r.GithubClient, githubIssue); err != nil {
-			log.Error(err, "unable to delete GithubIssue")
-			return ctrl.Result{}, err
+	// the token secret and linked resource watches enqueue GithubIssues of other instances too
+	if !r.selects(githubIssue) {
+		log.V(1).Info("GithubIssue doesn't match the label selector, left to another instance")
+		return ctrl.Result{}, nil
+	}
+
+	// hold every GitHub edit, closing deleted GithubIssues included, during a change freeze
+	frozenFor, err := r.frozen(ctx, githubIssue)
+	if apierrors.IsConflict(err) {
+		log.Info("conflict occurred, requeueing...")
+		return ctrl.Result{RequeueAfter: r.conflictRequeue()}, nil
+	}
+	if err != nil {
+		log.Error(err, "unable to check the change freeze")
+		return ctrl.Result{}, err
+	}
+	if frozenFor > 0 {
+		log.Info("change freeze in effect, holding edits", "endsIn", frozenFor)
+		return ctrl.Result{RequeueAfter: frozenFor}, nil
+	}
+
+	// the token is sent to the API base URL, only allowed hosts may receive it
+	apiHostAllowed := githubIssue.Spec.APIBaseURL == "" || repourl.APIHostAllowed(githubIssue.Spec.APIBaseURL, r.AllowedAPIHosts)
+
+	// check if issue is marked for deletion (has DeletionTimestamp), its issue is closed once
+	// the GitHub client of the GithubIssue is built below
+	deleting := !githubIssue.GetDeletionTimestamp().IsZero()
+	if deleting {
+		// issues skipping the finalizer are left open on GitHub, and there's nothing to close
+		// in a deleted repository, once the issue became a discussion or behind a host the
+		// token mustn't be sent to
+		if githubIssue.Spec.SkipFinalizer || status.RepoNotFound(githubIssue) || status.ConvertedToDiscussion(githubIssue) || !apiHostAllowed {
+			if !apiHostAllowed {
+				log.Info("API base URL host isn't allowed, leaving the issue open", "apiBaseURL", githubIssue.Spec.APIBaseURL)
+			}
+			if err := finalizer.RemoveFinalizer(ctx, r.Client, githubIssue); err != nil {
+				log.Error(err, "unable to remove finalizer")
+				return ctrl.Result{}, err
+			}
+			return ctrl.Result{}, nil
 		}
+	}
 
-		if err := finalizer.RemoveFinalizer(ctx, r.Client, githubIssue); err != nil {
-			log.Error(err, "unable to remove finalizer")
+	// a deleted repository isn't retried until the spec changes
+	if status.RepoNotFound(githubIssue) {
+		log.Info("repository not found, waiting for the spec to change")
+		return ctrl.Result{}, nil
+	}
+	// nor is an issue converted to a discussion
+	if status.ConvertedToDiscussion(githubIssue) {
+		log.Info("issue converted to a discussion, waiting for the spec to change")
+		return ctrl.Result{}, nil
+	}
+	// nor is a body GitHub refused as too long, the issue of a deleted GithubIssue is closed still
+	if !deleting && status.BodyTooLarge(githubIssue) {
+		log.Info("issue body too long for GitHub, waiting for the spec to change")
+		return ctrl.Result{}, nil
+	}
+
+	if !apiHostAllowed {
+		log.Info("API base URL host isn't allowed, waiting for the spec to change", "apiBaseURL", githubIssue.Spec.APIBaseURL)
+		if err := status.UpdateAPIHostForbidden(ctx, r.Client, githubIssue); err != nil {
+			if apierrors.IsConflict(err) {
+				return ctrl.Result{RequeueAfter: r.conflictRequeue()}, nil
+			}
+			log.Error(err, "unable to update APIHostForbidden status")
 			return ctrl.Result{}, err
 		}
 		return ctrl.Result{}, nil
 	}
+	// written by the status update once the allowed spec is reconciled
+	meta.RemoveStatusCondition(&githubIssue.Status.Conditions, status.APIHostForbiddenCondition)
 
-	// Fetch the associated Secret to get the token
-	secret := &corev1.Secret{}
-	if err := r.Client.Get(ctx, client.ObjectKey{
-		Name:      fmt.Sprintf("%s-token-secret", githubIssue.Name),
-		Namespace: githubIssue.Namespace,
-	}, secret); err != nil {
-		if apierrors.IsNotFound(err) {
-			// Secret not found, create it
-			err = resources.CreateSecret(githubIssue, r.Client, ctx)
-			if err != nil {
-				return ctrl.Result{}, err
-			}
-			// Update status to indicate token is required
-			if err := status.UpdateTokenRequired(ctx, r.Client, githubIssue, true); err != nil {
-				log.Error(err, "unable to update TokenRequired status")
-				return ctrl.Result{}, err
+	var (
+		token     []byte
+		tokens    []string
+		secretKey client.ObjectKey
+	)
+	if r.TokenFile != nil {
+		// the token mounted as a file stands in for the token secrets
+		token = []byte(r.TokenFile.Token())
+	} else {
+		// Fetch the associated Secret to get the token
+		secretName, err := r.TokenSecretNames.For(githubIssue.Namespace, githubIssue.Name)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		secret := &corev1.Secret{}
+		if err := r.Client.Get(ctx, client.ObjectKey{
+			Name:      secretName,
+			Namespace: githubIssue.Namespace,
+		}, secret); err != nil {
+			if apierrors.IsNotFound(err) {
+				// Secret not found, create it (seeded from the operator env when configured)
+				seedToken := ""
+				if r.SeedTokenEnv != "" {
+					seedToken = os.Getenv(r.SeedTokenEnv)
+				}
+				err = resources.CreateSecret(githubIssue, secretName, r.Client, ctx, seedToken)
+				if err != nil {
+					return ctrl.Result{}, err
+				}
+				// Update status to indicate whether a token is still required
+				if err := status.UpdateTokenRequired(ctx, r.Client, githubIssue, seedToken == ""); err != nil {
+					log.Error(err, "unable to update TokenRequired status")
+					return ctrl.Result{}, err
+				}
+				return ctrl.Result{Requeue: true}, nil
 			}
-			return ctrl.Result{Requeue: true}, nil
+			return ctrl.Result{}, err
+		}
+		// a GithubIssue recreated under the same name takes over the secret of its predecessor,
+		// which would otherwise be garbage collected along with the token
+		if reowned, err := resources.ReownSecret(ctx, r.Client, githubIssue, secret); err != nil {
+			log.Error(err, "unable to re-own token secret")
+			return ctrl.Result{}, err
+		} else if reowned {
+			log.Info("re-owned the token secret of a deleted GithubIssue of the same name")
+		}
+		// secrets created before they were labeled are selected by the orphan sweep from now on
+		if _, err := resources.LabelTokenSecret(ctx, r.Client, githubIssue, secret); err != nil {
+			log.Error(err, "unable to label token secret")
+			return ctrl.Result{}, err
 		}
-		return ctrl.Result{}, err
-	}
 
-	// Fetch token from secret
-	token, exists := secret.Data["token"]
-	if !exists || len(token) == 0 {
-		log.Info("GitHub token missing in secret, requeueing...")
+		// Fetch token from secret, the optional "tokens" key holds more tokens to fail over to
+		var tokenKey string
+		token, tokenKey = resources.TokenFromSecret(secret, r.tokenKey())
+		if tokenKey != "" && tokenKey != r.tokenKey() {
+			log.Info("token not found under the configured key, using a fallback key", "key", tokenKey)
+		}
+		tokens = resources.ParseTokens(string(secret.Data[resources.TokensKey]))
+		if len(token) > 0 && len(tokens) > 0 {
+			tokens = append([]string{string(token)}, tokens...)
+		}
+		secretKey = client.ObjectKeyFromObject(secret)
+	}
+	if len(token) == 0 && len(tokens) == 0 {
+		log.Info("GitHub token missing, requeueing...")
 		// Update status to indicate token is required
 		if err := status.UpdateTokenRequired(ctx, r.Client, githubIssue, true); err != nil {
 			log.Error(err, "unable to update TokenRequired status")
 			return ctrl.Result{}, err
 		}
-		return ctrl.Result{RequeueAfter: time.Minute}, nil
+		return ctrl.Result{RequeueAfter: r.missingTokenRequeue()}, nil
 	}
 
 	// Token exists, update status to indicate token is not required
@@ -115,7 +560,63 @@ func (r *GithubIssueReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		return ctrl.Result{}, err
 	}
 	// initialize GitHub Client dynamically with the token from the secret
-	r.GithubClient = resources.NewGithubClient(string(token))
+	clientOptions := r.githubClientOptions()
+	if githubIssue.Spec.APIBaseURL != "" {
+		baseURL, err := resources.ParseBaseURL(githubIssue.Spec.APIBaseURL)
+		if err != nil {
+			log.Error(err, "invalid GitHub API base URL")
+			return ctrl.Result{}, err
+		}
+		clientOptions = append(clientOptions, resources.WithBaseURL(baseURL))
+	}
+	var pool *resources.TokenPool
+	if len(tokens) > 0 {
+		pool = r.tokenPool(secretKey, tokens)
+	} else if secretKey != (client.ObjectKey{}) {
+		r.dropTokenPool(secretKey)
+	}
+	newGithubClient := func(opts ...resources.Option) *resources.GithubClient {
+		if pool != nil {
+			return resources.NewGithubClientWithPool(pool, opts...)
+		}
+		return resources.NewGithubClient(string(token), opts...)
+	}
+	// gate the features older GitHub Enterprise Servers lack by their version
+	var serverVersion string
+	if githubIssue.Spec.APIBaseURL != "" {
+		serverVersion = r.serverVersion(ctx, log, githubIssue.Spec.APIBaseURL, func() *resources.GithubClient {
+			return newGithubClient(clientOptions...)
+		})
+	}
+	clientOptions = append([]resources.Option{resources.WithServerVersion(serverVersion)}, clientOptions...)
+	if pool == nil && r.ReuseGithubClients {
+		r.GithubClient = r.cachedGithubClient(secretKey, string(token), githubIssue.Spec.APIBaseURL, serverVersion, clientOptions...)
+	} else {
+		r.GithubClient = newGithubClient(clientOptions...)
+	}
+
+	if deleting {
+		// close the issue through the client of this GithubIssue and remove it from the cluster
+		title, err := r.issueTitle(githubIssue)
+		if err != nil {
+			log.Error(err, "unable to render issue title")
+			return ctrl.Result{}, err
+		}
+		if err := status.Delete(ctx, r.Client, r.GithubClient, githubIssue, title); err != nil {
+			log.Error(err, "unable to delete GithubIssue")
+			// best-effort, telling why the GithubIssue stays Terminating
+			if err := status.UpdateFinalizerPresent(ctx, r.Client, githubIssue); err != nil {
+				log.Error(err, "unable to update FinalizerPresent status")
+			}
+			return ctrl.Result{}, err
+		}
+
+		if err := finalizer.RemoveFinalizer(ctx, r.Client, githubIssue); err != nil {
+			log.Error(err, "unable to remove finalizer")
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
 
 	if err := finalizer.EnsureFinalizer(ctx, r.Client, githubIssue); err != nil {
 		log.Error(err, "unable to add finalizer")
@@ -127,49 +628,1965 @@ func (r *GithubIssueReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		log.Error(err, "unable to parse repo url")
 		return ctrl.Result{}, err
 	}
-	title := githubIssue.Spec.Title
-	description := githubIssue.Spec.Description
+	// security advisories are drafted in place of the issue
+	if githubIssue.Spec.SecurityAdvisory {
+		return r.reconcileAdvisory(ctx, log, githubIssue, owner, repo)
+	}
+
+	var extraConditions []metav1.Condition
+
+	// probe the repository once per interval for every GithubIssue targeting it
+	var repoReachable bool
+	if r.RepoProbeInterval > 0 {
+		reachable := r.probeRepo(ctx, log, secretKey, githubIssue.Spec.APIBaseURL, owner, repo)
+		repoReachable = reachable.Status == metav1.ConditionTrue
+		if !repoReachable {
+			// reported on its own since the reconcile may not get further
+			if err := status.UpdateRepoReachable(ctx, r.Client, githubIssue, reachable); err != nil {
+				if apierrors.IsConflict(err) {
+					return ctrl.Result{RequeueAfter: r.conflictRequeue()}, nil
+				}
+				log.Error(err, "unable to update RepoReachable status")
+				return ctrl.Result{}, err
+			}
+		}
+		extraConditions = append(extraConditions, reachable)
+	}
+
+	title, err := r.issueTitle(githubIssue)
+	if err != nil {
+		log.Error(err, "unable to render issue title")
+		return ctrl.Result{}, err
+	}
+	// GitHub stores bodies with LF line endings, comparing CRLF ones would edit on every reconcile
+	description := utils.NormalizeLineEndings(issueBody(githubIssue))
 	issueNumber := githubIssue.Status.IssueNumber
 
-	issue, err := r.GithubClient.CheckIssueExists(owner, repo, title, int(issueNumber))
+	fields, description, err := issueFields(githubIssue, description)
 	if err != nil {
-		log.Error(err, "unable to check issue existence")
+		log.Error(err, "unable to build issue fields")
 		return ctrl.Result{}, err
 	}
+	if githubIssue.Spec.Milestone != "" {
+		if fields.Milestone, err = r.milestoneNumber(ctx, owner, repo, githubIssue); err != nil {
+			log.Error(err, "unable to resolve the milestone", "milestone", githubIssue.Spec.Milestone)
+			return ctrl.Result{}, err
+		}
+	}
 
-	if issue == nil {
-		// create issue if it doesn't exist
-		issue, err = r.GithubClient.CreateIssue(owner, repo, title, description)
+	// assign the issue to a member of its team, checking the membership again periodically
+	if githubIssue.Spec.AssignFromTeam != "" {
+		assignees, teamCondition, err := r.teamAssignees(ctx, githubIssue, fields.Assignees)
 		if err != nil {
-			log.Error(err, "unable to create issue")
+			log.Error(err, "unable to resolve the team assignee")
 			return ctrl.Result{}, err
 		}
-	} else {
-		// update the issue if it exists
-		updatedIssue, err := r.GithubClient.UpdateIssue(owner, repo, issue, description, title)
+		if teamCondition != nil {
+			log.Info("unable to assign a member of the team", "team", githubIssue.Spec.AssignFromTeam, "reason", teamCondition.Reason)
+			extraConditions = append(extraConditions, *teamCondition)
+		}
+		fields.Assignees = assignees
+	}
+	// the logins assigned before are unassigned once none is left, sending an empty set
+	if len(fields.Assignees) == 0 && len(githubIssue.Status.ManagedAssignees) > 0 {
+		fields.Assignees = []string{}
+	}
+	if serverVersion != "" {
+		var features []resources.Feature
+		if githubIssue.Spec.DuplicateOf > 0 || githubIssue.Spec.SupersededBy != nil {
+			features = append(features, resources.FeatureStateReason)
+		}
+		unavailable := resources.UnavailableFeatures(serverVersion, features...)
+		if len(unavailable) > 0 {
+			log.Info("GitHub Enterprise Server lacks features, degrading", "version", serverVersion, "features", unavailable)
+		}
+		extraConditions = append(extraConditions, status.FeatureUnavailable(serverVersion, unavailable))
+	}
+
+	// render the body with the template of the highest priority label
+	if tmpl := r.LabelTemplates.Select(fields.Labels); tmpl != nil {
+		body, err := tmpl.Render(templates.Data{
+			Repo:        githubIssue.Spec.Repo,
+			Title:       title,
+			Description: description,
+			Category:    githubIssue.Spec.Category,
+			Labels:      fields.Labels,
+		})
 		if err != nil {
-			log.Error(err, "unable to update issue")
+			log.Error(err, "unable to render label template")
 			return ctrl.Result{}, err
 		}
-		issue = updatedIssue
+		description = body
+		extraConditions = append(extraConditions, status.TemplateApplied(tmpl.Label))
 	}
-	// update the status of the GithubIssue CR
-	if err := status.Update(ctx, r.Client, githubIssue, issue); err != nil {
-		if apierrors.IsConflict(err) {
-			log.Info("conflict occurred, requeueing...")
-			return ctrl.Result{RequeueAfter: time.Second * 5}, nil
+
+	// label the issue with the op-id of the GithubIssue, so it's found again after a title change
+	if githubIssue.UID != "" {
+		fields.Labels = append(fields.Labels, resources.OpIDLabel(string(githubIssue.UID)))
+	}
+
+	if githubIssue.Spec.BodyFormat == issuev1.BodyFormatPlaintext {
+		description = utils.FenceBody(description)
+	}
+	description = utils.RenderBlockedBy(description, githubIssue.Spec.BlockedBy)
+	description = utils.RenderAttachments(description, githubIssue.Spec.Attachments)
+	if r.TruncateBody {
+		// leave room for the markers so they survive the truncation
+		limit := utils.GithubBodyLimit
+		if r.AdoptOnlyOwned {
+			limit -= utf8.RuneCountInString(utils.AddOperatorMarker(""))
 		}
-		log.Error(err, "unable to update GithubIssue")
+		if githubIssue.UID != "" {
+			limit -= utf8.RuneCountInString(utils.AddDedupeMarker("", string(githubIssue.UID)))
+		}
+		var truncated bool
+		description, truncated = utils.TruncateBody(description, limit)
+		if truncated {
+			log.Info("issue body exceeds GitHub's limit, truncating")
+		}
+		extraConditions = append(extraConditions, status.BodyTruncated(truncated))
+	}
+	if r.AdoptOnlyOwned {
+		description = utils.AddOperatorMarker(description)
+	}
+	if githubIssue.UID != "" {
+		description = utils.AddDedupeMarker(description, string(githubIssue.UID))
+	}
+
+	// the issue should be open unless a linked resource reports it is healthy, a deleted
+	// linked resource leaves the issue state as is until it is back
+	desiredOpen := true
+	linkedMissing := false
+	if githubIssue.Spec.LinkedResource != nil {
+		healthy, err := r.linkedHealthy(ctx, githubIssue)
+		var forbidden *linkedForbiddenError
+		switch {
+		case errors.As(err, &forbidden):
+			log.Info("linked resource isn't allowed, leaving the issue state as is", "reason", forbidden.err.Error())
+			linkedMissing = true
+			extraConditions = append(extraConditions, status.LinkedResourceForbidden(forbidden.err))
+		case apierrors.IsNotFound(err):
+			log.Info("linked resource not found, leaving the issue state as is")
+			linkedMissing = true
+			extraConditions = append(extraConditions, status.LinkedResourceMissing())
+		case err != nil:
+			log.Error(err, "unable to check linked resource health")
+			return ctrl.Result{}, err
+		default:
+			desiredOpen = !healthy
+			extraConditions = append(extraConditions, status.LinkedResourceHealthy(healthy))
+		}
+	}
+
+	// fetch the recorded issue directly unless the repo or title changed since it was resolved
+	var issue *github.Issue
+	if utils.CanSkipScan(githubIssue) {
+		issue, err = r.GithubClient.GetIssue(ctx, owner, repo, int(issueNumber))
+		// an issue GitHub no longer serves may have been converted to a discussion, which
+		// mustn't be created again
+		if (issue == nil && err == nil) || resources.IsGone(err) {
+			converted, convertedErr := r.convertedToDiscussion(ctx, log, githubIssue, owner, repo)
+			if convertedErr != nil {
+				return ctrl.Result{}, convertedErr
+			}
+			if converted {
+				return ctrl.Result{}, nil
+			}
+		}
+	} else {
+		issue, err = r.GithubClient.CheckIssueExists(owner, repo, title, int(issueNumber), string(githubIssue.UID))
+	}
+	if err != nil {
+		log.Error(err, "unable to check issue existence")
 		return ctrl.Result{}, err
 	}
 
-	// no requeue needed, reconcile succeeded
-	return ctrl.Result{}, nil
-}
+	// GithubIssues resolved to the same issue would fight over it, only the oldest edits it
+	if issue != nil {
+		collision, standDown, err := r.reconcileCollision(ctx, log, githubIssue, owner, repo, issue.GetNumber())
+		if err != nil {
+			log.Error(err, "unable to check for issue number collisions")
+			return ctrl.Result{}, err
+		}
+		if collision != nil {
+			extraConditions = append(extraConditions, *collision)
+		}
+		if standDown {
+			log.Info("issue is managed by an older GithubIssue, not editing it", "number", issue.GetNumber())
+			if err := status.Update(ctx, r.Client, githubIssue, issue, extraConditions...); err != nil {
+				if apierrors.IsConflict(err) {
+					return ctrl.Result{RequeueAfter: r.conflictRequeue()}, nil
+				}
+				log.Error(err, "unable to update GithubIssue")
+				return ctrl.Result{}, err
+			}
+			return ctrl.Result{}, nil
+		}
+	}
 
-// SetupWithManager sets up the controller with the Manager.
-func (r *GithubIssueReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&issuev1.GithubIssue{}).
-		Complete(r)
+	// delete the GithubIssue once its issue has been closed past the retention, before anything
+	// could reopen or recreate the issue. The finalizer is removed first, there's no issue left
+	// to close and looking it up could close another one.
+	retainedFor, deleted, err := r.reconcileRetention(ctx, log, githubIssue, issue)
+	if err != nil {
+		log.Error(err, "unable to delete GithubIssue past its retention")
+		return ctrl.Result{}, err
+	}
+	if deleted {
+		return ctrl.Result{}, nil
+	}
+
+	// a linked resource failing again long after the operator closed its issue gets a new
+	// issue, the closed one keeps the history of the earlier failure
+	if issue != nil && r.reopenExpired(githubIssue, issue, desiredOpen && !linkedMissing) {
+		log.Info("issue closed too long ago to reopen, creating a new one", "closed", issue.GetNumber())
+		githubIssue.Status.PreviousIssueNumber = int32(issue.GetNumber())
+		issue = nil
+	}
+
+	if issue == nil {
+		if !desiredOpen {
+			log.Info("linked resource is healthy, no issue needed")
+			return ctrl.Result{}, nil
+		}
+		// defer creating the issue until its create window opens
+		opensIn, err := r.deferCreation(ctx, githubIssue)
+		if apierrors.IsConflict(err) {
+			log.Info("conflict occurred, requeueing...")
+			return ctrl.Result{RequeueAfter: r.conflictRequeue()}, nil
+		}
+		if err != nil {
+			log.Error(err, "unable to check the create window")
+			return ctrl.Result{}, err
+		}
+		if opensIn > 0 {
+			log.Info("outside the create window, deferring creation", "opensIn", opensIn)
+			return ctrl.Result{RequeueAfter: opensIn}, nil
+		}
+		// hold the creation back while the operator creates issues at its maximum rate
+		retryIn, err := r.limitCreation(ctx, githubIssue)
+		if apierrors.IsConflict(err) {
+			log.Info("conflict occurred, requeueing...")
+			return ctrl.Result{RequeueAfter: r.conflictRequeue()}, nil
+		}
+		if err != nil {
+			log.Error(err, "unable to report the creation rate limit")
+			return ctrl.Result{}, err
+		}
+		if retryIn > 0 {
+			log.Info("creation rate limit exceeded, deferring creation", "retryIn", retryIn)
+			return ctrl.Result{RequeueAfter: retryIn}, nil
+		}
+		// create issue if it doesn't exist
+		body := description
+		if githubIssue.Spec.ManagedSection {
+			body = utils.RenderManagedSection("", description)
+		}
+		issue, err = r.createIssue(ctx, log, githubIssue, owner, repo, title, body, fields)
+		if err != nil {
+			log.Error(err, "unable to create issue")
+			return ctrl.Result{}, err
+		}
+		// record the number first, the full status update below can still fail
+		if err := status.RecordIssueNumber(ctx, r.Client, githubIssue, issue.GetNumber()); err != nil {
+			log.Error(err, "unable to record the issue number", "number", issue.GetNumber())
+			return ctrl.Result{}, err
+		}
+		githubIssue.Status.LastClosedAt = nil
+		githubIssue.Status.BodyHash = utils.ContentHash(body)
+		if githubIssue.Spec.Footer != "" {
+			if issue, err = r.fillFooter(githubIssue, owner, repo, issue, description, title, fields); err != nil {
+				log.Error(err, "unable to fill the issue footer in")
+				return ctrl.Result{}, err
+			}
+		}
+	} else {
+		// update the issue if it exists, keeping its body when changes are posted as comments
+		appendComment := githubIssue.Spec.UpdatePolicy == issuev1.UpdatePolicyAppendComment
+		body := description
+		if !appendComment {
+			if body, err = withFooter(githubIssue, description, issue); err != nil {
+				log.Error(err, "unable to render the issue footer")
+				return ctrl.Result{}, err
+			}
+			body = withTrackedByPR(githubIssue, body, issue)
+		}
+		switch {
+		case appendComment:
+			body = issue.GetBody()
+		case githubIssue.Spec.ManagedSection:
+			// only the managed section is the operator's, edits around it are left in place
+			body = utils.RenderManagedSection(issue.GetBody(), body)
+			githubIssue.Status.BodyHash = utils.ContentHash(body)
+		default:
+			var edited bool
+			body, edited = managedBody(githubIssue, issue, body)
+			if edited {
+				log.Info("issue body was edited on GitHub", "policy", githubIssue.Spec.ExternalEditPolicy)
+			}
+			extraConditions = append(extraConditions, status.ExternalEditDetected(edited, githubIssue.Spec.ExternalEditPolicy))
+			// a preserved edit keeps the recorded hash, so it stays detected
+			if !edited || githubIssue.Spec.ExternalEditPolicy != issuev1.ExternalEditPolicyPreserve {
+				githubIssue.Status.BodyHash = utils.ContentHash(body)
+			}
+		}
+		editSummary := resources.DiffSummary(issue, title, body, fields)
+		updatedIssue, sent, err := r.updateIssue(owner, repo, issue, body, title, fields)
+		if err != nil {
+			log.Error(err, "unable to update issue")
+			return ctrl.Result{}, err
+		}
+		issue = updatedIssue
+		if editSummary != "" {
+			log.Info("updated issue", "changes", editSummary)
+			githubIssue.Status.LastEditSummary = editSummary
+		}
+		// record the truncated body GitHub holds, so it isn't taken for an external edit
+		if sent != body && githubIssue.Status.BodyHash == utils.ContentHash(body) {
+			githubIssue.Status.BodyHash = utils.ContentHash(sent)
+		}
+
+		if appendComment {
+			hash, err := r.GithubClient.AppendComment(ctx, owner, repo, issue, description, githubIssue.Status.ContentHash)
+			if err != nil {
+				log.Error(err, "unable to comment the updated description")
+				return ctrl.Result{}, err
+			}
+			githubIssue.Status.ContentHash = hash
+		}
+	}
+	githubIssue.Status.ManagedAssignees = fields.Assignees
+
+	// post the initial comments of the issue just created, resuming after a partial failure
+	if githubIssue.Status.InitialCommentsPending {
+		if err := r.GithubClient.PostInitialComments(ctx, owner, repo, issue.GetNumber(), githubIssue.Spec.InitialComments); err != nil {
+			log.Error(err, "unable to post the initial comments")
+			return ctrl.Result{}, err
+		}
+		githubIssue.Status.InitialCommentsPending = false
+		// count the comments just posted
+		issue.Comments = nil
+	}
+
+	// post the comment of a state change a failed reconcile didn't get to, even though the
+	// issue already changed state
+	if githubIssue.Status.PendingComment != nil {
+		if err := status.PostPendingComment(ctx, r.GithubClient, githubIssue, owner, repo, issue); err != nil {
+			log.Error(err, "unable to post the pending comment")
+			return ctrl.Result{}, err
+		}
+		issue.Comments = nil
+	}
+
+	// align the issue state with the health of the linked resource, duplicates and issues past
+	// their deadline stay closed
+	var throttledFor time.Duration
+	if githubIssue.Spec.LinkedResource != nil && !linkedMissing && githubIssue.Spec.DuplicateOf == 0 &&
+		githubIssue.Spec.SupersededBy == nil && !r.pastDeadline(githubIssue) && !closedByMilestone(githubIssue) {
+		var throttledCondition *metav1.Condition
+		if issue, throttledCondition, throttledFor, err = r.reconcileLinkedState(ctx, owner, repo, githubIssue, issue, desiredOpen); err != nil {
+			log.Error(err, "unable to update issue state")
+			return ctrl.Result{}, err
+		}
+		if throttledCondition != nil {
+			log.Info("issue changed state too recently, holding it", "wait", throttledFor)
+			extraConditions = append(extraConditions, *throttledCondition)
+		}
+	}
+
+	// close the issue as a duplicate, reopening it once it is no longer marked as one
+	var duplicateCondition *metav1.Condition
+	if issue, duplicateCondition, err = r.reconcileDuplicate(ctx, owner, repo, githubIssue, issue); err != nil {
+		log.Error(err, "unable to update duplicate state")
+		return ctrl.Result{}, err
+	}
+	if duplicateCondition != nil {
+		extraConditions = append(extraConditions, *duplicateCondition)
+	}
+
+	// close the issue pointing at its successor
+	var supersededCondition *metav1.Condition
+	if issue, supersededCondition, err = r.reconcileSuperseded(ctx, owner, repo, githubIssue, issue); err != nil {
+		log.Error(err, "unable to close superseded issue")
+		return ctrl.Result{}, err
+	}
+	if supersededCondition != nil {
+		extraConditions = append(extraConditions, *supersededCondition)
+	}
+
+	// close the issue once its milestone is complete
+	var milestoneCondition *metav1.Condition
+	if issue, milestoneCondition, err = r.reconcileMilestone(ctx, owner, repo, githubIssue, issue); err != nil {
+		log.Error(err, "unable to close issue of the completed milestone")
+		return ctrl.Result{}, err
+	}
+	if milestoneCondition != nil {
+		extraConditions = append(extraConditions, *milestoneCondition)
+	}
+
+	// close the issue once its deadline passed, rechecking when it will
+	var deadlineCondition *metav1.Condition
+	var closesIn time.Duration
+	if issue, deadlineCondition, closesIn, err = r.reconcileDeadline(ctx, owner, repo, githubIssue, issue); err != nil {
+		log.Error(err, "unable to close issue past its deadline")
+		return ctrl.Result{}, err
+	}
+	if deadlineCondition != nil {
+		extraConditions = append(extraConditions, *deadlineCondition)
+	}
+
+	// lock the issue once it is closed, a failed lock is reported and retried on its own
+	lockFailed := false
+	if githubIssue.Spec.LockOnClose && issue.GetState() == "closed" {
+		var lockErr error
+		if !issue.GetLocked() {
+			if lockErr = r.GithubClient.LockIssue(ctx, owner, repo, issue.GetNumber(), githubIssue.Spec.LockReason); lockErr != nil {
+				log.Error(lockErr, "issue closed but unable to lock it")
+				lockFailed = true
+			} else {
+				issue.Locked = github.Bool(true)
+			}
+		}
+		extraConditions = append(extraConditions, status.Locked(githubIssue.Spec.LockReason, lockErr))
+	}
+
+	// attach the label of the issue category, removing it once the category is cleared
+	if err := r.reconcileCategoryLabel(owner, repo, issue, githubIssue.Spec.Category); err != nil {
+		log.Error(err, "unable to update category label")
+		return ctrl.Result{}, err
+	}
+
+	// attach the label of the issue severity, removing it once the severity is cleared
+	if err := r.reconcileSeverityLabel(owner, repo, issue, githubIssue.Spec.Severity); err != nil {
+		log.Error(err, "unable to update severity label")
+		return ctrl.Result{}, err
+	}
+
+	// keep the checklist item of the issue in the tracking issue
+	if githubIssue.Spec.TrackingIssue > 0 {
+		if err := r.GithubClient.SyncTrackingIssue(ctx, owner, repo, githubIssue.Spec.TrackingIssue,
+			issue.GetNumber(), issue.GetState() == "closed"); err != nil {
+			log.Error(err, "unable to update tracking issue")
+			return ctrl.Result{}, err
+		}
+	}
+
+	// label the issues linked to a pull request
+	if githubIssue.Spec.TrackPR {
+		_, hasPR := resources.LinkedPullRequest(issue)
+		if err := r.reconcileToggledLabel(owner, repo, issue, resources.HasPRLabel, hasPR); err != nil {
+			log.Error(err, "unable to update has-pr label")
+			return ctrl.Result{}, err
+		}
+	}
+
+	// record the operator version managing the issue, for upgrade audits
+	if err := r.reconcileVersionLabel(owner, repo, issue); err != nil {
+		log.Error(err, "unable to update operator version label")
+		return ctrl.Result{}, err
+	}
+	githubIssue.Status.OperatorVersion = resources.Version
+
+	// keep the approval label until the GithubIssue is approved, the watch reconciles it on
+	// annotation changes too
+	if githubIssue.Spec.ApprovalLabel != "" {
+		if err := r.reconcileApprovalLabel(owner, repo, githubIssue, issue); err != nil {
+			log.Error(err, "unable to update approval label")
+			return ctrl.Result{}, err
+		}
+	}
+
+	// track the blocking issues, toggling the blocked label when requested
+	var blockedCondition *metav1.Condition
+	if blockedCondition, err = r.reconcileBlocked(log, owner, repo, githubIssue, issue); err != nil {
+		log.Error(err, "unable to update blocked label")
+		return ctrl.Result{}, err
+	}
+	if blockedCondition != nil {
+		extraConditions = append(extraConditions, *blockedCondition)
+	}
+
+	// flag the open issues whose milestone is about to be due
+	var recheckIn time.Duration
+	if r.AtRiskWithin > 0 {
+		if recheckIn, err = r.reconcileAtRiskLabel(owner, repo, issue); err != nil {
+			log.Error(err, "unable to update at-risk label")
+			return ctrl.Result{}, err
+		}
+	}
+
+	// flag the open issues outliving their SLA, rechecking once they would
+	if githubIssue.Spec.SLAMaxAge != nil {
+		breached, breachesIn := r.slaBreached(githubIssue, issue)
+		extraConditions = append(extraConditions, breached)
+		if breachesIn > 0 && (recheckIn == 0 || breachesIn < recheckIn) {
+			recheckIn = breachesIn
+		}
+	}
+
+	// report the rate limit headroom, best-effort since not every response carries it
+	if rate, ok := r.GithubClient.Rate(); ok {
+		status.SetRateLimit(githubIssue, rate)
+	}
+
+	// warn about assignees already holding too many open issues, without holding the issue back
+	if r.MaxAssignedIssues > 0 && len(fields.Assignees) > 0 && issue.GetState() == "open" {
+		githubIssues := &issuev1.GithubIssueList{}
+		if err := r.listSelected(ctx, githubIssues); err != nil {
+			log.Error(err, "unable to list GithubIssues")
+			return ctrl.Result{}, err
+		}
+		counts := summary.OpenAssigned(githubIssues.Items, req.NamespacedName)
+		overloaded := summary.Overloaded(counts, fields.Assignees, r.MaxAssignedIssues)
+		if len(overloaded) > 0 {
+			log.Info("assignees hold too many open issues", "assignees", overloaded, "limit", r.MaxAssignedIssues)
+		}
+		extraConditions = append(extraConditions, status.AssigneesOverloaded(overloaded, r.MaxAssignedIssues))
+	}
+
+	// report the token scopes lacking for the issue, only known for classic tokens
+	if scopes, ok := r.GithubClient.Scopes(); ok {
+		missing := resources.MissingScopes(scopes)
+		if len(missing) > 0 {
+			log.Info("GitHub token is missing required scopes", "missing", missing)
+		}
+		extraConditions = append(extraConditions, status.TokenScopes(missing))
+	}
+
+	// notify of the issue being created or closed, a failed delivery doesn't fail the reconcile
+	if notified := r.notifyState(ctx, log, githubIssue, owner, repo, issue); notified != nil {
+		extraConditions = append(extraConditions, *notified)
+	}
+
+	// count the comments by listing them when GitHub didn't send the count along with the issue
+	if issue.Comments == nil {
+		comments, err := r.GithubClient.ListComments(ctx, owner, repo, issue.GetNumber())
+		if err != nil {
+			log.Error(err, "unable to list comments")
+			return ctrl.Result{}, err
+		}
+		issue.Comments = github.Int(len(comments))
+	}
+
+	// update the status of the GithubIssue CR
+	// the number of a created issue is already recorded, compare with the one reconciled from
+	firstRecorded := issueNumber == 0
+	if err := status.Update(ctx, r.Client, githubIssue, issue, extraConditions...); err != nil {
+		if apierrors.IsConflict(err) {
+			log.Info("conflict occurred, requeueing...")
+			return ctrl.Result{RequeueAfter: r.conflictRequeue()}, nil
+		}
+		log.Error(err, "unable to update GithubIssue")
+		return ctrl.Result{}, err
+	}
+	if firstRecorded {
+		metrics.ObserveTimeToCreate(githubIssue.CreationTimestamp.Time, r.currentTime())
+	}
+
+	// record the repo and title the issue number was resolved for
+	if err := r.ensureTitleHash(ctx, githubIssue); err != nil {
+		if apierrors.IsConflict(err) {
+			log.Info("conflict occurred, requeueing...")
+			return ctrl.Result{RequeueAfter: r.conflictRequeue()}, nil
+		}
+		log.Error(err, "unable to record title hash")
+		return ctrl.Result{}, err
+	}
+
+	if closesIn > 0 && (recheckIn == 0 || closesIn < recheckIn) {
+		recheckIn = closesIn
+	}
+	if throttledFor > 0 && (recheckIn == 0 || throttledFor < recheckIn) {
+		recheckIn = throttledFor
+	}
+	// the issue may have been reopened since, the retention only applies while it's closed
+	if retainedFor > 0 && issue.GetState() == "closed" && (recheckIn == 0 || retainedFor < recheckIn) {
+		recheckIn = retainedFor
+	}
+	// probe an unreachable repository again as soon as the cached probe expires
+	if r.RepoProbeInterval > 0 && !repoReachable && (recheckIn == 0 || recheckIn > r.RepoProbeInterval) {
+		recheckIn = r.RepoProbeInterval
+	}
+	if lockFailed && (recheckIn == 0 || recheckIn > time.Minute) {
+		recheckIn = time.Minute
+	}
+	if githubIssue.Spec.AssignFromTeam != "" && (recheckIn == 0 || recheckIn > teamMembershipRecheck) {
+		recheckIn = teamMembershipRecheck
+	}
+
+	// requeue only to retry the lock, to change a throttled state or close the issue at its
+	// deadline, to flag the issue once its milestone is at risk or its SLA breached, to
+	// follow the membership of its team, or to delete the GithubIssue past its retention
+	return ctrl.Result{RequeueAfter: recheckIn}, nil
+}
+
+// ensureTitleHash annotates the GithubIssue with the hash of its repo and title
+func (r *GithubIssueReconciler) ensureTitleHash(ctx context.Context, githubIssue *issuev1.GithubIssue) error {
+	hash := utils.IssueHash(githubIssue.Spec.Repo, githubIssue.Spec.Title)
+	if githubIssue.Annotations[utils.TitleHashAnnotation] == hash {
+		return nil
+	}
+
+	patch := client.MergeFrom(githubIssue.DeepCopy())
+	if githubIssue.Annotations == nil {
+		githubIssue.Annotations = map[string]string{}
+	}
+	githubIssue.Annotations[utils.TitleHashAnnotation] = hash
+	return r.Client.Patch(ctx, githubIssue, patch)
+}
+
+// reconcileAdvisory drafts the security advisory of the GithubIssue once, drafts aren't kept in sync afterwards
+func (r *issueReconcile) reconcileAdvisory(ctx context.Context, log logr.Logger, githubIssue *issuev1.GithubIssue, owner, repo string) (ctrl.Result, error) {
+	if id := githubIssue.Status.AdvisoryID; id != "" {
+		// the advisory was recorded but the status update reporting it failed
+		if !meta.IsStatusConditionTrue(githubIssue.Status.Conditions, "AdvisoryCreated") {
+			if err := status.UpdateAdvisory(ctx, r.Client, githubIssue, &resources.SecurityAdvisory{GHSAID: id}, nil); err != nil {
+				log.Error(err, "unable to update GithubIssue")
+				return ctrl.Result{}, err
+			}
+		}
+		return ctrl.Result{}, nil
+	}
+
+	summary := githubIssue.Spec.AdvisorySummary
+	if summary == "" {
+		summary = githubIssue.Spec.Title
+	}
+	advisory, err := r.GithubClient.CreateAdvisory(ctx, owner, repo, summary, githubIssue.Spec.Description, githubIssue.Spec.AdvisorySeverity)
+	if err != nil && !errors.Is(err, resources.ErrInsufficientScope) {
+		log.Error(err, "unable to create security advisory")
+		return ctrl.Result{}, err
+	}
+	if err == nil {
+		// recorded right away, the next reconcile drafts no other advisory if the update fails
+		if err := status.RecordAdvisoryID(ctx, r.Client, githubIssue, advisory.GHSAID); err != nil {
+			log.Error(err, "unable to record the security advisory")
+			return ctrl.Result{}, err
+		}
+	}
+
+	if err := status.UpdateAdvisory(ctx, r.Client, githubIssue, advisory, err); err != nil {
+		log.Error(err, "unable to update GithubIssue")
+		return ctrl.Result{}, err
+	}
+	if err != nil {
+		// retrying won't help until the token is granted the scope
+		log.Info("token can't create security advisories, requeueing...")
+		return ctrl.Result{RequeueAfter: time.Hour}, nil
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// githubClientOptions returns the options of the GitHub clients created by the reconciler
+func (r *GithubIssueReconciler) githubClientOptions() []resources.Option {
+	return []resources.Option{
+		resources.WithUserAgent(r.UserAgent),
+		resources.WithOwnedOnly(r.AdoptOnlyOwned),
+		resources.WithTitleEmojis(r.severityEmojis()),
+		resources.WithTitleMatch(r.TitleMatch),
+		resources.WithPageSize(r.PageSize),
+	}
+}
+
+// severityEmojis returns the configured severity emojis, sorted so the matching is stable
+func (r *GithubIssueReconciler) severityEmojis() []string {
+	emojis := make([]string, 0, len(r.SeverityEmojis))
+	for _, emoji := range r.SeverityEmojis {
+		emojis = append(emojis, emoji)
+	}
+	sort.Strings(emojis)
+	return emojis
+}
+
+// cachedTokenPool is the pool of a secret along with when a reconcile last used it
+type cachedTokenPool struct {
+	pool   *resources.TokenPool
+	usedAt time.Time
+}
+
+// tokenPool returns the pool of the secret, updated with its current tokens. The pools of the
+// other secrets gone unused for githubClientIdle are dropped, along with their tokens.
+func (r *GithubIssueReconciler) tokenPool(key client.ObjectKey, tokens []string) *resources.TokenPool {
+	r.tokenPoolsMu.Lock()
+	defer r.tokenPoolsMu.Unlock()
+
+	if r.tokenPools == nil {
+		r.tokenPools = map[client.ObjectKey]*cachedTokenPool{}
+	}
+	now := r.currentTime()
+	for other, cached := range r.tokenPools {
+		if other != key && now.Sub(cached.usedAt) > githubClientIdle {
+			delete(r.tokenPools, other)
+		}
+	}
+	cached, ok := r.tokenPools[key]
+	if !ok {
+		cached = &cachedTokenPool{pool: resources.NewTokenPool(tokens)}
+		r.tokenPools[key] = cached
+	} else {
+		cached.pool.SetTokens(tokens)
+	}
+	cached.usedAt = now
+	return cached.pool
+}
+
+// dropTokenPool drops the pool of a secret that no longer holds multiple tokens
+func (r *GithubIssueReconciler) dropTokenPool(key client.ObjectKey) {
+	r.tokenPoolsMu.Lock()
+	defer r.tokenPoolsMu.Unlock()
+
+	delete(r.tokenPools, key)
+}
+
+// managedBody returns the body the issue should have, the live one when it was edited on
+// GitHub and the policy preserves external edits. A body is edited when it matches neither
+// the one the operator last wrote nor the description.
+func managedBody(githubIssue *issuev1.GithubIssue, issue *github.Issue, description string) (body string, edited bool) {
+	liveHash := utils.ContentHash(issue.GetBody())
+	edited = githubIssue.Status.BodyHash != "" &&
+		liveHash != githubIssue.Status.BodyHash &&
+		liveHash != utils.ContentHash(description)
+	if edited && githubIssue.Spec.ExternalEditPolicy == issuev1.ExternalEditPolicyPreserve {
+		return issue.GetBody(), true
+	}
+	return description, edited
+}
+
+// githubClientIdle is how long a token secret goes without reconciles before its cached
+// GitHub client and token pool are dropped
+const githubClientIdle = time.Hour
+
+// cachedGithubClient is a reused GitHub client along with the server version it was built for
+type cachedGithubClient struct {
+	client        *resources.GithubClient
+	serverVersion string
+}
+
+// githubClientUser is a token secret using a cached client with an API base URL, the zero
+// secret standing for the token file
+type githubClientUser struct {
+	secret  client.ObjectKey
+	baseURL string
+}
+
+// githubClientUse is the cached client a token secret last used and when
+type githubClientUse struct {
+	key    string
+	usedAt time.Time
+}
+
+// cachedGithubClient returns the client of the token for the API base URL, reusing the one
+// built by an earlier reconcile and rebuilding it when the server version differs. The client
+// the secret used before is evicted once its token rotated, or the secret went unused for
+// githubClientIdle, and no other secret shares it.
+func (r *GithubIssueReconciler) cachedGithubClient(secretKey client.ObjectKey, token, baseURL, serverVersion string, opts ...resources.Option) *resources.GithubClient {
+	r.githubClientsMu.Lock()
+	defer r.githubClientsMu.Unlock()
+
+	if r.githubClients == nil {
+		r.githubClients = map[string]*cachedGithubClient{}
+		r.githubClientKeys = map[githubClientUser]githubClientUse{}
+	}
+	now := r.currentTime()
+	user := githubClientUser{secret: secretKey, baseURL: baseURL}
+	key := utils.ContentHash(token) + "@" + baseURL
+	for other, use := range r.githubClientKeys {
+		if other != user && now.Sub(use.usedAt) > githubClientIdle {
+			delete(r.githubClientKeys, other)
+			r.evictGithubClient(use.key)
+		}
+	}
+	previous, ok := r.githubClientKeys[user]
+	r.githubClientKeys[user] = githubClientUse{key: key, usedAt: now}
+	if ok && previous.key != key {
+		r.evictGithubClient(previous.key)
+	}
+
+	cached, ok := r.githubClients[key]
+	if !ok || cached.serverVersion != serverVersion {
+		cached = &cachedGithubClient{client: resources.NewGithubClient(token, opts...), serverVersion: serverVersion}
+		r.githubClients[key] = cached
+	}
+	return cached.client
+}
+
+// evictGithubClient drops the cached client unless a token secret still uses it
+func (r *GithubIssueReconciler) evictGithubClient(key string) {
+	for _, use := range r.githubClientKeys {
+		if use.key == key {
+			return
+		}
+	}
+	delete(r.githubClients, key)
+}
+
+// serverVersion returns the GitHub Enterprise Server version behind the API base URL, detected
+// once per server with a client from newClient. Empty when the detection fails, which is
+// retried with the next reconcile, leaving every feature enabled. The detection runs outside
+// of the lock, concurrent reconciles of a new server may detect it each.
+func (r *GithubIssueReconciler) serverVersion(ctx context.Context, log logr.Logger, baseURL string, newClient func() *resources.GithubClient) string {
+	r.serverVersionsMu.Lock()
+	serverVersion, ok := r.serverVersions[baseURL]
+	r.serverVersionsMu.Unlock()
+	if ok {
+		return serverVersion
+	}
+
+	serverVersion, err := newClient().ServerVersion(ctx)
+	if err != nil {
+		log.Error(err, "unable to detect the GitHub Enterprise Server version, assuming every feature is available")
+		return ""
+	}
+	log.Info("detected GitHub Enterprise Server version", "baseURL", baseURL, "version", serverVersion)
+	r.serverVersionsMu.Lock()
+	defer r.serverVersionsMu.Unlock()
+	if r.serverVersions == nil {
+		r.serverVersions = map[string]string{}
+	}
+	r.serverVersions[baseURL] = serverVersion
+	return serverVersion
+}
+
+// repoProbe is the last probe of a repository, in flight while done is set, closing it when
+// the probe completes
+type repoProbe struct {
+	done      chan struct{}
+	reachable bool
+	err       error
+	probedAt  time.Time
+}
+
+// probeRepo returns the RepoReachable condition of the repository, probing it with the current
+// GitHub client when its last probe is older than RepoProbeInterval, so the GithubIssues
+// targeting the same repository with the same token secret share a single probe per interval.
+// The probe runs outside of the lock, concurrent reconciles wait for the one in flight.
+func (r *issueReconcile) probeRepo(ctx context.Context, log logr.Logger, secretKey client.ObjectKey, baseURL, owner, repo string) metav1.Condition {
+	githubClient := r.GithubClient
+	name := owner + "/" + repo
+	key := secretKey.String() + " " + strings.ToLower(baseURL+" "+name)
+	for {
+		r.repoProbesMu.Lock()
+		if r.repoProbes == nil {
+			r.repoProbes = map[string]*repoProbe{}
+		}
+		now := r.currentTime()
+		for k, probe := range r.repoProbes {
+			if probe.done == nil && now.Sub(probe.probedAt) >= r.RepoProbeInterval {
+				delete(r.repoProbes, k)
+			}
+		}
+		probe, ok := r.repoProbes[key]
+		if ok && probe.done == nil {
+			r.repoProbesMu.Unlock()
+			return status.RepoReachable(name, probe.reachable, probe.err)
+		}
+		if !ok {
+			probe = &repoProbe{done: make(chan struct{})}
+			r.repoProbes[key] = probe
+			r.repoProbesMu.Unlock()
+
+			reachable, err := githubClient.RepoExists(ctx, owner, repo)
+			if err != nil {
+				log.Error(err, "unable to probe the repository", "repo", name)
+			}
+			r.repoProbesMu.Lock()
+			probe.reachable, probe.err, probe.probedAt = reachable, err, r.currentTime()
+			close(probe.done)
+			probe.done = nil
+			r.repoProbesMu.Unlock()
+			return status.RepoReachable(name, reachable, err)
+		}
+		done := probe.done
+		r.repoProbesMu.Unlock()
+
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return status.RepoReachable(name, false, ctx.Err())
+		}
+	}
+}
+
+// issueTitle returns the title of the GitHub issue, the spec title behind the title prefix
+// and the emoji of the severity
+func (r *GithubIssueReconciler) issueTitle(githubIssue *issuev1.GithubIssue) (string, error) {
+	title, err := utils.PrefixTitle(r.TitlePrefix, utils.TitlePrefixData{
+		Cluster:   r.ClusterName,
+		Namespace: githubIssue.Namespace,
+	}, githubIssue.Spec.Title)
+	if err != nil {
+		return "", err
+	}
+	return utils.PrefixEmoji(r.SeverityEmojis[string(githubIssue.Spec.Severity)], title), nil
+}
+
+// issueBody returns the description of the GithubIssue, the body of its default locale when it
+// has bodies in several languages, followed by the other locales when AppendLocales
+func issueBody(githubIssue *issuev1.GithubIssue) string {
+	spec := githubIssue.Spec
+	if len(spec.Bodies) == 0 {
+		return spec.Description
+	}
+	body := spec.Bodies[spec.DefaultLocale]
+	if spec.AppendLocales {
+		body = utils.RenderLocales(body, spec.Bodies, spec.DefaultLocale)
+	}
+	return body
+}
+
+// issueFields returns the labels, assignees and milestone of the issue, with the labels
+// rendered with the metadata of the GithubIssue. With front-matter, its fields are added and
+// it's stripped from the returned description.
+func issueFields(githubIssue *issuev1.GithubIssue, description string) (resources.IssueFields, string, error) {
+	labels, err := labeltemplate.Render(githubIssue.Spec.Labels, labeltemplate.Data{
+		Namespace: githubIssue.Namespace,
+		Name:      githubIssue.Name,
+	})
+	if err != nil {
+		return resources.IssueFields{}, "", err
+	}
+	// the assignees are copied so appending the front-matter ones doesn't write to the spec
+	fields := resources.IssueFields{Assignees: slices.Clone(githubIssue.Spec.Assignees), Labels: labels}
+	if githubIssue.Spec.FrontMatter {
+		metadata, body, err := frontmatter.Parse(description)
+		if err != nil {
+			return resources.IssueFields{}, "", fmt.Errorf("failed to parse front-matter: %w", err)
+		}
+		description = body
+		fields.Labels = append(fields.Labels, metadata.Labels...)
+		fields.Assignees = append(fields.Assignees, metadata.Assignees...)
+		fields.Milestone = metadata.Milestone
+	}
+	return fields, description, nil
+}
+
+// milestoneNumber returns the number of the milestone of the GithubIssue, creating the
+// milestone when missing if CreateMilestoneIfMissing
+func (r *issueReconcile) milestoneNumber(ctx context.Context, owner, repo string, githubIssue *issuev1.GithubIssue) (int, error) {
+	title := githubIssue.Spec.Milestone
+	if githubIssue.Spec.CreateMilestoneIfMissing {
+		return r.GithubClient.EnsureMilestone(ctx, owner, repo, title)
+	}
+	number, ok, err := r.GithubClient.MilestoneNumber(ctx, owner, repo, title)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return 0, fmt.Errorf("milestone %q does not exist in %s/%s", title, owner, repo)
+	}
+	return number, nil
+}
+
+// teamAssignees returns the assignees of the issue with the member of AssignFromTeam in place
+// of the one assigned from the team before, who's any assignee in the status besides the given
+// ones. The previous member is kept while the team can't be resolved.
+func (r *issueReconcile) teamAssignees(ctx context.Context, githubIssue *issuev1.GithubIssue, assignees []string) ([]string, *metav1.Condition, error) {
+	member, condition, err := r.teamAssignee(ctx, githubIssue)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	assigned := func(logins []string, login string) bool {
+		return slices.ContainsFunc(logins, func(other string) bool { return strings.EqualFold(other, login) })
+	}
+	// copied so the spec assignees aren't appended to
+	result := slices.Clone(assignees)
+	if condition != nil {
+		for _, previous := range githubIssue.Status.Assignees {
+			if !assigned(result, previous) {
+				result = append(result, previous)
+			}
+		}
+		return result, condition, nil
+	}
+	if !assigned(result, member) {
+		result = append(result, member)
+	}
+	return result, nil, nil
+}
+
+// teamAssignee returns the member of AssignFromTeam to assign the issue to: an assignee in the
+// status while they're still in the team, else the member holding the fewest open issues. A
+// team the token can't read or without members is reported through the TeamUnresolved
+// condition instead, leaving the issue to its other assignees.
+func (r *issueReconcile) teamAssignee(ctx context.Context, githubIssue *issuev1.GithubIssue) (string, *metav1.Condition, error) {
+	team := githubIssue.Spec.AssignFromTeam
+	org, slug, err := resources.ParseTeam(team)
+	if err != nil {
+		return "", nil, err
+	}
+	members, err := r.GithubClient.TeamMembers(ctx, org, slug)
+	var (
+		scopeErr  *resources.ScopeInsufficientError
+		deniedErr *resources.AccessDeniedError
+	)
+	forbiddenErr := resources.ClassifyForbidden(err)
+	if resources.IsNotFound(err) || errors.As(forbiddenErr, &scopeErr) || errors.As(forbiddenErr, &deniedErr) {
+		condition := status.TeamUnresolved(team, err)
+		return "", &condition, nil
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	if len(members) == 0 {
+		condition := status.TeamUnresolved(team, nil)
+		return "", &condition, nil
+	}
+
+	for _, assignee := range githubIssue.Status.Assignees {
+		for _, member := range members {
+			if strings.EqualFold(assignee, member) {
+				return member, nil, nil
+			}
+		}
+	}
+	githubIssues := &issuev1.GithubIssueList{}
+	if err := r.listSelected(ctx, githubIssues); err != nil {
+		return "", nil, err
+	}
+	counts := summary.OpenAssigned(githubIssues.Items, client.ObjectKeyFromObject(githubIssue))
+	return summary.LeastLoaded(counts, members), nil, nil
+}
+
+// tokenKey returns the key of the token in the token secret
+func (r *GithubIssueReconciler) tokenKey() string {
+	if r.TokenKey != "" {
+		return r.TokenKey
+	}
+	return resources.DefaultTokenKey
+}
+
+// missingTokenRequeue returns the requeue delay of an issue whose secret holds no token
+func (r *GithubIssueReconciler) missingTokenRequeue() time.Duration {
+	if r.MissingTokenRequeue > 0 {
+		return r.MissingTokenRequeue
+	}
+	return time.Minute
+}
+
+// conflictRequeue returns the requeue delay after a conflicting update
+func (r *GithubIssueReconciler) conflictRequeue() time.Duration {
+	if r.ConflictRequeue > 0 {
+		return r.ConflictRequeue
+	}
+	return time.Second * 5
+}
+
+// AutoResolvedComment is commented on an issue closed because its linked resource became healthy
+const AutoResolvedComment = "auto-resolved"
+
+// reopenAllowed reports whether the operator may reopen the closed issue of the GithubIssue
+func reopenAllowed(githubIssue *issuev1.GithubIssue) bool {
+	return githubIssue.Spec.AllowReopen == nil || *githubIssue.Spec.AllowReopen
+}
+
+// reconcileLinkedState opens or closes the issue to follow the health of the linked resource,
+// recording the transition. Within MinStateChangeInterval of the last transition the issue is
+// left as is: the StateChangeThrottled condition and how long until the state can change are
+// returned then, nil and zero otherwise.
+func (r *issueReconcile) reconcileLinkedState(ctx context.Context, owner, repo string, githubIssue *issuev1.GithubIssue, issue *github.Issue, desiredOpen bool) (*github.Issue, *metav1.Condition, time.Duration, error) {
+	wasOpen := issue.GetState() == "open"
+	if wasOpen == desiredOpen {
+		return issue, nil, 0, nil
+	}
+
+	if last := githubIssue.Status.LastStateTransition; r.MinStateChangeInterval > 0 && last != nil {
+		if wait := last.Add(r.MinStateChangeInterval).Sub(r.currentTime()); wait > 0 {
+			condition := status.StateChangeThrottled(wait)
+			return issue, &condition, wait, nil
+		}
+	}
+
+	issue, err := r.reconcileIssueState(ctx, owner, repo, githubIssue, issue, desiredOpen, reopenAllowed(githubIssue))
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	if (issue.GetState() == "open") != wasOpen {
+		githubIssue.Status.LastStateTransition = &metav1.Time{Time: r.currentTime()}
+		if wasOpen {
+			githubIssue.Status.LastClosedAt = githubIssue.Status.LastStateTransition
+		}
+	}
+	return issue, nil, 0, nil
+}
+
+// reconcileRetention deletes the GithubIssue once its issue has been closed for longer than
+// RetentionAfterClose, reporting whether it did, without the finalizer closing its issue.
+// Otherwise it returns how long the closed issue is still retained, zero while the issue is
+// open or without a retention.
+func (r *GithubIssueReconciler) reconcileRetention(ctx context.Context, log logr.Logger, githubIssue *issuev1.GithubIssue, issue *github.Issue) (time.Duration, bool, error) {
+	left, ok := r.retentionLeft(githubIssue, issue)
+	if !ok {
+		return 0, false, nil
+	}
+	if left > 0 {
+		return left, false, nil
+	}
+	log.Info("issue closed past its retention, deleting the GithubIssue", "number", issue.GetNumber())
+	if err := finalizer.RemoveFinalizer(ctx, r.Client, githubIssue); err != nil {
+		return 0, false, err
+	}
+	if err := r.Client.Delete(ctx, githubIssue); client.IgnoreNotFound(err) != nil {
+		return 0, false, err
+	}
+	return 0, true, nil
+}
+
+// retentionLeft returns how long the GithubIssue is kept for once its issue is closed, zero or
+// less when the retention is over. ok is false while the issue is open or without a retention.
+func (r *GithubIssueReconciler) retentionLeft(githubIssue *issuev1.GithubIssue, issue *github.Issue) (left time.Duration, ok bool) {
+	retention := githubIssue.Spec.RetentionAfterClose
+	if retention == nil || issue == nil || issue.GetState() != "closed" {
+		return 0, false
+	}
+	closedAt := issue.GetClosedAt()
+	if closedAt.IsZero() && githubIssue.Status.LastClosedAt != nil {
+		closedAt = githubIssue.Status.LastClosedAt.Time
+	}
+	if closedAt.IsZero() {
+		return 0, false
+	}
+	return closedAt.Add(retention.Duration).Sub(r.currentTime()), true
+}
+
+// reopenExpired reports whether the issue the operator closed once the linked resource
+// recovered was closed longer than ReopenWithin ago, the resource failing again then gets a
+// new issue rather than the closed one reopened
+func (r *GithubIssueReconciler) reopenExpired(githubIssue *issuev1.GithubIssue, issue *github.Issue, failing bool) bool {
+	spec := githubIssue.Spec
+	closedAt := githubIssue.Status.LastClosedAt
+	if !failing || spec.ReopenWithin == nil || spec.LinkedResource == nil || closedAt == nil || issue.GetState() != "closed" {
+		return false
+	}
+	// duplicates, superseded issues, issues past their deadline and of a completed milestone stay closed
+	if spec.DuplicateOf > 0 || spec.SupersededBy != nil || r.pastDeadline(githubIssue) || closedByMilestone(githubIssue) {
+		return false
+	}
+	return r.currentTime().Sub(closedAt.Time) > spec.ReopenWithin.Duration
+}
+
+// reconcileIssueState opens or closes the issue so its state matches the desired one, a
+// closed issue is left closed unless allowReopen. The close comment references the ClosedBy
+// of the GithubIssue when set. It returns the issue with its current state.
+func (r *issueReconcile) reconcileIssueState(ctx context.Context, owner, repo string, githubIssue *issuev1.GithubIssue, issue *github.Issue, desiredOpen, allowReopen bool) (*github.Issue, error) {
+	isOpen := issue.GetState() == "open"
+	switch {
+	case desiredOpen && !isOpen && allowReopen:
+		return r.GithubClient.ReopenIssue(ctx, owner, repo, issue.GetNumber())
+	case !desiredOpen && isOpen:
+		comment := utils.ClosingComment(AutoResolvedComment, githubIssue.Spec.ClosedBy)
+		return r.changeState(ctx, owner, repo, githubIssue, comment, "closed", func() (*github.Issue, error) {
+			if err := r.GithubClient.CloseIssue(owner, repo, issue); err != nil {
+				return nil, err
+			}
+			issue.State = github.String("closed")
+			return issue, nil
+		})
+	}
+	return issue, nil
+}
+
+// changeState changes the issue to the state, open or closed, through change and posts the
+// comment along with it. The comment is recorded as pending in the status first, so a
+// reconcile failing after the issue changed state still posts it.
+func (r *issueReconcile) changeState(ctx context.Context, owner, repo string, githubIssue *issuev1.GithubIssue, comment, state string, change func() (*github.Issue, error)) (*github.Issue, error) {
+	if err := status.RecordPendingComment(ctx, r.Client, githubIssue, comment, state); err != nil {
+		return nil, err
+	}
+	changedIssue, err := change()
+	if err != nil {
+		return nil, err
+	}
+	if err := status.PostPendingComment(ctx, r.GithubClient, githubIssue, owner, repo, changedIssue); err != nil {
+		return nil, err
+	}
+	return changedIssue, nil
+}
+
+// NotDuplicateComment is commented on an issue reopened once it is no longer marked as a duplicate
+const NotDuplicateComment = "no longer a duplicate"
+
+// reconcileDuplicate closes the issue as a duplicate of DuplicateOf, and reopens an issue
+// it closed as a duplicate once DuplicateOf is removed, when AllowReopen. It returns the issue with its
+// current state and the ClosedAsDuplicate condition, nil for issues never marked as duplicates.
+func (r *issueReconcile) reconcileDuplicate(ctx context.Context, owner, repo string, githubIssue *issuev1.GithubIssue, issue *github.Issue) (*github.Issue, *metav1.Condition, error) {
+	duplicateOf := githubIssue.Spec.DuplicateOf
+	wasDuplicate := meta.IsStatusConditionTrue(githubIssue.Status.Conditions, status.ClosedAsDuplicateCondition)
+
+	switch {
+	case duplicateOf > 0:
+		if issue.GetState() == "open" {
+			closedIssue, err := r.changeState(ctx, owner, repo, githubIssue, resources.DuplicateComment(duplicateOf), "closed", func() (*github.Issue, error) {
+				return r.GithubClient.CloseAsDuplicate(ctx, owner, repo, issue.GetNumber())
+			})
+			if err != nil {
+				return nil, nil, err
+			}
+			issue = closedIssue
+		}
+	case wasDuplicate && !reopenAllowed(githubIssue):
+		// the issue stays closed, no longer reported as a duplicate
+		return issue, nil, nil
+	case wasDuplicate:
+		if issue.GetState() == "closed" {
+			reopenedIssue, err := r.changeState(ctx, owner, repo, githubIssue, NotDuplicateComment, "open", func() (*github.Issue, error) {
+				return r.GithubClient.ReopenIssue(ctx, owner, repo, issue.GetNumber())
+			})
+			if err != nil {
+				return nil, nil, err
+			}
+			issue = reopenedIssue
+		}
+	default:
+		return issue, nil, nil
+	}
+
+	condition := status.ClosedAsDuplicate(duplicateOf)
+	return issue, &condition, nil
+}
+
+// closedByMilestone reports whether the operator closed the issue as its milestone is complete,
+// the issue then stays closed whatever the linked resource
+func closedByMilestone(githubIssue *issuev1.GithubIssue) bool {
+	return githubIssue.Spec.CloseOnMilestoneComplete &&
+		meta.IsStatusConditionTrue(githubIssue.Status.Conditions, status.ClosedByMilestoneCondition)
+}
+
+// reconcileMilestone closes the open issue once its milestone is closed on GitHub, when
+// CloseOnMilestoneComplete. It returns the issue with its current state and the
+// ClosedByMilestone condition, nil while the milestone is open. The milestone of a closed
+// issue isn't looked up, the condition it was closed with is kept.
+func (r *issueReconcile) reconcileMilestone(ctx context.Context, owner, repo string, githubIssue *issuev1.GithubIssue, issue *github.Issue) (*github.Issue, *metav1.Condition, error) {
+	milestone := issue.GetMilestone()
+	if !githubIssue.Spec.CloseOnMilestoneComplete || milestone == nil {
+		return issue, nil, nil
+	}
+	if issue.GetState() != "open" {
+		return issue, meta.FindStatusCondition(githubIssue.Status.Conditions, status.ClosedByMilestoneCondition), nil
+	}
+
+	closed, err := r.GithubClient.MilestoneClosed(ctx, owner, repo, milestone.GetNumber())
+	if err != nil {
+		return nil, nil, err
+	}
+	if !closed {
+		return issue, nil, nil
+	}
+
+	comment := resources.MilestoneCompletedComment(milestone.GetTitle())
+	closedIssue, err := r.changeState(ctx, owner, repo, githubIssue, comment, "closed", func() (*github.Issue, error) {
+		return r.GithubClient.CloseAsMilestoneCompleted(ctx, owner, repo, issue.GetNumber())
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	condition := status.ClosedByMilestone(milestone.GetTitle())
+	return closedIssue, &condition, nil
+}
+
+// DeadlineComment is commented on an issue closed because its closeAt time passed
+const DeadlineComment = "closed at its deadline"
+
+// pastDeadline reports whether the closeAt time of the GithubIssue passed
+func (r *GithubIssueReconciler) pastDeadline(githubIssue *issuev1.GithubIssue) bool {
+	return githubIssue.Spec.CloseAt != nil && !r.currentTime().Before(githubIssue.Spec.CloseAt.Time)
+}
+
+// reconcileDeadline closes the open issue once its CloseAt time passed. It returns the issue
+// with its current state, the ClosedByDeadline condition, nil before the deadline, and how
+// long until the deadline, zero when there's nothing to wait for.
+func (r *issueReconcile) reconcileDeadline(ctx context.Context, owner, repo string, githubIssue *issuev1.GithubIssue, issue *github.Issue) (*github.Issue, *metav1.Condition, time.Duration, error) {
+	if githubIssue.Spec.CloseAt == nil {
+		return issue, nil, 0, nil
+	}
+	closeAt := githubIssue.Spec.CloseAt.Time
+	if !r.pastDeadline(githubIssue) {
+		return issue, nil, closeAt.Sub(r.currentTime()), nil
+	}
+
+	if issue.GetState() == "open" {
+		closedIssue, err := r.changeState(ctx, owner, repo, githubIssue, DeadlineComment, "closed", func() (*github.Issue, error) {
+			if err := r.GithubClient.CloseIssue(owner, repo, issue); err != nil {
+				return nil, err
+			}
+			issue.State = github.String("closed")
+			return issue, nil
+		})
+		if err != nil {
+			return nil, nil, 0, err
+		}
+		issue = closedIssue
+	}
+
+	condition := status.ClosedByDeadline(closeAt)
+	return issue, &condition, 0, nil
+}
+
+// reconcileSuperseded closes the open issue with a comment linking the issue SupersededBy
+// references. It returns the issue with its current state and the Superseded condition, nil
+// for issues that aren't superseded.
+func (r *issueReconcile) reconcileSuperseded(ctx context.Context, owner, repo string, githubIssue *issuev1.GithubIssue, issue *github.Issue) (*github.Issue, *metav1.Condition, error) {
+	supersededBy := githubIssue.Spec.SupersededBy
+	if supersededBy == nil {
+		return issue, nil, nil
+	}
+
+	// GitHub links "#N" within the repository and "owner/repo#N" across repositories
+	successor := fmt.Sprintf("#%d", supersededBy.Number)
+	if supersededBy.Repo != "" {
+		successorOwner, successorRepo, err := utils.ParseRepoUrl(supersededBy.Repo)
+		if err != nil {
+			return nil, nil, err
+		}
+		if successorOwner != owner || successorRepo != repo {
+			successor = fmt.Sprintf("%s/%s#%d", successorOwner, successorRepo, supersededBy.Number)
+		}
+	}
+
+	if issue.GetState() == "open" {
+		closedIssue, err := r.changeState(ctx, owner, repo, githubIssue, resources.SupersededComment(successor), "closed", func() (*github.Issue, error) {
+			return r.GithubClient.CloseAsSuperseded(ctx, owner, repo, issue.GetNumber())
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		issue = closedIssue
+	}
+
+	condition := status.Superseded(successor)
+	return issue, &condition, nil
+}
+
+// reconcileBlocked reads which issues of BlockedBy are still open, returning the Blocked
+// condition, nil without BlockedBy. The blocked label is kept while they're open and
+// LabelBlocked, it's removed once BlockedBy is cleared or the label turned off. A failure to
+// read the blocking issues is reported through the condition, leaving the label as it is.
+func (r *issueReconcile) reconcileBlocked(log logr.Logger, owner, repo string, githubIssue *issuev1.GithubIssue, issue *github.Issue) (*metav1.Condition, error) {
+	var (
+		condition *metav1.Condition
+		blocked   bool
+	)
+	if len(githubIssue.Spec.BlockedBy) > 0 {
+		openDependencies, err := r.GithubClient.OpenDependencies(owner, repo, githubIssue.Spec.BlockedBy)
+		blockedCondition := status.Blocked(openDependencies, err)
+		condition = &blockedCondition
+		if err != nil {
+			log.Error(err, "unable to read blocking issues")
+			return condition, nil
+		}
+		blocked = len(openDependencies) > 0
+	}
+	return condition, r.reconcileBlockedLabel(owner, repo, issue, githubIssue.Spec.LabelBlocked && blocked)
+}
+
+// reconcileBlockedLabel adds or removes the blocked label so it matches whether the issue is blocked
+func (r *issueReconcile) reconcileBlockedLabel(owner, repo string, issue *github.Issue, blocked bool) error {
+	return r.reconcileToggledLabel(owner, repo, issue, resources.BlockedLabel, blocked)
+}
+
+// reconcileCollision looks for other GithubIssues whose status holds the same issue, reporting
+// the collision through the IssueNumberCollision condition on them too. It returns the
+// condition of the GithubIssue, nil without a collision, and whether it should leave the issue
+// to an older GithubIssue rather than fight over it.
+func (r *GithubIssueReconciler) reconcileCollision(ctx context.Context, log logr.Logger, githubIssue *issuev1.GithubIssue, owner, repo string, number int) (*metav1.Condition, bool, error) {
+	githubIssues := &issuev1.GithubIssueList{}
+	if err := r.listSelected(ctx, githubIssues); err != nil {
+		return nil, false, err
+	}
+	self := client.ObjectKeyFromObject(githubIssue)
+	colliding := summary.Colliding(githubIssues.Items, self, owner, repo, number)
+	if len(colliding) == 0 {
+		return nil, false, nil
+	}
+
+	keeping := summary.Older(githubIssue, &colliding[0])
+	others := make([]string, 0, len(colliding))
+	for i := range colliding {
+		other := &colliding[i]
+		others = append(others, client.ObjectKeyFromObject(other).String())
+
+		// best-effort, the other GithubIssue reports the collision on its own reconcile too
+		if meta.IsStatusConditionTrue(other.Status.Conditions, status.IssueNumberCollisionCondition) {
+			continue
+		}
+		meta.SetStatusCondition(&other.Status.Conditions, status.IssueNumberCollision(number, []string{self.String()}, !keeping && i == 0))
+		if err := r.Client.Status().Update(ctx, other); err != nil {
+			log.Error(err, "unable to report the collision", "other", client.ObjectKeyFromObject(other))
+		}
+	}
+
+	condition := status.IssueNumberCollision(number, others, keeping)
+	return &condition, !keeping, nil
+}
+
+// reconcileVersionLabel labels the issue with the running operator version when VersionLabel
+// is set, replacing the label of the version that managed it before
+func (r *issueReconcile) reconcileVersionLabel(owner, repo string, issue *github.Issue) error {
+	if !r.VersionLabel {
+		return nil
+	}
+	current := resources.VersionLabel()
+	for _, label := range issue.Labels {
+		if name := label.GetName(); strings.HasPrefix(name, resources.VersionLabelPrefix) && name != current {
+			if err := r.GithubClient.RemoveLabel(owner, repo, issue, name); err != nil {
+				return err
+			}
+		}
+	}
+	if !resources.HasLabel(issue, current) {
+		return r.GithubClient.AddLabel(owner, repo, issue, current)
+	}
+	return nil
+}
+
+// reconcileApprovalLabel adds the ApprovalLabel while the GithubIssue awaits approval and
+// removes it once the GithubIssue is annotated as approved
+func (r *issueReconcile) reconcileApprovalLabel(owner, repo string, githubIssue *issuev1.GithubIssue, issue *github.Issue) error {
+	approved := githubIssue.Annotations[utils.ApprovedAnnotation] == "true"
+	return r.reconcileToggledLabel(owner, repo, issue, githubIssue.Spec.ApprovalLabel, !approved)
+}
+
+// reconcileToggledLabel adds or removes the label so it matches whether the issue should have it
+func (r *issueReconcile) reconcileToggledLabel(owner, repo string, issue *github.Issue, label string, want bool) error {
+	hasLabel := resources.HasLabel(issue, label)
+	switch {
+	case want && !hasLabel:
+		return r.GithubClient.AddLabel(owner, repo, issue, label)
+	case !want && hasLabel:
+		return r.GithubClient.RemoveLabel(owner, repo, issue, label)
+	}
+	return nil
+}
+
+// createIssue creates the issue, unless an earlier reconcile created it but failed to record
+// it in the status: the issue carrying the dedupe marker of the GithubIssue is returned then
+func (r *issueReconcile) createIssue(ctx context.Context, log logr.Logger, githubIssue *issuev1.GithubIssue, owner, repo, title, description string, fields resources.IssueFields) (*github.Issue, error) {
+	// a concurrent reconcile creating the same issue could go unnoticed by the marker lookup,
+	// the issue it created may not be listed yet
+	issue, release, err := r.claimCreate(ctx, owner+"/"+repo+"\x00"+title)
+	if err != nil {
+		return nil, err
+	}
+	if issue != nil {
+		log.Info("found the issue a concurrent reconcile created", "number", issue.GetNumber())
+		return issue, nil
+	}
+	issue, err = r.findOrCreateIssue(ctx, log, githubIssue, owner, repo, title, description, fields)
+	release(issue)
+	return issue, err
+}
+
+// findOrCreateIssue creates the issue unless an earlier reconcile created it already
+func (r *issueReconcile) findOrCreateIssue(ctx context.Context, log logr.Logger, githubIssue *issuev1.GithubIssue, owner, repo, title, description string, fields resources.IssueFields) (*github.Issue, error) {
+	if githubIssue.UID != "" {
+		issue, err := r.GithubClient.FindIssueByMarker(ctx, owner, repo, utils.DedupeMarker(string(githubIssue.UID)))
+		if err != nil {
+			return nil, err
+		}
+		// the closed issue a new one replaces carries the marker too, it is the latest created
+		if issue != nil && int32(issue.GetNumber()) != githubIssue.Status.PreviousIssueNumber {
+			log.Info("found the issue created by an earlier reconcile", "number", issue.GetNumber())
+			return issue, nil
+		}
+	}
+	issue, err := r.GithubClient.CreateIssue(owner, repo, title, description, fields)
+	if truncated, ok := r.truncateRefusedBody(err, description); ok {
+		log.Info("GitHub refused the issue body as too long, retrying truncated")
+		return r.GithubClient.CreateIssue(owner, repo, title, truncated, fields)
+	}
+	return issue, err
+}
+
+// updateIssue updates the issue, retrying with the body truncated once when GitHub refuses it
+// as too long. It returns the updated issue and the body it was updated with.
+func (r *issueReconcile) updateIssue(owner, repo string, issue *github.Issue, body, title string, fields resources.IssueFields) (*github.Issue, string, error) {
+	updatedIssue, err := r.GithubClient.UpdateIssue(owner, repo, issue, body, title, fields)
+	if truncated, ok := r.truncateRefusedBody(err, body); ok {
+		updatedIssue, err = r.GithubClient.UpdateIssue(owner, repo, issue, truncated, title, fields)
+		return updatedIssue, truncated, err
+	}
+	return updatedIssue, body, err
+}
+
+// truncateRefusedBody returns the body truncated to GitHub's limit when GitHub refused it as
+// too long and TruncateBody is set, ok is false when truncating doesn't shorten the body
+func (r *GithubIssueReconciler) truncateRefusedBody(err error, body string) (truncated string, ok bool) {
+	if !r.TruncateBody || !resources.IsBodyTooLarge(err) {
+		return "", false
+	}
+	return utils.TruncateBody(body, utils.GithubBodyLimit)
+}
+
+// convertedToDiscussion checks whether the recorded issue GitHub no longer serves was
+// converted to a discussion, reporting it through the IssueConvertedToDiscussion condition
+func (r *issueReconcile) convertedToDiscussion(ctx context.Context, log logr.Logger, githubIssue *issuev1.GithubIssue, owner, repo string) (bool, error) {
+	discussionURL, err := r.GithubClient.DiscussionURL(ctx, owner, repo, int(githubIssue.Status.IssueNumber))
+	if err != nil {
+		log.Error(err, "unable to look up a discussion for the issue")
+		return false, err
+	}
+	if discussionURL == "" {
+		return false, nil
+	}
+
+	log.Info("issue was converted to a discussion, no longer editing it", "discussion", discussionURL)
+	if err := status.UpdateConvertedToDiscussion(ctx, r.Client, githubIssue, discussionURL); err != nil {
+		log.Error(err, "unable to update IssueConvertedToDiscussion status")
+		return false, err
+	}
+	return true, nil
+}
+
+// withFooter renders the footer of the GithubIssue with the number and URL of the issue
+// into the body
+func withFooter(githubIssue *issuev1.GithubIssue, body string, issue *github.Issue) (string, error) {
+	if githubIssue.Spec.Footer == "" {
+		return body, nil
+	}
+	return utils.RenderFooter(body, githubIssue.Spec.Footer, footer.Data{
+		IssueNumber: issue.GetNumber(),
+		IssueURL:    issue.GetHTMLURL(),
+	})
+}
+
+// withTrackedByPR renders the line naming the pull request the issue is linked to into the
+// body, dropping it once the link is gone
+func withTrackedByPR(githubIssue *issuev1.GithubIssue, body string, issue *github.Issue) string {
+	number, hasPR := resources.LinkedPullRequest(issue)
+	if !githubIssue.Spec.TrackPR || !hasPR {
+		number = 0
+	}
+	return utils.RenderTrackedByPR(body, number)
+}
+
+// fillFooter edits the footer into the body of the issue just created, now its number and URL
+// are known. Later reconciles render the same marked footer, so the issue isn't edited again.
+func (r *issueReconcile) fillFooter(githubIssue *issuev1.GithubIssue, owner, repo string, issue *github.Issue, description, title string, fields resources.IssueFields) (*github.Issue, error) {
+	body, err := withFooter(githubIssue, description, issue)
+	if err != nil {
+		return nil, err
+	}
+	if githubIssue.Spec.ManagedSection {
+		body = utils.RenderManagedSection(issue.GetBody(), body)
+	}
+	updatedIssue, sent, err := r.updateIssue(owner, repo, issue, body, title, fields)
+	if err != nil {
+		return nil, err
+	}
+	githubIssue.Status.BodyHash = utils.ContentHash(sent)
+	return updatedIssue, nil
+}
+
+// notifyState notifies the notify URL of the issue being created or closed, recording the
+// state notified of. While there's nothing new the condition of the latest delivery is kept,
+// a failed delivery is retried by the next reconcile. The state is recorded as soon as the
+// delivery succeeds, and a delivery sent again when that fails carries the same delivery ID.
+func (r *GithubIssueReconciler) notifyState(ctx context.Context, log logr.Logger, githubIssue *issuev1.GithubIssue, owner, repo string, issue *github.Issue) *metav1.Condition {
+	if githubIssue.Spec.NotifyURL == "" {
+		return nil
+	}
+
+	state := issue.GetState()
+	event := notify.Event(githubIssue.Status.NotifiedState, state)
+	if event == "" {
+		githubIssue.Status.NotifiedState = state
+		return meta.FindStatusCondition(githubIssue.Status.Conditions, "NotificationDelivered")
+	}
+
+	notifier := r.Notifier
+	if notifier == nil {
+		notifier = &notify.Notifier{}
+	}
+	err := notifier.Send(ctx, githubIssue.Spec.NotifyURL, notify.Payload{
+		Event:      event,
+		Repo:       owner + "/" + repo,
+		Number:     issue.GetNumber(),
+		URL:        issue.GetHTMLURL(),
+		Title:      issue.GetTitle(),
+		DeliveryID: notify.DeliveryID(string(githubIssue.UID), issue.GetNumber(), event),
+	})
+	if err != nil {
+		log.Error(err, "unable to notify of the issue", "event", event)
+	} else if err := status.RecordNotifiedState(ctx, r.Client, githubIssue, state); err != nil {
+		log.Error(err, "unable to record the notification", "event", event)
+		githubIssue.Status.NotifiedState = state
+	}
+	condition := status.NotificationDelivered(event, err)
+	return &condition
+}
+
+// recentCreate is the creation of an issue, in flight while done is set, closing it when
+// the creation completes. It holds the created issue, nil when the creation failed.
+type recentCreate struct {
+	done  chan struct{}
+	issue *github.Issue
+	at    time.Time
+}
+
+// createDedupeWindow returns how long a created issue is handed to concurrent reconciles
+func (r *GithubIssueReconciler) createDedupeWindow() time.Duration {
+	if r.CreateDedupeWindow > 0 {
+		return r.CreateDedupeWindow
+	}
+	return 30 * time.Second
+}
+
+// claimCreate claims the creation of the issue of the key. It returns the issue created by
+// another reconcile within the dedupe window, waiting for a creation in flight, or else a
+// release func the claiming reconcile reports the issue it created to.
+func (r *GithubIssueReconciler) claimCreate(ctx context.Context, key string) (*github.Issue, func(*github.Issue), error) {
+	for {
+		r.recentCreatesMu.Lock()
+		if r.recentCreates == nil {
+			r.recentCreates = map[string]*recentCreate{}
+		}
+		now := r.currentTime()
+		for k, recent := range r.recentCreates {
+			if recent.done == nil && (recent.issue == nil || now.Sub(recent.at) > r.createDedupeWindow()) {
+				delete(r.recentCreates, k)
+			}
+		}
+		recent, ok := r.recentCreates[key]
+		if ok && recent.done == nil {
+			issue := recent.issue
+			r.recentCreatesMu.Unlock()
+			return issue, nil, nil
+		}
+		if !ok {
+			recent = &recentCreate{done: make(chan struct{})}
+			r.recentCreates[key] = recent
+			r.recentCreatesMu.Unlock()
+			return nil, func(issue *github.Issue) {
+				r.recentCreatesMu.Lock()
+				defer r.recentCreatesMu.Unlock()
+				recent.issue = issue
+				recent.at = r.currentTime()
+				close(recent.done)
+				recent.done = nil
+			}, nil
+		}
+		done := recent.done
+		r.recentCreatesMu.Unlock()
+
+		// wait for the creation in flight, claiming it again when it failed
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		}
+	}
+}
+
+// deferCreation returns how long until the create window of the issue opens, reporting the
+// deferral through the DeferredCreation condition. Zero means the issue can be created now.
+func (r *GithubIssueReconciler) deferCreation(ctx context.Context, githubIssue *issuev1.GithubIssue) (time.Duration, error) {
+	window := githubIssue.Spec.CreateWindow
+	if window == nil {
+		return 0, nil
+	}
+	now := r.currentTime()
+	in, opensIn, err := utils.InCreateWindow(window, now)
+	if err != nil || in {
+		return 0, err
+	}
+	if err := status.UpdateDeferredCreation(ctx, r.Client, githubIssue, now.Add(opensIn)); err != nil {
+		return 0, err
+	}
+	return opensIn, nil
+}
+
+// frozen returns how long until the change freeze ends, reporting it through the Frozen
+// condition. Zero means there is no freeze in effect.
+func (r *GithubIssueReconciler) frozen(ctx context.Context, githubIssue *issuev1.GithubIssue) (time.Duration, error) {
+	if r.FreezeConfigMap.Name == "" {
+		return 0, nil
+	}
+	reader := r.APIReader
+	if reader == nil {
+		reader = r.Client
+	}
+	window, err := freeze.Load(ctx, reader, r.FreezeConfigMap)
+	if err != nil || window == nil {
+		return 0, err
+	}
+	active, endsIn := window.Active(r.currentTime())
+	if !active {
+		return 0, nil
+	}
+	if err := status.UpdateFrozen(ctx, r.Client, githubIssue, window.End, window.Reason); err != nil {
+		return 0, err
+	}
+	return endsIn, nil
+}
+
+// limitCreation takes a creation from the creation rate limit, returning how long until one
+// is available when it's exhausted and reporting it through the CreationRateLimited condition.
+// Zero means the issue can be created now.
+func (r *GithubIssueReconciler) limitCreation(ctx context.Context, githubIssue *issuev1.GithubIssue) (time.Duration, error) {
+	if r.CreationLimiter == nil {
+		return 0, nil
+	}
+	now := r.currentTime()
+	reservation := r.CreationLimiter.ReserveN(now, 1)
+	retryIn := reservation.DelayFrom(now)
+	if retryIn == 0 {
+		return 0, nil
+	}
+	// the creation is retried later rather than waited for, so it isn't taken now
+	reservation.CancelAt(now)
+	if err := status.UpdateCreationRateLimited(ctx, r.Client, githubIssue, retryIn); err != nil {
+		return 0, err
+	}
+	return retryIn, nil
+}
+
+// handleSecondaryRateLimit backs every reconcile off for as long as GitHub asks, reporting it
+// through the SecondaryRateLimited condition, and requeues the issue once the cooldown is over
+func (r *GithubIssueReconciler) handleSecondaryRateLimit(ctx context.Context, req ctrl.Request, abuseErr *github.AbuseRateLimitError) (ctrl.Result, error) {
+	log := r.Log.WithValues("githubissue", req.NamespacedName)
+
+	retryAfter := abuseErr.GetRetryAfter()
+	if retryAfter <= 0 {
+		retryAfter = defaultSecondaryRateLimitCooldown
+	}
+	log.Info("hit GitHub's secondary rate limit, backing off", "retryAfter", retryAfter)
+
+	r.secondaryRateLimitMu.Lock()
+	if until := r.currentTime().Add(retryAfter); until.After(r.secondaryRateLimitUntil) {
+		r.secondaryRateLimitUntil = until
+	}
+	r.secondaryRateLimitMu.Unlock()
+
+	// best-effort, the condition is cleared by the next successful reconcile
+	githubIssue := &issuev1.GithubIssue{}
+	if err := r.Client.Get(ctx, req.NamespacedName, githubIssue); err == nil {
+		if err := status.UpdateSecondaryRateLimited(ctx, r.Client, githubIssue, retryAfter); err != nil {
+			log.Error(err, "unable to update SecondaryRateLimited status")
+		}
+	}
+	return ctrl.Result{RequeueAfter: retryAfter}, nil
+}
+
+// secondaryRateLimitWait returns how long until the secondary rate limit cools down, zero
+// when it isn't hit
+func (r *GithubIssueReconciler) secondaryRateLimitWait() time.Duration {
+	r.secondaryRateLimitMu.Lock()
+	defer r.secondaryRateLimitMu.Unlock()
+	if wait := r.secondaryRateLimitUntil.Sub(r.currentTime()); wait > 0 {
+		return wait
+	}
+	return 0
+}
+
+// currentTime returns the time of the fake clock in tests, the current time otherwise
+func (r *GithubIssueReconciler) currentTime() time.Time {
+	if r.now != nil {
+		return r.now()
+	}
+	return time.Now()
+}
+
+// reconcileAtRiskLabel adds the at-risk label while the issue is open and its milestone
+// is due within AtRiskWithin, removing it otherwise. It returns how long until the
+// milestone becomes at risk, zero when there's nothing to wait for.
+func (r *issueReconcile) reconcileAtRiskLabel(owner, repo string, issue *github.Issue) (time.Duration, error) {
+	atRisk, recheckIn := false, time.Duration(0)
+	if dueOn := issue.GetMilestone().GetDueOn(); issue.GetState() == "open" && !dueOn.IsZero() {
+		atRisk, recheckIn = utils.AtRisk(dueOn, r.currentTime(), r.AtRiskWithin)
+	}
+
+	hasLabel := resources.HasLabel(issue, resources.AtRiskLabel)
+	switch {
+	case atRisk && !hasLabel:
+		return recheckIn, r.GithubClient.AddLabel(owner, repo, issue, resources.AtRiskLabel)
+	case !atRisk && hasLabel:
+		return recheckIn, r.GithubClient.RemoveLabel(owner, repo, issue, resources.AtRiskLabel)
+	}
+	return recheckIn, nil
+}
+
+// slaBreached returns the condition reporting whether the open issue outlived SLAMaxAge,
+// counting the breach when it's new. It returns how long until the issue breaches it,
+// zero when there's nothing to wait for.
+func (r *GithubIssueReconciler) slaBreached(githubIssue *issuev1.GithubIssue, issue *github.Issue) (metav1.Condition, time.Duration) {
+	maxAge := githubIssue.Spec.SLAMaxAge.Duration
+	breached, breachesIn := false, time.Duration(0)
+	if createdAt := issue.GetCreatedAt(); issue.GetState() == "open" && !createdAt.IsZero() {
+		breached, breachesIn = utils.AtRisk(createdAt.Add(maxAge), r.currentTime(), 0)
+	}
+	if breached && !meta.IsStatusConditionTrue(githubIssue.Status.Conditions, status.SLABreachedCondition) {
+		metrics.ObserveSLABreach()
+	}
+	return status.SLABreached(breached, maxAge), breachesIn
+}
+
+// reconcileCategoryLabel attaches the label mapped to the category, removing the labels of other
+// categories. An empty category removes them all.
+func (r *issueReconcile) reconcileCategoryLabel(owner, repo string, issue *github.Issue, category string) error {
+	desired, ok := r.CategoryLabels[category]
+	if !ok && category != "" {
+		return fmt.Errorf("no label configured for category %s", category)
+	}
+	return r.reconcileExclusiveLabel(owner, repo, issue, r.CategoryLabels, desired)
+}
+
+// reconcileSeverityLabel attaches the label mapped to the severity, removing the labels of other
+// severities. An empty severity removes them all.
+func (r *issueReconcile) reconcileSeverityLabel(owner, repo string, issue *github.Issue, severity issuev1.Severity) error {
+	desired, ok := r.SeverityLabels[string(severity)]
+	if !ok && severity != "" {
+		return fmt.Errorf("no label configured for severity %s", severity)
+	}
+	return r.reconcileExclusiveLabel(owner, repo, issue, r.SeverityLabels, desired)
+}
+
+// reconcileExclusiveLabel attaches the desired label, removing the other labels of the mapping,
+// an empty desired label leaves none of them
+func (r *issueReconcile) reconcileExclusiveLabel(owner, repo string, issue *github.Issue, labels map[string]string, desired string) error {
+	for _, label := range labels {
+		if label != desired && resources.HasLabel(issue, label) {
+			if err := r.GithubClient.RemoveLabel(owner, repo, issue, label); err != nil {
+				return err
+			}
+		}
+	}
+	if desired != "" && !resources.HasLabel(issue, desired) {
+		return r.GithubClient.AddLabel(owner, repo, issue, desired)
+	}
+	return nil
+}
+
+// linkedForbiddenError reports a linked resource the GithubIssue isn't allowed to read
+type linkedForbiddenError struct {
+	err error
+}
+
+func (e *linkedForbiddenError) Error() string {
+	return e.err.Error()
+}
+
+// linkedHealthy reports whether the resource linked to the GithubIssue is healthy, watching
+// its kind. Resources of other kinds or namespaces than allowed aren't read.
+func (r *GithubIssueReconciler) linkedHealthy(ctx context.Context, githubIssue *issuev1.GithubIssue) (bool, error) {
+	kinds := r.LinkedKinds
+	if kinds == nil {
+		kinds = linked.DefaultKinds
+	}
+	if err := linked.Check(githubIssue, kinds); err != nil {
+		return false, &linkedForbiddenError{err: err}
+	}
+	if err := r.watchLinkedResource(githubIssue.Spec.LinkedResource); err != nil {
+		return false, fmt.Errorf("unable to watch linked resource: %w", err)
+	}
+	return linked.IsHealthy(ctx, r.Client, githubIssue)
+}
+
+// watchLinkedResource starts watching the kind of the linked resource, unless it is
+// watched already, so that health changes trigger a reconcile of the GithubIssues linked to it
+func (r *GithubIssueReconciler) watchLinkedResource(linkedResource *issuev1.LinkedResource) error {
+	if r.controller == nil {
+		return nil
+	}
+
+	gvk := linked.GroupVersionKind(linkedResource)
+	r.watchedKindsMu.Lock()
+	defer r.watchedKindsMu.Unlock()
+	if r.watchedKinds[gvk] {
+		return nil
+	}
+
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(gvk)
+	if err := r.controller.Watch(source.Kind[client.Object](r.cache, obj,
+		handler.EnqueueRequestsFromMapFunc(r.mapLinkedResource(gvk)))); err != nil {
+		return fmt.Errorf("failed to watch %s: %w", gvk, err)
+	}
+	r.watchedKinds[gvk] = true
+	return nil
+}
+
+// mapLinkedResource maps an object of the given kind to the GithubIssues linked to it
+func (r *GithubIssueReconciler) mapLinkedResource(gvk schema.GroupVersionKind) handler.MapFunc {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		githubIssues := &issuev1.GithubIssueList{}
+		if err := r.listSelected(ctx, githubIssues); err != nil {
+			r.Log.Error(err, "unable to list GithubIssues for linked resource", "resource", obj.GetName())
+			return nil
+		}
+
+		var requests []reconcile.Request
+		for i := range githubIssues.Items {
+			if linked.References(&githubIssues.Items[i], obj, gvk) {
+				requests = append(requests, reconcile.Request{
+					NamespacedName: client.ObjectKeyFromObject(&githubIssues.Items[i]),
+				})
+			}
+		}
+		return requests
+	}
+}
+
+// createFirstHandler enqueues the GithubIssues that have no issue yet right away, and the
+// ones that have after the delay, so creations aren't queued behind a large apply's updates.
+// GithubIssues being deleted are enqueued right away.
+func createFirstHandler(delay time.Duration) handler.EventHandler {
+	enqueue := func(obj client.Object, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+		req := reconcile.Request{NamespacedName: client.ObjectKeyFromObject(obj)}
+		githubIssue, ok := obj.(*issuev1.GithubIssue)
+		if ok && delay > 0 && githubIssue.Status.IssueNumber != 0 && githubIssue.DeletionTimestamp.IsZero() {
+			q.AddAfter(req, delay)
+			return
+		}
+		q.Add(req)
+	}
+	return handler.Funcs{
+		CreateFunc: func(_ context.Context, e event.CreateEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			enqueue(e.Object, q)
+		},
+		UpdateFunc: func(_ context.Context, e event.UpdateEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			enqueue(e.ObjectNew, q)
+		},
+		DeleteFunc: func(_ context.Context, e event.DeleteEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			q.Add(reconcile.Request{NamespacedName: client.ObjectKeyFromObject(e.Object)})
+		},
+		GenericFunc: func(_ context.Context, e event.GenericEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			enqueue(e.Object, q)
+		},
+	}
+}
+
+// selects reports whether the labels of the GithubIssue match the Selector of the instance
+func (r *GithubIssueReconciler) selects(obj client.Object) bool {
+	return r.Selector == nil || r.Selector.Matches(labels.Set(obj.GetLabels()))
+}
+
+// listSelected lists the GithubIssues matching the Selector of the instance, those of other
+// shards are left to the instances reconciling them
+func (r *GithubIssueReconciler) listSelected(ctx context.Context, githubIssues *issuev1.GithubIssueList) error {
+	if r.Selector == nil {
+		return r.Client.List(ctx, githubIssues)
+	}
+	return r.Client.List(ctx, githubIssues, client.MatchingLabelsSelector{Selector: r.Selector})
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *GithubIssueReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	c, err := ctrl.NewControllerManagedBy(mgr).
+		Named("githubissue").
+		WithOptions(controller.Options{MaxConcurrentReconciles: r.MaxConcurrentReconciles}).
+		Watches(&issuev1.GithubIssue{}, createFirstHandler(r.UpdateDelay),
+			builder.WithPredicates(predicate.NewPredicateFuncs(r.selects))).
+		// the token secrets, reconciling their GithubIssue when the token or the owner changes
+		Watches(&corev1.Secret{}, handler.EnqueueRequestForOwner(mgr.GetScheme(), mgr.GetRESTMapper(),
+			&issuev1.GithubIssue{}, handler.OnlyControllerOwner())).
+		Build(r)
+	if err != nil {
+		return err
+	}
+
+	r.controller = c
+	r.cache = mgr.GetCache()
+	r.watchedKinds = map[schema.GroupVersionKind]bool{}
+	return nil
 }
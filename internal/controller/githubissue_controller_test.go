@@ -18,20 +18,53 @@ package controller
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-logr/logr"
 	"github.com/google/go-github/v47/github"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	issuev1 "github.com/oshribelay/github-issue-operator/api/v1"
+	"github.com/oshribelay/github-issue-operator/internal/controller/freeze"
+	"github.com/oshribelay/github-issue-operator/internal/controller/metrics"
+	"github.com/oshribelay/github-issue-operator/internal/controller/notify"
+	"github.com/oshribelay/github-issue-operator/internal/controller/resources"
+	"github.com/oshribelay/github-issue-operator/internal/controller/status"
+	"github.com/oshribelay/github-issue-operator/internal/controller/tokenfile"
 	"github.com/oshribelay/github-issue-operator/internal/controller/utils"
+	dto "github.com/prometheus/client_model/go"
 	"golang.org/x/oauth2"
+	"golang.org/x/time/rate"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/api/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
-	"os"
-	"time"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 var _ = Describe("GithubIssue Controller", func() {
@@ -58,7 +91,7 @@ var _ = Describe("GithubIssue Controller", func() {
 
 			// create issue if it doesn't exist
 			err := k8sClient.Get(ctx, typeNamespacedName, githubIssue)
-			if err != nil && errors.IsNotFound(err) {
+			if err != nil && apierrors.IsNotFound(err) {
 				resource := &issuev1.GithubIssue{
 					ObjectMeta: metav1.ObjectMeta{
 						Name:      resourceName,
@@ -101,7 +134,7 @@ var _ = Describe("GithubIssue Controller", func() {
 				},
 			})
 			// Only return an error if the Secret exists and can't be deleted
-			Expect(err != nil && !errors.IsNotFound(err)).To(BeFalse())
+			Expect(err != nil && !apierrors.IsNotFound(err)).To(BeFalse())
 
 			Expect(k8sClient.Create(ctx, secret)).To(Succeed())
 
@@ -134,7 +167,7 @@ var _ = Describe("GithubIssue Controller", func() {
 				By("waiting for the controller to handle deletion")
 				Eventually(func() string {
 					err := k8sClient.Get(ctx, typeNamespacedName, updatedIssue)
-					if errors.IsNotFound(err) {
+					if apierrors.IsNotFound(err) {
 						return "deleted"
 					}
 					if err != nil {
@@ -153,7 +186,7 @@ var _ = Describe("GithubIssue Controller", func() {
 						Namespace: secret.Namespace,
 						Name:      secret.Name,
 					}, secret)
-					if errors.IsNotFound(err) {
+					if apierrors.IsNotFound(err) {
 						return "deleted"
 					}
 					if err != nil {
@@ -223,7 +256,7 @@ var _ = Describe("GithubIssue Controller", func() {
 			By("waiting for the controller to handle deletion")
 			Eventually(func() bool {
 				err := k8sClient.Get(ctx, typeNamespacedName, createdIssue)
-				return errors.IsNotFound(err)
+				return apierrors.IsNotFound(err)
 			}, timeout, interval).Should(BeTrue())
 
 			By("waiting for the issue to be deleted on GitHub")
@@ -407,3 +440,4050 @@ func checkIssueClosedOnGithub(c *github.Client, githubIssue *issuev1.GithubIssue
 	}
 	return false, nil
 }
+
+var _ = Describe("GithubIssue Controller requeue delays", func() {
+	ctx := context.Background()
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "requeue-resource", Namespace: "default"}}
+
+	// newReconciler returns a reconciler whose issue has a secret without a token
+	newReconciler := func() *GithubIssueReconciler {
+		s := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(s)).To(Succeed())
+		Expect(issuev1.AddToScheme(s)).To(Succeed())
+
+		githubIssue := &issuev1.GithubIssue{
+			ObjectMeta: metav1.ObjectMeta{Name: req.Name, Namespace: req.Namespace},
+			Spec: issuev1.GithubIssueSpec{
+				Repo:  "https://github.com/owner/repo",
+				Title: "Test Issue",
+			},
+		}
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "requeue-resource-token-secret", Namespace: req.Namespace},
+			Data:       map[string][]byte{"token": []byte("")},
+		}
+		c := fake.NewClientBuilder().WithScheme(s).
+			WithObjects(githubIssue, secret).
+			WithStatusSubresource(githubIssue).
+			Build()
+
+		return &GithubIssueReconciler{Client: c, Scheme: s, Log: logr.Discard()}
+	}
+
+	It("Should requeue a missing token after a minute by default", func() {
+		result, err := newReconciler().Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(time.Minute))
+	})
+
+	It("Should requeue a missing token after the configured delay", func() {
+		r := newReconciler()
+		r.MissingTokenRequeue = 10 * time.Second
+
+		result, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(10 * time.Second))
+	})
+
+	// conflictingReconciler returns a reconciler whose issue has an API base URL the operator
+	// doesn't allow, and whose status updates all conflict
+	conflictingReconciler := func() *GithubIssueReconciler {
+		s := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(s)).To(Succeed())
+		Expect(issuev1.AddToScheme(s)).To(Succeed())
+
+		githubIssue := &issuev1.GithubIssue{
+			ObjectMeta: metav1.ObjectMeta{Name: req.Name, Namespace: req.Namespace},
+			Spec: issuev1.GithubIssueSpec{
+				Repo:       "https://github.com/owner/repo",
+				Title:      "Test Issue",
+				APIBaseURL: "https://github.example.com/api/v3/",
+			},
+		}
+		c := fake.NewClientBuilder().WithScheme(s).
+			WithObjects(githubIssue).
+			WithStatusSubresource(githubIssue).
+			WithInterceptorFuncs(interceptor.Funcs{
+				SubResourceUpdate: func(context.Context, client.Client, string, client.Object, ...client.SubResourceUpdateOption) error {
+					return apierrors.NewConflict(schema.GroupResource{Group: "issue.core.github.io", Resource: "githubissues"}, req.Name, nil)
+				},
+			}).
+			Build()
+
+		return &GithubIssueReconciler{Client: c, Scheme: s, Log: logr.Discard()}
+	}
+
+	It("Should requeue a conflict after five seconds by default", func() {
+		result, err := conflictingReconciler().Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal(ctrl.Result{RequeueAfter: 5 * time.Second}))
+	})
+
+	It("Should requeue a conflict after the configured delay", func() {
+		r := conflictingReconciler()
+		r.ConflictRequeue = 30 * time.Second
+
+		result, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal(ctrl.Result{RequeueAfter: 30 * time.Second}))
+	})
+
+	It("Should requeue at the boundary where the milestone becomes at risk", func() {
+		now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+		r := &issueReconcile{GithubIssueReconciler: &GithubIssueReconciler{AtRiskWithin: 48 * time.Hour, now: func() time.Time { return now }}}
+		dueOn := now.Add(72 * time.Hour)
+		issue := &github.Issue{
+			State:     github.String("open"),
+			Milestone: &github.Milestone{DueOn: &dueOn},
+		}
+
+		// not at risk yet and unlabeled, so GitHub isn't called
+		recheckIn, err := r.reconcileAtRiskLabel("owner", "repo", issue)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(recheckIn).To(Equal(24 * time.Hour))
+	})
+})
+
+var _ = Describe("GithubIssue Controller duplicates", func() {
+	ctx := context.Background()
+
+	var (
+		state        string
+		comments     []string
+		failEdits    int
+		failComments int
+		githubIssue  *issuev1.GithubIssue
+		r            *issueReconcile
+	)
+
+	BeforeEach(func() {
+		state = "open"
+		comments = nil
+		failEdits = 0
+		failComments = 0
+
+		mux := http.NewServeMux()
+		mux.HandleFunc("/repos/owner/repo/issues/4", func(w http.ResponseWriter, req *http.Request) {
+			var request map[string]string
+			Expect(json.NewDecoder(req.Body).Decode(&request)).To(Succeed())
+			if failEdits > 0 {
+				failEdits--
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			state = request["state"]
+			fmt.Fprintf(w, `{"number": 4, "state": %q}`, state)
+		})
+		postComment := commentsHandler(&comments)
+		mux.HandleFunc("/repos/owner/repo/issues/4/comments", func(w http.ResponseWriter, req *http.Request) {
+			if req.Method == http.MethodPost && failComments > 0 {
+				failComments--
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			postComment(w, req)
+		})
+		githubClient := newGithubServer(mux)
+		githubIssue = &issuev1.GithubIssue{ObjectMeta: metav1.ObjectMeta{Name: "duplicate", Namespace: "default"}}
+		r = &issueReconcile{
+			GithubIssueReconciler: &GithubIssueReconciler{
+				Client: newFakeClient(githubIssue),
+			},
+			GithubClient: githubClient,
+		}
+	})
+
+	It("Should comment once when closing the duplicate is retried", func() {
+		githubIssue.Spec.DuplicateOf = 2
+		failEdits = 1
+
+		_, _, err := r.reconcileDuplicate(ctx, "owner", "repo", githubIssue, &github.Issue{Number: github.Int(4), State: github.String("open")})
+		Expect(err).To(HaveOccurred())
+		Expect(comments).To(BeEmpty())
+
+		issue, _, err := r.reconcileDuplicate(ctx, "owner", "repo", githubIssue, &github.Issue{Number: github.Int(4), State: github.String("open")})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(issue.GetState()).To(Equal("closed"))
+		Expect(commentTexts(comments)).To(Equal([]string{"Duplicate of #2"}))
+	})
+
+	It("Should still comment on the closed duplicate when commenting failed", func() {
+		githubIssue.Spec.DuplicateOf = 2
+		failComments = 1
+
+		_, _, err := r.reconcileDuplicate(ctx, "owner", "repo", githubIssue, &github.Issue{Number: github.Int(4), State: github.String("open")})
+		Expect(err).To(HaveOccurred())
+		Expect(state).To(Equal("closed"))
+		Expect(comments).To(BeEmpty())
+
+		By("finding the comment pending on the next reconcile")
+		stored := &issuev1.GithubIssue{}
+		Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(githubIssue), stored)).To(Succeed())
+		Expect(stored.Status.PendingComment.Body).To(HavePrefix("Duplicate of #2"))
+		closedIssue := &github.Issue{Number: github.Int(4), State: github.String("closed")}
+		Expect(status.PostPendingComment(ctx, r.GithubClient, stored, "owner", "repo", closedIssue)).To(Succeed())
+		Expect(commentTexts(comments)).To(Equal([]string{"Duplicate of #2"}))
+		Expect(stored.Status.PendingComment).To(BeNil())
+
+		By("not commenting again when clearing the pending comment failed")
+		stored.Status.PendingComment = &issuev1.PendingComment{Body: comments[0], State: "closed"}
+		Expect(status.PostPendingComment(ctx, r.GithubClient, stored, "owner", "repo", closedIssue)).To(Succeed())
+		Expect(comments).To(HaveLen(1))
+
+		By("leaving the closed duplicate alone afterwards")
+		_, _, err = r.reconcileDuplicate(ctx, "owner", "repo", stored, closedIssue)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(comments).To(HaveLen(1))
+	})
+
+	It("Should close the duplicate and reopen it once the mark is removed", func() {
+		githubIssue.Spec.DuplicateOf = 2
+		issue := &github.Issue{Number: github.Int(4), State: github.String("open")}
+
+		By("closing the issue marked as a duplicate")
+		issue, condition, err := r.reconcileDuplicate(ctx, "owner", "repo", githubIssue, issue)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(issue.GetState()).To(Equal("closed"))
+		Expect(commentTexts(comments)).To(Equal([]string{"Duplicate of #2"}))
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		githubIssue.Status.Conditions = []metav1.Condition{*condition}
+
+		By("leaving the closed duplicate alone")
+		issue, _, err = r.reconcileDuplicate(ctx, "owner", "repo", githubIssue, issue)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(comments).To(HaveLen(1))
+
+		By("reopening the issue once it is no longer a duplicate")
+		githubIssue.Spec.DuplicateOf = 0
+		issue, condition, err = r.reconcileDuplicate(ctx, "owner", "repo", githubIssue, issue)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(issue.GetState()).To(Equal("open"))
+		Expect(commentTexts(comments)).To(Equal([]string{"Duplicate of #2", NotDuplicateComment}))
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		githubIssue.Status.Conditions = []metav1.Condition{*condition}
+
+		By("clearing the condition afterwards")
+		_, condition, err = r.reconcileDuplicate(ctx, "owner", "repo", githubIssue, issue)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(condition).To(BeNil())
+		Expect(meta.IsStatusConditionTrue(githubIssue.Status.Conditions, status.ClosedAsDuplicateCondition)).To(BeFalse())
+	})
+
+	It("Should keep a former duplicate closed without allowReopen", func() {
+		githubIssue.Spec.AllowReopen = github.Bool(false)
+		githubIssue.Status.Conditions = []metav1.Condition{status.ClosedAsDuplicate(2)}
+		issue := &github.Issue{Number: github.Int(4), State: github.String("closed")}
+
+		issue, condition, err := r.reconcileDuplicate(ctx, "owner", "repo", githubIssue, issue)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(condition).To(BeNil())
+		Expect(issue.GetState()).To(Equal("closed"))
+		Expect(comments).To(BeEmpty())
+	})
+
+	It("Should ignore issues never marked as duplicates", func() {
+		issue := &github.Issue{Number: github.Int(4), State: github.String("closed")}
+		issue, condition, err := r.reconcileDuplicate(ctx, "owner", "repo", githubIssue, issue)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(condition).To(BeNil())
+		Expect(issue.GetState()).To(Equal("closed"))
+		Expect(comments).To(BeEmpty())
+	})
+})
+
+var _ = Describe("GithubIssue Controller client reuse", func() {
+	secretA := types.NamespacedName{Name: "a-token-secret", Namespace: "default"}
+	secretB := types.NamespacedName{Name: "b-token-secret", Namespace: "default"}
+	ghe := "https://ghe.example.com/api/v3/"
+
+	It("Should reuse the client of the same token", func() {
+		r := &GithubIssueReconciler{}
+		first := r.cachedGithubClient(secretA, "token-1", "", "")
+		Expect(r.cachedGithubClient(secretA, "token-1", "", "")).To(BeIdenticalTo(first))
+		Expect(r.cachedGithubClient(secretB, "token-1", "", "")).To(BeIdenticalTo(first))
+	})
+
+	It("Should not share a client across servers", func() {
+		r := &GithubIssueReconciler{}
+		first := r.cachedGithubClient(secretA, "token-1", "", "")
+		Expect(r.cachedGithubClient(secretB, "token-1", ghe, "3.9.0")).NotTo(BeIdenticalTo(first))
+	})
+
+	It("Should keep the clients of a token used with several servers", func() {
+		r := &GithubIssueReconciler{}
+		first := r.cachedGithubClient(secretA, "token-1", "", "")
+		onGHE := r.cachedGithubClient(secretA, "token-1", ghe, "3.9.0")
+		Expect(r.cachedGithubClient(secretA, "token-1", "", "")).To(BeIdenticalTo(first))
+		Expect(r.cachedGithubClient(secretA, "token-1", ghe, "3.9.0")).To(BeIdenticalTo(onGHE))
+		Expect(r.githubClients).To(HaveLen(2))
+	})
+
+	It("Should rebuild the client once the server version is known", func() {
+		r := &GithubIssueReconciler{}
+		first := r.cachedGithubClient(secretA, "token-1", ghe, "")
+		detected := r.cachedGithubClient(secretA, "token-1", ghe, "3.9.0")
+		Expect(detected).NotTo(BeIdenticalTo(first))
+		Expect(r.cachedGithubClient(secretA, "token-1", ghe, "3.9.0")).To(BeIdenticalTo(detected))
+		Expect(r.githubClients).To(HaveLen(1))
+	})
+
+	It("Should evict the client of a rotated token", func() {
+		r := &GithubIssueReconciler{}
+		first := r.cachedGithubClient(secretA, "token-1", "", "")
+		rotated := r.cachedGithubClient(secretA, "token-2", "", "")
+		Expect(rotated).NotTo(BeIdenticalTo(first))
+		Expect(r.githubClients).To(HaveLen(1))
+	})
+
+	It("Should keep the client of a rotated token another secret still uses", func() {
+		r := &GithubIssueReconciler{}
+		first := r.cachedGithubClient(secretA, "token-1", "", "")
+		r.cachedGithubClient(secretB, "token-1", "", "")
+		r.cachedGithubClient(secretA, "token-2", "", "")
+		Expect(r.githubClients).To(HaveLen(2))
+		Expect(r.cachedGithubClient(secretB, "token-1", "", "")).To(BeIdenticalTo(first))
+	})
+
+	It("Should drop the clients of secrets gone unused", func() {
+		now := time.Date(2024, 8, 1, 9, 0, 0, 0, time.UTC)
+		r := &GithubIssueReconciler{now: func() time.Time { return now }}
+		r.cachedGithubClient(secretA, "token-1", "", "")
+		r.cachedGithubClient(secretB, "token-2", "", "")
+
+		now = now.Add(githubClientIdle + time.Minute)
+		r.cachedGithubClient(secretB, "token-2", "", "")
+		Expect(r.githubClientKeys).To(HaveLen(1))
+		Expect(r.githubClients).To(HaveLen(1))
+	})
+})
+
+var _ = Describe("GithubIssue Controller token pools", func() {
+	secretA := types.NamespacedName{Name: "a-token-secret", Namespace: "default"}
+	secretB := types.NamespacedName{Name: "b-token-secret", Namespace: "default"}
+
+	It("Should keep the pool of a secret across reconciles", func() {
+		r := &GithubIssueReconciler{}
+		first := r.tokenPool(secretA, []string{"token-1", "token-2"})
+		Expect(r.tokenPool(secretA, []string{"token-1", "token-3"})).To(BeIdenticalTo(first))
+		Expect(r.tokenPools).To(HaveLen(1))
+	})
+
+	It("Should drop the pool of a secret no longer holding multiple tokens", func() {
+		r := &GithubIssueReconciler{}
+		first := r.tokenPool(secretA, []string{"token-1", "token-2"})
+		r.dropTokenPool(secretA)
+		Expect(r.tokenPools).To(BeEmpty())
+		Expect(r.tokenPool(secretA, []string{"token-1", "token-2"})).NotTo(BeIdenticalTo(first))
+	})
+
+	It("Should drop the pools of secrets gone unused", func() {
+		now := time.Date(2024, 8, 1, 9, 0, 0, 0, time.UTC)
+		r := &GithubIssueReconciler{now: func() time.Time { return now }}
+		r.tokenPool(secretA, []string{"token-1", "token-2"})
+		r.tokenPool(secretB, []string{"token-3", "token-4"})
+
+		now = now.Add(githubClientIdle + time.Minute)
+		r.tokenPool(secretB, []string{"token-3", "token-4"})
+		Expect(r.tokenPools).To(HaveLen(1))
+		Expect(r.tokenPools).To(HaveKey(secretB))
+	})
+})
+
+var _ = Describe("GithubIssue Controller external edits", func() {
+	// newIssues returns a GithubIssue whose operator last wrote the written body, and the
+	// GitHub issue currently holding the live body
+	newIssues := func(written, live string, policy issuev1.ExternalEditPolicy) (*issuev1.GithubIssue, *github.Issue) {
+		githubIssue := &issuev1.GithubIssue{
+			Spec:   issuev1.GithubIssueSpec{ExternalEditPolicy: policy},
+			Status: issuev1.GithubIssueStatus{BodyHash: utils.ContentHash(written)},
+		}
+		return githubIssue, &github.Issue{Body: github.String(live)}
+	}
+
+	It("Should not detect an edit before the operator recorded a body", func() {
+		githubIssue, issue := newIssues("", "adopted body", issuev1.ExternalEditPolicyPreserve)
+		githubIssue.Status.BodyHash = ""
+
+		body, edited := managedBody(githubIssue, issue, "desired body")
+		Expect(edited).To(BeFalse())
+		Expect(body).To(Equal("desired body"))
+	})
+
+	It("Should update a body only the description changed", func() {
+		githubIssue, issue := newIssues("old body", "old body", issuev1.ExternalEditPolicyPreserve)
+
+		body, edited := managedBody(githubIssue, issue, "new body")
+		Expect(edited).To(BeFalse())
+		Expect(body).To(Equal("new body"))
+	})
+
+	It("Should not detect an edit matching the description", func() {
+		githubIssue, issue := newIssues("old body", "new body", issuev1.ExternalEditPolicyPreserve)
+
+		_, edited := managedBody(githubIssue, issue, "new body")
+		Expect(edited).To(BeFalse())
+	})
+
+	It("Should overwrite an external edit by default", func() {
+		githubIssue, issue := newIssues("managed body", "edited on GitHub", "")
+
+		body, edited := managedBody(githubIssue, issue, "managed body")
+		Expect(edited).To(BeTrue())
+		Expect(body).To(Equal("managed body"))
+	})
+
+	It("Should overwrite an external edit with the Overwrite policy", func() {
+		githubIssue, issue := newIssues("managed body", "edited on GitHub", issuev1.ExternalEditPolicyOverwrite)
+
+		body, edited := managedBody(githubIssue, issue, "managed body")
+		Expect(edited).To(BeTrue())
+		Expect(body).To(Equal("managed body"))
+	})
+
+	It("Should keep an external edit with the Preserve policy", func() {
+		githubIssue, issue := newIssues("managed body", "edited on GitHub", issuev1.ExternalEditPolicyPreserve)
+
+		body, edited := managedBody(githubIssue, issue, "new body")
+		Expect(edited).To(BeTrue())
+		Expect(body).To(Equal("edited on GitHub"))
+	})
+})
+
+var _ = Describe("GithubIssue Controller create window", func() {
+	ctx := context.Background()
+	key := types.NamespacedName{Name: "window-resource", Namespace: "default"}
+
+	var (
+		r           *GithubIssueReconciler
+		githubIssue *issuev1.GithubIssue
+	)
+
+	// at returns a reconciler whose clock reads the given time
+	at := func(now time.Time) *GithubIssueReconciler {
+		r.now = func() time.Time { return now }
+		return r
+	}
+
+	BeforeEach(func() {
+		s := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(s)).To(Succeed())
+		Expect(issuev1.AddToScheme(s)).To(Succeed())
+
+		githubIssue = &issuev1.GithubIssue{
+			ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+			Spec: issuev1.GithubIssueSpec{
+				Repo:         "https://github.com/owner/repo",
+				Title:        "Test Issue",
+				CreateWindow: &issuev1.CreateWindow{Start: "09:00", End: "17:00", TimeZone: "Europe/Berlin"},
+			},
+		}
+		c := fake.NewClientBuilder().WithScheme(s).
+			WithObjects(githubIssue).
+			WithStatusSubresource(githubIssue).
+			Build()
+		r = &GithubIssueReconciler{Client: c, Scheme: s, Log: logr.Discard()}
+	})
+
+	It("Should create the issue inside the window", func() {
+		// 10:00 UTC is noon in Berlin
+		opensIn, err := at(time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)).deferCreation(ctx, githubIssue)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(opensIn).To(BeZero())
+
+		stored := &issuev1.GithubIssue{}
+		Expect(r.Client.Get(ctx, key, stored)).To(Succeed())
+		Expect(meta.FindStatusCondition(stored.Status.Conditions, "DeferredCreation")).To(BeNil())
+	})
+
+	It("Should defer the issue until the window opens", func() {
+		// 04:00 UTC is 06:00 in Berlin
+		opensIn, err := at(time.Date(2024, 5, 1, 4, 0, 0, 0, time.UTC)).deferCreation(ctx, githubIssue)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(opensIn).To(Equal(3 * time.Hour))
+
+		stored := &issuev1.GithubIssue{}
+		Expect(r.Client.Get(ctx, key, stored)).To(Succeed())
+		Expect(meta.IsStatusConditionTrue(stored.Status.Conditions, "DeferredCreation")).To(BeTrue())
+	})
+
+	It("Should create the issue at any time without a window", func() {
+		githubIssue.Spec.CreateWindow = nil
+		opensIn, err := at(time.Date(2024, 5, 1, 4, 0, 0, 0, time.UTC)).deferCreation(ctx, githubIssue)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(opensIn).To(BeZero())
+	})
+})
+
+var _ = Describe("GithubIssue Controller secondary rate limit", func() {
+	ctx := context.Background()
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "abuse-resource", Namespace: "default"}}
+	other := ctrl.Request{NamespacedName: types.NamespacedName{Name: "other-resource", Namespace: "default"}}
+
+	var (
+		r   *GithubIssueReconciler
+		now time.Time
+	)
+
+	BeforeEach(func() {
+		s := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(s)).To(Succeed())
+		Expect(issuev1.AddToScheme(s)).To(Succeed())
+
+		githubIssue := &issuev1.GithubIssue{
+			ObjectMeta: metav1.ObjectMeta{Name: req.Name, Namespace: req.Namespace},
+			Spec:       issuev1.GithubIssueSpec{Repo: "https://github.com/owner/repo", Title: "Test Issue"},
+		}
+		c := fake.NewClientBuilder().WithScheme(s).
+			WithObjects(githubIssue).
+			WithStatusSubresource(githubIssue).
+			Build()
+
+		now = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+		r = &GithubIssueReconciler{Client: c, Scheme: s, Log: logr.Discard(), now: func() time.Time { return now }}
+	})
+
+	// abuseError returns the error of a request GitHub's abuse detection refused
+	abuseError := func(retryAfter *time.Duration) error {
+		return fmt.Errorf("failed to update issue: %w", &github.AbuseRateLimitError{
+			Message:    "You have exceeded a secondary rate limit.",
+			RetryAfter: retryAfter,
+		})
+	}
+
+	It("Should requeue after the RetryAfter GitHub provided", func() {
+		retryAfter := 90 * time.Second
+		var abuseErr *github.AbuseRateLimitError
+		Expect(errors.As(abuseError(&retryAfter), &abuseErr)).To(BeTrue())
+		result, err := r.handleSecondaryRateLimit(ctx, req, abuseErr)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(retryAfter))
+
+		stored := &issuev1.GithubIssue{}
+		Expect(r.Client.Get(ctx, req.NamespacedName, stored)).To(Succeed())
+		Expect(meta.IsStatusConditionTrue(stored.Status.Conditions, "SecondaryRateLimited")).To(BeTrue())
+	})
+
+	It("Should fall back to the default cooldown without a RetryAfter", func() {
+		var abuseErr *github.AbuseRateLimitError
+		Expect(errors.As(abuseError(nil), &abuseErr)).To(BeTrue())
+		result, err := r.handleSecondaryRateLimit(ctx, req, abuseErr)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(defaultSecondaryRateLimitCooldown))
+	})
+
+	It("Should hold other reconciles back until the cooldown is over", func() {
+		retryAfter := time.Minute
+		var abuseErr *github.AbuseRateLimitError
+		Expect(errors.As(abuseError(&retryAfter), &abuseErr)).To(BeTrue())
+		_, err := r.handleSecondaryRateLimit(ctx, req, abuseErr)
+		Expect(err).NotTo(HaveOccurred())
+
+		now = now.Add(20 * time.Second)
+		result, err := r.Reconcile(ctx, other)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(40 * time.Second))
+
+		By("reconciling again once the cooldown is over")
+		now = now.Add(time.Minute)
+		result, err = r.Reconcile(ctx, other)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(BeZero())
+	})
+})
+
+var _ = Describe("GithubIssue Controller deleted repository", func() {
+	ctx := context.Background()
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "deleted-repo-resource", Namespace: "default"}}
+
+	var (
+		repoExists bool
+		r          *issueReconcile
+	)
+
+	BeforeEach(func() {
+		repoExists = false
+		mux := http.NewServeMux()
+		mux.HandleFunc("/repos/owner/repo", func(w http.ResponseWriter, req *http.Request) {
+			if !repoExists {
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, `{"message": "Not Found"}`)
+				return
+			}
+			fmt.Fprint(w, `{"name": "repo"}`)
+		})
+		mux.HandleFunc("/repos/owner/repo/issues", func(w http.ResponseWriter, req *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message": "Not Found"}`)
+		})
+		githubClient := newGithubServer(mux)
+
+		s := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(s)).To(Succeed())
+		Expect(issuev1.AddToScheme(s)).To(Succeed())
+		githubIssue := &issuev1.GithubIssue{
+			ObjectMeta: metav1.ObjectMeta{Name: req.Name, Namespace: req.Namespace, Generation: 1},
+			Spec:       issuev1.GithubIssueSpec{Repo: "https://github.com/owner/repo", Title: "Test Issue"},
+		}
+		c := fake.NewClientBuilder().WithScheme(s).
+			WithObjects(githubIssue).
+			WithStatusSubresource(githubIssue).
+			Build()
+		r = &issueReconcile{
+			GithubIssueReconciler: &GithubIssueReconciler{
+				Client: c,
+				Scheme: s,
+				Log:    logr.Discard(),
+			},
+			GithubClient: githubClient,
+		}
+	})
+
+	// notFound returns the error of an issue request GitHub answered with 404
+	notFound := func() error {
+		_, err := r.GithubClient.CheckIssueExists("owner", "repo", "Test Issue", 0, "")
+		Expect(resources.IsNotFound(err)).To(BeTrue())
+		return err
+	}
+
+	It("Should stop retrying a deleted repository until the spec changes", func() {
+		result, err := r.handleNotFound(ctx, req, notFound())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Requeue).To(BeFalse())
+		Expect(result.RequeueAfter).To(BeZero())
+
+		githubIssue := &issuev1.GithubIssue{}
+		Expect(r.Client.Get(ctx, req.NamespacedName, githubIssue)).To(Succeed())
+		Expect(status.RepoNotFound(githubIssue)).To(BeTrue())
+
+		By("skipping the reconcile before anything else is done")
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		secret := &corev1.Secret{}
+		err = r.Client.Get(ctx, types.NamespacedName{Name: req.Name + resources.TokenSecretSuffix, Namespace: req.Namespace}, secret)
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+
+		By("retrying once the spec changes")
+		githubIssue.Generation++
+		Expect(status.RepoNotFound(githubIssue)).To(BeFalse())
+	})
+
+	It("Should retry a 404 from an existing repository", func() {
+		repoExists = true
+		notFoundErr := notFound()
+
+		_, err := r.handleNotFound(ctx, req, notFoundErr)
+		Expect(err).To(MatchError(notFoundErr))
+
+		githubIssue := &issuev1.GithubIssue{}
+		Expect(r.Client.Get(ctx, req.NamespacedName, githubIssue)).To(Succeed())
+		Expect(status.RepoNotFound(githubIssue)).To(BeFalse())
+	})
+})
+
+var _ = Describe("GithubIssue Controller idempotent creation", func() {
+	ctx := context.Background()
+
+	var (
+		mu      sync.Mutex
+		created []*github.Issue
+		r       *issueReconcile
+	)
+
+	BeforeEach(func() {
+		created = nil
+		mux := http.NewServeMux()
+		mux.HandleFunc("/repos/owner/repo/issues", func(w http.ResponseWriter, req *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+			if req.Method == http.MethodPost {
+				var request github.IssueRequest
+				Expect(json.NewDecoder(req.Body).Decode(&request)).To(Succeed())
+				issue := &github.Issue{
+					Number: github.Int(len(created) + 1),
+					Title:  request.Title,
+					Body:   request.Body,
+					State:  github.String("open"),
+				}
+				created = append(created, issue)
+				Expect(json.NewEncoder(w).Encode(issue)).To(Succeed())
+				return
+			}
+			Expect(req.URL.Query().Get("state")).To(Equal("all"))
+			Expect(json.NewEncoder(w).Encode(created)).To(Succeed())
+		})
+		githubClient := newGithubServer(mux)
+		r = &issueReconcile{
+			GithubIssueReconciler: &GithubIssueReconciler{
+				Log: logr.Discard(),
+			},
+			GithubClient: githubClient,
+		}
+	})
+
+	// newGithubIssue returns a GithubIssue with the UID and the body carrying its dedupe marker
+	newGithubIssue := func(uid string) (*issuev1.GithubIssue, string) {
+		githubIssue := &issuev1.GithubIssue{ObjectMeta: metav1.ObjectMeta{UID: types.UID("uid-" + uid)}}
+		return githubIssue, utils.AddDedupeMarker("body", string(githubIssue.UID))
+	}
+
+	It("Should find the issue created before a failed status update instead of creating it again", func() {
+		githubIssue, body := newGithubIssue("1")
+
+		By("creating the issue, then crashing before the status records it")
+		issue, err := r.createIssue(ctx, r.Log, githubIssue, "owner", "repo", "Test Issue", body, resources.IssueFields{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(issue.GetNumber()).To(Equal(1))
+		Expect(githubIssue.Status.IssueNumber).To(BeZero())
+
+		By("retrying the creation")
+		issue, err = r.createIssue(ctx, r.Log, githubIssue, "owner", "repo", "Test Issue", body, resources.IssueFields{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(issue.GetNumber()).To(Equal(1))
+		Expect(created).To(HaveLen(1))
+	})
+
+	It("Should create the issues of other GithubIssues", func() {
+		first, firstBody := newGithubIssue("1")
+		second, secondBody := newGithubIssue("2")
+
+		_, err := r.createIssue(ctx, r.Log, first, "owner", "repo", "Test Issue", firstBody, resources.IssueFields{})
+		Expect(err).NotTo(HaveOccurred())
+		issue, err := r.createIssue(ctx, r.Log, second, "owner", "repo", "Other Issue", secondBody, resources.IssueFields{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(issue.GetNumber()).To(Equal(2))
+		Expect(created).To(HaveLen(2))
+	})
+
+	It("Should create a single issue for GithubIssues racing to create the same title", func() {
+		var wg sync.WaitGroup
+		numbers := make([]int, 5)
+		for i := range numbers {
+			wg.Add(1)
+			go func(i int) {
+				defer GinkgoRecover()
+				defer wg.Done()
+				githubIssue, body := newGithubIssue(strconv.Itoa(i))
+				issue, err := r.createIssue(ctx, r.Log, githubIssue, "owner", "repo", "Test Issue", body, resources.IssueFields{})
+				Expect(err).NotTo(HaveOccurred())
+				numbers[i] = issue.GetNumber()
+			}(i)
+		}
+		wg.Wait()
+
+		Expect(created).To(HaveLen(1))
+		Expect(numbers).To(HaveEach(1))
+	})
+
+	It("Should create the issue again once the dedupe window passed", func() {
+		now := time.Now()
+		r.now = func() time.Time { return now }
+		first, firstBody := newGithubIssue("1")
+		second, secondBody := newGithubIssue("2")
+
+		_, err := r.createIssue(ctx, r.Log, first, "owner", "repo", "Test Issue", firstBody, resources.IssueFields{})
+		Expect(err).NotTo(HaveOccurred())
+		now = now.Add(31 * time.Second)
+		issue, err := r.createIssue(ctx, r.Log, second, "owner", "repo", "Test Issue", secondBody, resources.IssueFields{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(issue.GetNumber()).To(Equal(2))
+	})
+})
+
+var _ = Describe("GithubIssue Controller superseded issues", func() {
+	ctx := context.Background()
+
+	var (
+		state       string
+		comments    []string
+		githubIssue *issuev1.GithubIssue
+		r           *issueReconcile
+	)
+
+	BeforeEach(func() {
+		state = "open"
+		comments = nil
+
+		mux := http.NewServeMux()
+		mux.HandleFunc("/repos/owner/repo/issues/4", func(w http.ResponseWriter, req *http.Request) {
+			var request map[string]string
+			Expect(json.NewDecoder(req.Body).Decode(&request)).To(Succeed())
+			state = request["state"]
+			fmt.Fprintf(w, `{"number": 4, "state": %q}`, state)
+		})
+		mux.HandleFunc("/repos/owner/repo/issues/4/comments", commentsHandler(&comments))
+		githubClient := newGithubServer(mux)
+		githubIssue = &issuev1.GithubIssue{ObjectMeta: metav1.ObjectMeta{Name: "superseded", Namespace: "default"}}
+		r = &issueReconcile{
+			GithubIssueReconciler: &GithubIssueReconciler{
+				Client: newFakeClient(githubIssue),
+			},
+			GithubClient: githubClient,
+		}
+	})
+
+	It("Should close the issue linking its successor in the same repository", func() {
+		githubIssue.Spec.SupersededBy = &issuev1.IssueReference{Number: 9}
+		issue := &github.Issue{Number: github.Int(4), State: github.String("open")}
+
+		issue, condition, err := r.reconcileSuperseded(ctx, "owner", "repo", githubIssue, issue)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(issue.GetState()).To(Equal("closed"))
+		Expect(commentTexts(comments)).To(Equal([]string{"Superseded by #9"}))
+		Expect(condition.Type).To(Equal("Superseded"))
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(condition.Message).To(ContainSubstring("#9"))
+
+		By("leaving the closed issue alone")
+		_, _, err = r.reconcileSuperseded(ctx, "owner", "repo", githubIssue, issue)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(comments).To(HaveLen(1))
+	})
+
+	It("Should link a successor in another repository", func() {
+		githubIssue.Spec.SupersededBy = &issuev1.IssueReference{Repo: "https://github.com/new-owner/new-repo", Number: 2}
+		issue := &github.Issue{Number: github.Int(4), State: github.String("open")}
+
+		_, condition, err := r.reconcileSuperseded(ctx, "owner", "repo", githubIssue, issue)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(commentTexts(comments)).To(Equal([]string{"Superseded by new-owner/new-repo#2"}))
+		Expect(condition.Message).To(ContainSubstring("new-owner/new-repo#2"))
+	})
+
+	It("Should ignore issues that aren't superseded", func() {
+		issue := &github.Issue{Number: github.Int(4), State: github.String("open")}
+		issue, condition, err := r.reconcileSuperseded(ctx, "owner", "repo", githubIssue, issue)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(condition).To(BeNil())
+		Expect(issue.GetState()).To(Equal("open"))
+		Expect(comments).To(BeEmpty())
+	})
+})
+
+var _ = Describe("GithubIssue Controller GitHub maintenance", func() {
+	ctx := context.Background()
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "maintenance-resource", Namespace: "default"}}
+
+	var (
+		r *issueReconcile
+	)
+
+	BeforeEach(func() {
+		mux := http.NewServeMux()
+		mux.HandleFunc("/repos/owner/repo/issues/1", func(w http.ResponseWriter, req *http.Request) {
+			w.Header().Set("Retry-After", "120")
+			w.WriteHeader(http.StatusServiceUnavailable)
+		})
+		githubClient := newGithubServer(mux)
+
+		s := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(s)).To(Succeed())
+		Expect(issuev1.AddToScheme(s)).To(Succeed())
+		githubIssue := &issuev1.GithubIssue{
+			ObjectMeta: metav1.ObjectMeta{Name: req.Name, Namespace: req.Namespace},
+			Spec:       issuev1.GithubIssueSpec{Repo: "https://github.com/owner/repo", Title: "Test Issue"},
+		}
+		c := fake.NewClientBuilder().WithScheme(s).
+			WithObjects(githubIssue).
+			WithStatusSubresource(githubIssue).
+			Build()
+		r = &issueReconcile{
+			GithubIssueReconciler: &GithubIssueReconciler{
+				Client: c,
+				Scheme: s,
+				Log:    logr.Discard(),
+			},
+			GithubClient: githubClient,
+		}
+	})
+
+	It("Should requeue after the Retry-After of a 503", func() {
+		_, err := r.GithubClient.GetIssue(ctx, "owner", "repo", 1)
+		Expect(err).To(HaveOccurred())
+
+		retryAfter, ok := resources.ServiceUnavailable(err, time.Now())
+		Expect(ok).To(BeTrue())
+		result, err := r.handleUnavailable(ctx, req, retryAfter)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(2 * time.Minute))
+
+		stored := &issuev1.GithubIssue{}
+		Expect(r.Client.Get(ctx, req.NamespacedName, stored)).To(Succeed())
+		Expect(meta.IsStatusConditionTrue(stored.Status.Conditions, "GitHubUnavailable")).To(BeTrue())
+	})
+})
+
+var _ = Describe("GithubIssue Controller severity", func() {
+	var (
+		mux *http.ServeMux
+		r   *issueReconcile
+	)
+
+	BeforeEach(func() {
+		mux = http.NewServeMux()
+		githubClient := newGithubServer(mux)
+
+		r = &issueReconcile{
+			GithubIssueReconciler: &GithubIssueReconciler{
+				SeverityLabels: map[string]string{"sev1": "sev1", "sev2": "sev2"},
+				SeverityEmojis: map[string]string{"sev1": "🔥"},
+			},
+			GithubClient: githubClient,
+		}
+	})
+
+	newIssue := func(severity issuev1.Severity) *issuev1.GithubIssue {
+		return &issuev1.GithubIssue{
+			ObjectMeta: metav1.ObjectMeta{Name: "severity-resource", Namespace: "default"},
+			Spec:       issuev1.GithubIssueSpec{Title: "Disk full", Severity: severity},
+		}
+	}
+
+	It("Should prepend the emoji of the severity to the title", func() {
+		Expect(r.issueTitle(newIssue(issuev1.SeveritySev1))).To(Equal("🔥 Disk full"))
+	})
+
+	It("Should leave the title alone for a severity without an emoji", func() {
+		Expect(r.issueTitle(newIssue(issuev1.SeveritySev2))).To(Equal("Disk full"))
+	})
+
+	It("Should swap the label of the previous severity for the current one", func() {
+		var added, removed []string
+		recordLabels(mux, 4, &added, &removed)
+
+		issue := &github.Issue{Number: github.Int(4), Labels: []*github.Label{{Name: github.String("sev2")}}}
+		Expect(r.reconcileSeverityLabel("owner", "repo", issue, issuev1.SeveritySev1)).To(Succeed())
+		Expect(added).To(Equal([]string{"sev1"}))
+		Expect(removed).To(Equal([]string{"sev2"}))
+	})
+
+	It("Should remove the severity label once the severity is cleared", func() {
+		var added, removed []string
+		recordLabels(mux, 4, &added, &removed)
+
+		issue := &github.Issue{Number: github.Int(4), Labels: []*github.Label{{Name: github.String("sev2")}}}
+		Expect(r.reconcileSeverityLabel("owner", "repo", issue, "")).To(Succeed())
+		Expect(added).To(BeEmpty())
+		Expect(removed).To(Equal([]string{"sev2"}))
+	})
+
+	It("Should fail for a severity without a label", func() {
+		issue := &github.Issue{Number: github.Int(4)}
+		Expect(r.reconcileSeverityLabel("owner", "repo", issue, issuev1.SeveritySev3)).To(MatchError(ContainSubstring("sev3")))
+	})
+})
+
+var _ = Describe("GithubIssue Controller recreated issues", func() {
+	ctx := context.Background()
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "recreated-resource", Namespace: "default"}}
+	secretName := types.NamespacedName{Name: "recreated-resource-token-secret", Namespace: "default"}
+
+	newIssue := func(uid types.UID) *issuev1.GithubIssue {
+		return &issuev1.GithubIssue{
+			ObjectMeta: metav1.ObjectMeta{Name: req.Name, Namespace: req.Namespace, UID: uid},
+			Spec: issuev1.GithubIssueSpec{
+				Repo:  "https://github.com/owner/repo",
+				Title: "Test Issue",
+			},
+		}
+	}
+
+	It("Should re-own the token secret of the deleted GithubIssue", func() {
+		s := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(s)).To(Succeed())
+		Expect(issuev1.AddToScheme(s)).To(Succeed())
+		original := newIssue("original-uid")
+		c := fake.NewClientBuilder().WithScheme(s).
+			WithObjects(original).
+			WithStatusSubresource(original).
+			Build()
+		r := &GithubIssueReconciler{Client: c, Scheme: s, Log: logr.Discard()}
+
+		// the first reconcile creates the secret owned by the original GithubIssue
+		result, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Requeue).To(BeTrue())
+
+		// the GithubIssue is recreated before the secret is garbage collected
+		Expect(c.Delete(ctx, original)).To(Succeed())
+		Expect(c.Create(ctx, newIssue("recreated-uid"))).To(Succeed())
+
+		result, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(time.Minute))
+
+		secret := &corev1.Secret{}
+		Expect(c.Get(ctx, secretName, secret)).To(Succeed())
+		Expect(secret.OwnerReferences).To(HaveLen(1))
+		Expect(secret.OwnerReferences[0].UID).To(Equal(types.UID("recreated-uid")))
+		Expect(*secret.OwnerReferences[0].Controller).To(BeTrue())
+	})
+})
+
+var _ = Describe("GithubIssue Controller notifications", func() {
+	ctx := context.Background()
+
+	var (
+		server     *httptest.Server
+		statusCode int
+		received   []notify.Payload
+		r          *GithubIssueReconciler
+	)
+
+	BeforeEach(func() {
+		statusCode = http.StatusOK
+		received = nil
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			var payload notify.Payload
+			Expect(json.NewDecoder(req.Body).Decode(&payload)).To(Succeed())
+			received = append(received, payload)
+			w.WriteHeader(statusCode)
+		}))
+		s := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(s)).To(Succeed())
+		Expect(issuev1.AddToScheme(s)).To(Succeed())
+		r = &GithubIssueReconciler{
+			Client:   fake.NewClientBuilder().WithScheme(s).WithStatusSubresource(&issuev1.GithubIssue{}).Build(),
+			Notifier: &notify.Notifier{Attempts: 2, Backoff: time.Millisecond, AllowedHosts: []string{"127.0.0.1"}},
+		}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	newIssues := func(state string) (*issuev1.GithubIssue, *github.Issue) {
+		githubIssue := &issuev1.GithubIssue{
+			ObjectMeta: metav1.ObjectMeta{Name: "disk-full", Namespace: "default", UID: "uid-1"},
+			Spec:       issuev1.GithubIssueSpec{Repo: "https://github.com/owner/repo", Title: "Disk full", NotifyURL: server.URL},
+		}
+		Expect(r.Client.Create(ctx, githubIssue)).To(Succeed())
+		issue := &github.Issue{
+			Number:  github.Int(4),
+			State:   github.String(state),
+			Title:   github.String("Disk full"),
+			HTMLURL: github.String("https://github.com/owner/repo/issues/4"),
+		}
+		return githubIssue, issue
+	}
+
+	// stored returns the GithubIssue as written
+	stored := func(githubIssue *issuev1.GithubIssue) *issuev1.GithubIssue {
+		written := &issuev1.GithubIssue{}
+		Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(githubIssue), written)).To(Succeed())
+		return written
+	}
+
+	It("Should notify of a created issue", func() {
+		githubIssue, issue := newIssues("open")
+
+		condition := r.notifyState(ctx, logr.Discard(), githubIssue, "owner", "repo", issue)
+		Expect(received).To(Equal([]notify.Payload{{
+			Event:      notify.EventCreated,
+			Repo:       "owner/repo",
+			Number:     4,
+			URL:        "https://github.com/owner/repo/issues/4",
+			Title:      "Disk full",
+			DeliveryID: "uid-1/4/created",
+		}}))
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(githubIssue.Status.NotifiedState).To(Equal("open"))
+	})
+
+	It("Should record the delivery so that a failed status update doesn't send it again", func() {
+		githubIssue, issue := newIssues("open")
+
+		r.notifyState(ctx, logr.Discard(), githubIssue, "owner", "repo", issue)
+		Expect(received).To(HaveLen(1))
+
+		// the next reconcile starts over from the GithubIssue as written, the status update
+		// that would have followed the delivery lost
+		again := stored(githubIssue)
+		Expect(again.Status.NotifiedState).To(Equal("open"))
+		r.notifyState(ctx, logr.Discard(), again, "owner", "repo", issue)
+		Expect(received).To(HaveLen(1))
+	})
+
+	It("Should not notify a host that isn't allowed", func() {
+		r.Notifier.AllowedHosts = []string{"hooks.example.com"}
+		githubIssue, issue := newIssues("open")
+
+		condition := r.notifyState(ctx, logr.Discard(), githubIssue, "owner", "repo", issue)
+		Expect(received).To(BeEmpty())
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Message).To(ContainSubstring("not allowed"))
+		Expect(stored(githubIssue).Status.NotifiedState).To(BeEmpty())
+	})
+
+	It("Should notify of a closed issue once", func() {
+		githubIssue, issue := newIssues("closed")
+		githubIssue.Status.NotifiedState = "open"
+
+		r.notifyState(ctx, logr.Discard(), githubIssue, "owner", "repo", issue)
+		githubIssue.Status.Conditions = []metav1.Condition{status.NotificationDelivered(notify.EventClosed, nil)}
+		condition := r.notifyState(ctx, logr.Discard(), githubIssue, "owner", "repo", issue)
+		Expect(received).To(HaveLen(1))
+		Expect(received[0].Event).To(Equal(notify.EventClosed))
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+	})
+
+	It("Should keep the reconcile going when the delivery fails", func() {
+		statusCode = http.StatusInternalServerError
+		githubIssue, issue := newIssues("open")
+
+		condition := r.notifyState(ctx, logr.Discard(), githubIssue, "owner", "repo", issue)
+		Expect(received).To(HaveLen(2))
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Reason).To(Equal("DeliveryFailed"))
+		Expect(githubIssue.Status.NotifiedState).To(BeEmpty())
+	})
+
+	It("Should not notify without a notify URL", func() {
+		githubIssue, issue := newIssues("open")
+		githubIssue.Spec.NotifyURL = ""
+
+		Expect(r.notifyState(ctx, logr.Discard(), githubIssue, "owner", "repo", issue)).To(BeNil())
+		Expect(received).To(BeEmpty())
+	})
+})
+
+var _ = Describe("GithubIssue Controller footer", func() {
+	var (
+		edits []string
+		r     *issueReconcile
+	)
+
+	BeforeEach(func() {
+		edits = nil
+		mux := http.NewServeMux()
+		mux.HandleFunc("/repos/owner/repo/issues", func(w http.ResponseWriter, req *http.Request) {
+			var request github.IssueRequest
+			Expect(json.NewDecoder(req.Body).Decode(&request)).To(Succeed())
+			Expect(json.NewEncoder(w).Encode(&github.Issue{
+				Number:  github.Int(7),
+				Title:   request.Title,
+				Body:    request.Body,
+				State:   github.String("open"),
+				HTMLURL: github.String("https://github.com/owner/repo/issues/7"),
+			})).To(Succeed())
+		})
+		mux.HandleFunc("/repos/owner/repo/issues/7", func(w http.ResponseWriter, req *http.Request) {
+			var request github.IssueRequest
+			Expect(json.NewDecoder(req.Body).Decode(&request)).To(Succeed())
+			edits = append(edits, request.GetBody())
+			Expect(json.NewEncoder(w).Encode(&github.Issue{
+				Number:  github.Int(7),
+				Title:   request.Title,
+				Body:    request.Body,
+				State:   github.String("open"),
+				HTMLURL: github.String("https://github.com/owner/repo/issues/7"),
+			})).To(Succeed())
+		})
+		githubClient := newGithubServer(mux)
+		r = &issueReconcile{GithubIssueReconciler: &GithubIssueReconciler{}, GithubClient: githubClient}
+	})
+
+	It("Should edit the real issue number into the footer once", func() {
+		githubIssue := &issuev1.GithubIssue{Spec: issuev1.GithubIssueSpec{
+			Footer: "Tracked as #{{ .IssueNumber }}: {{ .IssueURL }}",
+		}}
+		issue, err := r.GithubClient.CreateIssue("owner", "repo", "Disk full", "body", resources.IssueFields{})
+		Expect(err).NotTo(HaveOccurred())
+
+		issue, err = r.fillFooter(githubIssue, "owner", "repo", issue, "body", "Disk full", resources.IssueFields{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(edits).To(HaveLen(1))
+		Expect(issue.GetBody()).To(ContainSubstring("Tracked as #7: https://github.com/owner/repo/issues/7"))
+
+		By("rendering the footer of the next reconciles")
+		body, err := withFooter(githubIssue, "body", issue)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = r.GithubClient.UpdateIssue("owner", "repo", issue, body, "Disk full", resources.IssueFields{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(edits).To(HaveLen(1))
+	})
+})
+
+var _ = Describe("GithubIssue Controller pull request tracking", func() {
+	var (
+		added   []string
+		removed []string
+		r       *issueReconcile
+	)
+
+	BeforeEach(func() {
+		added, removed = nil, nil
+		mux := http.NewServeMux()
+		recordLabels(mux, 4, &added, &removed)
+		githubClient := newGithubServer(mux)
+		r = &issueReconcile{GithubIssueReconciler: &GithubIssueReconciler{}, GithubClient: githubClient}
+	})
+
+	githubIssue := &issuev1.GithubIssue{Spec: issuev1.GithubIssueSpec{TrackPR: true}}
+	linked := &github.Issue{
+		Number:           github.Int(4),
+		PullRequestLinks: &github.PullRequestLinks{HTMLURL: github.String("https://github.com/owner/repo/pull/9")},
+	}
+	unlinked := &github.Issue{
+		Number: github.Int(4),
+		Labels: []*github.Label{{Name: github.String(resources.HasPRLabel)}},
+	}
+
+	It("Should add the line and the label once a pull request is linked", func() {
+		body := withTrackedByPR(githubIssue, "body", linked)
+		Expect(body).To(ContainSubstring("Tracked by PR #9"))
+
+		Expect(r.reconcileToggledLabel("owner", "repo", linked, resources.HasPRLabel, true)).To(Succeed())
+		Expect(added).To(Equal([]string{resources.HasPRLabel}))
+	})
+
+	It("Should remove the line and the label once the link is gone", func() {
+		body := withTrackedByPR(githubIssue, withTrackedByPR(githubIssue, "body", linked), unlinked)
+		Expect(body).To(Equal("body"))
+
+		Expect(r.reconcileToggledLabel("owner", "repo", unlinked, resources.HasPRLabel, false)).To(Succeed())
+		Expect(removed).To(Equal([]string{resources.HasPRLabel}))
+	})
+
+	It("Should leave the body alone without tracking", func() {
+		Expect(withTrackedByPR(&issuev1.GithubIssue{}, "body", linked)).To(Equal("body"))
+	})
+})
+
+var _ = Describe("GithubIssue Controller issues converted to discussions", func() {
+	ctx := context.Background()
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "discussion-resource", Namespace: "default"}}
+
+	var (
+		r *issueReconcile
+	)
+
+	BeforeEach(func() {
+		mux := http.NewServeMux()
+		mux.HandleFunc("/repos/owner/repo/issues/4", func(w http.ResponseWriter, req *http.Request) {
+			w.WriteHeader(http.StatusGone)
+		})
+		mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+			fmt.Fprint(w, `{"data": {"repository": {"discussion": {"url": "https://github.com/owner/repo/discussions/4"}}}}`)
+		})
+		githubClient := newGithubServer(mux)
+
+		s := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(s)).To(Succeed())
+		Expect(issuev1.AddToScheme(s)).To(Succeed())
+		githubIssue := &issuev1.GithubIssue{
+			ObjectMeta: metav1.ObjectMeta{Name: req.Name, Namespace: req.Namespace, Generation: 1},
+			Spec:       issuev1.GithubIssueSpec{Repo: "https://github.com/owner/repo", Title: "Test Issue"},
+			Status:     issuev1.GithubIssueStatus{IssueNumber: 4},
+		}
+		c := fake.NewClientBuilder().WithScheme(s).
+			WithObjects(githubIssue).
+			WithStatusSubresource(githubIssue).
+			Build()
+		r = &issueReconcile{
+			GithubIssueReconciler: &GithubIssueReconciler{
+				Client: c,
+				Scheme: s,
+				Log:    logr.Discard(),
+			},
+			GithubClient: githubClient,
+		}
+	})
+
+	It("Should report the discussion and stop reconciling the issue", func() {
+		githubIssue := &issuev1.GithubIssue{}
+		Expect(r.Client.Get(ctx, req.NamespacedName, githubIssue)).To(Succeed())
+		_, err := r.GithubClient.GetIssue(ctx, "owner", "repo", 4)
+		Expect(resources.IsGone(err)).To(BeTrue())
+
+		converted, err := r.convertedToDiscussion(ctx, r.Log, githubIssue, "owner", "repo")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(converted).To(BeTrue())
+
+		stored := &issuev1.GithubIssue{}
+		Expect(r.Client.Get(ctx, req.NamespacedName, stored)).To(Succeed())
+		Expect(stored.Status.DiscussionURL).To(Equal("https://github.com/owner/repo/discussions/4"))
+		Expect(meta.IsStatusConditionTrue(stored.Status.Conditions, status.ConvertedToDiscussionCondition)).To(BeTrue())
+
+		By("reconciling without touching GitHub until the spec changes")
+		result, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal(ctrl.Result{}))
+	})
+})
+
+var _ = Describe("GithubIssue Controller reopening", func() {
+	ctx := context.Background()
+
+	var (
+		edits []github.IssueRequest
+		r     *issueReconcile
+	)
+
+	BeforeEach(func() {
+		edits = nil
+		mux := http.NewServeMux()
+		mux.HandleFunc("/repos/owner/repo/issues/4", func(w http.ResponseWriter, req *http.Request) {
+			var request github.IssueRequest
+			Expect(json.NewDecoder(req.Body).Decode(&request)).To(Succeed())
+			edits = append(edits, request)
+			state := "closed"
+			if request.State != nil {
+				state = request.GetState()
+			}
+			Expect(json.NewEncoder(w).Encode(&github.Issue{
+				Number: github.Int(4),
+				Title:  request.Title,
+				Body:   request.Body,
+				State:  github.String(state),
+			})).To(Succeed())
+		})
+		githubClient := newGithubServer(mux)
+		r = &issueReconcile{GithubIssueReconciler: &GithubIssueReconciler{}, GithubClient: githubClient}
+	})
+
+	closedIssue := func() *github.Issue {
+		return &github.Issue{
+			Number: github.Int(4),
+			Title:  github.String("Old title"),
+			Body:   github.String("body"),
+			State:  github.String("closed"),
+		}
+	}
+
+	It("Should update a closed issue but keep it closed without allowReopen", func() {
+		githubIssue := &issuev1.GithubIssue{Spec: issuev1.GithubIssueSpec{AllowReopen: github.Bool(false)}}
+
+		issue, err := r.GithubClient.UpdateIssue("owner", "repo", closedIssue(), "body", "New title", resources.IssueFields{})
+		Expect(err).NotTo(HaveOccurred())
+		issue, err = r.reconcileIssueState(ctx, "owner", "repo", githubIssue, issue, true, reopenAllowed(githubIssue))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(issue.GetTitle()).To(Equal("New title"))
+		Expect(issue.GetState()).To(Equal("closed"))
+		Expect(edits).To(HaveLen(1))
+		Expect(edits[0].State).To(BeNil())
+	})
+
+	It("Should reopen a closed issue by default", func() {
+		githubIssue := &issuev1.GithubIssue{}
+		issue, err := r.reconcileIssueState(ctx, "owner", "repo", githubIssue, closedIssue(), true, reopenAllowed(githubIssue))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(issue.GetState()).To(Equal("open"))
+	})
+})
+
+var _ = Describe("GithubIssue Controller SLA", func() {
+	created := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	// slaBreaches returns the number of SLA breaches counted so far
+	slaBreaches := func() float64 {
+		m := &dto.Metric{}
+		Expect(metrics.SLABreaches.Write(m)).To(Succeed())
+		return m.GetCounter().GetValue()
+	}
+
+	newGithubIssue := func() *issuev1.GithubIssue {
+		return &issuev1.GithubIssue{
+			Spec: issuev1.GithubIssueSpec{SLAMaxAge: &metav1.Duration{Duration: 72 * time.Hour}},
+		}
+	}
+
+	It("Should flip the condition once the fake clock crosses the SLA", func() {
+		now := created.Add(48 * time.Hour)
+		r := &GithubIssueReconciler{now: func() time.Time { return now }}
+		githubIssue := newGithubIssue()
+		issue := &github.Issue{State: github.String("open"), CreatedAt: &created}
+		before := slaBreaches()
+
+		// within the SLA, recheck at the boundary
+		condition, recheckIn := r.slaBreached(githubIssue, issue)
+		Expect(condition.Type).To(Equal(status.SLABreachedCondition))
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(recheckIn).To(Equal(24 * time.Hour))
+		Expect(slaBreaches()).To(Equal(before))
+
+		// past the SLA the breach is counted once
+		now = now.Add(recheckIn)
+		condition, recheckIn = r.slaBreached(githubIssue, issue)
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(recheckIn).To(BeZero())
+		Expect(slaBreaches()).To(Equal(before + 1))
+
+		githubIssue.Status.Conditions = []metav1.Condition{condition}
+		now = now.Add(time.Hour)
+		condition, _ = r.slaBreached(githubIssue, issue)
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(slaBreaches()).To(Equal(before + 1))
+	})
+
+	It("Should not flag a closed issue past the SLA", func() {
+		r := &GithubIssueReconciler{now: func() time.Time { return created.Add(96 * time.Hour) }}
+		issue := &github.Issue{State: github.String("closed"), CreatedAt: &created}
+		before := slaBreaches()
+
+		condition, recheckIn := r.slaBreached(newGithubIssue(), issue)
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(recheckIn).To(BeZero())
+		Expect(slaBreaches()).To(Equal(before))
+	})
+})
+
+var _ = Describe("GithubIssue Controller closing reference", func() {
+	ctx := context.Background()
+
+	var (
+		comments    []string
+		githubIssue *issuev1.GithubIssue
+		r           *issueReconcile
+	)
+
+	BeforeEach(func() {
+		comments = nil
+		mux := http.NewServeMux()
+		mux.HandleFunc("/repos/owner/repo/issues/4/comments", commentsHandler(&comments))
+		mux.HandleFunc("/repos/owner/repo/issues/4", func(w http.ResponseWriter, req *http.Request) {
+			Expect(json.NewEncoder(w).Encode(&github.Issue{
+				Number: github.Int(4),
+				State:  github.String("closed"),
+			})).To(Succeed())
+		})
+		githubClient := newGithubServer(mux)
+		githubIssue = &issuev1.GithubIssue{ObjectMeta: metav1.ObjectMeta{Name: "closed-by", Namespace: "default"}}
+		r = &issueReconcile{
+			GithubIssueReconciler: &GithubIssueReconciler{
+				Client: newFakeClient(githubIssue),
+			},
+			GithubClient: githubClient,
+		}
+	})
+
+	openIssue := func() *github.Issue {
+		return &github.Issue{Number: github.Int(4), State: github.String("open")}
+	}
+
+	It("Should reference the commit in the close comment", func() {
+		githubIssue.Spec.ClosedBy = "1a2b3c4d"
+		issue, err := r.reconcileIssueState(ctx, "owner", "repo", githubIssue, openIssue(), false, true)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(issue.GetState()).To(Equal("closed"))
+		Expect(commentTexts(comments)).To(Equal([]string{AutoResolvedComment + "\n\nClosed by 1a2b3c4d"}))
+	})
+
+	It("Should reference the pull request in the close comment", func() {
+		githubIssue.Spec.ClosedBy = "https://github.com/owner/repo/pull/7"
+		_, err := r.reconcileIssueState(ctx, "owner", "repo", githubIssue, openIssue(), false, true)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(commentTexts(comments)).To(Equal([]string{AutoResolvedComment + "\n\nClosed by https://github.com/owner/repo/pull/7"}))
+	})
+
+	It("Should comment only the auto-resolved note without a closing reference", func() {
+		_, err := r.reconcileIssueState(ctx, "owner", "repo", githubIssue, openIssue(), false, true)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(commentTexts(comments)).To(Equal([]string{AutoResolvedComment}))
+	})
+})
+
+var _ = Describe("GithubIssue Controller milestone completion", func() {
+	ctx := context.Background()
+
+	var (
+		milestoneState  string
+		lookups         int
+		stateReason     string
+		comments        []string
+		closeOnComplete *issuev1.GithubIssue
+		r               *issueReconcile
+	)
+
+	BeforeEach(func() {
+		milestoneState = "closed"
+		lookups = 0
+		stateReason = ""
+		comments = nil
+
+		mux := http.NewServeMux()
+		mux.HandleFunc("/repos/owner/repo/milestones/2", func(w http.ResponseWriter, req *http.Request) {
+			lookups++
+			fmt.Fprintf(w, `{"number": 2, "title": "v1.0", "state": %q}`, milestoneState)
+		})
+		mux.HandleFunc("/repos/owner/repo/issues/4", func(w http.ResponseWriter, req *http.Request) {
+			var request map[string]string
+			Expect(json.NewDecoder(req.Body).Decode(&request)).To(Succeed())
+			stateReason = request["state_reason"]
+			fmt.Fprintf(w, `{"number": 4, "state": %q, "milestone": {"number": 2, "title": "v1.0"}}`, request["state"])
+		})
+		mux.HandleFunc("/repos/owner/repo/issues/4/comments", commentsHandler(&comments))
+		githubClient := newGithubServer(mux)
+		closeOnComplete = &issuev1.GithubIssue{
+			ObjectMeta: metav1.ObjectMeta{Name: "milestone", Namespace: "default"},
+			Spec:       issuev1.GithubIssueSpec{CloseOnMilestoneComplete: true},
+		}
+		r = &issueReconcile{
+			GithubIssueReconciler: &GithubIssueReconciler{
+				Client: newFakeClient(closeOnComplete),
+			},
+			GithubClient: githubClient,
+		}
+	})
+
+	openIssue := func() *github.Issue {
+		return &github.Issue{
+			Number:    github.Int(4),
+			State:     github.String("open"),
+			Milestone: &github.Milestone{Number: github.Int(2), Title: github.String("v1.0")},
+		}
+	}
+	It("Should close the issue as completed once its milestone is closed", func() {
+		issue, condition, err := r.reconcileMilestone(ctx, "owner", "repo", closeOnComplete, openIssue())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(issue.GetState()).To(Equal("closed"))
+		Expect(stateReason).To(Equal("completed"))
+		Expect(commentTexts(comments)).To(Equal([]string{`Closed as milestone "v1.0" is complete`}))
+		Expect(condition.Type).To(Equal("ClosedByMilestone"))
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+	})
+
+	It("Should keep reporting the condition without looking the milestone up again", func() {
+		closedIssue := closeOnComplete.DeepCopy()
+		closedIssue.Status.Conditions = []metav1.Condition{status.ClosedByMilestone("v1.0")}
+		issue := openIssue()
+		issue.State = github.String("closed")
+
+		_, condition, err := r.reconcileMilestone(ctx, "owner", "repo", closedIssue, issue)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(lookups).To(BeZero())
+		Expect(comments).To(BeEmpty())
+		Expect(condition.Type).To(Equal(status.ClosedByMilestoneCondition))
+	})
+
+	It("Should not look the milestone of an issue closed otherwise up", func() {
+		issue := openIssue()
+		issue.State = github.String("closed")
+
+		_, condition, err := r.reconcileMilestone(ctx, "owner", "repo", closeOnComplete, issue)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(lookups).To(BeZero())
+		Expect(condition).To(BeNil())
+	})
+
+	It("Should keep an issue closed by its milestone closed", func() {
+		closedIssue := closeOnComplete.DeepCopy()
+		closedIssue.Status.Conditions = []metav1.Condition{status.ClosedByMilestone("v1.0")}
+		Expect(closedByMilestone(closedIssue)).To(BeTrue())
+		Expect(closedByMilestone(closeOnComplete)).To(BeFalse())
+	})
+
+	It("Should leave the issue open while its milestone is open", func() {
+		milestoneState = "open"
+
+		issue, condition, err := r.reconcileMilestone(ctx, "owner", "repo", closeOnComplete, openIssue())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(issue.GetState()).To(Equal("open"))
+		Expect(condition).To(BeNil())
+	})
+
+	It("Should leave the issue open without closeOnMilestoneComplete", func() {
+		issue, condition, err := r.reconcileMilestone(ctx, "owner", "repo", &issuev1.GithubIssue{}, openIssue())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(issue.GetState()).To(Equal("open"))
+		Expect(condition).To(BeNil())
+		Expect(comments).To(BeEmpty())
+	})
+})
+
+var _ = Describe("GithubIssue Controller milestone creation", func() {
+	ctx := context.Background()
+
+	var (
+		mu      sync.Mutex
+		titles  []string
+		creates int
+		barrier bool
+		lookups sync.WaitGroup
+		r       *issueReconcile
+	)
+
+	BeforeEach(func() {
+		titles = []string{"v1.0"}
+		creates = 0
+		barrier = false
+		lookups = sync.WaitGroup{}
+
+		mux := http.NewServeMux()
+		mux.HandleFunc("/repos/owner/repo/milestones", func(w http.ResponseWriter, req *http.Request) {
+			if req.Method == http.MethodPost {
+				var request map[string]string
+				Expect(json.NewDecoder(req.Body).Decode(&request)).To(Succeed())
+				mu.Lock()
+				defer mu.Unlock()
+				creates++
+				for _, title := range titles {
+					if title == request["title"] {
+						w.WriteHeader(http.StatusUnprocessableEntity)
+						fmt.Fprint(w, `{"message": "Validation Failed", "errors": [{"resource": "Milestone", "code": "already_exists", "field": "title"}]}`)
+						return
+					}
+				}
+				titles = append(titles, request["title"])
+				w.WriteHeader(http.StatusCreated)
+				fmt.Fprintf(w, `{"number": %d, "title": %q}`, len(titles), request["title"])
+				return
+			}
+
+			mu.Lock()
+			missing := len(titles) == 1
+			mu.Unlock()
+			if barrier && missing {
+				lookups.Done()
+				lookups.Wait()
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			milestones := make([]map[string]any, 0, len(titles))
+			for i, title := range titles {
+				milestones = append(milestones, map[string]any{"number": i + 1, "title": title})
+			}
+			Expect(json.NewEncoder(w).Encode(milestones)).To(Succeed())
+		})
+		githubClient := newGithubServer(mux)
+		r = &issueReconcile{GithubIssueReconciler: &GithubIssueReconciler{}, GithubClient: githubClient}
+	})
+
+	newIssue := func(milestone string, create bool) *issuev1.GithubIssue {
+		return &issuev1.GithubIssue{Spec: issuev1.GithubIssueSpec{Milestone: milestone, CreateMilestoneIfMissing: create}}
+	}
+
+	It("Should resolve an existing milestone by its title", func() {
+		number, err := r.milestoneNumber(ctx, "owner", "repo", newIssue("v1.0", false))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(number).To(Equal(1))
+		Expect(creates).To(BeZero())
+	})
+
+	It("Should fail on a missing milestone without createMilestoneIfMissing", func() {
+		_, err := r.milestoneNumber(ctx, "owner", "repo", newIssue("v2.0", false))
+		Expect(err).To(MatchError(`milestone "v2.0" does not exist in owner/repo`))
+		Expect(creates).To(BeZero())
+	})
+
+	It("Should create a missing milestone with createMilestoneIfMissing", func() {
+		number, err := r.milestoneNumber(ctx, "owner", "repo", newIssue("v2.0", true))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(number).To(Equal(2))
+		Expect(titles).To(Equal([]string{"v1.0", "v2.0"}))
+	})
+
+	It("Should agree on the milestone when two reconciles create it concurrently", func() {
+		// hold both lookups until each has seen the milestone missing, so both try to create it
+		barrier = true
+		lookups.Add(2)
+
+		numbers := make([]int, 2)
+		errs := make([]error, 2)
+		var reconciles sync.WaitGroup
+		for i := range numbers {
+			reconciles.Add(1)
+			go func() {
+				defer GinkgoRecover()
+				defer reconciles.Done()
+				numbers[i], errs[i] = r.milestoneNumber(ctx, "owner", "repo", newIssue("v2.0", true))
+			}()
+		}
+		reconciles.Wait()
+
+		Expect(errs).To(Equal([]error{nil, nil}))
+		Expect(numbers).To(Equal([]int{2, 2}))
+		Expect(creates).To(Equal(2))
+		Expect(titles).To(Equal([]string{"v1.0", "v2.0"}))
+	})
+})
+
+var _ = Describe("GithubIssue Controller denied requests", func() {
+	ctx := context.Background()
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "forbidden-resource", Namespace: "default"}}
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	denied := errors.New("403 Forbidden")
+
+	var r *GithubIssueReconciler
+
+	BeforeEach(func() {
+		s := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(s)).To(Succeed())
+		Expect(issuev1.AddToScheme(s)).To(Succeed())
+		githubIssue := &issuev1.GithubIssue{
+			ObjectMeta: metav1.ObjectMeta{Name: req.Name, Namespace: req.Namespace},
+			Spec:       issuev1.GithubIssueSpec{Repo: "https://github.com/owner/repo", Title: "Test Issue"},
+		}
+		c := fake.NewClientBuilder().WithScheme(s).
+			WithObjects(githubIssue).
+			WithStatusSubresource(githubIssue).
+			Build()
+		r = &GithubIssueReconciler{Client: c, Scheme: s, Log: logr.Discard(), now: func() time.Time { return now }}
+	})
+
+	// stored returns the conditions of the GithubIssue as written
+	stored := func() []metav1.Condition {
+		githubIssue := &issuev1.GithubIssue{}
+		Expect(r.Client.Get(ctx, req.NamespacedName, githubIssue)).To(Succeed())
+		return githubIssue.Status.Conditions
+	}
+
+	It("Should requeue a rate limited issue once the rate limit resets", func() {
+		result, err := r.handleForbidden(ctx, req, &resources.RateLimitedError{Reset: now.Add(15 * time.Minute), Err: denied})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(15 * time.Minute))
+		Expect(meta.IsStatusConditionTrue(stored(), "RateLimited")).To(BeTrue())
+	})
+
+	It("Should report the scopes a token is missing", func() {
+		result, err := r.handleForbidden(ctx, req, &resources.ScopeInsufficientError{Missing: []string{"repo"}, Err: denied})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(forbiddenRequeue))
+
+		condition := meta.FindStatusCondition(stored(), "TokenScopeInsufficient")
+		Expect(condition.Reason).To(Equal("MissingScopes"))
+		Expect(condition.Message).To(ContainSubstring("repo"))
+	})
+
+	It("Should report a denied access", func() {
+		result, err := r.handleForbidden(ctx, req, &resources.AccessDeniedError{Err: denied})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(forbiddenRequeue))
+		Expect(meta.IsStatusConditionTrue(stored(), "AccessDenied")).To(BeTrue())
+		Expect(meta.FindStatusCondition(stored(), "RateLimited")).To(BeNil())
+	})
+})
+
+var _ = Describe("GithubIssue Controller bodies too long for GitHub", func() {
+	ctx := context.Background()
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "too-large-resource", Namespace: "default"}}
+	description := strings.Repeat("a", utils.GithubBodyLimit+100)
+
+	var (
+		created []string
+		r       *issueReconcile
+	)
+
+	BeforeEach(func() {
+		created = nil
+		mux := http.NewServeMux()
+		// GitHub refuses bodies over its limit with 422
+		mux.HandleFunc("/repos/owner/repo/issues", func(w http.ResponseWriter, req *http.Request) {
+			var request github.IssueRequest
+			Expect(json.NewDecoder(req.Body).Decode(&request)).To(Succeed())
+			if utf8.RuneCountInString(request.GetBody()) > utils.GithubBodyLimit {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				fmt.Fprint(w, `{"message": "Validation Failed", "errors": [{"resource": "Issue", "code": "custom", "field": "body", "message": "body is too long (maximum is 65536 characters)"}]}`)
+				return
+			}
+			created = append(created, request.GetBody())
+			w.WriteHeader(http.StatusCreated)
+			Expect(json.NewEncoder(w).Encode(&github.Issue{Number: github.Int(1), Body: request.Body})).To(Succeed())
+		})
+		githubClient := newGithubServer(mux)
+
+		s := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(s)).To(Succeed())
+		Expect(issuev1.AddToScheme(s)).To(Succeed())
+		githubIssue := &issuev1.GithubIssue{
+			ObjectMeta: metav1.ObjectMeta{Name: req.Name, Namespace: req.Namespace, Generation: 1},
+			Spec:       issuev1.GithubIssueSpec{Repo: "https://github.com/owner/repo", Title: "Test Issue"},
+		}
+		c := fake.NewClientBuilder().WithScheme(s).
+			WithObjects(githubIssue).
+			WithStatusSubresource(githubIssue).
+			Build()
+		r = &issueReconcile{
+			GithubIssueReconciler: &GithubIssueReconciler{
+				Client: c,
+				Scheme: s,
+				Log:    logr.Discard(),
+			},
+			GithubClient: githubClient,
+		}
+	})
+
+	It("Should retry the create with the body truncated when truncation is enabled", func() {
+		r.TruncateBody = true
+
+		issue, err := r.findOrCreateIssue(ctx, logr.Discard(), &issuev1.GithubIssue{}, "owner", "repo", "Test Issue", description, resources.IssueFields{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(issue.GetNumber()).To(Equal(1))
+		Expect(created).To(HaveLen(1))
+		Expect(utf8.RuneCountInString(created[0])).To(Equal(utils.GithubBodyLimit))
+	})
+
+	It("Should report the body too long and stop retrying until the spec changes", func() {
+		_, err := r.findOrCreateIssue(ctx, logr.Discard(), &issuev1.GithubIssue{}, "owner", "repo", "Test Issue", description, resources.IssueFields{})
+		Expect(resources.IsBodyTooLarge(err)).To(BeTrue())
+		Expect(created).To(BeEmpty())
+
+		result, err := r.handleBodyTooLarge(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(BeZero())
+
+		stored := &issuev1.GithubIssue{}
+		Expect(r.Client.Get(ctx, req.NamespacedName, stored)).To(Succeed())
+		Expect(status.BodyTooLarge(stored)).To(BeTrue())
+
+		// the next reconcile waits for the spec to change instead of looping
+		result, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal(ctrl.Result{}))
+		Expect(created).To(BeEmpty())
+
+		stored.Generation = 2
+		Expect(status.BodyTooLarge(stored)).To(BeFalse())
+	})
+})
+
+var _ = Describe("GithubIssue Controller approval label", func() {
+	var (
+		added   []string
+		removed []string
+		r       *issueReconcile
+	)
+
+	BeforeEach(func() {
+		added, removed = nil, nil
+		mux := http.NewServeMux()
+		recordLabels(mux, 4, &added, &removed)
+		githubClient := newGithubServer(mux)
+		r = &issueReconcile{GithubIssueReconciler: &GithubIssueReconciler{}, GithubClient: githubClient}
+	})
+
+	newGithubIssue := func(annotations map[string]string) *issuev1.GithubIssue {
+		return &issuev1.GithubIssue{
+			ObjectMeta: metav1.ObjectMeta{Annotations: annotations},
+			Spec:       issuev1.GithubIssueSpec{ApprovalLabel: "awaiting-approval"},
+		}
+	}
+	labeled := func() *github.Issue {
+		return &github.Issue{Number: github.Int(4), Labels: []*github.Label{{Name: github.String("awaiting-approval")}}}
+	}
+
+	It("Should apply the label while the GithubIssue awaits approval", func() {
+		Expect(r.reconcileApprovalLabel("owner", "repo", newGithubIssue(nil), &github.Issue{Number: github.Int(4)})).To(Succeed())
+		Expect(added).To(Equal([]string{"awaiting-approval"}))
+		Expect(removed).To(BeEmpty())
+	})
+
+	It("Should keep the label of a pending issue without calling GitHub", func() {
+		githubIssue := newGithubIssue(map[string]string{utils.ApprovedAnnotation: "false"})
+		Expect(r.reconcileApprovalLabel("owner", "repo", githubIssue, labeled())).To(Succeed())
+		Expect(added).To(BeEmpty())
+		Expect(removed).To(BeEmpty())
+	})
+
+	It("Should remove the label once the GithubIssue is approved", func() {
+		githubIssue := newGithubIssue(map[string]string{utils.ApprovedAnnotation: "true"})
+		Expect(r.reconcileApprovalLabel("owner", "repo", githubIssue, labeled())).To(Succeed())
+		Expect(removed).To(Equal([]string{"awaiting-approval"}))
+		Expect(added).To(BeEmpty())
+	})
+})
+
+var _ = Describe("GithubIssue Controller operator version", func() {
+	var (
+		added   []string
+		removed []string
+		version string
+		r       *issueReconcile
+	)
+
+	BeforeEach(func() {
+		added, removed = nil, nil
+		version = resources.Version
+		resources.Version = "v1.2.0"
+
+		mux := http.NewServeMux()
+		recordLabels(mux, 4, &added, &removed)
+		githubClient := newGithubServer(mux)
+		r = &issueReconcile{GithubIssueReconciler: &GithubIssueReconciler{VersionLabel: true}, GithubClient: githubClient}
+	})
+
+	AfterEach(func() {
+		resources.Version = version
+	})
+
+	labeled := func(labels ...string) *github.Issue {
+		issue := &github.Issue{Number: github.Int(4)}
+		for _, label := range labels {
+			issue.Labels = append(issue.Labels, &github.Label{Name: github.String(label)})
+		}
+		return issue
+	}
+
+	It("Should set the version marker on an issue without one", func() {
+		Expect(r.reconcileVersionLabel("owner", "repo", labeled("bug"))).To(Succeed())
+		Expect(added).To(Equal([]string{"operator-version:v1.2.0"}))
+		Expect(removed).To(BeEmpty())
+	})
+
+	It("Should replace the version marker of a stale operator version", func() {
+		Expect(r.reconcileVersionLabel("owner", "repo", labeled("bug", "operator-version:v1.1.0"))).To(Succeed())
+		Expect(removed).To(Equal([]string{"operator-version:v1.1.0"}))
+		Expect(added).To(Equal([]string{"operator-version:v1.2.0"}))
+	})
+
+	It("Should leave the version marker of the running version alone", func() {
+		Expect(r.reconcileVersionLabel("owner", "repo", labeled("operator-version:v1.2.0"))).To(Succeed())
+		Expect(added).To(BeEmpty())
+		Expect(removed).To(BeEmpty())
+	})
+
+	It("Should not label the issue unless the version label is enabled", func() {
+		r.VersionLabel = false
+		Expect(r.reconcileVersionLabel("owner", "repo", labeled("bug", "operator-version:v1.1.0"))).To(Succeed())
+		Expect(added).To(BeEmpty())
+		Expect(removed).To(BeEmpty())
+	})
+})
+
+var _ = Describe("GithubIssue Controller issue number collisions", func() {
+	ctx := context.Background()
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	var (
+		r     *GithubIssueReconciler
+		older *issuev1.GithubIssue
+		newer *issuev1.GithubIssue
+	)
+
+	newGithubIssue := func(name, title string, created time.Time) *issuev1.GithubIssue {
+		return &issuev1.GithubIssue{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", CreationTimestamp: metav1.NewTime(created)},
+			Spec:       issuev1.GithubIssueSpec{Repo: "https://github.com/owner/repo", Title: title},
+			Status:     issuev1.GithubIssueStatus{IssueNumber: 7},
+		}
+	}
+
+	BeforeEach(func() {
+		s := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(s)).To(Succeed())
+		Expect(issuev1.AddToScheme(s)).To(Succeed())
+
+		// two GithubIssues whose overlapping titles resolved to the same issue
+		older = newGithubIssue("older", "Disk full", now.Add(-time.Hour))
+		newer = newGithubIssue("newer", "Disk full on node-1", now)
+		c := fake.NewClientBuilder().WithScheme(s).
+			WithObjects(older, newer).
+			WithStatusSubresource(older, newer).
+			Build()
+		r = &GithubIssueReconciler{Client: c, Scheme: s, Log: logr.Discard()}
+	})
+
+	// stored returns the conditions of the GithubIssue as written
+	stored := func(githubIssue *issuev1.GithubIssue) []metav1.Condition {
+		written := &issuev1.GithubIssue{}
+		Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(githubIssue), written)).To(Succeed())
+		return written.Status.Conditions
+	}
+
+	It("Should set the condition on both GithubIssues, leaving the issue to the older one", func() {
+		condition, standDown, err := r.reconcileCollision(ctx, logr.Discard(), newer, "owner", "repo", 7)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(standDown).To(BeTrue())
+		Expect(condition.Type).To(Equal(status.IssueNumberCollisionCondition))
+		Expect(condition.Message).To(ContainSubstring("default/older"))
+
+		olderCondition := meta.FindStatusCondition(stored(older), status.IssueNumberCollisionCondition)
+		Expect(olderCondition).NotTo(BeNil())
+		Expect(olderCondition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(olderCondition.Message).To(ContainSubstring("default/newer"))
+		Expect(olderCondition.Message).To(ContainSubstring("keeps editing it"))
+
+		condition, standDown, err = r.reconcileCollision(ctx, logr.Discard(), older, "owner", "repo", 7)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(standDown).To(BeFalse())
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(meta.IsStatusConditionTrue(stored(newer), status.IssueNumberCollisionCondition)).To(BeTrue())
+	})
+
+	It("Should report no collision for a GithubIssue alone on its issue", func() {
+		condition, standDown, err := r.reconcileCollision(ctx, logr.Discard(), newer, "owner", "repo", 8)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(condition).To(BeNil())
+		Expect(standDown).To(BeFalse())
+		Expect(meta.FindStatusCondition(stored(older), status.IssueNumberCollisionCondition)).To(BeNil())
+	})
+})
+
+var _ = Describe("GithubIssue Controller deadlines", func() {
+	ctx := context.Background()
+	deadline := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	var (
+		states      []string
+		comments    []string
+		failCloses  int
+		now         time.Time
+		githubIssue *issuev1.GithubIssue
+		r           *issueReconcile
+	)
+
+	BeforeEach(func() {
+		states, comments = nil, nil
+		failCloses = 0
+		mux := http.NewServeMux()
+		mux.HandleFunc("/repos/owner/repo/issues/4", func(w http.ResponseWriter, req *http.Request) {
+			var request map[string]string
+			Expect(json.NewDecoder(req.Body).Decode(&request)).To(Succeed())
+			if failCloses > 0 {
+				failCloses--
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			states = append(states, request["state"])
+			fmt.Fprintf(w, `{"number": 4, "state": %q}`, request["state"])
+		})
+		mux.HandleFunc("/repos/owner/repo/issues/4/comments", commentsHandler(&comments))
+		githubClient := newGithubServer(mux)
+		githubIssue = &issuev1.GithubIssue{
+			ObjectMeta: metav1.ObjectMeta{Name: "deadline", Namespace: "default"},
+			Spec:       issuev1.GithubIssueSpec{CloseAt: &metav1.Time{Time: deadline}},
+		}
+		r = &issueReconcile{
+			GithubIssueReconciler: &GithubIssueReconciler{
+				Client: newFakeClient(githubIssue),
+				now:    func() time.Time { return now },
+			},
+			GithubClient: githubClient,
+		}
+	})
+
+	openIssue := func() *github.Issue {
+		return &github.Issue{Number: github.Int(4), State: github.String("open")}
+	}
+
+	It("Should close an issue whose deadline already passed right away", func() {
+		now = deadline.Add(time.Hour)
+
+		issue, condition, closesIn, err := r.reconcileDeadline(ctx, "owner", "repo", githubIssue, openIssue())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(issue.GetState()).To(Equal("closed"))
+		Expect(states).To(Equal([]string{"closed"}))
+		Expect(commentTexts(comments)).To(Equal([]string{DeadlineComment}))
+		Expect(condition.Type).To(Equal("ClosedByDeadline"))
+		Expect(closesIn).To(BeZero())
+	})
+
+	It("Should wait for a future deadline, then close the issue once the clock crosses it", func() {
+		now = deadline.Add(-90 * time.Minute)
+
+		issue, condition, closesIn, err := r.reconcileDeadline(ctx, "owner", "repo", githubIssue, openIssue())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(issue.GetState()).To(Equal("open"))
+		Expect(condition).To(BeNil())
+		Expect(closesIn).To(Equal(90 * time.Minute))
+		Expect(states).To(BeEmpty())
+
+		now = now.Add(closesIn)
+		issue, condition, _, err = r.reconcileDeadline(ctx, "owner", "repo", githubIssue, issue)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(issue.GetState()).To(Equal("closed"))
+		Expect(condition).NotTo(BeNil())
+		Expect(r.pastDeadline(githubIssue)).To(BeTrue())
+	})
+
+	It("Should comment once when closing the issue is retried", func() {
+		now = deadline.Add(time.Hour)
+		failCloses = 1
+
+		_, _, _, err := r.reconcileDeadline(ctx, "owner", "repo", githubIssue, openIssue())
+		Expect(err).To(HaveOccurred())
+		Expect(comments).To(BeEmpty())
+
+		issue, _, _, err := r.reconcileDeadline(ctx, "owner", "repo", githubIssue, openIssue())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(issue.GetState()).To(Equal("closed"))
+		Expect(commentTexts(comments)).To(Equal([]string{DeadlineComment}))
+	})
+
+	It("Should not close the issue again once it is closed", func() {
+		now = deadline.Add(time.Hour)
+		closedIssue := openIssue()
+		closedIssue.State = github.String("closed")
+
+		_, condition, _, err := r.reconcileDeadline(ctx, "owner", "repo", githubIssue, closedIssue)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(condition).NotTo(BeNil())
+		Expect(states).To(BeEmpty())
+		Expect(comments).To(BeEmpty())
+	})
+})
+
+var _ = Describe("GithubIssue Controller state change throttling", func() {
+	ctx := context.Background()
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	var (
+		states      []string
+		comments    []string
+		now         time.Time
+		r           *issueReconcile
+		githubIssue *issuev1.GithubIssue
+	)
+
+	BeforeEach(func() {
+		states, comments = nil, nil
+		mux := http.NewServeMux()
+		mux.HandleFunc("/repos/owner/repo/issues/4", func(w http.ResponseWriter, req *http.Request) {
+			var request map[string]string
+			Expect(json.NewDecoder(req.Body).Decode(&request)).To(Succeed())
+			states = append(states, request["state"])
+			fmt.Fprintf(w, `{"number": 4, "state": %q}`, request["state"])
+		})
+		mux.HandleFunc("/repos/owner/repo/issues/4/comments", commentsHandler(&comments))
+		githubClient := newGithubServer(mux)
+		now = start
+		githubIssue = &issuev1.GithubIssue{ObjectMeta: metav1.ObjectMeta{Name: "throttle", Namespace: "default"}}
+		r = &issueReconcile{
+			GithubIssueReconciler: &GithubIssueReconciler{
+				Client:                 newFakeClient(githubIssue),
+				MinStateChangeInterval: 10 * time.Minute,
+				now:                    func() time.Time { return now },
+			},
+			GithubClient: githubClient,
+		}
+	})
+
+	openIssue := func() *github.Issue {
+		return &github.Issue{Number: github.Int(4), State: github.String("open")}
+	}
+
+	It("Should hold a flapping issue closed until the interval passed", func() {
+		issue, condition, wait, err := r.reconcileLinkedState(ctx, "owner", "repo", githubIssue, openIssue(), false)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(issue.GetState()).To(Equal("closed"))
+		Expect(condition).To(BeNil())
+		Expect(wait).To(BeZero())
+		Expect(githubIssue.Status.LastStateTransition.Time).To(Equal(start))
+
+		// the linked resource recovers two minutes later
+		now = start.Add(2 * time.Minute)
+		issue, condition, wait, err = r.reconcileLinkedState(ctx, "owner", "repo", githubIssue, issue, true)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(issue.GetState()).To(Equal("closed"))
+		Expect(condition.Type).To(Equal("StateChangeThrottled"))
+		Expect(wait).To(Equal(8 * time.Minute))
+		Expect(states).To(Equal([]string{"closed"}))
+
+		now = now.Add(wait)
+		issue, condition, wait, err = r.reconcileLinkedState(ctx, "owner", "repo", githubIssue, issue, true)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(issue.GetState()).To(Equal("open"))
+		Expect(condition).To(BeNil())
+		Expect(wait).To(BeZero())
+		Expect(states).To(Equal([]string{"closed", "open"}))
+		Expect(githubIssue.Status.LastStateTransition.Time).To(Equal(start.Add(10 * time.Minute)))
+	})
+
+	It("Should not throttle an issue already in the desired state", func() {
+		_, _, _, err := r.reconcileLinkedState(ctx, "owner", "repo", githubIssue, openIssue(), false)
+		Expect(err).NotTo(HaveOccurred())
+
+		now = start.Add(time.Minute)
+		closedIssue := openIssue()
+		closedIssue.State = github.String("closed")
+		_, condition, wait, err := r.reconcileLinkedState(ctx, "owner", "repo", githubIssue, closedIssue, false)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(condition).To(BeNil())
+		Expect(wait).To(BeZero())
+	})
+
+	It("Should never throttle without an interval", func() {
+		r.MinStateChangeInterval = 0
+
+		issue, _, _, err := r.reconcileLinkedState(ctx, "owner", "repo", githubIssue, openIssue(), false)
+		Expect(err).NotTo(HaveOccurred())
+		issue, condition, _, err := r.reconcileLinkedState(ctx, "owner", "repo", githubIssue, issue, true)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(condition).To(BeNil())
+		Expect(issue.GetState()).To(Equal("open"))
+		Expect(states).To(Equal([]string{"closed", "open"}))
+	})
+})
+
+var _ = Describe("GithubIssue Controller templated labels", func() {
+	ctx := context.Background()
+
+	newIssue := func(labels ...string) *issuev1.GithubIssue {
+		return &issuev1.GithubIssue{
+			ObjectMeta: metav1.ObjectMeta{Name: "db-down", Namespace: "payments"},
+			Spec:       issuev1.GithubIssueSpec{Repo: "https://github.com/owner/repo", Title: "Test Issue", Labels: labels},
+		}
+	}
+
+	It("Should render the labels with the metadata of the GithubIssue", func() {
+		fields, _, err := issueFields(newIssue("bug", "ns/{{ .Namespace }}", "cr/{{ .Name }}"), "description")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fields.Labels).To(Equal([]string{"bug", "ns/payments", "cr/db-down"}))
+	})
+
+	It("Should dedupe labels rendering the same, keeping front-matter labels", func() {
+		githubIssue := newIssue("ns/payments", "ns/{{ .Namespace }}")
+		githubIssue.Spec.FrontMatter = true
+
+		fields, description, err := issueFields(githubIssue, "---\nlabels: [triage]\n---\nbody")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fields.Labels).To(Equal([]string{"ns/payments", "triage"}))
+		Expect(description).To(Equal("body"))
+	})
+
+	It("Should add the front-matter assignees without writing to the spec", func() {
+		githubIssue := newIssue()
+		githubIssue.Spec.FrontMatter = true
+		githubIssue.Spec.Assignees = make([]string, 1, 2)
+		githubIssue.Spec.Assignees[0] = "octocat"
+
+		fields, _, err := issueFields(githubIssue, "---\nassignees: [hubot]\n---\nbody")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fields.Assignees).To(Equal([]string{"octocat", "hubot"}))
+		Expect(githubIssue.Spec.Assignees[:2]).To(Equal([]string{"octocat", ""}))
+	})
+
+	It("Should fail on labels that don't render", func() {
+		_, _, err := issueFields(newIssue("{{ .Cluster }}"), "description")
+		Expect(err).To(MatchError(ContainSubstring("failed to render label")))
+	})
+
+	It("Should attach the rendered labels to the created issue", func() {
+		var labels []string
+		mux := http.NewServeMux()
+		mux.HandleFunc("/repos/owner/repo/issues", func(w http.ResponseWriter, req *http.Request) {
+			if req.Method == http.MethodGet {
+				_, _ = w.Write([]byte(`[]`))
+				return
+			}
+			var request github.IssueRequest
+			Expect(json.NewDecoder(req.Body).Decode(&request)).To(Succeed())
+			labels = request.GetLabels()
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"number": 1}`))
+		})
+		githubClient := newGithubServer(mux)
+		r := &issueReconcile{GithubIssueReconciler: &GithubIssueReconciler{}, GithubClient: githubClient}
+
+		githubIssue := newIssue("ns/{{ .Namespace }}")
+		fields, description, err := issueFields(githubIssue, "description")
+		Expect(err).NotTo(HaveOccurred())
+		_, err = r.findOrCreateIssue(ctx, logr.Discard(), githubIssue, "owner", "repo", "Test Issue", description, fields)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(labels).To(Equal([]string{"ns/payments"}))
+	})
+})
+
+var _ = Describe("GithubIssue Controller create-first ordering", func() {
+	ctx := context.Background()
+
+	var queue workqueue.TypedRateLimitingInterface[reconcile.Request]
+
+	BeforeEach(func() {
+		queue = workqueue.NewTypedRateLimitingQueue(workqueue.DefaultTypedControllerRateLimiter[reconcile.Request]())
+	})
+
+	AfterEach(func() {
+		queue.ShutDown()
+	})
+
+	newIssue := func(name string, issueNumber int32) *issuev1.GithubIssue {
+		return &issuev1.GithubIssue{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Status:     issuev1.GithubIssueStatus{IssueNumber: issueNumber},
+		}
+	}
+
+	// drain returns the names of the next n requests, in the order they're handed out
+	drain := func(n int) []string {
+		names := make([]string, 0, n)
+		for range n {
+			req, shutdown := queue.Get()
+			Expect(shutdown).To(BeFalse())
+			names = append(names, req.Name)
+			queue.Done(req)
+		}
+		return names
+	}
+
+	It("Should hand out creations before the updates of a large apply", func() {
+		h := createFirstHandler(100 * time.Millisecond)
+
+		// updates and creations interleaved, as a large apply delivers them
+		var creations, updates []string
+		for i := int32(1); i <= 50; i++ {
+			update := newIssue(fmt.Sprintf("existing-%d", i), i)
+			h.Update(ctx, event.UpdateEvent{ObjectOld: update, ObjectNew: update}, queue)
+			updates = append(updates, update.Name)
+
+			creation := newIssue(fmt.Sprintf("new-%d", i), 0)
+			h.Create(ctx, event.CreateEvent{Object: creation}, queue)
+			creations = append(creations, creation.Name)
+		}
+
+		Expect(drain(50)).To(Equal(creations))
+		Expect(drain(50)).To(ConsistOf(updates))
+	})
+
+	It("Should enqueue GithubIssues being deleted right away", func() {
+		h := createFirstHandler(time.Hour)
+		deleting := newIssue("deleting", 1)
+		deleting.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+
+		h.Update(ctx, event.UpdateEvent{ObjectOld: deleting, ObjectNew: deleting}, queue)
+		Expect(queue.Len()).To(Equal(1))
+	})
+
+	It("Should enqueue everything right away without a delay", func() {
+		h := createFirstHandler(0)
+		h.Create(ctx, event.CreateEvent{Object: newIssue("existing", 1)}, queue)
+		h.Create(ctx, event.CreateEvent{Object: newIssue("new", 0)}, queue)
+
+		Expect(drain(2)).To(Equal([]string{"existing", "new"}))
+	})
+})
+
+var _ = Describe("GithubIssue Controller change freezes", func() {
+	ctx := context.Background()
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "freeze-resource", Namespace: "default"}}
+	freezeKey := client.ObjectKey{Namespace: "operator", Name: "change-freeze"}
+	start := time.Date(2024, 12, 20, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+
+	var (
+		now time.Time
+		r   *GithubIssueReconciler
+	)
+
+	BeforeEach(func() {
+		s := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(s)).To(Succeed())
+		Expect(issuev1.AddToScheme(s)).To(Succeed())
+		githubIssue := &issuev1.GithubIssue{
+			ObjectMeta: metav1.ObjectMeta{Name: req.Name, Namespace: req.Namespace},
+			Spec:       issuev1.GithubIssueSpec{Repo: "https://github.com/owner/repo", Title: "Test Issue"},
+		}
+		configMap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: freezeKey.Namespace, Name: freezeKey.Name},
+			Data: map[string]string{
+				freeze.StartKey:  start.Format(time.RFC3339),
+				freeze.EndKey:    end.Format(time.RFC3339),
+				freeze.ReasonKey: "holidays",
+			},
+		}
+		c := fake.NewClientBuilder().WithScheme(s).
+			WithObjects(githubIssue, configMap).
+			WithStatusSubresource(githubIssue).
+			Build()
+		// GitHub isn't reached: the freeze returns first, and without it the token secret
+		// is created first
+		r = &GithubIssueReconciler{
+			Client:          c,
+			Scheme:          s,
+			Log:             logr.Discard(),
+			FreezeConfigMap: freezeKey,
+			now:             func() time.Time { return now },
+		}
+	})
+
+	tokenSecretExists := func() bool {
+		secret := &corev1.Secret{}
+		err := r.Client.Get(ctx, types.NamespacedName{Name: req.Name + "-token-secret", Namespace: req.Namespace}, secret)
+		return err == nil
+	}
+
+	It("Should hold the reconcile during the freeze, requeueing once it ends", func() {
+		now = end.Add(-time.Hour)
+
+		result, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(time.Hour))
+		Expect(tokenSecretExists()).To(BeFalse())
+
+		stored := &issuev1.GithubIssue{}
+		Expect(r.Client.Get(ctx, req.NamespacedName, stored)).To(Succeed())
+		condition := meta.FindStatusCondition(stored.Status.Conditions, "Frozen")
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Message).To(ContainSubstring("holidays"))
+	})
+
+	DescribeTable("Should reconcile as usual outside the freeze",
+		func(outside time.Time) {
+			now = outside
+			result, err := r.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Requeue).To(BeTrue())
+			Expect(tokenSecretExists()).To(BeTrue())
+		},
+		Entry("before the freeze", start.Add(-time.Minute)),
+		Entry("once the freeze ended", end),
+	)
+
+	It("Should reconcile as usual without a freeze ConfigMap", func() {
+		r.FreezeConfigMap = client.ObjectKey{Namespace: "operator", Name: "missing"}
+		now = end.Add(-time.Hour)
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tokenSecretExists()).To(BeTrue())
+	})
+})
+
+var _ = Describe("GithubIssue Controller creation rate limit", func() {
+	ctx := context.Background()
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	var (
+		now          time.Time
+		r            *GithubIssueReconciler
+		githubIssues []*issuev1.GithubIssue
+	)
+
+	BeforeEach(func() {
+		s := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(s)).To(Succeed())
+		Expect(issuev1.AddToScheme(s)).To(Succeed())
+		builder := fake.NewClientBuilder().WithScheme(s)
+		githubIssues = nil
+		for i := range 20 {
+			githubIssue := &issuev1.GithubIssue{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("issue-%d", i), Namespace: "default"}}
+			githubIssues = append(githubIssues, githubIssue)
+			builder = builder.WithObjects(githubIssue).WithStatusSubresource(githubIssue)
+		}
+		now = start
+		// 6 creations per minute, with a burst of 6
+		r = &GithubIssueReconciler{
+			Client:          builder.Build(),
+			Scheme:          s,
+			Log:             logr.Discard(),
+			CreationLimiter: rate.NewLimiter(rate.Every(10*time.Second), 6),
+			now:             func() time.Time { return now },
+		}
+	})
+
+	It("Should throttle a runaway apply to the configured rate", func() {
+		// every GithubIssue not created yet retries once a second
+		created := map[string]bool{}
+		var createdAt []time.Time
+		for now = start; now.Before(start.Add(5 * time.Minute)); now = now.Add(time.Second) {
+			for _, githubIssue := range githubIssues {
+				if created[githubIssue.Name] {
+					continue
+				}
+				retryIn, err := r.limitCreation(ctx, githubIssue)
+				Expect(err).NotTo(HaveOccurred())
+				if retryIn == 0 {
+					created[githubIssue.Name] = true
+					createdAt = append(createdAt, now)
+				}
+			}
+		}
+
+		// the burst right away, then one every 10 seconds
+		Expect(createdAt).To(HaveLen(len(githubIssues)))
+		for i, at := range createdAt {
+			expected := start
+			if i >= 6 {
+				expected = start.Add(time.Duration(i-5) * 10 * time.Second)
+			}
+			Expect(at).To(Equal(expected), "creation %d", i)
+		}
+	})
+
+	It("Should report the limit and when to retry", func() {
+		for _, githubIssue := range githubIssues[:6] {
+			retryIn, err := r.limitCreation(ctx, githubIssue)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(retryIn).To(BeZero())
+		}
+
+		throttled := githubIssues[6]
+		retryIn, err := r.limitCreation(ctx, throttled)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(retryIn).To(Equal(10 * time.Second))
+		Expect(meta.IsStatusConditionTrue(throttled.Status.Conditions, "CreationRateLimited")).To(BeTrue())
+
+		// the throttled creation didn't take the next one
+		now = now.Add(retryIn)
+		retryIn, err = r.limitCreation(ctx, throttled)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(retryIn).To(BeZero())
+	})
+
+	It("Should not limit creations without a limiter", func() {
+		r.CreationLimiter = nil
+		for _, githubIssue := range githubIssues {
+			retryIn, err := r.limitCreation(ctx, githubIssue)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(retryIn).To(BeZero())
+		}
+	})
+})
+
+var _ = Describe("GithubIssue Controller team assignment", func() {
+	ctx := context.Background()
+
+	var (
+		members string
+		r       *issueReconcile
+	)
+
+	// openIssueAssignedTo is another GithubIssue holding an open issue assigned to the login
+	openIssueAssignedTo := func(name, login string) *issuev1.GithubIssue {
+		return &issuev1.GithubIssue{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Status: issuev1.GithubIssueStatus{
+				IssueNumber: 1,
+				Assignees:   []string{login},
+				Conditions:  []metav1.Condition{{Type: "IssueOpen", Status: metav1.ConditionTrue}},
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		members = `[{"login": "octocat"}, {"login": "hubot"}, {"login": "monalisa"}]`
+		mux := http.NewServeMux()
+		mux.HandleFunc("/orgs/org/teams/oncall/members", func(w http.ResponseWriter, req *http.Request) {
+			fmt.Fprint(w, members)
+		})
+		mux.HandleFunc("/orgs/org/teams/secret/members", func(w http.ResponseWriter, req *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message": "Not Found"}`)
+		})
+		githubClient := newGithubServer(mux)
+
+		s := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(s)).To(Succeed())
+		Expect(issuev1.AddToScheme(s)).To(Succeed())
+		c := fake.NewClientBuilder().WithScheme(s).WithObjects(
+			openIssueAssignedTo("first", "octocat"),
+			openIssueAssignedTo("second", "octocat"),
+			openIssueAssignedTo("third", "hubot"),
+		).Build()
+		r = &issueReconcile{
+			GithubIssueReconciler: &GithubIssueReconciler{
+				Client: c,
+				Scheme: s,
+			},
+			GithubClient: githubClient,
+		}
+	})
+
+	newIssue := func(team string, assignees ...string) *issuev1.GithubIssue {
+		return &issuev1.GithubIssue{
+			ObjectMeta: metav1.ObjectMeta{Name: "team-issue", Namespace: "default"},
+			Spec:       issuev1.GithubIssueSpec{AssignFromTeam: team},
+			Status:     issuev1.GithubIssueStatus{Assignees: assignees},
+		}
+	}
+
+	It("Should assign the member holding the fewest open issues", func() {
+		assignee, condition, err := r.teamAssignee(ctx, newIssue("org/oncall"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(condition).To(BeNil())
+		Expect(assignee).To(Equal("monalisa"))
+	})
+
+	It("Should keep the assigned member while they're in the team", func() {
+		assignee, _, err := r.teamAssignee(ctx, newIssue("org/oncall", "Octocat"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(assignee).To(Equal("octocat"))
+	})
+
+	It("Should reassign the issue once its assignee left the team", func() {
+		members = `[{"login": "octocat"}, {"login": "hubot"}]`
+		assignee, _, err := r.teamAssignee(ctx, newIssue("org/oncall", "monalisa"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(assignee).To(Equal("hubot"))
+	})
+
+	It("Should replace the member assigned from the team before, keeping the other assignees", func() {
+		members = `[{"login": "octocat"}, {"login": "hubot"}]`
+		githubIssue := newIssue("org/oncall", "alice", "monalisa")
+		githubIssue.Spec.Assignees = []string{"alice"}
+
+		assignees, condition, err := r.teamAssignees(ctx, githubIssue, githubIssue.Spec.Assignees)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(condition).To(BeNil())
+		Expect(assignees).To(Equal([]string{"alice", "hubot"}))
+		Expect(githubIssue.Spec.Assignees).To(Equal([]string{"alice"}))
+	})
+
+	It("Should keep the member assigned before while the team can't be resolved", func() {
+		assignees, condition, err := r.teamAssignees(ctx, newIssue("org/secret", "alice", "monalisa"), []string{"alice"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(condition.Type).To(Equal("TeamUnresolved"))
+		Expect(assignees).To(Equal([]string{"alice", "monalisa"}))
+	})
+
+	It("Should count the open issues of members whatever the case of their login", func() {
+		members = `[{"login": "OctoCat"}, {"login": "Zed"}]`
+		assignee, _, err := r.teamAssignee(ctx, newIssue("org/oncall"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(assignee).To(Equal("Zed"))
+	})
+
+	It("Should report a team the token can't read", func() {
+		assignee, condition, err := r.teamAssignee(ctx, newIssue("org/secret"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(assignee).To(BeEmpty())
+		Expect(condition.Type).To(Equal("TeamUnresolved"))
+		Expect(condition.Reason).To(Equal("TeamNotAccessible"))
+		Expect(condition.Message).To(ContainSubstring("read:org"))
+	})
+
+	It("Should report a team without members", func() {
+		members = `[]`
+		_, condition, err := r.teamAssignee(ctx, newIssue("org/oncall"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(condition.Reason).To(Equal("EmptyTeam"))
+	})
+})
+
+var _ = Describe("GithubIssue Controller recording the created issue number", func() {
+	ctx := context.Background()
+
+	var (
+		created     int
+		drafted     int
+		githubIssue *issuev1.GithubIssue
+		r           *issueReconcile
+	)
+
+	BeforeEach(func() {
+		created, drafted = 0, 0
+		mux := http.NewServeMux()
+		mux.HandleFunc("/repos/owner/repo/issues", func(w http.ResponseWriter, req *http.Request) {
+			if req.Method != http.MethodPost {
+				Expect(json.NewEncoder(w).Encode([]*github.Issue{})).To(Succeed())
+				return
+			}
+			created++
+			w.WriteHeader(http.StatusCreated)
+			Expect(json.NewEncoder(w).Encode(&github.Issue{Number: github.Int(7), State: github.String("open")})).To(Succeed())
+		})
+		mux.HandleFunc("/repos/owner/repo/security-advisories", func(w http.ResponseWriter, req *http.Request) {
+			drafted++
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"ghsa_id": "GHSA-abcd-efgh-ijkl", "state": "draft"}`)
+		})
+		githubClient := newGithubServer(mux)
+
+		s := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(s)).To(Succeed())
+		Expect(issuev1.AddToScheme(s)).To(Succeed())
+		githubIssue = &issuev1.GithubIssue{
+			ObjectMeta: metav1.ObjectMeta{Name: "record-number-resource", Namespace: "default", UID: "record-uid"},
+			Spec:       issuev1.GithubIssueSpec{Repo: "https://github.com/owner/repo", Title: "Test Issue"},
+		}
+		// every full status update conflicts, as if the GithubIssue changed meanwhile
+		c := fake.NewClientBuilder().WithScheme(s).
+			WithObjects(githubIssue).
+			WithStatusSubresource(githubIssue).
+			WithInterceptorFuncs(interceptor.Funcs{
+				SubResourceUpdate: func(context.Context, client.Client, string, client.Object, ...client.SubResourceUpdateOption) error {
+					return apierrors.NewConflict(schema.GroupResource{Group: "issue.core.github.io", Resource: "githubissues"}, "record-number-resource", nil)
+				},
+			}).
+			Build()
+		r = &issueReconcile{
+			GithubIssueReconciler: &GithubIssueReconciler{
+				Client: c,
+				Scheme: s,
+				Log:    logr.Discard(),
+			},
+			GithubClient: githubClient,
+		}
+	})
+
+	It("Should keep the number of the created issue when the status update fails", func() {
+		Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(githubIssue), githubIssue)).To(Succeed())
+
+		issue, err := r.createIssue(ctx, r.Log, githubIssue, "owner", "repo", "Test Issue", "body", resources.IssueFields{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status.RecordIssueNumber(ctx, r.Client, githubIssue, issue.GetNumber())).To(Succeed())
+		Expect(githubIssue.Status.IssueNumber).To(Equal(int32(7)))
+
+		// the status fields set in memory survive the patch
+		githubIssue.Status.BodyHash = "hash"
+		err = status.Update(ctx, r.Client, githubIssue, issue)
+		Expect(apierrors.IsConflict(err)).To(BeTrue())
+		Expect(githubIssue.Status.BodyHash).To(Equal("hash"))
+
+		stored := &issuev1.GithubIssue{}
+		Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(githubIssue), stored)).To(Succeed())
+		Expect(stored.Status.IssueNumber).To(Equal(int32(7)))
+		Expect(created).To(Equal(1))
+	})
+
+	It("Should draft a single advisory when the status update fails", func() {
+		Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(githubIssue), githubIssue)).To(Succeed())
+		githubIssue.Spec.SecurityAdvisory = true
+
+		_, err := r.reconcileAdvisory(ctx, r.Log, githubIssue, "owner", "repo")
+		Expect(apierrors.IsConflict(err)).To(BeTrue())
+
+		stored := &issuev1.GithubIssue{}
+		Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(githubIssue), stored)).To(Succeed())
+		Expect(stored.Status.AdvisoryID).To(Equal("GHSA-abcd-efgh-ijkl"))
+
+		stored.Spec.SecurityAdvisory = true
+		_, err = r.reconcileAdvisory(ctx, r.Log, stored, "owner", "repo")
+		Expect(apierrors.IsConflict(err)).To(BeTrue())
+		Expect(drafted).To(Equal(1))
+	})
+
+	It("Should not touch the other status fields stored", func() {
+		Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(githubIssue), githubIssue)).To(Succeed())
+		githubIssue.Status.CommentCount = 3
+
+		Expect(status.RecordIssueNumber(ctx, r.Client, githubIssue, 7)).To(Succeed())
+
+		stored := &issuev1.GithubIssue{}
+		Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(githubIssue), stored)).To(Succeed())
+		Expect(stored.Status.IssueNumber).To(Equal(int32(7)))
+		Expect(stored.Status.CommentCount).To(BeZero())
+		Expect(githubIssue.Status.CommentCount).To(Equal(int32(3)))
+	})
+})
+
+var _ = Describe("GithubIssue Controller label selector", func() {
+	ctx := context.Background()
+
+	var (
+		c       client.Client
+		r       *GithubIssueReconciler
+		inShard *issuev1.GithubIssue
+		other   *issuev1.GithubIssue
+	)
+
+	newIssue := func(name, shard string) *issuev1.GithubIssue {
+		return &issuev1.GithubIssue{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{"shard": shard}},
+			Spec:       issuev1.GithubIssueSpec{Repo: "https://github.com/owner/repo", Title: "Test Issue"},
+		}
+	}
+
+	// tokenSecretCreated reports whether reconciling the GithubIssue got as far as creating its token secret
+	tokenSecretCreated := func(githubIssue *issuev1.GithubIssue) bool {
+		secret := &corev1.Secret{}
+		err := c.Get(ctx, types.NamespacedName{Name: githubIssue.Name + "-token-secret", Namespace: githubIssue.Namespace}, secret)
+		if apierrors.IsNotFound(err) {
+			return false
+		}
+		Expect(err).NotTo(HaveOccurred())
+		return true
+	}
+
+	BeforeEach(func() {
+		s := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(s)).To(Succeed())
+		Expect(issuev1.AddToScheme(s)).To(Succeed())
+		inShard = newIssue("shard-a-resource", "a")
+		other = newIssue("shard-b-resource", "b")
+		c = fake.NewClientBuilder().WithScheme(s).
+			WithObjects(inShard, other).
+			WithStatusSubresource(inShard, other).
+			Build()
+		selector, err := labels.Parse("shard=a")
+		Expect(err).NotTo(HaveOccurred())
+		r = &GithubIssueReconciler{Client: c, Scheme: s, Log: logr.Discard(), Selector: selector}
+	})
+
+	It("Should never reconcile a GithubIssue not matching the selector", func() {
+		result, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(other)})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal(ctrl.Result{}))
+		Expect(tokenSecretCreated(other)).To(BeFalse())
+
+		stored := &issuev1.GithubIssue{}
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(other), stored)).To(Succeed())
+		Expect(stored.Finalizers).To(BeEmpty())
+		Expect(stored.Status.Conditions).To(BeEmpty())
+	})
+
+	It("Should reconcile a GithubIssue matching the selector", func() {
+		result, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(inShard)})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Requeue).To(BeTrue())
+		Expect(tokenSecretCreated(inShard)).To(BeTrue())
+	})
+
+	It("Should filter the watch events of GithubIssues not matching the selector", func() {
+		filter := predicate.NewPredicateFuncs(r.selects)
+		Expect(filter.Create(event.CreateEvent{Object: inShard})).To(BeTrue())
+		Expect(filter.Create(event.CreateEvent{Object: other})).To(BeFalse())
+		Expect(filter.Update(event.UpdateEvent{ObjectOld: inShard, ObjectNew: other})).To(BeFalse())
+	})
+
+	It("Should reconcile every GithubIssue without a selector", func() {
+		r.Selector = nil
+		Expect(r.selects(inShard)).To(BeTrue())
+		Expect(r.selects(other)).To(BeTrue())
+	})
+
+	It("Should look for collisions among the GithubIssues of the shard only", func() {
+		// the other shard's GithubIssue holds the same issue, it isn't this instance's to mark
+		other.Status.IssueNumber = 7
+		Expect(c.Status().Update(ctx, other)).To(Succeed())
+
+		condition, standDown, err := r.reconcileCollision(ctx, logr.Discard(), inShard, "owner", "repo", 7)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(condition).To(BeNil())
+		Expect(standDown).To(BeFalse())
+
+		stored := &issuev1.GithubIssue{}
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(other), stored)).To(Succeed())
+		Expect(stored.Status.Conditions).To(BeEmpty())
+	})
+})
+
+var _ = Describe("GithubIssue Controller token file", func() {
+	ctx := context.Background()
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "token-file-resource", Namespace: "default"}}
+	// nothing listens there, the reconcile fails right after building its GitHub client
+	apiBaseURL := "https://127.0.0.1:1/api/v3/"
+
+	var (
+		path string
+		c    client.Client
+		r    *GithubIssueReconciler
+	)
+
+	// clientToken returns the hash of the token the reconcile built its GitHub client with
+	clientToken := func() string {
+		use, ok := r.githubClientKeys[githubClientUser{baseURL: apiBaseURL}]
+		Expect(ok).To(BeTrue())
+		return use.key
+	}
+
+	BeforeEach(func() {
+		path = filepath.Join(GinkgoT().TempDir(), "token")
+		Expect(os.WriteFile(path, []byte("ghp_first\n"), 0o600)).To(Succeed())
+		source, err := tokenfile.Load(path, logr.Discard())
+		Expect(err).NotTo(HaveOccurred())
+
+		s := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(s)).To(Succeed())
+		Expect(issuev1.AddToScheme(s)).To(Succeed())
+		githubIssue := &issuev1.GithubIssue{
+			ObjectMeta: metav1.ObjectMeta{Name: req.Name, Namespace: req.Namespace},
+			Spec: issuev1.GithubIssueSpec{
+				Repo:       "https://github.com/owner/repo",
+				Title:      "Test Issue",
+				APIBaseURL: apiBaseURL,
+			},
+		}
+		c = fake.NewClientBuilder().WithScheme(s).
+			WithObjects(githubIssue).
+			WithStatusSubresource(githubIssue).
+			Build()
+		r = &GithubIssueReconciler{
+			Client:             c,
+			Scheme:             s,
+			Log:                logr.Discard(),
+			TokenFile:          source,
+			ReuseGithubClients: true,
+			AllowedAPIHosts:    []string{"127.0.0.1"},
+		}
+	})
+
+	It("Should use the token of the file without a token secret", func() {
+		_, _ = r.Reconcile(ctx, req)
+		Expect(clientToken()).To(Equal(utils.ContentHash("ghp_first") + "@" + apiBaseURL))
+
+		secrets := &corev1.SecretList{}
+		Expect(c.List(ctx, secrets)).To(Succeed())
+		Expect(secrets.Items).To(BeEmpty())
+
+		stored := &issuev1.GithubIssue{}
+		Expect(c.Get(ctx, req.NamespacedName, stored)).To(Succeed())
+		Expect(stored.Status.TokenRequired).To(BeFalse())
+	})
+
+	It("Should use the token written to the file once reloaded", func() {
+		_, _ = r.Reconcile(ctx, req)
+		Expect(os.WriteFile(path, []byte("ghp_second\n"), 0o600)).To(Succeed())
+		Expect(r.TokenFile.Reload()).To(Succeed())
+
+		_, _ = r.Reconcile(ctx, req)
+		Expect(clientToken()).To(Equal(utils.ContentHash("ghp_second") + "@" + apiBaseURL))
+	})
+
+	It("Should require a token while the file is empty", func() {
+		Expect(os.WriteFile(path, nil, 0o600)).To(Succeed())
+		Expect(r.TokenFile.Reload()).To(Succeed())
+
+		result, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(r.missingTokenRequeue()))
+
+		stored := &issuev1.GithubIssue{}
+		Expect(c.Get(ctx, req.NamespacedName, stored)).To(Succeed())
+		Expect(stored.Status.TokenRequired).To(BeTrue())
+	})
+
+	It("Should not send the token to an API host that isn't allowed", func() {
+		r.AllowedAPIHosts = nil
+
+		result, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal(ctrl.Result{}))
+		Expect(r.githubClientKeys).To(BeEmpty())
+
+		stored := &issuev1.GithubIssue{}
+		Expect(c.Get(ctx, req.NamespacedName, stored)).To(Succeed())
+		Expect(meta.IsStatusConditionTrue(stored.Status.Conditions, status.APIHostForbiddenCondition)).To(BeTrue())
+	})
+})
+
+var _ = Describe("GithubIssue Controller deleting a GithubIssue with an API base URL", func() {
+	ctx := context.Background()
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "enterprise-resource", Namespace: "default"}}
+	otherReq := ctrl.Request{NamespacedName: types.NamespacedName{Name: "other-resource", Namespace: "default"}}
+
+	var (
+		otherRequests int
+		closed        bool
+		c             client.Client
+		r             *GithubIssueReconciler
+	)
+
+	BeforeEach(func() {
+		otherRequests = 0
+		closed = false
+		mux := http.NewServeMux()
+		mux.HandleFunc("/other/", func(w http.ResponseWriter, req *http.Request) {
+			otherRequests++
+			w.WriteHeader(http.StatusNotFound)
+		})
+		mux.HandleFunc("/api/v3/repos/owner/repo/issues", func(w http.ResponseWriter, req *http.Request) {
+			fmt.Fprintf(w, `[{"number": 1, "title": "Test Issue", "state": %q}]`, map[bool]string{false: "open", true: "closed"}[closed])
+		})
+		mux.HandleFunc("/api/v3/repos/owner/repo/issues/1", func(w http.ResponseWriter, req *http.Request) {
+			if req.Method == http.MethodPatch {
+				closed = true
+			}
+			fmt.Fprintf(w, `{"number": 1, "title": "Test Issue", "state": %q}`, map[bool]string{false: "open", true: "closed"}[closed])
+		})
+		server := newGithubTLSServer(mux)
+
+		path := filepath.Join(GinkgoT().TempDir(), "token")
+		Expect(os.WriteFile(path, []byte("ghp_token\n"), 0o600)).To(Succeed())
+		source, err := tokenfile.Load(path, logr.Discard())
+		Expect(err).NotTo(HaveOccurred())
+
+		githubIssue := &issuev1.GithubIssue{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              req.Name,
+				Namespace:         req.Namespace,
+				Finalizers:        []string{"finalizer.githubissue.issue.core.github.io"},
+				DeletionTimestamp: &metav1.Time{Time: time.Now()},
+			},
+			Spec: issuev1.GithubIssueSpec{
+				Repo:       "https://github.com/owner/repo",
+				Title:      "Test Issue",
+				APIBaseURL: server.URL + "/api/v3/",
+			},
+			Status: issuev1.GithubIssueStatus{IssueNumber: 1},
+		}
+		// reconciled through another API base URL right before
+		other := &issuev1.GithubIssue{
+			ObjectMeta: metav1.ObjectMeta{Name: otherReq.Name, Namespace: otherReq.Namespace},
+			Spec: issuev1.GithubIssueSpec{
+				Repo:       "https://github.com/owner/repo",
+				Title:      "Other Issue",
+				APIBaseURL: server.URL + "/other/",
+			},
+		}
+		// the API server leaves a GithubIssue that's already terminating as it is, where the fake
+		// client would bump its resource version
+		c = interceptor.NewClient(newFakeClient(githubIssue, other), interceptor.Funcs{
+			Delete: func(context.Context, client.WithWatch, client.Object, ...client.DeleteOption) error {
+				return nil
+			},
+		})
+		r = &GithubIssueReconciler{
+			Client:          c,
+			Log:             logr.Discard(),
+			TokenFile:       source,
+			AllowedAPIHosts: []string{"127.0.0.1"},
+		}
+		_, _ = r.Reconcile(ctx, otherReq)
+		Expect(otherRequests).NotTo(BeZero())
+		otherRequests = 0
+	})
+
+	It("Should close the issue through the API base URL of the GithubIssue", func() {
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(closed).To(BeTrue())
+		Expect(otherRequests).To(BeZero())
+
+		err = c.Get(ctx, req.NamespacedName, &issuev1.GithubIssue{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("Should not send the token to an API host that isn't allowed", func() {
+		r.AllowedAPIHosts = nil
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(closed).To(BeFalse())
+		Expect(otherRequests).To(BeZero())
+
+		err = c.Get(ctx, req.NamespacedName, &issuev1.GithubIssue{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+})
+
+var _ = Describe("GithubIssue Controller reopen window", func() {
+	ctx := context.Background()
+	start := time.Date(2024, 7, 1, 9, 0, 0, 0, time.UTC)
+
+	var (
+		now         time.Time
+		issue       *github.Issue
+		created     []*github.Issue
+		comments    []string
+		githubIssue *issuev1.GithubIssue
+		r           *issueReconcile
+	)
+
+	BeforeEach(func() {
+		now = start
+		created, comments = nil, nil
+		githubIssue = &issuev1.GithubIssue{
+			ObjectMeta: metav1.ObjectMeta{Name: "reopen-window", Namespace: "default", UID: "alert-uid"},
+			Spec: issuev1.GithubIssueSpec{
+				LinkedResource: &issuev1.LinkedResource{APIVersion: "apps/v1", Kind: "Deployment", Name: "web"},
+				ReopenWithin:   &metav1.Duration{Duration: 24 * time.Hour},
+			},
+			Status: issuev1.GithubIssueStatus{IssueNumber: 4},
+		}
+		issue = &github.Issue{
+			Number: github.Int(4),
+			Body:   github.String(utils.AddDedupeMarker("body", "alert-uid")),
+			State:  github.String("open"),
+		}
+
+		mux := http.NewServeMux()
+		mux.HandleFunc("/repos/owner/repo/issues/4/comments", commentsHandler(&comments))
+		mux.HandleFunc("/repos/owner/repo/issues/4", func(w http.ResponseWriter, req *http.Request) {
+			var request github.IssueRequest
+			Expect(json.NewDecoder(req.Body).Decode(&request)).To(Succeed())
+			issue.State = request.State
+			Expect(json.NewEncoder(w).Encode(issue)).To(Succeed())
+		})
+		// the closed issue carries the dedupe marker of the GithubIssue
+		mux.HandleFunc("/repos/owner/repo/issues", func(w http.ResponseWriter, req *http.Request) {
+			if req.Method == http.MethodPost {
+				var request github.IssueRequest
+				Expect(json.NewDecoder(req.Body).Decode(&request)).To(Succeed())
+				newIssue := &github.Issue{Number: github.Int(5), Body: request.Body, State: github.String("open")}
+				created = append(created, newIssue)
+				w.WriteHeader(http.StatusCreated)
+				Expect(json.NewEncoder(w).Encode(newIssue)).To(Succeed())
+				return
+			}
+			Expect(json.NewEncoder(w).Encode(append(created, issue))).To(Succeed())
+		})
+		githubClient := newGithubServer(mux)
+		r = &issueReconcile{
+			GithubIssueReconciler: &GithubIssueReconciler{
+				Client: newFakeClient(githubIssue),
+				Log:    logr.Discard(),
+				now:    func() time.Time { return now },
+			},
+			GithubClient: githubClient,
+		}
+	})
+
+	// closeOnRecovery closes the issue as the linked resource recovered
+	closeOnRecovery := func() {
+		var err error
+		issue, _, _, err = r.reconcileLinkedState(ctx, "owner", "repo", githubIssue, issue, false)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(issue.GetState()).To(Equal("closed"))
+	}
+
+	It("Should record when the issue was closed", func() {
+		closeOnRecovery()
+		Expect(githubIssue.Status.LastClosedAt).NotTo(BeNil())
+		Expect(githubIssue.Status.LastClosedAt.Time).To(Equal(start))
+	})
+
+	It("Should reopen the same issue when the resource fails again within the window", func() {
+		closeOnRecovery()
+
+		now = start.Add(23 * time.Hour)
+		Expect(r.reopenExpired(githubIssue, issue, true)).To(BeFalse())
+		reopened, _, _, err := r.reconcileLinkedState(ctx, "owner", "repo", githubIssue, issue, true)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reopened.GetNumber()).To(Equal(4))
+		Expect(reopened.GetState()).To(Equal("open"))
+		Expect(created).To(BeEmpty())
+	})
+
+	It("Should create a new issue when the resource fails again past the window", func() {
+		closeOnRecovery()
+
+		now = start.Add(25 * time.Hour)
+		Expect(r.reopenExpired(githubIssue, issue, true)).To(BeTrue())
+
+		// the reconcile records the replaced issue before creating the new one
+		githubIssue.Status.PreviousIssueNumber = int32(issue.GetNumber())
+		newIssue, err := r.findOrCreateIssue(ctx, r.Log, githubIssue, "owner", "repo", "Test Issue",
+			utils.AddDedupeMarker("body", "alert-uid"), resources.IssueFields{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(newIssue.GetNumber()).To(Equal(5))
+		Expect(created).To(HaveLen(1))
+		Expect(issue.GetState()).To(Equal("closed"))
+
+		// a retry finds the new issue rather than creating another one
+		again, err := r.findOrCreateIssue(ctx, r.Log, githubIssue, "owner", "repo", "Test Issue",
+			utils.AddDedupeMarker("body", "alert-uid"), resources.IssueFields{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(again.GetNumber()).To(Equal(5))
+		Expect(created).To(HaveLen(1))
+	})
+
+	It("Should always reopen without a window", func() {
+		closeOnRecovery()
+		githubIssue.Spec.ReopenWithin = nil
+
+		now = start.Add(30 * 24 * time.Hour)
+		Expect(r.reopenExpired(githubIssue, issue, true)).To(BeFalse())
+	})
+
+	It("Should not replace an issue closed by someone else", func() {
+		issue.State = github.String("closed")
+
+		now = start.Add(30 * 24 * time.Hour)
+		Expect(r.reopenExpired(githubIssue, issue, true)).To(BeFalse())
+	})
+
+	It("Should not replace the issue while the resource is healthy", func() {
+		closeOnRecovery()
+
+		now = start.Add(25 * time.Hour)
+		Expect(r.reopenExpired(githubIssue, issue, false)).To(BeFalse())
+	})
+
+	It("Should not replace an issue closed as its milestone is complete", func() {
+		closeOnRecovery()
+		githubIssue.Spec.CloseOnMilestoneComplete = true
+		githubIssue.Status.Conditions = []metav1.Condition{status.ClosedByMilestone("v1.0")}
+
+		now = start.Add(30 * 24 * time.Hour)
+		Expect(r.reopenExpired(githubIssue, issue, true)).To(BeFalse())
+	})
+})
+
+var _ = Describe("GithubIssue Controller token secret pattern", func() {
+	ctx := context.Background()
+
+	var (
+		c           client.Client
+		r           *GithubIssueReconciler
+		githubIssue *issuev1.GithubIssue
+	)
+
+	BeforeEach(func() {
+		s := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(s)).To(Succeed())
+		Expect(issuev1.AddToScheme(s)).To(Succeed())
+		githubIssue = &issuev1.GithubIssue{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec:       issuev1.GithubIssueSpec{Repo: "https://github.com/owner/repo", Title: "Test Issue"},
+		}
+		c = fake.NewClientBuilder().WithScheme(s).
+			WithObjects(githubIssue).
+			WithStatusSubresource(githubIssue).
+			Build()
+		names, err := resources.ParseTokenSecretNames("{{ .Name }}-gh")
+		Expect(err).NotTo(HaveOccurred())
+		r = &GithubIssueReconciler{Client: c, Scheme: s, Log: logr.Discard(), TokenSecretNames: names}
+	})
+
+	It("Should create and look up the token secret after the pattern", func() {
+		request := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(githubIssue)}
+		result, err := r.Reconcile(ctx, request)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Requeue).To(BeTrue())
+
+		secret := &corev1.Secret{}
+		Expect(c.Get(ctx, client.ObjectKey{Name: "web-gh", Namespace: "default"}, secret)).To(Succeed())
+		Expect(secret.OwnerReferences).To(HaveLen(1))
+
+		// the blank secret is found again rather than created under the default name
+		_, err = r.Reconcile(ctx, request)
+		Expect(err).NotTo(HaveOccurred())
+		secrets := &corev1.SecretList{}
+		Expect(c.List(ctx, secrets, client.InNamespace("default"))).To(Succeed())
+		Expect(secrets.Items).To(HaveLen(1))
+		Expect(secrets.Items[0].Name).To(Equal("web-gh"))
+
+		stored := &issuev1.GithubIssue{}
+		Expect(c.Get(ctx, request.NamespacedName, stored)).To(Succeed())
+		Expect(stored.Status.TokenRequired).To(BeTrue())
+	})
+})
+
+var _ = Describe("GithubIssue Controller retention after close", func() {
+	ctx := context.Background()
+	closedAt := time.Date(2024, 8, 1, 9, 0, 0, 0, time.UTC)
+	retention := 30 * 24 * time.Hour
+
+	var (
+		now         time.Time
+		issue       *github.Issue
+		listed      []*github.Issue
+		writes      []string
+		githubIssue *issuev1.GithubIssue
+		c           client.Client
+		r           *issueReconcile
+	)
+
+	BeforeEach(func() {
+		now = closedAt.Add(time.Hour)
+		writes = nil
+		issue = &github.Issue{
+			Number:   github.Int(4),
+			Title:    github.String("Test Issue"),
+			Body:     github.String(utils.AddDedupeMarker("body", "retention-uid")),
+			State:    github.String("closed"),
+			ClosedAt: &closedAt,
+		}
+		listed = []*github.Issue{issue}
+
+		// the finalizer looks the issue up, any write would reopen or recreate it
+		mux := http.NewServeMux()
+		mux.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) {
+			if req.Method != http.MethodGet {
+				writes = append(writes, req.Method+" "+req.URL.Path)
+			}
+			if req.URL.Path == "/repos/owner/repo/issues/4" {
+				Expect(json.NewEncoder(w).Encode(issue)).To(Succeed())
+				return
+			}
+			Expect(json.NewEncoder(w).Encode(listed)).To(Succeed())
+		})
+		githubClient := newGithubServer(mux)
+
+		s := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(s)).To(Succeed())
+		Expect(issuev1.AddToScheme(s)).To(Succeed())
+		githubIssue = &issuev1.GithubIssue{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       "retention-resource",
+				Namespace:  "default",
+				UID:        "retention-uid",
+				Finalizers: []string{"finalizer.githubissue.issue.core.github.io"},
+			},
+			Spec: issuev1.GithubIssueSpec{
+				Repo:                "https://github.com/owner/repo",
+				Title:               "Test Issue",
+				RetentionAfterClose: &metav1.Duration{Duration: retention},
+			},
+			Status: issuev1.GithubIssueStatus{IssueNumber: 4},
+		}
+		c = fake.NewClientBuilder().WithScheme(s).
+			WithObjects(githubIssue).
+			WithStatusSubresource(githubIssue).
+			Build()
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(githubIssue), githubIssue)).To(Succeed())
+		r = &issueReconcile{
+			GithubIssueReconciler: &GithubIssueReconciler{
+				Client: c,
+				Scheme: s,
+				Log:    logr.Discard(),
+				now:    func() time.Time { return now },
+			},
+			GithubClient: githubClient,
+		}
+	})
+
+	It("Should keep the GithubIssue within the retention, until it ends", func() {
+		retainedFor, deleted, err := r.reconcileRetention(ctx, r.Log, githubIssue, issue)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(deleted).To(BeFalse())
+		Expect(retainedFor).To(Equal(retention - time.Hour))
+
+		stored := &issuev1.GithubIssue{}
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(githubIssue), stored)).To(Succeed())
+		Expect(stored.DeletionTimestamp).To(BeNil())
+	})
+
+	It("Should delete the GithubIssue past the retention without reopening or recreating the issue", func() {
+		now = closedAt.Add(retention + time.Minute)
+
+		_, deleted, err := r.reconcileRetention(ctx, r.Log, githubIssue, issue)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(deleted).To(BeTrue())
+
+		// deleted without the finalizer, the next reconcile leaves the closed issue alone
+		stored := &issuev1.GithubIssue{}
+		Expect(apierrors.IsNotFound(c.Get(ctx, client.ObjectKeyFromObject(githubIssue), stored))).To(BeTrue())
+		_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(githubIssue)})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(writes).To(BeEmpty())
+		Expect(issue.GetState()).To(Equal("closed"))
+	})
+
+	It("Should not close an open issue of the same title past the retention", func() {
+		now = closedAt.Add(retention + time.Minute)
+		// the open issues listed are another issue that happens to share the title
+		listed = []*github.Issue{{
+			Number: github.Int(9),
+			Title:  github.String("Test Issue"),
+			Body:   github.String("filed by hand"),
+			State:  github.String("open"),
+		}}
+
+		_, deleted, err := r.reconcileRetention(ctx, r.Log, githubIssue, issue)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(deleted).To(BeTrue())
+
+		_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(githubIssue)})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(writes).To(BeEmpty())
+	})
+
+	It("Should fall back to when the operator closed the issue", func() {
+		issue.ClosedAt = nil
+		githubIssue.Status.LastClosedAt = &metav1.Time{Time: closedAt.Add(-time.Hour)}
+
+		retainedFor, deleted, err := r.reconcileRetention(ctx, r.Log, githubIssue, issue)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(deleted).To(BeFalse())
+		Expect(retainedFor).To(Equal(retention - 2*time.Hour))
+	})
+
+	It("Should keep the GithubIssue of an open issue", func() {
+		issue.State = github.String("open")
+		now = closedAt.Add(2 * retention)
+
+		retainedFor, deleted, err := r.reconcileRetention(ctx, r.Log, githubIssue, issue)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(deleted).To(BeFalse())
+		Expect(retainedFor).To(BeZero())
+	})
+
+	It("Should keep the GithubIssue without a retention", func() {
+		githubIssue.Spec.RetentionAfterClose = nil
+		now = closedAt.Add(2 * retention)
+
+		_, deleted, err := r.reconcileRetention(ctx, r.Log, githubIssue, issue)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(deleted).To(BeFalse())
+	})
+})
+
+var _ = Describe("GithubIssue Controller bodies in several languages", func() {
+	newIssue := func(appendLocales bool) *issuev1.GithubIssue {
+		return &issuev1.GithubIssue{Spec: issuev1.GithubIssueSpec{
+			Title: "Disk full",
+			Bodies: map[string]string{
+				"en": "The disk of web-1 is full.",
+				"es": "El disco de web-1 está lleno.",
+				"fr": "Le disque de web-1 est plein.",
+			},
+			DefaultLocale: "en",
+			AppendLocales: appendLocales,
+		}}
+	}
+
+	It("Should post the body of the default locale", func() {
+		Expect(issueBody(newIssue(false))).To(Equal("The disk of web-1 is full."))
+	})
+
+	It("Should append the other locales in collapsible sections", func() {
+		body := issueBody(newIssue(true))
+		Expect(body).To(HavePrefix("The disk of web-1 is full.\n\n"))
+		Expect(body).To(ContainSubstring("<details>\n<summary>es</summary>\n\nEl disco de web-1 está lleno.\n\n</details>"))
+		Expect(body).To(ContainSubstring("<details>\n<summary>fr</summary>\n\nLe disque de web-1 est plein.\n\n</details>"))
+		Expect(body).NotTo(ContainSubstring("<summary>en</summary>"))
+		Expect(body).To(MatchRegexp(`(?s)<summary>es</summary>.*<summary>fr</summary>`))
+	})
+
+	It("Should render the same body again, leaving the issue unedited", func() {
+		githubIssue := newIssue(true)
+		issue := &github.Issue{Title: github.String("Disk full"), Body: github.String(issueBody(githubIssue))}
+		Expect(resources.Drifted(issue, "Disk full", issueBody(githubIssue), resources.IssueFields{})).To(BeFalse())
+
+		githubIssue.Spec.Bodies["de"] = "Die Festplatte von web-1 ist voll."
+		Expect(resources.Drifted(issue, "Disk full", issueBody(githubIssue), resources.IssueFields{})).To(BeTrue())
+	})
+
+	It("Should post the description without bodies", func() {
+		Expect(issueBody(&issuev1.GithubIssue{Spec: issuev1.GithubIssueSpec{Description: "body"}})).To(Equal("body"))
+	})
+})
+
+var _ = Describe("GithubIssue Controller repository probe", func() {
+	ctx := context.Background()
+	interval := 5 * time.Minute
+	secretKey := client.ObjectKey{Namespace: "default", Name: "probe-resource-token-secret"}
+
+	var (
+		now     time.Time
+		probes  map[string]int
+		mu      sync.Mutex
+		release chan struct{}
+		r       *issueReconcile
+	)
+
+	BeforeEach(func() {
+		now = time.Date(2024, 8, 1, 9, 0, 0, 0, time.UTC)
+		probes = map[string]int{}
+		release = make(chan struct{})
+
+		mux := http.NewServeMux()
+		mux.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) {
+			mu.Lock()
+			probes[req.URL.Path]++
+			mu.Unlock()
+			switch req.URL.Path {
+			case "/repos/owner/slow":
+				<-release
+				_, _ = w.Write([]byte(`{"name": "slow"}`))
+			case "/repos/owner/up":
+				_, _ = w.Write([]byte(`{"name": "up"}`))
+			case "/repos/other/down":
+				w.WriteHeader(http.StatusForbidden)
+				_, _ = w.Write([]byte(`{"message": "Repository access blocked"}`))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = w.Write([]byte(`{"message": "Not Found"}`))
+			}
+		})
+		githubClient := newGithubServer(mux)
+
+		r = &issueReconcile{
+			GithubIssueReconciler: &GithubIssueReconciler{
+				Log:               logr.Discard(),
+				RepoProbeInterval: interval,
+				now:               func() time.Time { return now },
+			},
+			GithubClient: githubClient,
+		}
+	})
+
+	It("Should report reachable, missing and failing repositories", func() {
+		up := r.probeRepo(ctx, r.Log, secretKey, "", "owner", "up")
+		Expect(up.Type).To(Equal(status.RepoReachableCondition))
+		Expect(up.Status).To(Equal(metav1.ConditionTrue))
+		Expect(up.Reason).To(Equal("RepositoryReachable"))
+
+		gone := r.probeRepo(ctx, r.Log, secretKey, "", "owner", "gone")
+		Expect(gone.Status).To(Equal(metav1.ConditionFalse))
+		Expect(gone.Reason).To(Equal("RepositoryNotFound"))
+		Expect(gone.Message).To(ContainSubstring("owner/gone"))
+
+		down := r.probeRepo(ctx, r.Log, secretKey, "", "other", "down")
+		Expect(down.Status).To(Equal(metav1.ConditionFalse))
+		Expect(down.Reason).To(Equal("ProbeFailed"))
+		Expect(down.Message).To(ContainSubstring("Repository access blocked"))
+	})
+
+	It("Should probe each repository once per interval across the GithubIssues targeting it", func() {
+		var wg sync.WaitGroup
+		for i := 0; i < 5; i++ {
+			for _, repo := range [][2]string{{"owner", "up"}, {"owner", "gone"}, {"other", "down"}} {
+				wg.Add(1)
+				go func(owner, name string) {
+					defer GinkgoRecover()
+					defer wg.Done()
+					r.probeRepo(ctx, r.Log, secretKey, "", owner, name)
+				}(repo[0], repo[1])
+			}
+		}
+		wg.Wait()
+		Expect(probes).To(Equal(map[string]int{"/repos/owner/up": 1, "/repos/owner/gone": 1, "/repos/other/down": 1}))
+
+		// the repository names are matched case insensitively like GitHub does
+		Expect(r.probeRepo(ctx, r.Log, secretKey, "", "Owner", "Up").Status).To(Equal(metav1.ConditionTrue))
+		Expect(probes["/repos/owner/up"]).To(Equal(1))
+
+		now = now.Add(interval - time.Second)
+		r.probeRepo(ctx, r.Log, secretKey, "", "owner", "up")
+		Expect(probes["/repos/owner/up"]).To(Equal(1))
+
+		now = now.Add(time.Second)
+		r.probeRepo(ctx, r.Log, secretKey, "", "owner", "up")
+		r.probeRepo(ctx, r.Log, secretKey, "", "owner", "gone")
+		Expect(probes).To(Equal(map[string]int{"/repos/owner/up": 2, "/repos/owner/gone": 2, "/repos/other/down": 1}))
+	})
+
+	It("Should probe the same repository again on another server", func() {
+		r.probeRepo(ctx, r.Log, secretKey, "", "owner", "up")
+		r.probeRepo(ctx, r.Log, secretKey, "https://ghe.example.com/api/v3/", "owner", "up")
+		Expect(probes["/repos/owner/up"]).To(Equal(2))
+	})
+
+	It("Should probe the same repository again with another token secret", func() {
+		r.probeRepo(ctx, r.Log, secretKey, "", "owner", "up")
+		r.probeRepo(ctx, r.Log, client.ObjectKey{Namespace: "other", Name: "other-token-secret"}, "", "owner", "up")
+		r.probeRepo(ctx, r.Log, client.ObjectKey{}, "", "owner", "up")
+		Expect(probes["/repos/owner/up"]).To(Equal(3))
+	})
+
+	It("Should drop the probes older than the interval", func() {
+		r.probeRepo(ctx, r.Log, secretKey, "", "owner", "up")
+		r.probeRepo(ctx, r.Log, secretKey, "", "owner", "gone")
+		Expect(r.repoProbes).To(HaveLen(2))
+
+		now = now.Add(interval)
+		r.probeRepo(ctx, r.Log, secretKey, "", "owner", "up")
+		Expect(r.repoProbes).To(HaveLen(1))
+	})
+
+	It("Should probe other repositories while a probe is in flight", func() {
+		slow := make(chan metav1.Condition, 1)
+		go func() {
+			defer GinkgoRecover()
+			slow <- r.probeRepo(ctx, r.Log, secretKey, "", "owner", "slow")
+		}()
+		Eventually(func() int {
+			mu.Lock()
+			defer mu.Unlock()
+			return probes["/repos/owner/slow"]
+		}).Should(Equal(1))
+
+		Expect(r.probeRepo(ctx, r.Log, secretKey, "", "owner", "up").Status).To(Equal(metav1.ConditionTrue))
+		Consistently(slow).ShouldNot(Receive())
+
+		close(release)
+		Eventually(slow).Should(Receive(HaveField("Status", metav1.ConditionTrue)))
+	})
+
+	It("Should write the RepoReachable condition only when it changes", func() {
+		s := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(s)).To(Succeed())
+		Expect(issuev1.AddToScheme(s)).To(Succeed())
+		githubIssue := &issuev1.GithubIssue{
+			ObjectMeta: metav1.ObjectMeta{Name: "probe-resource", Namespace: "default"},
+			Spec:       issuev1.GithubIssueSpec{Repo: "https://github.com/owner/gone", Title: "Test Issue"},
+		}
+		c := fake.NewClientBuilder().WithScheme(s).
+			WithObjects(githubIssue).
+			WithStatusSubresource(githubIssue).
+			Build()
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(githubIssue), githubIssue)).To(Succeed())
+
+		gone := r.probeRepo(ctx, r.Log, secretKey, "", "owner", "gone")
+		Expect(status.UpdateRepoReachable(ctx, c, githubIssue, gone)).To(Succeed())
+		stored := &issuev1.GithubIssue{}
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(githubIssue), stored)).To(Succeed())
+		condition := meta.FindStatusCondition(stored.Status.Conditions, status.RepoReachableCondition)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Reason).To(Equal("RepositoryNotFound"))
+		version := stored.ResourceVersion
+
+		Expect(status.UpdateRepoReachable(ctx, c, githubIssue, r.probeRepo(ctx, r.Log, secretKey, "", "owner", "gone"))).To(Succeed())
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(githubIssue), stored)).To(Succeed())
+		Expect(stored.ResourceVersion).To(Equal(version))
+	})
+})
+
+var _ = Describe("GithubIssue Controller initial comments", func() {
+	ctx := context.Background()
+
+	var (
+		posted      []string
+		failOn      int
+		githubIssue *issuev1.GithubIssue
+		r           *issueReconcile
+	)
+
+	BeforeEach(func() {
+		posted = nil
+		failOn = -1
+		mux := http.NewServeMux()
+		mux.HandleFunc("/repos/owner/repo/issues", func(w http.ResponseWriter, req *http.Request) {
+			if req.Method != http.MethodPost {
+				Expect(json.NewEncoder(w).Encode([]*github.Issue{})).To(Succeed())
+				return
+			}
+			w.WriteHeader(http.StatusCreated)
+			Expect(json.NewEncoder(w).Encode(&github.Issue{Number: github.Int(7), State: github.String("open")})).To(Succeed())
+		})
+		mux.HandleFunc("/repos/owner/repo/issues/7/comments", func(w http.ResponseWriter, req *http.Request) {
+			if req.Method == http.MethodGet {
+				comments := []*github.IssueComment{}
+				for _, body := range posted {
+					comments = append(comments, &github.IssueComment{Body: github.String(body)})
+				}
+				Expect(json.NewEncoder(w).Encode(comments)).To(Succeed())
+				return
+			}
+			if len(posted) == failOn {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			var comment github.IssueComment
+			Expect(json.NewDecoder(req.Body).Decode(&comment)).To(Succeed())
+			posted = append(posted, comment.GetBody())
+			w.WriteHeader(http.StatusCreated)
+			Expect(json.NewEncoder(w).Encode(&comment)).To(Succeed())
+		})
+		githubClient := newGithubServer(mux)
+
+		s := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(s)).To(Succeed())
+		Expect(issuev1.AddToScheme(s)).To(Succeed())
+		githubIssue = &issuev1.GithubIssue{
+			ObjectMeta: metav1.ObjectMeta{Name: "initial-comments-resource", Namespace: "default", UID: "initial-comments-uid"},
+			Spec: issuev1.GithubIssueSpec{
+				Repo:            "https://github.com/owner/repo",
+				Title:           "Test Issue",
+				InitialComments: []string{"runbook", "escalation"},
+			},
+		}
+		c := fake.NewClientBuilder().WithScheme(s).
+			WithObjects(githubIssue).
+			WithStatusSubresource(githubIssue).
+			Build()
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(githubIssue), githubIssue)).To(Succeed())
+		r = &issueReconcile{
+			GithubIssueReconciler: &GithubIssueReconciler{
+				Client: c,
+				Scheme: s,
+				Log:    logr.Discard(),
+			},
+			GithubClient: githubClient,
+		}
+	})
+
+	It("Should record the initial comments as pending along with the created issue", func() {
+		issue, err := r.createIssue(ctx, r.Log, githubIssue, "owner", "repo", "Test Issue", "body", resources.IssueFields{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status.RecordIssueNumber(ctx, r.Client, githubIssue, issue.GetNumber())).To(Succeed())
+		Expect(githubIssue.Status.InitialCommentsPending).To(BeTrue())
+
+		stored := &issuev1.GithubIssue{}
+		Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(githubIssue), stored)).To(Succeed())
+		Expect(stored.Status.IssueNumber).To(Equal(int32(7)))
+		Expect(stored.Status.InitialCommentsPending).To(BeTrue())
+	})
+
+	It("Should not record pending comments for a GithubIssue without any", func() {
+		githubIssue.Spec.InitialComments = nil
+		Expect(status.RecordIssueNumber(ctx, r.Client, githubIssue, 7)).To(Succeed())
+		Expect(githubIssue.Status.InitialCommentsPending).To(BeFalse())
+	})
+
+	It("Should resume posting after a reconcile failed midway, without posting twice", func() {
+		Expect(status.RecordIssueNumber(ctx, r.Client, githubIssue, 7)).To(Succeed())
+
+		// the first reconcile fails after posting the first comment
+		failOn = 1
+		err := r.GithubClient.PostInitialComments(ctx, "owner", "repo", 7, githubIssue.Spec.InitialComments)
+		Expect(err).To(HaveOccurred())
+		Expect(posted).To(HaveLen(1))
+
+		// the next reconcile finds the comments still pending and posts the rest
+		failOn = -1
+		stored := &issuev1.GithubIssue{}
+		Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(githubIssue), stored)).To(Succeed())
+		Expect(stored.Status.InitialCommentsPending).To(BeTrue())
+		Expect(r.GithubClient.PostInitialComments(ctx, "owner", "repo", 7, stored.Spec.InitialComments)).To(Succeed())
+		Expect(posted).To(HaveLen(2))
+		Expect(posted[0]).To(HavePrefix("runbook"))
+		Expect(posted[1]).To(HavePrefix("escalation"))
+
+		// a reconcile whose status update failed after posting everything posts nothing more
+		Expect(r.GithubClient.PostInitialComments(ctx, "owner", "repo", 7, stored.Spec.InitialComments)).To(Succeed())
+		Expect(posted).To(HaveLen(2))
+	})
+})
+
+var _ = Describe("GithubIssue Controller linked resource", func() {
+	ctx := context.Background()
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "linked-resource", Namespace: "default"}}
+
+	var (
+		mu    sync.Mutex
+		issue *github.Issue
+		c     client.Client
+		r     *GithubIssueReconciler
+	)
+
+	// setAvailable sets the available replicas of the linked deployment
+	setAvailable := func(available int32) {
+		deployment := &appsv1.Deployment{}
+		Expect(c.Get(ctx, client.ObjectKey{Name: "web", Namespace: "default"}, deployment)).To(Succeed())
+		deployment.Status = appsv1.DeploymentStatus{
+			ObservedGeneration: deployment.Generation,
+			UpdatedReplicas:    available,
+			AvailableReplicas:  available,
+		}
+		Expect(c.Status().Update(ctx, deployment)).To(Succeed())
+	}
+
+	issueState := func() string {
+		mu.Lock()
+		defer mu.Unlock()
+		return issue.GetState()
+	}
+
+	BeforeEach(func() {
+		issue = &github.Issue{
+			Number: github.Int(4),
+			Title:  github.String("Web is down"),
+			Body:   github.String(utils.AddDedupeMarker("The web deployment is unavailable", "linked-uid")),
+			State:  github.String("open"),
+		}
+		// a GitHub serving the issue, the reconcile only reads it and edits its state
+		mux := http.NewServeMux()
+		mux.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case req.URL.Path == "/repos/owner/repo/issues/4" && req.Method == http.MethodPatch:
+				var edit map[string]any
+				Expect(json.NewDecoder(req.Body).Decode(&edit)).To(Succeed())
+				if state, ok := edit["state"].(string); ok {
+					issue.State = github.String(state)
+				}
+				Expect(json.NewEncoder(w).Encode(issue)).To(Succeed())
+			case req.URL.Path == "/repos/owner/repo/issues/4":
+				Expect(json.NewEncoder(w).Encode(issue)).To(Succeed())
+			case strings.HasSuffix(req.URL.Path, "/comments") && req.Method == http.MethodPost:
+				w.WriteHeader(http.StatusCreated)
+				_, _ = w.Write([]byte(`{"id": 1}`))
+			case req.Method == http.MethodGet || strings.HasSuffix(req.URL.Path, "/labels"):
+				_, _ = w.Write([]byte(`[]`))
+			default:
+				_, _ = w.Write([]byte(`{}`))
+			}
+		})
+		server := newGithubTLSServer(mux)
+
+		s := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(s)).To(Succeed())
+		Expect(issuev1.AddToScheme(s)).To(Succeed())
+		spec := issuev1.GithubIssueSpec{
+			Repo:           "https://github.com/owner/repo",
+			Title:          "Web is down",
+			Description:    "The web deployment is unavailable",
+			APIBaseURL:     server.URL + "/",
+			LinkedResource: &issuev1.LinkedResource{APIVersion: "apps/v1", Kind: "Deployment", Name: "web"},
+		}
+		githubIssue := &issuev1.GithubIssue{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        req.Name,
+				Namespace:   req.Namespace,
+				UID:         "linked-uid",
+				Finalizers:  []string{"finalizer.githubissue.issue.core.github.io"},
+				Annotations: map[string]string{utils.TitleHashAnnotation: utils.IssueHash(spec.Repo, spec.Title)},
+			},
+			Spec:   spec,
+			Status: issuev1.GithubIssueStatus{IssueNumber: 4},
+		}
+		deployment := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec:       appsv1.DeploymentSpec{Replicas: ptr.To[int32](2)},
+		}
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: req.Name + "-token-secret", Namespace: req.Namespace},
+			Data:       map[string][]byte{"token": []byte("ghp_token")},
+		}
+		c = fake.NewClientBuilder().WithScheme(s).
+			WithObjects(githubIssue, deployment, secret).
+			WithStatusSubresource(githubIssue, deployment).
+			Build()
+		r = &GithubIssueReconciler{
+			Client:          c,
+			Scheme:          s,
+			Log:             logr.Discard(),
+			AllowedAPIHosts: []string{"127.0.0.1"},
+		}
+	})
+
+	It("Should close the issue once the linked resource is healthy and reopen it when it fails again", func() {
+		setAvailable(0)
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(issueState()).To(Equal("open"))
+
+		setAvailable(2)
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(issueState()).To(Equal("closed"))
+
+		stored := &issuev1.GithubIssue{}
+		Expect(c.Get(ctx, req.NamespacedName, stored)).To(Succeed())
+		Expect(meta.IsStatusConditionTrue(stored.Status.Conditions, "LinkedResourceHealthy")).To(BeTrue())
+
+		setAvailable(1)
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(issueState()).To(Equal("open"))
+
+		Expect(c.Get(ctx, req.NamespacedName, stored)).To(Succeed())
+		Expect(meta.IsStatusConditionFalse(stored.Status.Conditions, "LinkedResourceHealthy")).To(BeTrue())
+	})
+
+	It("Should not read a linked resource in another namespace", func() {
+		githubIssue := &issuev1.GithubIssue{}
+		Expect(c.Get(ctx, req.NamespacedName, githubIssue)).To(Succeed())
+		githubIssue.Spec.LinkedResource.Namespace = "kube-system"
+		Expect(c.Update(ctx, githubIssue)).To(Succeed())
+		setAvailable(2)
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(issueState()).To(Equal("open"))
+
+		stored := &issuev1.GithubIssue{}
+		Expect(c.Get(ctx, req.NamespacedName, stored)).To(Succeed())
+		condition := meta.FindStatusCondition(stored.Status.Conditions, "LinkedResourceHealthy")
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Reason).To(Equal("ResourceNotAllowed"))
+	})
+})
+
+var _ = Describe("GithubIssue Controller managed section", func() {
+	ctx := context.Background()
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "managed-section", Namespace: "default"}}
+
+	var (
+		mu    sync.Mutex
+		issue *github.Issue
+		c     client.Client
+		r     *GithubIssueReconciler
+	)
+
+	issueBody := func() string {
+		mu.Lock()
+		defer mu.Unlock()
+		return issue.GetBody()
+	}
+
+	// editOnGitHub replaces the live body of the issue, as a human editing it on GitHub
+	editOnGitHub := func(body string) {
+		mu.Lock()
+		defer mu.Unlock()
+		issue.Body = github.String(body)
+	}
+
+	// setDescription changes the description of the GithubIssue
+	setDescription := func(description string) {
+		githubIssue := &issuev1.GithubIssue{}
+		Expect(c.Get(ctx, req.NamespacedName, githubIssue)).To(Succeed())
+		githubIssue.Spec.Description = description
+		Expect(c.Update(ctx, githubIssue)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		issue = &github.Issue{
+			Number: github.Int(4),
+			Title:  github.String("Web is down"),
+			Body:   github.String(utils.RenderManagedSection("", utils.AddDedupeMarker("The web deployment is unavailable", "managed-uid"))),
+			State:  github.String("open"),
+		}
+		// a GitHub serving the issue and applying the edits of its body
+		mux := http.NewServeMux()
+		mux.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case req.URL.Path == "/repos/owner/repo/issues/4" && req.Method == http.MethodPatch:
+				var edit map[string]any
+				Expect(json.NewDecoder(req.Body).Decode(&edit)).To(Succeed())
+				if body, ok := edit["body"].(string); ok {
+					issue.Body = github.String(body)
+				}
+				Expect(json.NewEncoder(w).Encode(issue)).To(Succeed())
+			case req.URL.Path == "/repos/owner/repo/issues/4":
+				Expect(json.NewEncoder(w).Encode(issue)).To(Succeed())
+			case strings.HasSuffix(req.URL.Path, "/comments") && req.Method == http.MethodPost:
+				w.WriteHeader(http.StatusCreated)
+				_, _ = w.Write([]byte(`{"id": 1}`))
+			case req.Method == http.MethodGet || strings.HasSuffix(req.URL.Path, "/labels"):
+				_, _ = w.Write([]byte(`[]`))
+			default:
+				_, _ = w.Write([]byte(`{}`))
+			}
+		})
+		server := newGithubTLSServer(mux)
+
+		s := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(s)).To(Succeed())
+		Expect(issuev1.AddToScheme(s)).To(Succeed())
+		spec := issuev1.GithubIssueSpec{
+			Repo:           "https://github.com/owner/repo",
+			Title:          "Web is down",
+			Description:    "The web deployment is unavailable",
+			APIBaseURL:     server.URL + "/",
+			ManagedSection: true,
+		}
+		githubIssue := &issuev1.GithubIssue{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        req.Name,
+				Namespace:   req.Namespace,
+				UID:         "managed-uid",
+				Finalizers:  []string{"finalizer.githubissue.issue.core.github.io"},
+				Annotations: map[string]string{utils.TitleHashAnnotation: utils.IssueHash(spec.Repo, spec.Title)},
+			},
+			Spec:   spec,
+			Status: issuev1.GithubIssueStatus{IssueNumber: 4},
+		}
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: req.Name + "-token-secret", Namespace: req.Namespace},
+			Data:       map[string][]byte{"token": []byte("ghp_token")},
+		}
+		c = fake.NewClientBuilder().WithScheme(s).
+			WithObjects(githubIssue, secret).
+			WithStatusSubresource(githubIssue).
+			Build()
+		r = &GithubIssueReconciler{
+			Client:          c,
+			Scheme:          s,
+			Log:             logr.Discard(),
+			AllowedAPIHosts: []string{"127.0.0.1"},
+		}
+	})
+
+	It("Should keep the content edited around the managed section", func() {
+		editOnGitHub("Triage notes from the on-call\n\n" + issueBody() + "\n\nFollow-up: check the load balancer")
+		setDescription("The web deployment is down in every zone")
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+
+		body := issueBody()
+		Expect(body).To(HavePrefix("Triage notes from the on-call\n\n<!-- operator:start -->\n"))
+		Expect(body).To(HaveSuffix("<!-- operator:end -->\n\nFollow-up: check the load balancer"))
+		Expect(body).To(ContainSubstring("The web deployment is down in every zone"))
+		Expect(body).NotTo(ContainSubstring("The web deployment is unavailable"))
+
+		// a reconcile without changes leaves the body as it is
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(issueBody()).To(Equal(body))
+	})
+})
+
+var _ = Describe("GithubIssue Controller blocked label", func() {
+	var (
+		mux     *http.ServeMux
+		added   []string
+		removed []string
+		r       *issueReconcile
+	)
+
+	BeforeEach(func() {
+		added, removed = nil, nil
+		mux = http.NewServeMux()
+		mux.HandleFunc("/repos/owner/repo/issues/7", func(w http.ResponseWriter, req *http.Request) {
+			fmt.Fprint(w, `{"number": 7, "state": "open"}`)
+		})
+		recordLabels(mux, 4, &added, &removed)
+		githubClient := newGithubServer(mux)
+		r = &issueReconcile{GithubIssueReconciler: &GithubIssueReconciler{}, GithubClient: githubClient}
+	})
+
+	newGithubIssue := func(labelBlocked bool, blockedBy ...int) *issuev1.GithubIssue {
+		return &issuev1.GithubIssue{
+			ObjectMeta: metav1.ObjectMeta{Name: "blocked-resource", Namespace: "default"},
+			Spec:       issuev1.GithubIssueSpec{BlockedBy: blockedBy, LabelBlocked: labelBlocked},
+		}
+	}
+	labeled := func() *github.Issue {
+		return &github.Issue{Number: github.Int(4), Labels: []*github.Label{{Name: github.String(resources.BlockedLabel)}}}
+	}
+
+	It("Should label the issue while a blocking issue is open", func() {
+		condition, err := r.reconcileBlocked(logr.Discard(), "owner", "repo", newGithubIssue(true, 7), &github.Issue{Number: github.Int(4)})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Type).To(Equal("Blocked"))
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(added).To(Equal([]string{resources.BlockedLabel}))
+		Expect(removed).To(BeEmpty())
+	})
+
+	It("Should remove the label once the blocking issues are cleared", func() {
+		condition, err := r.reconcileBlocked(logr.Discard(), "owner", "repo", newGithubIssue(true), labeled())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(condition).To(BeNil())
+		Expect(removed).To(Equal([]string{resources.BlockedLabel}))
+		Expect(added).To(BeEmpty())
+	})
+
+	It("Should remove the label once it is turned off", func() {
+		_, err := r.reconcileBlocked(logr.Discard(), "owner", "repo", newGithubIssue(false, 7), labeled())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(removed).To(Equal([]string{resources.BlockedLabel}))
+		Expect(added).To(BeEmpty())
+	})
+
+	It("Should not fail when the label is already gone", func() {
+		mux.HandleFunc("/repos/owner/repo/issues/4/labels/blocked", func(w http.ResponseWriter, req *http.Request) {
+			removed = append(removed, resources.BlockedLabel)
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message": "Label does not exist"}`)
+		})
+		_, err := r.reconcileBlocked(logr.Discard(), "owner", "repo", newGithubIssue(false), labeled())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(removed).To(Equal([]string{resources.BlockedLabel}))
+	})
+
+	It("Should not call GitHub for an unblocked issue without the label", func() {
+		condition, err := r.reconcileBlocked(logr.Discard(), "owner", "repo", newGithubIssue(false), &github.Issue{Number: github.Int(4)})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(condition).To(BeNil())
+		Expect(added).To(BeEmpty())
+		Expect(removed).To(BeEmpty())
+	})
+})
+
+var _ = Describe("GithubIssue Controller category", func() {
+	var (
+		added   []string
+		removed []string
+		r       *issueReconcile
+	)
+
+	BeforeEach(func() {
+		added, removed = nil, nil
+		mux := http.NewServeMux()
+		recordLabels(mux, 4, &added, &removed)
+		githubClient := newGithubServer(mux)
+
+		r = &issueReconcile{
+			GithubIssueReconciler: &GithubIssueReconciler{
+				CategoryLabels: map[string]string{"bug": "bug", "feature": "enhancement", "chore": "chore"},
+			},
+			GithubClient: githubClient,
+		}
+	})
+
+	It("Should attach the label mapped to the category", func() {
+		Expect(r.reconcileCategoryLabel("owner", "repo", &github.Issue{Number: github.Int(4)}, "feature")).To(Succeed())
+		Expect(added).To(Equal([]string{"enhancement"}))
+		Expect(removed).To(BeEmpty())
+	})
+
+	It("Should swap the label of the previous category for the current one", func() {
+		issue := &github.Issue{Number: github.Int(4), Labels: []*github.Label{{Name: github.String("bug")}}}
+		Expect(r.reconcileCategoryLabel("owner", "repo", issue, "chore")).To(Succeed())
+		Expect(added).To(Equal([]string{"chore"}))
+		Expect(removed).To(Equal([]string{"bug"}))
+	})
+
+	It("Should remove the category label once the category is cleared", func() {
+		issue := &github.Issue{Number: github.Int(4), Labels: []*github.Label{{Name: github.String("enhancement")}}}
+		Expect(r.reconcileCategoryLabel("owner", "repo", issue, "")).To(Succeed())
+		Expect(added).To(BeEmpty())
+		Expect(removed).To(Equal([]string{"enhancement"}))
+	})
+
+	It("Should fail for a category without a label", func() {
+		Expect(r.reconcileCategoryLabel("owner", "repo", &github.Issue{Number: github.Int(4)}, "docs")).To(MatchError(ContainSubstring("docs")))
+	})
+})
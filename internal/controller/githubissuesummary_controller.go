@@ -0,0 +1,113 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"github.com/go-logr/logr"
+	"github.com/oshribelay/github-issue-operator/internal/controller/summary"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	issuev1 "github.com/oshribelay/github-issue-operator/api/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// GithubIssueSummaryReconciler maintains the cluster-wide GithubIssueSummary. It counts every
+// GithubIssue whatever the label selector of the instance, so a single instance runs it.
+type GithubIssueSummaryReconciler struct {
+	Client client.Client
+	Scheme *runtime.Scheme
+	Log    logr.Logger
+}
+
+// +kubebuilder:rbac:groups=issue.core.github.io,resources=githubissuesummaries,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=issue.core.github.io,resources=githubissuesummaries/status,verbs=get;update;patch
+
+// Reconcile rebuilds the summary from the GithubIssues in the cluster its spec selects
+func (r *GithubIssueSummaryReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := r.Log.WithValues("githubissuesummary", req.Name)
+
+	// only the operator's own summary is maintained
+	if req.Name != issuev1.GithubIssueSummaryName {
+		return ctrl.Result{}, nil
+	}
+
+	githubIssues := &issuev1.GithubIssueList{}
+	if err := r.Client.List(ctx, githubIssues); err != nil {
+		log.Error(err, "unable to list GithubIssues")
+		return ctrl.Result{}, err
+	}
+
+	// create the summary the first time around
+	githubIssueSummary := &issuev1.GithubIssueSummary{}
+	if err := r.Client.Get(ctx, client.ObjectKey{Name: issuev1.GithubIssueSummaryName}, githubIssueSummary); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+		githubIssueSummary.Name = issuev1.GithubIssueSummaryName
+		if err := r.Client.Create(ctx, githubIssueSummary); err != nil {
+			log.Error(err, "unable to create GithubIssueSummary")
+			return ctrl.Result{}, err
+		}
+	}
+
+	summaryStatus := summary.Build(githubIssueSummary.Spec, githubIssues.Items)
+	summaryStatus.LastUpdated = githubIssueSummary.Status.LastUpdated
+	if equality.Semantic.DeepEqual(summaryStatus, githubIssueSummary.Status) {
+		return ctrl.Result{}, nil
+	}
+
+	summaryStatus.LastUpdated = metav1.Now()
+	githubIssueSummary.Status = summaryStatus
+	if err := r.Client.Status().Update(ctx, githubIssueSummary); err != nil {
+		if apierrors.IsConflict(err) {
+			return ctrl.Result{Requeue: true}, nil
+		}
+		log.Error(err, "unable to update GithubIssueSummary status")
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager. The summary is rebuilt when its
+// spec changes and when a GithubIssue changes in a way the summary reports, e.g. its state,
+// rather than on every status write of the GithubIssue controller.
+func (r *GithubIssueSummaryReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&issuev1.GithubIssueSummary{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&issuev1.GithubIssue{}, handler.EnqueueRequestsFromMapFunc(
+			func(ctx context.Context, obj client.Object) []reconcile.Request {
+				return []reconcile.Request{{NamespacedName: client.ObjectKey{Name: issuev1.GithubIssueSummaryName}}}
+			}), builder.WithPredicates(predicate.Funcs{
+			UpdateFunc: func(e event.UpdateEvent) bool {
+				oldIssue, ok := e.ObjectOld.(*issuev1.GithubIssue)
+				newIssue, newOk := e.ObjectNew.(*issuev1.GithubIssue)
+				return !ok || !newOk || summary.Affects(oldIssue, newIssue)
+			},
+		})).
+		Complete(r)
+}
@@ -0,0 +1,154 @@
+package linked
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	v1 "github.com/oshribelay/github-issue-operator/api/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// defaultConditionType is the condition checked when the LinkedResource doesn't specify one
+const defaultConditionType = "Ready"
+
+// deploymentGroupKind is the kind whose health is read from its replica counts
+var deploymentGroupKind = schema.GroupKind{Group: "apps", Kind: "Deployment"}
+
+// DefaultKinds are the kinds a GithubIssue can link to, the operator's RBAC only grants
+// reading these
+var DefaultKinds = []schema.GroupKind{
+	{Group: "apps", Kind: "Deployment"},
+	{Group: "apps", Kind: "StatefulSet"},
+	{Group: "apps", Kind: "DaemonSet"},
+	{Group: "batch", Kind: "Job"},
+	{Group: "", Kind: "Pod"},
+}
+
+// ParseKinds parses a comma-separated list of kinds in the "Kind.group" form, e.g.
+// "Certificate.cert-manager.io", a core kind having no group
+func ParseKinds(list string) ([]schema.GroupKind, error) {
+	var kinds []schema.GroupKind
+	for _, kind := range strings.Split(list, ",") {
+		kind = strings.TrimSpace(kind)
+		if kind == "" {
+			continue
+		}
+		groupKind := schema.ParseGroupKind(kind)
+		if groupKind.Kind == "" {
+			return nil, fmt.Errorf("%q is not a kind", kind)
+		}
+		kinds = append(kinds, groupKind)
+	}
+	return kinds, nil
+}
+
+// Check returns why the GithubIssue mustn't read its linked resource: the resource must be
+// in the namespace of the GithubIssue and of one of the kinds, so a GithubIssue can't make
+// the operator read objects its author can't
+func Check(githubIssue *v1.GithubIssue, kinds []schema.GroupKind) error {
+	linked := githubIssue.Spec.LinkedResource
+	if linked.Namespace != "" && linked.Namespace != githubIssue.Namespace {
+		return fmt.Errorf("linked resource must be in the namespace %s of the GithubIssue, not %s", githubIssue.Namespace, linked.Namespace)
+	}
+	groupKind := GroupVersionKind(linked).GroupKind()
+	for _, kind := range kinds {
+		if kind == groupKind {
+			return nil
+		}
+	}
+	return fmt.Errorf("linked resources of kind %s aren't allowed", groupKind)
+}
+
+// GroupVersionKind returns the GVK of the linked resource
+func GroupVersionKind(linked *v1.LinkedResource) schema.GroupVersionKind {
+	return schema.FromAPIVersionAndKind(linked.APIVersion, linked.Kind)
+}
+
+// Key returns the namespaced name of the resource linked to the GithubIssue
+func Key(githubIssue *v1.GithubIssue) client.ObjectKey {
+	linked := githubIssue.Spec.LinkedResource
+	namespace := linked.Namespace
+	if namespace == "" {
+		namespace = githubIssue.Namespace
+	}
+	return client.ObjectKey{Namespace: namespace, Name: linked.Name}
+}
+
+// IsHealthy fetches the resource linked to the GithubIssue and reports whether its
+// configured condition is "True". A Deployment checked for the default condition is
+// healthy once all of its replicas are available.
+func IsHealthy(ctx context.Context, c client.Client, githubIssue *v1.GithubIssue) (bool, error) {
+	linked := githubIssue.Spec.LinkedResource
+	if linked == nil {
+		return false, fmt.Errorf("GithubIssue %s has no linked resource", githubIssue.Name)
+	}
+
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(GroupVersionKind(linked))
+	if err := c.Get(ctx, Key(githubIssue), obj); err != nil {
+		return false, err
+	}
+
+	conditionType := linked.ConditionType
+	if conditionType == "" {
+		conditionType = defaultConditionType
+	}
+	if conditionType == defaultConditionType && obj.GroupVersionKind().GroupKind() == deploymentGroupKind {
+		return deploymentHealthy(obj), nil
+	}
+
+	return conditionHealthy(obj, conditionType), nil
+}
+
+// References reports whether the GithubIssue links to the given object
+func References(githubIssue *v1.GithubIssue, obj client.Object, gvk schema.GroupVersionKind) bool {
+	linked := githubIssue.Spec.LinkedResource
+	if linked == nil || GroupVersionKind(linked) != gvk {
+		return false
+	}
+	return Key(githubIssue) == client.ObjectKeyFromObject(obj)
+}
+
+// conditionHealthy looks up conditionType in the object's status conditions, a missing
+// condition is treated as unhealthy
+func conditionHealthy(obj *unstructured.Unstructured, conditionType string) bool {
+	conditions, found, err := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	if err != nil || !found {
+		return false
+	}
+
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if condition["type"] == conditionType {
+			return condition["status"] == "True"
+		}
+	}
+
+	return false
+}
+
+// deploymentHealthy reports whether the deployment rolled out its current generation with
+// all of its replicas available
+func deploymentHealthy(obj *unstructured.Unstructured) bool {
+	replicas, found, err := unstructured.NestedInt64(obj.Object, "spec", "replicas")
+	if err != nil {
+		return false
+	}
+	if !found {
+		// the API server defaults the replicas to 1
+		replicas = 1
+	}
+	observedGeneration, _, _ := unstructured.NestedInt64(obj.Object, "status", "observedGeneration")
+	if observedGeneration < obj.GetGeneration() {
+		return false
+	}
+	available, _, _ := unstructured.NestedInt64(obj.Object, "status", "availableReplicas")
+	updated, _, _ := unstructured.NestedInt64(obj.Object, "status", "updatedReplicas")
+	return available >= replicas && updated >= replicas
+}
@@ -0,0 +1,14 @@
+package linked
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestLinked(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecs(t, "Linked Suite")
+}
@@ -0,0 +1,190 @@
+package linked
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "github.com/oshribelay/github-issue-operator/api/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newTarget(status string) *unstructured.Unstructured {
+	target := &unstructured.Unstructured{}
+	target.SetAPIVersion("example.com/v1")
+	target.SetKind("Widget")
+	target.SetName("widget")
+	target.SetNamespace("default")
+	Expect(unstructured.SetNestedSlice(target.Object, []interface{}{
+		map[string]interface{}{"type": "Available", "status": status},
+	}, "status", "conditions")).To(Succeed())
+	return target
+}
+
+func newDeployment(replicas, available int64) *unstructured.Unstructured {
+	deployment := &unstructured.Unstructured{}
+	deployment.SetAPIVersion("apps/v1")
+	deployment.SetKind("Deployment")
+	deployment.SetName("web")
+	deployment.SetNamespace("default")
+	Expect(unstructured.SetNestedField(deployment.Object, replicas, "spec", "replicas")).To(Succeed())
+	setDeploymentStatus(deployment, available)
+	return deployment
+}
+
+func setDeploymentStatus(deployment *unstructured.Unstructured, available int64) {
+	Expect(unstructured.SetNestedMap(deployment.Object, map[string]interface{}{
+		"observedGeneration": deployment.GetGeneration(),
+		"updatedReplicas":    available,
+		"availableReplicas":  available,
+	}, "status")).To(Succeed())
+}
+
+var _ = Describe("Linked resource health", func() {
+	ctx := context.Background()
+	githubIssue := &v1.GithubIssue{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-resource", Namespace: "default"},
+		Spec: v1.GithubIssueSpec{
+			LinkedResource: &v1.LinkedResource{
+				APIVersion:    "example.com/v1",
+				Kind:          "Widget",
+				Name:          "widget",
+				ConditionType: "Available",
+			},
+		},
+	}
+
+	var c client.Client
+
+	BeforeEach(func() {
+		c = fake.NewClientBuilder().WithScheme(runtime.NewScheme()).Build()
+	})
+
+	It("Should follow the target flipping between unhealthy and healthy", func() {
+		target := newTarget("False")
+		Expect(c.Create(ctx, target)).To(Succeed())
+
+		healthy, err := IsHealthy(ctx, c, githubIssue)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(healthy).To(BeFalse())
+
+		Expect(unstructured.SetNestedSlice(target.Object, []interface{}{
+			map[string]interface{}{"type": "Available", "status": "True"},
+		}, "status", "conditions")).To(Succeed())
+		Expect(c.Update(ctx, target)).To(Succeed())
+
+		healthy, err = IsHealthy(ctx, c, githubIssue)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(healthy).To(BeTrue())
+	})
+
+	It("Should treat a missing condition as unhealthy", func() {
+		target := newTarget("True")
+		Expect(unstructured.SetNestedSlice(target.Object, []interface{}{}, "status", "conditions")).To(Succeed())
+		Expect(c.Create(ctx, target)).To(Succeed())
+
+		healthy, err := IsHealthy(ctx, c, githubIssue)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(healthy).To(BeFalse())
+	})
+
+	It("Should return a not found error when the target doesn't exist", func() {
+		_, err := IsHealthy(ctx, c, githubIssue)
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("Should match only the referenced object", func() {
+		target := newTarget("True")
+		Expect(References(githubIssue, target, target.GroupVersionKind())).To(BeTrue())
+
+		other := newTarget("True")
+		other.SetName("other")
+		Expect(References(githubIssue, other, other.GroupVersionKind())).To(BeFalse())
+	})
+
+	Context("When linked to a Deployment", func() {
+		deploymentIssue := &v1.GithubIssue{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-resource", Namespace: "default"},
+			Spec: v1.GithubIssueSpec{
+				LinkedResource: &v1.LinkedResource{
+					APIVersion:    "apps/v1",
+					Kind:          "Deployment",
+					Name:          "web",
+					ConditionType: "Ready",
+				},
+			},
+		}
+
+		It("Should become healthy once all replicas are available", func() {
+			deployment := newDeployment(3, 1)
+			Expect(c.Create(ctx, deployment)).To(Succeed())
+
+			healthy, err := IsHealthy(ctx, c, deploymentIssue)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(healthy).To(BeFalse())
+
+			setDeploymentStatus(deployment, 3)
+			Expect(c.Status().Update(ctx, deployment)).To(Succeed())
+
+			healthy, err = IsHealthy(ctx, c, deploymentIssue)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(healthy).To(BeTrue())
+		})
+
+		It("Should be unhealthy until the rollout of its generation is observed", func() {
+			deployment := newDeployment(2, 2)
+			deployment.SetGeneration(2)
+			Expect(unstructured.SetNestedField(deployment.Object, int64(1), "status", "observedGeneration")).To(Succeed())
+			Expect(c.Create(ctx, deployment)).To(Succeed())
+
+			healthy, err := IsHealthy(ctx, c, deploymentIssue)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(healthy).To(BeFalse())
+		})
+
+		It("Should return a not found error once the Deployment is deleted", func() {
+			deployment := newDeployment(1, 1)
+			Expect(c.Create(ctx, deployment)).To(Succeed())
+			Expect(c.Delete(ctx, deployment)).To(Succeed())
+
+			_, err := IsHealthy(ctx, c, deploymentIssue)
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+	})
+})
+
+var _ = Describe("Linked resource check", func() {
+	newIssue := func(linked *v1.LinkedResource) *v1.GithubIssue {
+		return &v1.GithubIssue{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-resource", Namespace: "default"},
+			Spec:       v1.GithubIssueSpec{LinkedResource: linked},
+		}
+	}
+
+	It("Should allow the kinds in the namespace of the GithubIssue", func() {
+		Expect(Check(newIssue(&v1.LinkedResource{APIVersion: "apps/v1", Kind: "Deployment", Name: "web"}), DefaultKinds)).To(Succeed())
+		Expect(Check(newIssue(&v1.LinkedResource{APIVersion: "v1", Kind: "Pod", Name: "web", Namespace: "default"}), DefaultKinds)).To(Succeed())
+	})
+
+	It("Should deny a resource in another namespace", func() {
+		err := Check(newIssue(&v1.LinkedResource{APIVersion: "apps/v1", Kind: "Deployment", Name: "web", Namespace: "kube-system"}), DefaultKinds)
+		Expect(err).To(MatchError(ContainSubstring("namespace default")))
+	})
+
+	It("Should deny kinds that aren't allowed", func() {
+		Expect(Check(newIssue(&v1.LinkedResource{APIVersion: "v1", Kind: "Secret", Name: "token"}), DefaultKinds)).NotTo(Succeed())
+		Expect(Check(newIssue(&v1.LinkedResource{APIVersion: "example.com/v1", Kind: "Widget", Name: "widget"}), DefaultKinds)).NotTo(Succeed())
+	})
+
+	It("Should parse extra kinds", func() {
+		kinds, err := ParseKinds("Certificate.cert-manager.io, ConfigMap")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(kinds).To(Equal([]schema.GroupKind{{Group: "cert-manager.io", Kind: "Certificate"}, {Kind: "ConfigMap"}}))
+	})
+})
@@ -0,0 +1,38 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// TimeToCreate is how long it took from creating a GithubIssue until its GitHub issue number
+// was recorded, including the time spent waiting for a token
+var TimeToCreate = prometheus.NewHistogram(prometheus.HistogramOpts{
+	Name: "githubissue_time_to_create_seconds",
+	Help: "Time from the creation of a GithubIssue until its GitHub issue number is recorded.",
+	// from seconds for a seeded token up to days for a token entered by hand
+	Buckets: prometheus.ExponentialBuckets(1, 4, 10),
+})
+
+// SLABreaches counts the open issues that outlived their SLA max age
+var SLABreaches = prometheus.NewCounter(prometheus.CounterOpts{
+	Name: "githubissue_sla_breaches_total",
+	Help: "Number of open issues that outlived their SLA max age.",
+})
+
+func init() {
+	metrics.Registry.MustRegister(TimeToCreate, SLABreaches)
+}
+
+// ObserveTimeToCreate records the time from the creation of a GithubIssue until its issue
+// number was recorded at the given time
+func ObserveTimeToCreate(created, recorded time.Time) {
+	TimeToCreate.Observe(recorded.Sub(created).Seconds())
+}
+
+// ObserveSLABreach records an issue that just outlived its SLA max age
+func ObserveSLABreach() {
+	SLABreaches.Inc()
+}
@@ -0,0 +1,58 @@
+package metrics
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// timeToCreate returns the number and sum of the time-to-create observations
+func timeToCreate() (uint64, float64) {
+	families, err := metrics.Registry.Gather()
+	Expect(err).NotTo(HaveOccurred())
+	for _, family := range families {
+		if family.GetName() == "githubissue_time_to_create_seconds" {
+			histogram := family.GetMetric()[0].GetHistogram()
+			return histogram.GetSampleCount(), histogram.GetSampleSum()
+		}
+	}
+	return 0, 0
+}
+
+// slaBreaches returns the number of SLA breaches counted so far
+func slaBreaches() float64 {
+	families, err := metrics.Registry.Gather()
+	Expect(err).NotTo(HaveOccurred())
+	for _, family := range families {
+		if family.GetName() == "githubissue_sla_breaches_total" {
+			return family.GetMetric()[0].GetCounter().GetValue()
+		}
+	}
+	return 0
+}
+
+var _ = Describe("Time to create", func() {
+	It("Should observe the time from creation until the issue number is recorded", func() {
+		countBefore, sumBefore := timeToCreate()
+
+		created := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+		ObserveTimeToCreate(created, created.Add(90*time.Second))
+
+		count, sum := timeToCreate()
+		Expect(count).To(Equal(countBefore + 1))
+		Expect(sum - sumBefore).To(BeNumerically("~", 90))
+	})
+})
+
+var _ = Describe("SLA breaches", func() {
+	It("Should count every breach", func() {
+		before := slaBreaches()
+
+		ObserveSLABreach()
+		ObserveSLABreach()
+
+		Expect(slaBreaches() - before).To(Equal(2.0))
+	})
+})
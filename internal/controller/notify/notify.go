@@ -0,0 +1,154 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/oshribelay/github-issue-operator/internal/repourl"
+)
+
+// The events notified of
+const (
+	EventCreated = "created"
+	EventClosed  = "closed"
+)
+
+// DeliveryIDHeader is the header carrying the delivery ID, the same for every retry of a delivery
+const DeliveryIDHeader = "X-Delivery-ID"
+
+// ErrHostNotAllowed is returned for notify URLs whose host the notifier isn't allowed to post to
+var ErrHostNotAllowed = errors.New("notify URL host is not allowed")
+
+// Payload is the JSON posted to the notify URL
+type Payload struct {
+	Event  string `json:"event"`
+	Repo   string `json:"repo"`
+	Number int    `json:"number"`
+	URL    string `json:"url"`
+	Title  string `json:"title"`
+
+	// DeliveryID identifies the event of the issue, receivers drop the deliveries they had
+	DeliveryID string `json:"deliveryID"`
+}
+
+// DeliveryID returns the ID of the delivery of the event of the issue numbered number, uid
+// being the UID of its GithubIssue
+func DeliveryID(uid string, number int, event string) string {
+	return fmt.Sprintf("%s/%d/%s", uid, number, event)
+}
+
+// Event returns the event to notify of for the issue state, given the state last notified
+// of, empty when there's nothing new. A reopened issue isn't notified of.
+func Event(notifiedState, state string) string {
+	switch {
+	case notifiedState == "" && state == "open":
+		return EventCreated
+	case state == "closed" && notifiedState != "closed":
+		return EventClosed
+	}
+	return ""
+}
+
+// Notifier posts the payloads to the notify URLs, retrying failed deliveries
+type Notifier struct {
+	// Client sends the requests, defaults to a client timing out after 5 seconds that doesn't
+	// follow redirects
+	Client *http.Client
+
+	// AllowedHosts are the hosts the notify URLs may point at, matched with or without their
+	// port. No notification is sent without any.
+	AllowedHosts []string
+
+	// Timeout bounds a delivery, its retries included, defaults to 5 seconds
+	Timeout time.Duration
+
+	// Attempts is how many times a delivery is tried, defaults to 3
+	Attempts int
+
+	// Backoff is how long to wait before the first retry, doubled for every next one,
+	// defaults to a second
+	Backoff time.Duration
+}
+
+// defaultClient sends the notifications of notifiers without a client. A redirect is taken
+// as the response, it could point anywhere.
+var defaultClient = &http.Client{
+	Timeout: 5 * time.Second,
+	CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+// Send posts the payload to the URL, retrying failures other than the URL rejecting it
+// until the timeout
+func (n *Notifier) Send(ctx context.Context, url string, payload Payload) error {
+	if !repourl.HostAllowed(url, n.AllowedHosts) {
+		return ErrHostNotAllowed
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification: %w", err)
+	}
+
+	timeout := n.Timeout
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	attempts, backoff := n.Attempts, n.Backoff
+	if attempts <= 0 {
+		attempts = 3
+	}
+	if backoff <= 0 {
+		backoff = time.Second
+	}
+	for attempt := 1; ; attempt++ {
+		retry, err := n.post(ctx, url, payload.DeliveryID, body)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt == attempts {
+			return err
+		}
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		backoff *= 2
+	}
+}
+
+// post posts the body once, reporting whether a failure is worth retrying
+func (n *Notifier) post(ctx context.Context, url, deliveryID string, body []byte) (retry bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("failed to create notification request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(DeliveryIDHeader, deliveryID)
+
+	client := n.Client
+	if client == nil {
+		client = defaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return true, fmt.Errorf("failed to send notification: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	// the URL rejecting the payload won't change its mind, unless it's throttling
+	retry = resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+	return retry, fmt.Errorf("notification rejected with status %d", resp.StatusCode)
+}
@@ -0,0 +1,95 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Notifier", func() {
+	ctx := context.Background()
+	payload := Payload{Event: EventCreated, Repo: "owner/repo", Number: 4, URL: "https://github.com/owner/repo/issues/4", Title: "Disk full", DeliveryID: "uid/4/created"}
+	notifier := &Notifier{Attempts: 3, Backoff: time.Millisecond, AllowedHosts: []string{"127.0.0.1"}}
+
+	// serve answers the notifications with the statuses in turn, the last one repeated
+	serve := func(statuses ...int) (*httptest.Server, *[]Payload) {
+		var received []Payload
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var p Payload
+			Expect(json.NewDecoder(r.Body).Decode(&p)).To(Succeed())
+			Expect(r.Header.Get("Content-Type")).To(Equal("application/json"))
+			Expect(r.Header.Get(DeliveryIDHeader)).To(Equal(p.DeliveryID))
+			received = append(received, p)
+			w.WriteHeader(statuses[min(len(received), len(statuses))-1])
+		}))
+		DeferCleanup(server.Close)
+		return server, &received
+	}
+
+	It("Should post the payload", func() {
+		server, received := serve(http.StatusOK)
+		Expect(notifier.Send(ctx, server.URL, payload)).To(Succeed())
+		Expect(*received).To(Equal([]Payload{payload}))
+	})
+
+	It("Should retry a failed delivery", func() {
+		server, received := serve(http.StatusBadGateway, http.StatusNoContent)
+		Expect(notifier.Send(ctx, server.URL, payload)).To(Succeed())
+		Expect(*received).To(HaveLen(2))
+	})
+
+	It("Should give up after the attempts", func() {
+		server, received := serve(http.StatusServiceUnavailable)
+		Expect(notifier.Send(ctx, server.URL, payload)).To(MatchError(ContainSubstring("status 503")))
+		Expect(*received).To(HaveLen(3))
+	})
+
+	It("Should not retry a rejected payload", func() {
+		server, received := serve(http.StatusBadRequest)
+		Expect(notifier.Send(ctx, server.URL, payload)).To(MatchError(ContainSubstring("status 400")))
+		Expect(*received).To(HaveLen(1))
+	})
+
+	It("Should not post to a host that isn't allowed", func() {
+		server, received := serve(http.StatusOK)
+		other := &Notifier{AllowedHosts: []string{"hooks.example.com"}}
+		Expect(other.Send(ctx, server.URL, payload)).To(MatchError(ErrHostNotAllowed))
+		Expect((&Notifier{}).Send(ctx, server.URL, payload)).To(MatchError(ErrHostNotAllowed))
+		Expect(*received).To(BeEmpty())
+	})
+
+	It("Should not follow redirects", func() {
+		target, received := serve(http.StatusOK)
+		redirect := httptest.NewServer(http.RedirectHandler(target.URL, http.StatusTemporaryRedirect))
+		DeferCleanup(redirect.Close)
+		Expect(notifier.Send(ctx, redirect.URL, payload)).To(MatchError(ContainSubstring("status 307")))
+		Expect(*received).To(BeEmpty())
+	})
+
+	It("Should give up once the timeout is up", func() {
+		server, _ := serve(http.StatusServiceUnavailable)
+		slow := &Notifier{Attempts: 10, Backoff: time.Hour, Timeout: 50 * time.Millisecond, AllowedHosts: []string{"127.0.0.1"}}
+		started := time.Now()
+		Expect(slow.Send(ctx, server.URL, payload)).To(MatchError(context.DeadlineExceeded))
+		Expect(time.Since(started)).To(BeNumerically("<", time.Second))
+	})
+})
+
+var _ = Describe("Event", func() {
+	DescribeTable("the event to notify of",
+		func(notifiedState, state, event string) {
+			Expect(Event(notifiedState, state)).To(Equal(event))
+		},
+		Entry("a created issue", "", "open", EventCreated),
+		Entry("an issue closed since", "open", "closed", EventClosed),
+		Entry("an issue adopted closed", "", "closed", EventClosed),
+		Entry("an issue still open", "open", "open", ""),
+		Entry("an issue still closed", "closed", "closed", ""),
+		Entry("a reopened issue", "closed", "open", ""),
+	)
+})
@@ -0,0 +1,79 @@
+package resources
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/go-github/v47/github"
+	"net/http"
+)
+
+// ErrInsufficientScope is returned when the token isn't allowed to manage security advisories
+var ErrInsufficientScope = errors.New("token lacks the scope to manage security advisories")
+
+// SecurityAdvisory is a repository security advisory as returned by GitHub
+type SecurityAdvisory struct {
+	GHSAID  string `json:"ghsa_id"`
+	State   string `json:"state"`
+	HTMLURL string `json:"html_url"`
+}
+
+// advisoryRequest is the request creating or editing a repository security advisory
+type advisoryRequest struct {
+	Summary         *string        `json:"summary,omitempty"`
+	Description     *string        `json:"description,omitempty"`
+	Severity        *string        `json:"severity,omitempty"`
+	State           *string        `json:"state,omitempty"`
+	Vulnerabilities *[]interface{} `json:"vulnerabilities,omitempty"`
+}
+
+// CreateAdvisory creates a draft security advisory in the specified repo
+func (g *GithubClient) CreateAdvisory(ctx context.Context, owner, repo, summary, description, severity string) (*SecurityAdvisory, error) {
+	vulnerabilities := []interface{}{}
+	request := &advisoryRequest{
+		Summary:         &summary,
+		Description:     &description,
+		Severity:        &severity,
+		Vulnerabilities: &vulnerabilities,
+	}
+
+	advisory, err := g.sendAdvisory(ctx, http.MethodPost, fmt.Sprintf("repos/%s/%s/security-advisories", owner, repo), request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create security advisory: %w", err)
+	}
+
+	return advisory, nil
+}
+
+// CloseAdvisory closes the draft security advisory
+func (g *GithubClient) CloseAdvisory(ctx context.Context, owner, repo, ghsaID string) error {
+	state := "closed"
+	request := &advisoryRequest{State: &state}
+
+	if _, err := g.sendAdvisory(ctx, http.MethodPatch, fmt.Sprintf("repos/%s/%s/security-advisories/%s", owner, repo, ghsaID), request); err != nil {
+		return fmt.Errorf("failed to close security advisory: %w", err)
+	}
+
+	return nil
+}
+
+// sendAdvisory sends a security advisory request, go-github has no support for the endpoint yet
+func (g *GithubClient) sendAdvisory(ctx context.Context, method, url string, request *advisoryRequest) (*SecurityAdvisory, error) {
+	req, err := g.client.NewRequest(method, url, request)
+	if err != nil {
+		return nil, err
+	}
+
+	advisory := &SecurityAdvisory{}
+	if _, err := g.client.Do(ctx, req, advisory); err != nil {
+		// GitHub hides the endpoint from tokens that can't manage advisories
+		var errResp *github.ErrorResponse
+		if errors.As(err, &errResp) && errResp.Response != nil && (errResp.Response.StatusCode == http.StatusForbidden ||
+			errResp.Response.StatusCode == http.StatusNotFound) {
+			return nil, fmt.Errorf("%w: %v", ErrInsufficientScope, err)
+		}
+		return nil, err
+	}
+
+	return advisory, nil
+}
@@ -0,0 +1,68 @@
+package resources
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Security advisories", func() {
+	var (
+		mux    *http.ServeMux
+		server *httptest.Server
+		g      *GithubClient
+	)
+
+	BeforeEach(func() {
+		mux = http.NewServeMux()
+		server = httptest.NewServer(mux)
+		g = newTestGithubClient(server)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("Should draft an advisory and return its GHSA ID", func() {
+		var request map[string]interface{}
+		mux.HandleFunc("/repos/owner/repo/security-advisories", func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.Method).To(Equal(http.MethodPost))
+			Expect(json.NewDecoder(r.Body).Decode(&request)).To(Succeed())
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"ghsa_id": "GHSA-abcd-efgh-ijkl", "state": "draft"}`)
+		})
+
+		advisory, err := g.CreateAdvisory(context.Background(), "owner", "repo", "summary", "description", "high")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(advisory.GHSAID).To(Equal("GHSA-abcd-efgh-ijkl"))
+		Expect(request).To(HaveKeyWithValue("summary", "summary"))
+		Expect(request).To(HaveKeyWithValue("severity", "high"))
+		Expect(request).To(HaveKeyWithValue("vulnerabilities", BeEmpty()))
+	})
+
+	It("Should report a token lacking the advisory scope", func() {
+		mux.HandleFunc("/repos/owner/repo/security-advisories", func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, `{"message": "Resource not accessible by personal access token"}`, http.StatusForbidden)
+		})
+
+		_, err := g.CreateAdvisory(context.Background(), "owner", "repo", "summary", "description", "high")
+		Expect(err).To(MatchError(ErrInsufficientScope))
+	})
+
+	It("Should close a draft advisory", func() {
+		var request map[string]interface{}
+		mux.HandleFunc("/repos/owner/repo/security-advisories/GHSA-abcd-efgh-ijkl", func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.Method).To(Equal(http.MethodPatch))
+			Expect(json.NewDecoder(r.Body).Decode(&request)).To(Succeed())
+			fmt.Fprint(w, `{"ghsa_id": "GHSA-abcd-efgh-ijkl", "state": "closed"}`)
+		})
+
+		Expect(g.CloseAdvisory(context.Background(), "owner", "repo", "GHSA-abcd-efgh-ijkl")).To(Succeed())
+		Expect(request).To(Equal(map[string]interface{}{"state": "closed"}))
+	})
+})
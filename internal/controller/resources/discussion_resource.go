@@ -0,0 +1,64 @@
+package resources
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/go-github/v47/github"
+	"net/http"
+	"strings"
+)
+
+// discussionQuery looks up the discussion of a number, which an issue converted to a
+// discussion keeps
+const discussionQuery = `query($owner: String!, $repo: String!, $number: Int!) {
+  repository(owner: $owner, name: $repo) { discussion(number: $number) { url } }
+}`
+
+// IsGone reports whether GitHub answered the request with 410, as it does for deleted issues
+func IsGone(err error) bool {
+	var errResp *github.ErrorResponse
+	return errors.As(err, &errResp) && errResp.Response != nil && errResp.Response.StatusCode == http.StatusGone
+}
+
+// graphQLURL returns the GraphQL endpoint of the server the client talks to, GitHub
+// Enterprise Server serves it under /api/graphql next to the /api/v3 REST API
+func (g *GithubClient) graphQLURL() string {
+	baseURL := *g.client.BaseURL
+	if strings.HasSuffix(baseURL.Path, "/api/v3/") {
+		baseURL.Path = strings.TrimSuffix(baseURL.Path, "v3/") + "graphql"
+		return baseURL.String()
+	}
+	return baseURL.String() + "graphql"
+}
+
+// DiscussionURL returns the URL of the discussion with the number of an issue GitHub no
+// longer serves, telling an issue converted to a discussion apart from a deleted one. It
+// returns an empty string when there's no such discussion.
+func (g *GithubClient) DiscussionURL(ctx context.Context, owner, repo string, number int) (string, error) {
+	req, err := g.client.NewRequest("POST", g.graphQLURL(), map[string]interface{}{
+		"query":     discussionQuery,
+		"variables": map[string]interface{}{"owner": owner, "repo": repo, "number": number},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	response := struct {
+		Data struct {
+			Repository struct {
+				Discussion *struct {
+					URL string `json:"url"`
+				} `json:"discussion"`
+			} `json:"repository"`
+		} `json:"data"`
+	}{}
+	if _, err := g.client.Do(ctx, req, &response); err != nil {
+		return "", fmt.Errorf("failed to look up discussion #%d: %w", number, err)
+	}
+	// a missing discussion comes back as null along with a NOT_FOUND error
+	if response.Data.Repository.Discussion == nil {
+		return "", nil
+	}
+	return response.Data.Repository.Discussion.URL, nil
+}
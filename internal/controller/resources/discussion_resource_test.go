@@ -0,0 +1,81 @@
+package resources
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Discussions", func() {
+	var (
+		mux    *http.ServeMux
+		server *httptest.Server
+	)
+
+	BeforeEach(func() {
+		mux = http.NewServeMux()
+		server = httptest.NewServer(mux)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	Context("When looking up the discussion of an issue", func() {
+		It("Should return the URL of the discussion the issue was converted to", func() {
+			mux.HandleFunc("/graphql", func(w http.ResponseWriter, r *http.Request) {
+				var request struct {
+					Variables map[string]interface{} `json:"variables"`
+				}
+				Expect(json.NewDecoder(r.Body).Decode(&request)).To(Succeed())
+				Expect(request.Variables).To(HaveKeyWithValue("number", BeNumerically("==", 4)))
+				fmt.Fprint(w, `{"data": {"repository": {"discussion": {"url": "https://github.com/owner/repo/discussions/4"}}}}`)
+			})
+
+			discussionURL, err := newTestGithubClient(server).DiscussionURL(context.Background(), "owner", "repo", 4)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(discussionURL).To(Equal("https://github.com/owner/repo/discussions/4"))
+		})
+
+		It("Should return no URL for a deleted issue", func() {
+			mux.HandleFunc("/graphql", func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, `{"data": {"repository": {"discussion": null}}, "errors": [{"type": "NOT_FOUND"}]}`)
+			})
+
+			discussionURL, err := newTestGithubClient(server).DiscussionURL(context.Background(), "owner", "repo", 4)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(discussionURL).To(BeEmpty())
+		})
+	})
+
+	Context("When resolving the GraphQL endpoint", func() {
+		It("Should use the endpoint of GitHub Enterprise Server next to its REST API", func() {
+			baseURL, err := url.Parse("https://github.example.com/api/v3/")
+			Expect(err).NotTo(HaveOccurred())
+			g := NewGithubClient("token", WithBaseURL(baseURL))
+			Expect(g.graphQLURL()).To(Equal("https://github.example.com/api/graphql"))
+		})
+
+		It("Should use the endpoint of github.com", func() {
+			Expect(NewGithubClient("token").graphQLURL()).To(Equal("https://api.github.com/graphql"))
+		})
+	})
+
+	Context("When GitHub no longer serves an issue", func() {
+		It("Should tell a gone issue apart", func() {
+			mux.HandleFunc("/repos/owner/repo/issues/4", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusGone)
+			})
+
+			_, err := newTestGithubClient(server).GetIssue(context.Background(), "owner", "repo", 4)
+			Expect(IsGone(err)).To(BeTrue())
+			Expect(IsNotFound(err)).To(BeFalse())
+		})
+	})
+})
@@ -0,0 +1,83 @@
+package resources
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v47/github"
+)
+
+// RateLimitedError is a 403 for the exhausted primary rate limit of the token
+type RateLimitedError struct {
+	// Reset is when the rate limit resets
+	Reset time.Time
+	Err   error
+}
+
+func (e *RateLimitedError) Error() string {
+	return fmt.Sprintf("rate limit exhausted until %s: %v", e.Reset.Format(time.RFC3339), e.Err)
+}
+
+func (e *RateLimitedError) Unwrap() error { return e.Err }
+
+// ScopeInsufficientError is a 403 for a token lacking the scopes or permissions the request needs
+type ScopeInsufficientError struct {
+	// Missing are the required scopes a classic token lacks, empty for fine-grained tokens
+	// and apps, which don't report their permissions
+	Missing []string
+	Err     error
+}
+
+func (e *ScopeInsufficientError) Error() string {
+	if len(e.Missing) > 0 {
+		return fmt.Sprintf("token is missing scopes %s: %v", strings.Join(e.Missing, ", "), e.Err)
+	}
+	return fmt.Sprintf("token lacks the permissions for the request: %v", e.Err)
+}
+
+func (e *ScopeInsufficientError) Unwrap() error { return e.Err }
+
+// AccessDeniedError is a 403 for a resource the token can't access, e.g. in an organization
+// enforcing SSO or blocking the user
+type AccessDeniedError struct {
+	Err error
+}
+
+func (e *AccessDeniedError) Error() string {
+	return fmt.Sprintf("access denied: %v", e.Err)
+}
+
+func (e *AccessDeniedError) Unwrap() error { return e.Err }
+
+// ClassifyForbidden tells apart why GitHub answered the request with 403, through the rate
+// limit and scopes headers and the message of the response. It returns a RateLimitedError,
+// ScopeInsufficientError or AccessDeniedError wrapping err, and err as is for other errors,
+// including the secondary rate limit.
+func ClassifyForbidden(err error) error {
+	var rateErr *github.RateLimitError
+	if errors.As(err, &rateErr) {
+		return &RateLimitedError{Reset: rateErr.Rate.Reset.Time, Err: err}
+	}
+
+	var errResp *github.ErrorResponse
+	if !errors.As(err, &errResp) || errResp.Response == nil || errResp.Response.StatusCode != http.StatusForbidden {
+		return err
+	}
+
+	if rate, ok := parseRate(errResp.Response); ok && rate.Remaining == 0 {
+		return &RateLimitedError{Reset: rate.Reset.Time, Err: err}
+	}
+	if scopes, ok := parseScopes(errResp.Response); ok {
+		if missing := MissingScopes(scopes); len(missing) > 0 {
+			return &ScopeInsufficientError{Missing: missing, Err: err}
+		}
+	}
+	// fine-grained tokens and apps answer this for permissions they weren't granted
+	if strings.HasPrefix(errResp.Message, "Resource not accessible by") {
+		return &ScopeInsufficientError{Err: err}
+	}
+	return &AccessDeniedError{Err: err}
+}
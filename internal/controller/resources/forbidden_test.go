@@ -0,0 +1,102 @@
+package resources
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ClassifyForbidden", func() {
+	ctx := context.Background()
+
+	var (
+		mux    *http.ServeMux
+		server *httptest.Server
+		g      *GithubClient
+	)
+
+	BeforeEach(func() {
+		mux = http.NewServeMux()
+		server = httptest.NewServer(mux)
+		g = newTestGithubClient(server)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	// getIssueError answers the issue request with the status, headers and message, and
+	// returns the classified error
+	getIssueError := func(statusCode int, headers map[string]string, message string) error {
+		mux.HandleFunc("/repos/owner/repo/issues/1", func(w http.ResponseWriter, r *http.Request) {
+			for key, value := range headers {
+				w.Header().Set(key, value)
+			}
+			w.WriteHeader(statusCode)
+			fmt.Fprintf(w, `{"message": %q}`, message)
+		})
+		_, err := g.GetIssue(ctx, "owner", "repo", 1)
+		Expect(err).To(HaveOccurred())
+		return ClassifyForbidden(err)
+	}
+
+	It("Should tell apart an exhausted rate limit", func() {
+		reset := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+		err := getIssueError(http.StatusForbidden, map[string]string{
+			"X-RateLimit-Limit":     "5000",
+			"X-RateLimit-Remaining": "0",
+			"X-RateLimit-Reset":     fmt.Sprint(reset.Unix()),
+		}, "API rate limit exceeded")
+
+		var rateErr *RateLimitedError
+		Expect(errors.As(err, &rateErr)).To(BeTrue())
+		Expect(rateErr.Reset.Equal(reset)).To(BeTrue())
+	})
+
+	It("Should tell apart a classic token missing scopes", func() {
+		err := getIssueError(http.StatusForbidden, map[string]string{"X-OAuth-Scopes": "read:org, gist"}, "Forbidden")
+
+		var scopeErr *ScopeInsufficientError
+		Expect(errors.As(err, &scopeErr)).To(BeTrue())
+		Expect(scopeErr.Missing).To(Equal([]string{"repo"}))
+	})
+
+	It("Should tell apart a fine-grained token lacking permissions", func() {
+		err := getIssueError(http.StatusForbidden, nil, "Resource not accessible by personal access token")
+
+		var scopeErr *ScopeInsufficientError
+		Expect(errors.As(err, &scopeErr)).To(BeTrue())
+		Expect(scopeErr.Missing).To(BeEmpty())
+	})
+
+	It("Should report other 403s as access denied", func() {
+		err := getIssueError(http.StatusForbidden, map[string]string{
+			"X-OAuth-Scopes":        "repo",
+			"X-RateLimit-Remaining": "4999",
+			"X-RateLimit-Reset":     "1714564800",
+		}, "Resource protected by organization SAML enforcement")
+
+		var deniedErr *AccessDeniedError
+		Expect(errors.As(err, &deniedErr)).To(BeTrue())
+	})
+
+	It("Should return other errors as is", func() {
+		err := getIssueError(http.StatusInternalServerError, nil, "Server Error")
+
+		var (
+			rateErr   *RateLimitedError
+			scopeErr  *ScopeInsufficientError
+			deniedErr *AccessDeniedError
+		)
+		Expect(errors.As(err, &rateErr)).To(BeFalse())
+		Expect(errors.As(err, &scopeErr)).To(BeFalse())
+		Expect(errors.As(err, &deniedErr)).To(BeFalse())
+		Expect(ClassifyForbidden(nil)).To(BeNil())
+	})
+})
@@ -2,51 +2,407 @@ package resources
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/google/go-github/v47/github"
+	"github.com/oshribelay/github-issue-operator/internal/controller/utils"
+	"github.com/oshribelay/github-issue-operator/internal/issueform"
 	"golang.org/x/oauth2"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
 )
 
+// BlockedLabel is the label marking an issue as blocked by other open issues
+const BlockedLabel = "blocked"
+
+// HasPRLabel is the label marking an issue linked to a pull request
+const HasPRLabel = "has-pr"
+
+// AtRiskLabel is the label marking an issue whose milestone is about to be due
+const AtRiskLabel = "at-risk"
+
+// OpIDLabel returns the label marking the issue created for the GithubIssue of the UID, which
+// identifies the issue however its title changes
+func OpIDLabel(uid string) string {
+	return "op-id:" + uid
+}
+
+// Version is the operator version reported to GitHub, it is set at build time via -ldflags
+var Version = "dev"
+
+// VersionLabelPrefix prefixes the label recording the operator version managing the issue
+const VersionLabelPrefix = "operator-version:"
+
+// VersionLabel returns the label recording the operator version managing the issue
+func VersionLabel() string {
+	return VersionLabelPrefix + Version
+}
+
 // GithubClient is a wrapper for the GitHub client
 type GithubClient struct {
 	client *github.Client
+
+	// ownedOnly restricts existence checks to issues carrying the operator marker
+	ownedOnly bool
+
+	// titleEmojis are the emojis existence checks ignore in front of the titles
+	titleEmojis []string
+
+	// titleMatch is how existence checks match the titles, exact when empty
+	titleMatch TitleMatch
+
+	// pageSize is how many issues existence checks list per page, GitHub's default when zero
+	pageSize int
+
+	// recorder keeps the rate limit and token scopes GitHub reported with the latest response
+	recorder *responseRecorder
+
+	// serverVersion is the GitHub Enterprise Server version gating the features used,
+	// empty for github.com
+	serverVersion string
+}
+
+// Option configures the GitHub client created by NewGithubClient
+type Option func(*GithubClient)
+
+// WithUserAgent sets the User-Agent header sent with every GitHub request
+func WithUserAgent(userAgent string) Option {
+	return func(g *GithubClient) {
+		g.client.UserAgent = userAgent
+	}
+}
+
+// WithOwnedOnly makes existence checks ignore issues that weren't created by the operator,
+// i.e. issues whose body doesn't carry the operator marker
+func WithOwnedOnly(ownedOnly bool) Option {
+	return func(g *GithubClient) {
+		g.ownedOnly = ownedOnly
+	}
+}
+
+// WithTitleEmojis makes existence checks ignore the emojis in front of the titles, so an
+// issue is still found after its severity, and with it its title emoji, changed
+func WithTitleEmojis(emojis []string) Option {
+	return func(g *GithubClient) {
+		g.titleEmojis = emojis
+	}
+}
+
+// TitleMatch is how existence checks match the title of an issue against the title of its
+// GithubIssue. The loose strategies find issues whose titles bots suffixed, at the risk of
+// adopting an unrelated issue whose title merely starts with or contains the title sought.
+// They only apply to adopting an issue, the one to close is always matched exactly.
+type TitleMatch string
+
+const (
+	// TitleMatchExact matches the titles that are the same
+	TitleMatchExact TitleMatch = "exact"
+	// TitleMatchPrefix matches the titles starting with the title sought
+	TitleMatchPrefix TitleMatch = "prefix"
+	// TitleMatchContains matches the titles containing the title sought
+	TitleMatchContains TitleMatch = "contains"
+)
+
+// matches reports whether the title matches the title sought, exactly or loosely
+func (m TitleMatch) matches(title, sought string) (matched, exact bool) {
+	switch {
+	case title == sought:
+		return true, true
+	case m == TitleMatchPrefix:
+		return strings.HasPrefix(title, sought), false
+	case m == TitleMatchContains:
+		return strings.Contains(title, sought), false
+	}
+	return false, false
+}
+
+// WithTitleMatch sets how existence checks match the titles, an exact match is preferred
+// over a loose one when several issues match
+func WithTitleMatch(titleMatch TitleMatch) Option {
+	return func(g *GithubClient) {
+		g.titleMatch = titleMatch
+	}
+}
+
+// WithPageSize sets how many issues existence checks list per page, up to GitHub's maximum of 100
+func WithPageSize(pageSize int) Option {
+	return func(g *GithubClient) {
+		g.pageSize = pageSize
+	}
+}
+
+// WithBaseURL sends the GitHub requests to the given API base URL instead of api.github.com,
+// e.g. an API gateway proxying GitHub under a path prefix
+func WithBaseURL(baseURL *url.URL) Option {
+	return func(g *GithubClient) {
+		g.client.BaseURL = baseURL
+	}
+}
+
+// ParseBaseURL parses a GitHub API base URL, it must be an https URL and gets the
+// trailing slash the GitHub client requires
+func ParseBaseURL(s string) (*url.URL, error) {
+	baseURL, err := url.Parse(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid API base URL %q: %w", s, err)
+	}
+	if baseURL.Scheme != "https" || baseURL.Host == "" {
+		return nil, fmt.Errorf("invalid API base URL %q: must be an https URL", s)
+	}
+	if !strings.HasSuffix(baseURL.Path, "/") {
+		baseURL.Path += "/"
+	}
+	return baseURL, nil
+}
+
+// UserAgent returns the operator's User-Agent, "github-issue-operator/<version>",
+// followed by the suffix when one is given
+func UserAgent(suffix string) string {
+	userAgent := "github-issue-operator/" + Version
+	if suffix != "" {
+		userAgent += " " + suffix
+	}
+	return userAgent
 }
 
 // NewGithubClient initializes a new GitHub client using OAuth2
-func NewGithubClient(token string) *GithubClient {
+func NewGithubClient(token string, opts ...Option) *GithubClient {
 	ts := oauth2.StaticTokenSource(
 		&oauth2.Token{AccessToken: token},
 	)
 
 	tc := oauth2.NewClient(context.Background(), ts)
+	recorder := &responseRecorder{base: tc.Transport}
+	tc.Transport = recorder
 	client := github.NewClient(tc)
+	client.UserAgent = UserAgent("")
+
+	g := &GithubClient{client: client, recorder: recorder}
+	for _, opt := range opts {
+		opt(g)
+	}
+
+	return g
+}
+
+// CheckIssueExists checks if and issue with the same title exists in the repository, preferring
+// the issue labeled with the op-id of the uid when there is one. Titles only match loosely
+// without a recorded issue number, when an existing issue is adopted rather than created.
+func (g *GithubClient) CheckIssueExists(owner, repo, title string, issueNumber int, uid string) (*github.Issue, error) {
+	return g.checkIssueExists(owner, repo, title, issueNumber, uid, issueNumber == 0)
+}
 
-	return &GithubClient{client: client}
+// CheckIssueExistsExact checks if an issue with the same title exists in the repository like
+// CheckIssueExists, never matching titles loosely so an unrelated issue is never closed
+func (g *GithubClient) CheckIssueExistsExact(owner, repo, title string, issueNumber int, uid string) (*github.Issue, error) {
+	return g.checkIssueExists(owner, repo, title, issueNumber, uid, false)
 }
 
-// CheckIssueExists checks if and issue with the same title exists in the repository
-func (g *GithubClient) CheckIssueExists(owner, repo, title string, issueNumber int) (*github.Issue, error) {
-	issues, _, err := g.client.Issues.ListByRepo(context.Background(), owner, repo, nil)
+// checkIssueExists looks for the issue of the title, number or op-id label, matching titles
+// with the title match strategy when loose
+func (g *GithubClient) checkIssueExists(owner, repo, title string, issueNumber int, uid string, loose bool) (*github.Issue, error) {
+	if uid != "" {
+		issue, err := g.findIssueByLabel(context.Background(), owner, repo, OpIDLabel(uid))
+		if err != nil || issue != nil {
+			return issue, err
+		}
+	}
+
+	// look for issue matching the title or number through every page, a new repository has
+	// none to match so the issue gets created. Partial issues without a number never match an
+	// unrecorded number. The first issue matching loosely is only returned when none matches
+	// exactly.
+	title = utils.TrimEmoji(title, g.titleEmojis)
+	var looseMatch *github.Issue
+	opts := &github.IssueListByRepoOptions{ListOptions: github.ListOptions{PerPage: g.pageSize}}
+	for {
+		issues, resp, err := g.client.Issues.ListByRepo(context.Background(), owner, repo, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list issues: %w %s", err, owner)
+		}
+		for _, issue := range issues {
+			if issue == nil || g.ownedOnly && !utils.HasOperatorMarker(issue.GetBody()) {
+				continue
+			}
+			matched, exact := g.titleMatch.matches(utils.TrimEmoji(issue.GetTitle(), g.titleEmojis), title)
+			if exact || issueNumber > 0 && issue.GetNumber() == issueNumber {
+				return issue, nil
+			}
+			if loose && matched && looseMatch == nil {
+				looseMatch = issue
+			}
+		}
+		if resp.NextPage == 0 {
+			return looseMatch, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// findIssueByLabel returns the issue carrying the label, open or closed, nil when none does
+func (g *GithubClient) findIssueByLabel(ctx context.Context, owner, repo, label string) (*github.Issue, error) {
+	opts := &github.IssueListByRepoOptions{
+		State:       "all",
+		Labels:      []string{label},
+		ListOptions: github.ListOptions{PerPage: g.pageSize},
+	}
+	for {
+		issues, resp, err := g.client.Issues.ListByRepo(ctx, owner, repo, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list issues labeled %s: %w", label, err)
+		}
+		for _, issue := range issues {
+			if issue != nil && HasLabel(issue, label) {
+				return issue, nil
+			}
+		}
+		if resp.NextPage == 0 {
+			return nil, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// FindIssueByMarker looks for the issue whose body carries the marker among the most recently
+// created issues of the repository, it returns nil when none does
+func (g *GithubClient) FindIssueByMarker(ctx context.Context, owner, repo, marker string) (*github.Issue, error) {
+	issues, _, err := g.client.Issues.ListByRepo(ctx, owner, repo, &github.IssueListByRepoOptions{
+		State:       "all",
+		Sort:        "created",
+		Direction:   "desc",
+		ListOptions: github.ListOptions{PerPage: 100},
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to list issues: %w %s", err, owner)
+		return nil, fmt.Errorf("failed to list issues: %w", err)
 	}
 
-	// look for issue matching the title or number
 	for _, issue := range issues {
-		if issue.GetTitle() == title || issue.GetNumber() == issueNumber {
+		if strings.Contains(issue.GetBody(), marker) {
 			return issue, nil
 		}
 	}
-
 	return nil, nil
 }
 
+// RepoExists probes the repository, telling a deleted repository apart from a missing issue
+// since GitHub answers both with 404
+func (g *GithubClient) RepoExists(ctx context.Context, owner, repo string) (bool, error) {
+	_, _, err := g.client.Repositories.Get(ctx, owner, repo)
+	if IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get repository %s/%s: %w", owner, repo, err)
+	}
+	return true, nil
+}
+
+// IssueForm returns the definition of the issue form of the repository, nil when the
+// repository has no such form
+func (g *GithubClient) IssueForm(ctx context.Context, owner, repo, name string) ([]byte, error) {
+	file, _, _, err := g.client.Repositories.GetContents(ctx, owner, repo, path.Join(issueform.Dir, name), nil)
+	if IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get issue form %s of %s/%s: %w", name, owner, repo, err)
+	}
+	if file == nil {
+		// the path is a directory
+		return nil, nil
+	}
+	content, err := file.GetContent()
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode issue form %s of %s/%s: %w", name, owner, repo, err)
+	}
+	return []byte(content), nil
+}
+
+// IsNotFound reports whether GitHub answered the request with 404
+func IsNotFound(err error) bool {
+	var errResp *github.ErrorResponse
+	return errors.As(err, &errResp) && errResp.Response != nil && errResp.Response.StatusCode == http.StatusNotFound
+}
+
+// IsBodyTooLarge reports whether GitHub refused the issue with 422 as its body is too long
+func IsBodyTooLarge(err error) bool {
+	var errResp *github.ErrorResponse
+	if !errors.As(err, &errResp) || errResp.Response == nil || errResp.Response.StatusCode != http.StatusUnprocessableEntity {
+		return false
+	}
+	for _, e := range errResp.Errors {
+		if e.Field == "body" && strings.Contains(e.Message, "too long") {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultUnavailableRetryAfter is how long to wait for GitHub to be back when a 503 doesn't
+// say when to retry
+const DefaultUnavailableRetryAfter = time.Minute
+
+// ServiceUnavailable reports whether GitHub answered the request with 503, as it does during
+// maintenance, and how long it asked to wait through the Retry-After header, in seconds or
+// as a date
+func ServiceUnavailable(err error, now time.Time) (retryAfter time.Duration, ok bool) {
+	var errResp *github.ErrorResponse
+	if !errors.As(err, &errResp) || errResp.Response == nil || errResp.Response.StatusCode != http.StatusServiceUnavailable {
+		return 0, false
+	}
+
+	header := errResp.Response.Header.Get("Retry-After")
+	if seconds, err := strconv.Atoi(header); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(header); err == nil && date.After(now) {
+		return date.Sub(now), true
+	}
+	return DefaultUnavailableRetryAfter, true
+}
+
+// GetIssue fetches the issue by number, it returns nil when the issue doesn't exist
+func (g *GithubClient) GetIssue(ctx context.Context, owner, repo string, number int) (*github.Issue, error) {
+	issue, _, err := g.client.Issues.Get(ctx, owner, repo, number)
+	if IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get issue #%d: %w", number, err)
+	}
+
+	return issue, nil
+}
+
+// IssueFields are the optional fields set on the issue along with its title and body
+type IssueFields struct {
+	// Assignees are the full set of logins the issue is assigned to, nil leaves the assignees
+	// picked on GitHub alone while an empty set unassigns everyone
+	Assignees []string
+	Labels    []string
+	Milestone int
+}
+
 // CreateIssue creates a new GitHub issue in the specified repo
-func (g *GithubClient) CreateIssue(owner, repo, title, description string) (*github.Issue, error) {
+func (g *GithubClient) CreateIssue(owner, repo, title, description string, fields IssueFields) (*github.Issue, error) {
 	newIssue := &github.IssueRequest{
 		Title: &title,
 		Body:  &description,
 	}
+	if len(fields.Assignees) > 0 {
+		newIssue.Assignees = &fields.Assignees
+	}
+	if len(fields.Labels) > 0 {
+		newIssue.Labels = &fields.Labels
+	}
+	if fields.Milestone > 0 {
+		newIssue.Milestone = &fields.Milestone
+	}
 	createdIssue, _, err := g.client.Issues.Create(context.Background(), owner, repo, newIssue)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create issue: %w", err)
@@ -55,18 +411,47 @@ func (g *GithubClient) CreateIssue(owner, repo, title, description string) (*git
 	return createdIssue, nil
 }
 
-func (g *GithubClient) UpdateIssue(owner, repo string, issue *github.Issue, description, title string) (*github.Issue, error) {
+// UpdateIssue edits the issue to match the title, description and fields, it doesn't
+// call GitHub at all when the issue already matches them
+func (g *GithubClient) UpdateIssue(owner, repo string, issue *github.Issue, description, title string, fields IssueFields) (*github.Issue, error) {
+	if !Drifted(issue, title, description, fields) {
+		return issue, nil
+	}
+
 	// prepare an issue request for updating
 	issueRequest := &github.IssueRequest{
 		Title: &title,
 		Body:  &description,
 	}
+	// leave the assignees and milestone picked on GitHub alone unless they are set, the
+	// assignees are sent in full so the ones left out are unassigned
+	if fields.Assignees != nil {
+		issueRequest.Assignees = &fields.Assignees
+	}
+	if fields.Milestone > 0 {
+		issueRequest.Milestone = &fields.Milestone
+	}
 
-	updatedIssue, _, err := g.client.Issues.Edit(context.Background(), owner, repo, *issue.Number, issueRequest)
+	updatedIssue, _, err := g.client.Issues.Edit(context.Background(), owner, repo, issue.GetNumber(), issueRequest)
 	if err != nil {
 		return nil, fmt.Errorf("failed to update issue: %w", err)
 	}
 
+	// labels are only added, replacing them would drop the labels managed elsewhere
+	var missing []string
+	for _, label := range fields.Labels {
+		if !HasLabel(updatedIssue, label) {
+			missing = append(missing, label)
+		}
+	}
+	if len(missing) > 0 {
+		labels, _, err := g.client.Issues.AddLabelsToIssue(context.Background(), owner, repo, updatedIssue.GetNumber(), missing)
+		if err != nil {
+			return nil, fmt.Errorf("failed to add labels %v: %w", missing, err)
+		}
+		updatedIssue.Labels = labels
+	}
+
 	return updatedIssue, nil
 }
 
@@ -86,3 +471,458 @@ func (g *GithubClient) CloseIssue(owner, repo string, issue *github.Issue) error
 
 	return nil
 }
+
+// CloseAsDuplicate closes the issue as not planned on servers supporting state reasons, for
+// the DuplicateComment posted along with it
+func (g *GithubClient) CloseAsDuplicate(ctx context.Context, owner, repo string, number int) (*github.Issue, error) {
+	closedIssue, err := g.closeWithReason(ctx, owner, repo, number, "not_planned")
+	if err != nil {
+		return nil, fmt.Errorf("failed to close issue as a duplicate: %w", err)
+	}
+	return closedIssue, nil
+}
+
+// DuplicateComment returns the comment GitHub recognizes to mark an issue as a duplicate of another one
+func DuplicateComment(duplicateOf int) string {
+	return fmt.Sprintf("Duplicate of #%d", duplicateOf)
+}
+
+// CloseAsSuperseded closes the issue as not planned, for the SupersededComment posted along with it
+func (g *GithubClient) CloseAsSuperseded(ctx context.Context, owner, repo string, number int) (*github.Issue, error) {
+	closedIssue, err := g.closeWithReason(ctx, owner, repo, number, "not_planned")
+	if err != nil {
+		return nil, fmt.Errorf("failed to close superseded issue: %w", err)
+	}
+	return closedIssue, nil
+}
+
+// SupersededComment returns the comment that an issue is superseded by its successor, an issue
+// reference such as "#12" or "owner/repo#12" GitHub links
+func SupersededComment(successor string) string {
+	return "Superseded by " + successor
+}
+
+// CloseAsMilestoneCompleted closes the issue as completed, for the MilestoneCompletedComment
+// posted along with it
+func (g *GithubClient) CloseAsMilestoneCompleted(ctx context.Context, owner, repo string, number int) (*github.Issue, error) {
+	closedIssue, err := g.closeWithReason(ctx, owner, repo, number, "completed")
+	if err != nil {
+		return nil, fmt.Errorf("failed to close issue of the completed milestone: %w", err)
+	}
+	return closedIssue, nil
+}
+
+// MilestoneCompletedComment returns the comment that an issue is closed as its milestone is complete
+func MilestoneCompletedComment(milestone string) string {
+	return fmt.Sprintf("Closed as milestone %q is complete", milestone)
+}
+
+// MilestoneClosed reports whether the milestone is closed on GitHub
+func (g *GithubClient) MilestoneClosed(ctx context.Context, owner, repo string, number int) (bool, error) {
+	milestone, _, err := g.client.Issues.GetMilestone(ctx, owner, repo, number)
+	if err != nil {
+		return false, fmt.Errorf("failed to get milestone: %w", err)
+	}
+	return milestone.GetState() == "closed", nil
+}
+
+// MilestoneNumber returns the number of the milestone with the title, open or closed, ok is
+// false when the repo has none
+func (g *GithubClient) MilestoneNumber(ctx context.Context, owner, repo, title string) (number int, ok bool, err error) {
+	opts := &github.MilestoneListOptions{State: "all", ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		milestones, resp, err := g.client.Issues.ListMilestones(ctx, owner, repo, opts)
+		if err != nil {
+			return 0, false, fmt.Errorf("failed to list milestones: %w", err)
+		}
+		for _, milestone := range milestones {
+			if milestone.GetTitle() == title {
+				return milestone.GetNumber(), true, nil
+			}
+		}
+		if resp.NextPage == 0 {
+			return 0, false, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// EnsureMilestone returns the number of the milestone with the title, creating it when the
+// repo has none. A milestone created concurrently, e.g. by the reconcile of another
+// GithubIssue, makes GitHub answer 422 already_exists, which is taken as success.
+func (g *GithubClient) EnsureMilestone(ctx context.Context, owner, repo, title string) (int, error) {
+	if number, ok, err := g.MilestoneNumber(ctx, owner, repo, title); err != nil || ok {
+		return number, err
+	}
+
+	milestone, _, err := g.client.Issues.CreateMilestone(ctx, owner, repo, &github.Milestone{Title: &title})
+	if err == nil {
+		return milestone.GetNumber(), nil
+	}
+	if !isAlreadyExists(err) {
+		return 0, fmt.Errorf("failed to create milestone: %w", err)
+	}
+	number, ok, err := g.MilestoneNumber(ctx, owner, repo, title)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return 0, fmt.Errorf("milestone %q already exists but was not found", title)
+	}
+	return number, nil
+}
+
+// isAlreadyExists reports whether GitHub refused to create something with 422 as it exists
+func isAlreadyExists(err error) bool {
+	var errResp *github.ErrorResponse
+	if !errors.As(err, &errResp) || errResp.Response == nil || errResp.Response.StatusCode != http.StatusUnprocessableEntity {
+		return false
+	}
+	for _, e := range errResp.Errors {
+		if e.Code == "already_exists" {
+			return true
+		}
+	}
+	return false
+}
+
+// closeWithReason closes the issue with the state reason on servers supporting state reasons
+func (g *GithubClient) closeWithReason(ctx context.Context, owner, repo string, number int, stateReason string) (*github.Issue, error) {
+	state := "closed"
+	issueRequest := &github.IssueRequest{State: &state}
+	if g.Supports(FeatureStateReason) {
+		issueRequest.StateReason = &stateReason
+	}
+	closedIssue, _, err := g.client.Issues.Edit(ctx, owner, repo, number, issueRequest)
+	if err != nil {
+		return nil, err
+	}
+	return closedIssue, nil
+}
+
+// ReopenIssue reopens the issue, marking it with the "reopened" state reason so GitHub's
+// timeline reflects it, on servers supporting state reasons
+func (g *GithubClient) ReopenIssue(ctx context.Context, owner, repo string, number int) (*github.Issue, error) {
+	state := "open"
+
+	// prepare the request to reopen the issue
+	issueRequest := &github.IssueRequest{
+		State: &state, // set the issue state back to open
+	}
+	if g.Supports(FeatureStateReason) {
+		stateReason := "reopened"
+		issueRequest.StateReason = &stateReason // record why the issue is open again
+	}
+
+	// reopen the issue with the GitHub client
+	reopenedIssue, _, err := g.client.Issues.Edit(ctx, owner, repo, number, issueRequest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reopen issue: %w", err)
+	}
+
+	return reopenedIssue, nil
+}
+
+// Drifted reports whether the issue differs from the title, description and fields,
+// fields left unset never drift and labels only drift while one is missing
+func Drifted(issue *github.Issue, title, description string, fields IssueFields) bool {
+	if issue.GetTitle() != title || issue.GetBody() != description {
+		return true
+	}
+	if fields.Milestone > 0 && issue.GetMilestone().GetNumber() != fields.Milestone {
+		return true
+	}
+	for _, label := range fields.Labels {
+		if !HasLabel(issue, label) {
+			return true
+		}
+	}
+	return assigneesDiffer(issue, fields.Assignees)
+}
+
+// assigneesDiffer reports whether the issue is assigned to others than the logins, nil logins
+// never differ
+func assigneesDiffer(issue *github.Issue, logins []string) bool {
+	if logins == nil {
+		return false
+	}
+	assigned := map[string]bool{}
+	for _, assignee := range issue.Assignees {
+		assigned[strings.ToLower(assignee.GetLogin())] = true
+	}
+	wanted := map[string]bool{}
+	for _, login := range logins {
+		wanted[strings.ToLower(login)] = true
+	}
+	if len(assigned) != len(wanted) {
+		return true
+	}
+	for login := range wanted {
+		if !assigned[login] {
+			return true
+		}
+	}
+	return false
+}
+
+// MaxDiffSummaryLength bounds the length of the summary DiffSummary returns
+const MaxDiffSummaryLength = 256
+
+// DiffSummary summarizes how the issue differs from the title, description and fields the
+// way Drifted compares them, e.g. "title changed; body +3/-1 lines", empty when it doesn't.
+// Body lines are counted as added or removed regardless of their order, and the summary is
+// cut at MaxDiffSummaryLength.
+func DiffSummary(issue *github.Issue, title, description string, fields IssueFields) string {
+	var changes []string
+	if issue.GetTitle() != title {
+		changes = append(changes, "title changed")
+	}
+	if issue.GetBody() != description {
+		added, removed := lineChanges(issue.GetBody(), description)
+		changes = append(changes, fmt.Sprintf("body +%d/-%d lines", added, removed))
+	}
+	if fields.Milestone > 0 && issue.GetMilestone().GetNumber() != fields.Milestone {
+		changes = append(changes, fmt.Sprintf("milestone set to %d", fields.Milestone))
+	}
+	var missing []string
+	for _, label := range fields.Labels {
+		if !HasLabel(issue, label) {
+			missing = append(missing, label)
+		}
+	}
+	if len(missing) > 0 {
+		changes = append(changes, "labels added: "+strings.Join(missing, ", "))
+	}
+	if assigneesDiffer(issue, fields.Assignees) {
+		changes = append(changes, "assignees changed")
+	}
+
+	summary := strings.Join(changes, "; ")
+	if utf8.RuneCountInString(summary) > MaxDiffSummaryLength {
+		summary = string([]rune(summary)[:MaxDiffSummaryLength-1]) + "…"
+	}
+	return summary
+}
+
+// lineChanges counts the lines of to missing from from as added, and the lines of from
+// missing from to as removed
+func lineChanges(from, to string) (added, removed int) {
+	counts := map[string]int{}
+	for _, line := range strings.Split(from, "\n") {
+		counts[line]++
+	}
+	for _, line := range strings.Split(to, "\n") {
+		if counts[line] > 0 {
+			counts[line]--
+		} else {
+			added++
+		}
+	}
+	for _, count := range counts {
+		removed += count
+	}
+	return added, removed
+}
+
+// CreateComment comments on the issue
+func (g *GithubClient) CreateComment(ctx context.Context, owner, repo string, number int, body string) error {
+	if _, _, err := g.client.Issues.CreateComment(ctx, owner, repo, number, &github.IssueComment{Body: &body}); err != nil {
+		return fmt.Errorf("failed to comment on issue #%d: %w", number, err)
+	}
+
+	return nil
+}
+
+// EnsureComment comments on the issue unless one of its comments already has the body, as
+// posted by an earlier attempt
+func (g *GithubClient) EnsureComment(ctx context.Context, owner, repo string, number int, body string) error {
+	comments, err := g.ListComments(ctx, owner, repo, number)
+	if err != nil {
+		return err
+	}
+	for _, comment := range comments {
+		if comment.GetBody() == body {
+			return nil
+		}
+	}
+	return g.CreateComment(ctx, owner, repo, number, body)
+}
+
+// AppendComment posts the description as a new comment on the issue unless it is the
+// content last posted, whose hash is postedHash, or already the issue body. It returns
+// the hash to record as the content last posted.
+func (g *GithubClient) AppendComment(ctx context.Context, owner, repo string, issue *github.Issue, description, postedHash string) (string, error) {
+	hash := utils.ContentHash(description)
+	if hash == postedHash || utils.ContentHash(issue.GetBody()) == hash {
+		return hash, nil
+	}
+
+	if err := g.CreateComment(ctx, owner, repo, issue.GetNumber(), description); err != nil {
+		return postedHash, err
+	}
+
+	return hash, nil
+}
+
+// PostInitialComments posts the initial comments on the issue in order, each tagged with its
+// marker. The comments already carrying their marker were posted by an earlier attempt and
+// are skipped, so posting can resume after a partial failure.
+func (g *GithubClient) PostInitialComments(ctx context.Context, owner, repo string, number int, comments []string) error {
+	existing, err := g.ListComments(ctx, owner, repo, number)
+	if err != nil {
+		return err
+	}
+	posted := map[int]bool{}
+	for _, comment := range existing {
+		for i := range comments {
+			if strings.Contains(comment.GetBody(), utils.InitialCommentMarker(i)) {
+				posted[i] = true
+			}
+		}
+	}
+	for i, comment := range comments {
+		if posted[i] {
+			continue
+		}
+		if err := g.CreateComment(ctx, owner, repo, number, comment+"\n\n"+utils.InitialCommentMarker(i)); err != nil {
+			return fmt.Errorf("failed to post initial comment %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// ListComments lists every comment of the issue, following the pages
+func (g *GithubClient) ListComments(ctx context.Context, owner, repo string, number int) ([]*github.IssueComment, error) {
+	opts := &github.IssueListCommentsOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	var comments []*github.IssueComment
+	for {
+		page, resp, err := g.client.Issues.ListComments(ctx, owner, repo, number, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list comments of issue #%d: %w", number, err)
+		}
+		comments = append(comments, page...)
+		if resp.NextPage == 0 {
+			return comments, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// LockIssue locks the conversation of the issue with the given reason
+func (g *GithubClient) LockIssue(ctx context.Context, owner, repo string, number int, reason string) error {
+	// lock the issue with the GitHub client
+	if _, err := g.client.Issues.Lock(ctx, owner, repo, number, &github.LockIssueOptions{LockReason: reason}); err != nil {
+		return fmt.Errorf("failed to lock issue #%d: %w", number, err)
+	}
+
+	return nil
+}
+
+// VerifyClosed re-fetches the issue until GitHub reports it closed, giving up after the
+// given number of attempts since a successful close may take a moment to be reflected
+func (g *GithubClient) VerifyClosed(ctx context.Context, owner, repo string, number, attempts int, interval time.Duration) error {
+	for attempt := 1; ; attempt++ {
+		issue, _, err := g.client.Issues.Get(ctx, owner, repo, number)
+		if err != nil {
+			return fmt.Errorf("failed to verify issue #%d is closed: %w", number, err)
+		}
+		if issue.GetState() == "closed" {
+			return nil
+		}
+		if attempt >= attempts {
+			return fmt.Errorf("issue #%d is still open after %d attempts", number, attempts)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+// SyncTrackingIssue keeps the checklist item of the issue in the body of the tracking issue,
+// checked once the issue is closed
+func (g *GithubClient) SyncTrackingIssue(ctx context.Context, owner, repo string, trackingNumber, number int, closed bool) error {
+	tracking, _, err := g.client.Issues.Get(ctx, owner, repo, trackingNumber)
+	if err != nil {
+		return fmt.Errorf("failed to get tracking issue #%d: %w", trackingNumber, err)
+	}
+
+	body, changed := utils.SetTrackingItem(tracking.GetBody(), number, closed)
+	if !changed {
+		return nil
+	}
+
+	if _, _, err := g.client.Issues.Edit(ctx, owner, repo, trackingNumber, &github.IssueRequest{Body: &body}); err != nil {
+		return fmt.Errorf("failed to update tracking issue #%d: %w", trackingNumber, err)
+	}
+
+	return nil
+}
+
+// OpenDependencies fetches the given issues of the repository and returns the numbers
+// of those still open
+func (g *GithubClient) OpenDependencies(owner, repo string, numbers []int) ([]int, error) {
+	var open []int
+	for _, number := range numbers {
+		issue, _, err := g.client.Issues.Get(context.Background(), owner, repo, number)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read dependency #%d: %w", number, err)
+		}
+		if issue.GetState() == "open" {
+			open = append(open, number)
+		}
+	}
+
+	return open, nil
+}
+
+// AddLabel adds the label to the issue
+func (g *GithubClient) AddLabel(owner, repo string, issue *github.Issue, label string) error {
+	if _, _, err := g.client.Issues.AddLabelsToIssue(context.Background(), owner, repo, issue.GetNumber(), []string{label}); err != nil {
+		return fmt.Errorf("failed to add label %s: %w", label, err)
+	}
+
+	return nil
+}
+
+// RemoveLabel removes the label from the issue, a label that is already gone is not an error
+func (g *GithubClient) RemoveLabel(owner, repo string, issue *github.Issue, label string) error {
+	_, err := g.client.Issues.RemoveLabelForIssue(context.Background(), owner, repo, issue.GetNumber(), label)
+	if IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to remove label %s: %w", label, err)
+	}
+
+	return nil
+}
+
+// LinkedPullRequest returns the number of the pull request the issue is linked to, read from
+// its pull request links. ok is false for an issue without a linked pull request.
+func LinkedPullRequest(issue *github.Issue) (number int, ok bool) {
+	if issue.PullRequestLinks == nil {
+		return 0, false
+	}
+	for _, link := range []string{issue.PullRequestLinks.GetHTMLURL(), issue.PullRequestLinks.GetURL()} {
+		if i := strings.LastIndex(link, "/"); i >= 0 {
+			if number, err := strconv.Atoi(link[i+1:]); err == nil {
+				return number, true
+			}
+		}
+	}
+	return issue.GetNumber(), true
+}
+
+// HasLabel checks if the issue carries the label
+func HasLabel(issue *github.Issue, label string) bool {
+	for _, l := range issue.Labels {
+		if l.GetName() == label {
+			return true
+		}
+	}
+
+	return false
+}
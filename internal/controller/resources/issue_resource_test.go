@@ -0,0 +1,1105 @@
+package resources
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"text/template"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/go-github/v47/github"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/oshribelay/github-issue-operator/internal/controller/utils"
+)
+
+// testBaseURL returns the GitHub API base URL served by the test server
+func testBaseURL(server *httptest.Server) *url.URL {
+	baseURL, err := url.Parse(server.URL + "/")
+	Expect(err).NotTo(HaveOccurred())
+	return baseURL
+}
+
+// newTestGithubClient returns a GithubClient sending its requests to the test server
+func newTestGithubClient(server *httptest.Server, opts ...Option) *GithubClient {
+	g := NewGithubClient("token", opts...)
+	g.client.BaseURL = testBaseURL(server)
+	return g
+}
+
+var _ = Describe("GithubClient", func() {
+	var (
+		mux    *http.ServeMux
+		server *httptest.Server
+		g      *GithubClient
+	)
+
+	BeforeEach(func() {
+		mux = http.NewServeMux()
+		server = httptest.NewServer(mux)
+		g = newTestGithubClient(server)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	Context("When sending requests", func() {
+		It("Should carry the configured User-Agent", func() {
+			var userAgent string
+			mux.HandleFunc("/repos/owner/repo/issues/1", func(w http.ResponseWriter, r *http.Request) {
+				userAgent = r.Header.Get("User-Agent")
+				fmt.Fprint(w, `{"number": 1, "state": "open"}`)
+			})
+
+			g := newTestGithubClient(server, WithUserAgent(UserAgent("(cluster=staging)")))
+			_, err := g.OpenDependencies("owner", "repo", []int{1})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(userAgent).To(Equal("github-issue-operator/dev (cluster=staging)"))
+		})
+
+		It("Should default to the operator User-Agent", func() {
+			Expect(NewGithubClient("token").client.UserAgent).To(Equal("github-issue-operator/dev"))
+		})
+	})
+
+	Context("When checking if an issue exists", func() {
+		BeforeEach(func() {
+			mux.HandleFunc("/repos/owner/repo/issues", func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, `[{"number": 1, "title": "Test Issue", "body": "filed by a human"}]`)
+			})
+		})
+
+		It("Should adopt an issue matching the title by default", func() {
+			issue, err := g.CheckIssueExists("owner", "repo", "Test Issue", 0, "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(issue.GetNumber()).To(Equal(1))
+		})
+
+		It("Should not adopt a human issue when restricted to owned issues", func() {
+			g := newTestGithubClient(server, WithOwnedOnly(true))
+			issue, err := g.CheckIssueExists("owner", "repo", "Test Issue", 0, "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(issue).To(BeNil())
+		})
+	})
+
+	Context("When checking if an issue exists in a new repository", func() {
+		It("Should find no issue in an empty list", func() {
+			mux.HandleFunc("/repos/owner/repo/issues", func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, `[]`)
+			})
+			issue, err := g.CheckIssueExists("owner", "repo", "Test Issue", 0, "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(issue).To(BeNil())
+		})
+
+		It("Should find no issue in a null list", func() {
+			mux.HandleFunc("/repos/owner/repo/issues", func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, `null`)
+			})
+			issue, err := g.CheckIssueExists("owner", "repo", "Test Issue", 0, "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(issue).To(BeNil())
+		})
+
+		It("Should skip null and partial issues", func() {
+			mux.HandleFunc("/repos/owner/repo/issues", func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, `[null, {"title": "Other Issue"}]`)
+			})
+			issue, err := g.CheckIssueExists("owner", "repo", "Test Issue", 0, "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(issue).To(BeNil())
+		})
+	})
+
+	Context("When checking if an issue labeled with its op-id exists", func() {
+		var labelQueries []string
+
+		BeforeEach(func() {
+			labelQueries = nil
+			mux.HandleFunc("/repos/owner/repo/issues", func(w http.ResponseWriter, r *http.Request) {
+				labels := r.URL.Query().Get("labels")
+				labelQueries = append(labelQueries, labels)
+				// the labeled issue is closed, so it's only listed when asking for all states
+				if labels == OpIDLabel("uid-1") && r.URL.Query().Get("state") == "all" {
+					fmt.Fprint(w, `[{"number": 5, "title": "Old Title", "state": "closed", "labels": [{"name": "op-id:uid-1"}]}]`)
+					return
+				}
+				fmt.Fprint(w, `[{"number": 6, "title": "New Title"}]`)
+			})
+		})
+
+		It("Should resolve a renamed issue through its op-id label, even once closed", func() {
+			issue, err := g.CheckIssueExists("owner", "repo", "New Title", 0, "uid-1")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(issue.GetNumber()).To(Equal(5))
+			Expect(labelQueries).To(Equal([]string{"op-id:uid-1"}))
+		})
+
+		It("Should fall back to the title without an op-id labeled issue", func() {
+			issue, err := g.CheckIssueExists("owner", "repo", "New Title", 0, "uid-2")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(issue.GetNumber()).To(Equal(6))
+			Expect(labelQueries).To(Equal([]string{"op-id:uid-2", ""}))
+		})
+
+		It("Should not match an issue missing the label the server didn't filter by", func() {
+			mux := http.NewServeMux()
+			mux.HandleFunc("/repos/owner/repo/issues", func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, `[{"number": 6, "title": "New Title"}, {"number": 7, "title": "Other"}]`)
+			})
+			server := httptest.NewServer(mux)
+			defer server.Close()
+
+			issue, err := newTestGithubClient(server).CheckIssueExists("owner", "repo", "New Title", 0, "uid-1")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(issue.GetNumber()).To(Equal(6))
+		})
+	})
+
+	Context("When listing issues to check for existence", func() {
+		var perPage string
+
+		BeforeEach(func() {
+			perPage = ""
+			mux.HandleFunc("/repos/owner/repo/issues", func(w http.ResponseWriter, r *http.Request) {
+				perPage = r.URL.Query().Get("per_page")
+				fmt.Fprint(w, `[]`)
+			})
+		})
+
+		It("Should send the configured page size", func() {
+			g := newTestGithubClient(server, WithPageSize(100))
+			_, err := g.CheckIssueExists("owner", "repo", "Test Issue", 0, "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(perPage).To(Equal("100"))
+		})
+
+		It("Should leave the page size to GitHub by default", func() {
+			_, err := g.CheckIssueExists("owner", "repo", "Test Issue", 0, "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(perPage).To(BeEmpty())
+		})
+	})
+
+	Context("When the issue is listed on a later page", func() {
+		var pages []string
+
+		BeforeEach(func() {
+			pages = nil
+			mux.HandleFunc("/repos/owner/repo/issues", func(w http.ResponseWriter, r *http.Request) {
+				page := r.URL.Query().Get("page")
+				labels := r.URL.Query().Get("labels")
+				pages = append(pages, labels+"@"+page)
+				next := fmt.Sprintf(`<%s/repos/owner/repo/issues?labels=%s&page=%%d>; rel="next"`, server.URL, labels)
+				switch page {
+				case "":
+					w.Header().Set("Link", fmt.Sprintf(next, 2))
+					fmt.Fprint(w, `[{"number": 1, "title": "Other"}]`)
+				case "2":
+					w.Header().Set("Link", fmt.Sprintf(next, 3))
+					if labels != "" {
+						fmt.Fprintf(w, `[{"number": 5, "title": "Old Title", "labels": [{"name": %q}]}]`, labels)
+						return
+					}
+					fmt.Fprint(w, `[{"number": 6, "title": "Test Issue"}]`)
+				default:
+					fmt.Fprint(w, `[{"number": 7, "title": "Test Issue"}]`)
+				}
+			})
+		})
+
+		It("Should find the issue of the title on the second page, without listing further", func() {
+			issue, err := g.CheckIssueExists("owner", "repo", "Test Issue", 0, "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(issue.GetNumber()).To(Equal(6))
+			Expect(pages).To(Equal([]string{"@", "@2"}))
+		})
+
+		It("Should find the issue of the op-id label on the second page", func() {
+			issue, err := g.CheckIssueExists("owner", "repo", "Test Issue", 0, "uid-1")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(issue.GetNumber()).To(Equal(5))
+			Expect(pages).To(Equal([]string{"op-id:uid-1@", "op-id:uid-1@2"}))
+		})
+	})
+
+	Context("When checking if an issue with a severity emoji exists", func() {
+		BeforeEach(func() {
+			mux.HandleFunc("/repos/owner/repo/issues", func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, `[{"number": 3, "title": "🔥 Test Issue"}]`)
+			})
+		})
+
+		It("Should match the title after the severity changed its emoji", func() {
+			g := newTestGithubClient(server, WithTitleEmojis([]string{"⚠️", "🔥"}))
+			issue, err := g.CheckIssueExists("owner", "repo", utils.PrefixEmoji("⚠️", "Test Issue"), 0, "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(issue.GetNumber()).To(Equal(3))
+		})
+
+		It("Should match the title after the emoji was dropped", func() {
+			g := newTestGithubClient(server, WithTitleEmojis([]string{"🔥"}))
+			issue, err := g.CheckIssueExists("owner", "repo", "Test Issue", 0, "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(issue.GetNumber()).To(Equal(3))
+		})
+
+		It("Should not ignore emojis that aren't configured", func() {
+			issue, err := g.CheckIssueExists("owner", "repo", "Test Issue", 0, "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(issue).To(BeNil())
+		})
+	})
+
+	Context("When checking if an issue with a title prefix exists", func() {
+		var prefixed string
+
+		BeforeEach(func() {
+			prefix := template.Must(template.New("prefix").Parse("[{{ .Cluster }}/{{ .Namespace }}] "))
+			var err error
+			prefixed, err = utils.PrefixTitle(prefix, utils.TitlePrefixData{Cluster: "prod", Namespace: "default"}, "Test Issue")
+			Expect(err).NotTo(HaveOccurred())
+
+			mux.HandleFunc("/repos/owner/repo/issues", func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, `[{"number": 2, "title": "[prod/default] Test Issue"}]`)
+			})
+		})
+
+		It("Should match the prefixed title", func() {
+			issue, err := g.CheckIssueExists("owner", "repo", prefixed, 0, "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(issue.GetNumber()).To(Equal(2))
+		})
+
+		It("Should not match the title without its prefix", func() {
+			issue, err := g.CheckIssueExists("owner", "repo", "Test Issue", 0, "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(issue).To(BeNil())
+		})
+
+		It("Should not match the prefix of another namespace", func() {
+			prefix := template.Must(template.New("prefix").Parse("[{{ .Cluster }}/{{ .Namespace }}] "))
+			other, err := utils.PrefixTitle(prefix, utils.TitlePrefixData{Cluster: "prod", Namespace: "team-a"}, "Test Issue")
+			Expect(err).NotTo(HaveOccurred())
+
+			issue, err := g.CheckIssueExists("owner", "repo", other, 0, "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(issue).To(BeNil())
+		})
+	})
+
+	Context("When matching titles with a strategy", func() {
+		BeforeEach(func() {
+			mux.HandleFunc("/repos/owner/repo/issues", func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, `[
+					{"number": 1, "title": "Flaky Test Issue"},
+					{"number": 2, "title": "Test Issue [bot: triaged]"},
+					{"number": 3, "title": "Test Issue"},
+					{"number": 4, "title": "Other Issue (stale)"}
+				]`)
+			})
+		})
+
+		DescribeTable("Should find the issue matching the title",
+			func(titleMatch TitleMatch, title string, expected int) {
+				g := newTestGithubClient(server, WithTitleMatch(titleMatch))
+				issue, err := g.CheckIssueExists("owner", "repo", title, 0, "")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(issue.GetNumber()).To(Equal(expected))
+			},
+			Entry("exact by default", TitleMatch(""), "Test Issue", 3),
+			Entry("exact preferred over a prefix match", TitleMatchPrefix, "Test Issue", 3),
+			Entry("exact preferred over a contained match", TitleMatchContains, "Test Issue", 3),
+			Entry("exact not matching a suffixed title", TitleMatchExact, "Other Issue", 0),
+			Entry("prefix matching a suffixed title", TitleMatchPrefix, "Other Issue", 4),
+			Entry("prefix not matching the middle of a title", TitleMatchPrefix, "Issue (stale)", 0),
+			Entry("contains matching the middle of a title", TitleMatchContains, "Issue (stale)", 4),
+			Entry("contains matching the first issue containing the title", TitleMatchContains, "Test Issue [bot", 2),
+			Entry("contains not matching an absent title", TitleMatchContains, "Missing Issue", 0),
+		)
+
+		It("Should only match loosely without a recorded number", func() {
+			g := newTestGithubClient(server, WithTitleMatch(TitleMatchContains))
+			issue, err := g.CheckIssueExists("owner", "repo", "Issue (stale)", 9, "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(issue).To(BeNil())
+		})
+
+		It("Should never match loosely when looking for the issue to close", func() {
+			g := newTestGithubClient(server, WithTitleMatch(TitleMatchContains))
+			issue, err := g.CheckIssueExistsExact("owner", "repo", "Issue (stale)", 0, "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(issue).To(BeNil())
+
+			issue, err = g.CheckIssueExistsExact("owner", "repo", "Test Issue", 0, "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(issue.GetNumber()).To(Equal(3))
+		})
+
+		It("Should prefer the recorded number over a loose match", func() {
+			g := newTestGithubClient(server, WithTitleMatch(TitleMatchContains))
+			issue, err := g.CheckIssueExists("owner", "repo", "Issue", 2, "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(issue.GetNumber()).To(Equal(2))
+		})
+	})
+
+	Context("When probing the repository", func() {
+		It("Should tell a deleted repository apart", func() {
+			mux.HandleFunc("/repos/owner/deleted", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, `{"message": "Not Found"}`)
+			})
+			mux.HandleFunc("/repos/owner/repo", func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, `{"name": "repo"}`)
+			})
+
+			exists, err := g.RepoExists(context.Background(), "owner", "deleted")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(exists).To(BeFalse())
+
+			exists, err = g.RepoExists(context.Background(), "owner", "repo")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(exists).To(BeTrue())
+		})
+
+		It("Should fail on other errors", func() {
+			mux.HandleFunc("/repos/owner/repo", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+			})
+
+			_, err := g.RepoExists(context.Background(), "owner", "repo")
+			Expect(err).To(HaveOccurred())
+			Expect(IsNotFound(err)).To(BeFalse())
+		})
+	})
+
+	Context("When fetching an issue form", func() {
+		It("Should decode the form of the repository", func() {
+			mux.HandleFunc("/repos/owner/repo/contents/.github/ISSUE_TEMPLATE/bug.yml", func(w http.ResponseWriter, r *http.Request) {
+				content := base64.StdEncoding.EncodeToString([]byte("name: Bug report\n"))
+				fmt.Fprintf(w, `{"type": "file", "encoding": "base64", "content": %q}`, content)
+			})
+
+			form, err := g.IssueForm(context.Background(), "owner", "repo", "bug.yml")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(form)).To(Equal("name: Bug report\n"))
+		})
+
+		It("Should return nothing for a form the repository lacks", func() {
+			mux.HandleFunc("/repos/owner/repo/contents/.github/ISSUE_TEMPLATE/missing.yml", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, `{"message": "Not Found"}`)
+			})
+
+			form, err := g.IssueForm(context.Background(), "owner", "repo", "missing.yml")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(form).To(BeNil())
+		})
+	})
+
+	Context("When listing comments", func() {
+		It("Should follow every page", func() {
+			mux.HandleFunc("/repos/owner/repo/issues/1/comments", func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Query().Get("page") == "2" {
+					fmt.Fprint(w, `[{"id": 3}]`)
+					return
+				}
+				w.Header().Set("Link", fmt.Sprintf(`<%s/repos/owner/repo/issues/1/comments?page=2>; rel="next"`, server.URL))
+				fmt.Fprint(w, `[{"id": 1}, {"id": 2}]`)
+			})
+
+			comments, err := g.ListComments(context.Background(), "owner", "repo", 1)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(comments).To(HaveLen(3))
+		})
+	})
+
+	Context("When ensuring a comment", func() {
+		var posted []string
+
+		BeforeEach(func() {
+			posted = []string{"earlier"}
+			mux.HandleFunc("/repos/owner/repo/issues/1/comments", func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodGet {
+					comments := []map[string]string{}
+					for _, body := range posted {
+						comments = append(comments, map[string]string{"body": body})
+					}
+					Expect(json.NewEncoder(w).Encode(comments)).To(Succeed())
+					return
+				}
+				var comment map[string]string
+				Expect(json.NewDecoder(r.Body).Decode(&comment)).To(Succeed())
+				posted = append(posted, comment["body"])
+				w.WriteHeader(http.StatusCreated)
+				fmt.Fprint(w, `{"id": 1}`)
+			})
+		})
+
+		It("Should post a comment the issue lacks, once", func() {
+			Expect(g.EnsureComment(context.Background(), "owner", "repo", 1, "closed")).To(Succeed())
+			Expect(g.EnsureComment(context.Background(), "owner", "repo", 1, "closed")).To(Succeed())
+			Expect(posted).To(Equal([]string{"earlier", "closed"}))
+		})
+	})
+
+	Context("When posting the initial comments", func() {
+		var posted []string
+		var failOn int
+
+		BeforeEach(func() {
+			posted = nil
+			failOn = -1
+			mux.HandleFunc("/repos/owner/repo/issues/1/comments", func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodGet {
+					comments := []map[string]string{}
+					for _, body := range posted {
+						comments = append(comments, map[string]string{"body": body})
+					}
+					Expect(json.NewEncoder(w).Encode(comments)).To(Succeed())
+					return
+				}
+				var comment map[string]string
+				Expect(json.NewDecoder(r.Body).Decode(&comment)).To(Succeed())
+				if len(posted) == failOn {
+					w.WriteHeader(http.StatusBadGateway)
+					return
+				}
+				posted = append(posted, comment["body"])
+				w.WriteHeader(http.StatusCreated)
+				fmt.Fprint(w, `{"id": 1}`)
+			})
+		})
+
+		It("Should post the comments in order, each with its marker", func() {
+			Expect(g.PostInitialComments(context.Background(), "owner", "repo", 1, []string{"runbook", "escalation"})).To(Succeed())
+			Expect(posted).To(Equal([]string{
+				"runbook\n\n" + utils.InitialCommentMarker(0),
+				"escalation\n\n" + utils.InitialCommentMarker(1),
+			}))
+		})
+
+		It("Should resume after a partial failure without posting a comment twice", func() {
+			comments := []string{"runbook", "escalation", "contacts"}
+			failOn = 1
+			err := g.PostInitialComments(context.Background(), "owner", "repo", 1, comments)
+			Expect(err).To(MatchError(ContainSubstring("failed to post initial comment 1")))
+			Expect(posted).To(HaveLen(1))
+
+			failOn = -1
+			Expect(g.PostInitialComments(context.Background(), "owner", "repo", 1, comments)).To(Succeed())
+			Expect(posted).To(Equal([]string{
+				"runbook\n\n" + utils.InitialCommentMarker(0),
+				"escalation\n\n" + utils.InitialCommentMarker(1),
+				"contacts\n\n" + utils.InitialCommentMarker(2),
+			}))
+
+			// a retry after every comment was posted posts nothing
+			Expect(g.PostInitialComments(context.Background(), "owner", "repo", 1, comments)).To(Succeed())
+			Expect(posted).To(HaveLen(3))
+		})
+	})
+
+	Context("When GitHub refuses the body", func() {
+		It("Should tell apart a body too long", func() {
+			mux.HandleFunc("/repos/owner/repo/issues", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				fmt.Fprint(w, `{"message": "Validation Failed", "errors": [{"resource": "Issue", "code": "custom", "field": "body", "message": "body is too long (maximum is 65536 characters)"}]}`)
+			})
+			_, err := g.CreateIssue("owner", "repo", "Test Issue", "body", IssueFields{})
+			Expect(IsBodyTooLarge(err)).To(BeTrue())
+		})
+
+		It("Should not take other validation failures for a body too long", func() {
+			mux.HandleFunc("/repos/owner/repo/issues", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				fmt.Fprint(w, `{"message": "Validation Failed", "errors": [{"resource": "Issue", "code": "invalid", "field": "assignees"}]}`)
+			})
+			_, err := g.CreateIssue("owner", "repo", "Test Issue", "body", IssueFields{})
+			Expect(err).To(HaveOccurred())
+			Expect(IsBodyTooLarge(err)).To(BeFalse())
+		})
+	})
+
+	Context("When ensuring a milestone exists", func() {
+		It("Should return an existing milestone, open or closed", func() {
+			mux.HandleFunc("/repos/owner/repo/milestones", func(w http.ResponseWriter, r *http.Request) {
+				Expect(r.Method).To(Equal(http.MethodGet))
+				Expect(r.URL.Query().Get("state")).To(Equal("all"))
+				fmt.Fprint(w, `[{"number": 1, "title": "v1.0", "state": "open"}, {"number": 2, "title": "v1.1", "state": "closed"}]`)
+			})
+
+			number, err := g.EnsureMilestone(context.Background(), "owner", "repo", "v1.1")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(number).To(Equal(2))
+		})
+
+		It("Should create a missing milestone", func() {
+			var created string
+			mux.HandleFunc("/repos/owner/repo/milestones", func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodPost {
+					var body map[string]any
+					Expect(json.NewDecoder(r.Body).Decode(&body)).To(Succeed())
+					created, _ = body["title"].(string)
+					w.WriteHeader(http.StatusCreated)
+					fmt.Fprint(w, `{"number": 3, "title": "v2.0", "state": "open"}`)
+					return
+				}
+				fmt.Fprint(w, `[{"number": 1, "title": "v1.0"}]`)
+			})
+
+			number, err := g.EnsureMilestone(context.Background(), "owner", "repo", "v2.0")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(number).To(Equal(3))
+			Expect(created).To(Equal("v2.0"))
+		})
+
+		It("Should take a milestone created concurrently as success", func() {
+			lists := 0
+			mux.HandleFunc("/repos/owner/repo/milestones", func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodPost {
+					w.WriteHeader(http.StatusUnprocessableEntity)
+					fmt.Fprint(w, `{"message": "Validation Failed", "errors": [{"resource": "Milestone", "code": "already_exists", "field": "title"}]}`)
+					return
+				}
+				// the other reconcile creates the milestone between the lookup and the create
+				lists++
+				if lists == 1 {
+					fmt.Fprint(w, `[]`)
+					return
+				}
+				fmt.Fprint(w, `[{"number": 4, "title": "v2.0"}]`)
+			})
+
+			number, err := g.EnsureMilestone(context.Background(), "owner", "repo", "v2.0")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(number).To(Equal(4))
+			Expect(lists).To(Equal(2))
+		})
+
+		It("Should fail on other validation failures", func() {
+			mux.HandleFunc("/repos/owner/repo/milestones", func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodPost {
+					w.WriteHeader(http.StatusUnprocessableEntity)
+					fmt.Fprint(w, `{"message": "Validation Failed", "errors": [{"resource": "Milestone", "code": "invalid", "field": "title"}]}`)
+					return
+				}
+				fmt.Fprint(w, `[]`)
+			})
+
+			_, err := g.EnsureMilestone(context.Background(), "owner", "repo", "v2.0")
+			Expect(err).To(MatchError(ContainSubstring("failed to create milestone")))
+		})
+	})
+
+	Context("When GitHub is unavailable", func() {
+		now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+		// unavailable returns the error of a request answered with the status and Retry-After
+		unavailable := func(statusCode int, retryAfter string) error {
+			mux.HandleFunc("/repos/owner/repo/issues/1", func(w http.ResponseWriter, r *http.Request) {
+				if retryAfter != "" {
+					w.Header().Set("Retry-After", retryAfter)
+				}
+				w.WriteHeader(statusCode)
+			})
+			_, err := g.GetIssue(context.Background(), "owner", "repo", 1)
+			Expect(err).To(HaveOccurred())
+			return err
+		}
+
+		It("Should read the Retry-After seconds", func() {
+			retryAfter, ok := ServiceUnavailable(unavailable(http.StatusServiceUnavailable, "30"), now)
+			Expect(ok).To(BeTrue())
+			Expect(retryAfter).To(Equal(30 * time.Second))
+		})
+
+		It("Should read the Retry-After date", func() {
+			date := now.Add(5 * time.Minute).Format(http.TimeFormat)
+			retryAfter, ok := ServiceUnavailable(unavailable(http.StatusServiceUnavailable, date), now)
+			Expect(ok).To(BeTrue())
+			Expect(retryAfter).To(Equal(5 * time.Minute))
+		})
+
+		It("Should fall back to the default without a Retry-After", func() {
+			retryAfter, ok := ServiceUnavailable(unavailable(http.StatusServiceUnavailable, ""), now)
+			Expect(ok).To(BeTrue())
+			Expect(retryAfter).To(Equal(DefaultUnavailableRetryAfter))
+		})
+
+		It("Should not mistake other errors for maintenance", func() {
+			_, ok := ServiceUnavailable(unavailable(http.StatusInternalServerError, "30"), now)
+			Expect(ok).To(BeFalse())
+		})
+	})
+
+	Context("When overriding the API base URL", func() {
+		It("Should send the requests under the base path", func() {
+			var path string
+			mux.HandleFunc("/github/api/v3/repos/owner/repo/issues/4", func(w http.ResponseWriter, r *http.Request) {
+				path = r.URL.Path
+				fmt.Fprint(w, `{"number": 4, "state": "open"}`)
+			})
+
+			// served over plain http by the test server, so built without ParseBaseURL
+			baseURL, err := url.Parse(server.URL + "/github/api/v3/")
+			Expect(err).NotTo(HaveOccurred())
+			g := NewGithubClient("token", WithBaseURL(baseURL))
+
+			issue, err := g.GetIssue(context.Background(), "owner", "repo", 4)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(issue.GetNumber()).To(Equal(4))
+			Expect(path).To(Equal("/github/api/v3/repos/owner/repo/issues/4"))
+		})
+
+		It("Should add the trailing slash to the base URL", func() {
+			baseURL, err := ParseBaseURL("https://gateway.example.com/github/api/v3")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(baseURL.String()).To(Equal("https://gateway.example.com/github/api/v3/"))
+		})
+
+		It("Should reject a base URL that isn't https", func() {
+			_, err := ParseBaseURL("http://gateway.example.com/github")
+			Expect(err).To(HaveOccurred())
+			_, err = ParseBaseURL("gateway.example.com/github")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("When getting an issue by number", func() {
+		It("Should return the issue", func() {
+			mux.HandleFunc("/repos/owner/repo/issues/7", func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, `{"number": 7, "state": "open"}`)
+			})
+
+			issue, err := g.GetIssue(context.Background(), "owner", "repo", 7)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(issue.GetNumber()).To(Equal(7))
+		})
+
+		It("Should return nil for a missing issue", func() {
+			mux.HandleFunc("/repos/owner/repo/issues/7", func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, `{"message": "Not Found"}`, http.StatusNotFound)
+			})
+
+			issue, err := g.GetIssue(context.Background(), "owner", "repo", 7)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(issue).To(BeNil())
+		})
+	})
+
+	Context("When reopening an issue", func() {
+		It("Should send the open state with the reopened state reason", func() {
+			var request map[string]interface{}
+			mux.HandleFunc("/repos/owner/repo/issues/4", func(w http.ResponseWriter, r *http.Request) {
+				Expect(r.Method).To(Equal(http.MethodPatch))
+				Expect(json.NewDecoder(r.Body).Decode(&request)).To(Succeed())
+				fmt.Fprint(w, `{"number": 4, "state": "open", "state_reason": "reopened"}`)
+			})
+
+			issue, err := g.ReopenIssue(context.Background(), "owner", "repo", 4)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(request).To(Equal(map[string]interface{}{"state": "open", "state_reason": "reopened"}))
+			Expect(issue.GetState()).To(Equal("open"))
+		})
+	})
+
+	Context("When assigning an issue", func() {
+		var requests []map[string]any
+
+		BeforeEach(func() {
+			requests = nil
+			record := func(w http.ResponseWriter, r *http.Request) {
+				var request map[string]any
+				Expect(json.NewDecoder(r.Body).Decode(&request)).To(Succeed())
+				requests = append(requests, request)
+				w.WriteHeader(http.StatusCreated)
+				fmt.Fprint(w, `{"number": 4, "state": "open"}`)
+			}
+			mux.HandleFunc("/repos/owner/repo/issues", record)
+			mux.HandleFunc("/repos/owner/repo/issues/4", record)
+		})
+
+		assigned := &github.Issue{
+			Number:    github.Int(4),
+			Title:     github.String("title"),
+			Body:      github.String("body"),
+			Assignees: []*github.User{{Login: github.String("octocat")}, {Login: github.String("hubot")}},
+		}
+
+		It("Should create the issue with its assignees", func() {
+			_, err := g.CreateIssue("owner", "repo", "title", "body", IssueFields{Assignees: []string{"octocat", "hubot"}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(requests).To(HaveLen(1))
+			Expect(requests[0]["assignees"]).To(Equal([]any{"octocat", "hubot"}))
+		})
+
+		It("Should create the issue without assignees", func() {
+			_, err := g.CreateIssue("owner", "repo", "title", "body", IssueFields{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(requests[0]).NotTo(HaveKey("assignees"))
+		})
+
+		It("Should send the full set of assignees so the removed ones are unassigned", func() {
+			_, err := g.UpdateIssue("owner", "repo", assigned, "body", "title", IssueFields{Assignees: []string{"octocat"}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(requests).To(HaveLen(1))
+			Expect(requests[0]["assignees"]).To(Equal([]any{"octocat"}))
+		})
+
+		It("Should unassign everyone with an empty set of assignees", func() {
+			_, err := g.UpdateIssue("owner", "repo", assigned, "body", "title", IssueFields{Assignees: []string{}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(requests).To(HaveLen(1))
+			Expect(requests[0]["assignees"]).To(Equal([]any{}))
+		})
+
+		It("Should leave the assignees picked on GitHub alone without assignees", func() {
+			_, err := g.UpdateIssue("owner", "repo", assigned, "edited", "title", IssueFields{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(requests).To(HaveLen(1))
+			Expect(requests[0]).NotTo(HaveKey("assignees"))
+		})
+	})
+
+	Context("When updating an issue", func() {
+		It("Should add only the missing labels", func() {
+			var added []string
+			mux.HandleFunc("/repos/owner/repo/issues/4", func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, `{"number": 4, "state": "open", "labels": [{"name": "incident"}]}`)
+			})
+			mux.HandleFunc("/repos/owner/repo/issues/4/labels", func(w http.ResponseWriter, r *http.Request) {
+				Expect(json.NewDecoder(r.Body).Decode(&added)).To(Succeed())
+				fmt.Fprint(w, `[{"name": "incident"}, {"name": "sev2"}]`)
+			})
+
+			issue, err := g.UpdateIssue("owner", "repo", &github.Issue{Number: github.Int(4)}, "body", "title",
+				IssueFields{Labels: []string{"incident", "sev2"}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(added).To(Equal([]string{"sev2"}))
+			Expect(HasLabel(issue, "sev2")).To(BeTrue())
+		})
+
+		It("Should not call GitHub for a closed issue that matches the spec", func() {
+			calls := 0
+			mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				w.WriteHeader(http.StatusInternalServerError)
+			})
+
+			closed := &github.Issue{
+				Number:    github.Int(4),
+				State:     github.String("closed"),
+				Title:     github.String("title"),
+				Body:      github.String("body"),
+				Labels:    []*github.Label{{Name: github.String("incident")}},
+				Assignees: []*github.User{{Login: github.String("Octocat")}},
+				Milestone: &github.Milestone{Number: github.Int(2)},
+			}
+			fields := IssueFields{Labels: []string{"incident"}, Assignees: []string{"octocat"}, Milestone: 2}
+			for i := 0; i < 3; i++ {
+				issue, err := g.UpdateIssue("owner", "repo", closed, "body", "title", fields)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(issue).To(BeIdenticalTo(closed))
+			}
+			Expect(calls).To(BeZero())
+		})
+	})
+
+	Context("When the description has CRLF line endings", func() {
+		It("Should not edit the issue GitHub stored with LF line endings again", func() {
+			edits := 0
+			mux.HandleFunc("/repos/owner/repo/issues/4", func(w http.ResponseWriter, r *http.Request) {
+				edits++
+				fmt.Fprint(w, `{"number": 4, "state": "open", "title": "title", "body": "first\nsecond"}`)
+			})
+
+			issue := &github.Issue{Number: github.Int(4), Title: github.String("title"), Body: github.String("first\nsecond")}
+			description := utils.NormalizeLineEndings("first\r\nsecond")
+			for i := 0; i < 3; i++ {
+				updated, err := g.UpdateIssue("owner", "repo", issue, description, "title", IssueFields{})
+				Expect(err).NotTo(HaveOccurred())
+				issue = updated
+			}
+			Expect(edits).To(BeZero())
+		})
+	})
+
+	Context("When reading the linked pull request", func() {
+		It("Should read the number from the pull request links", func() {
+			number, ok := LinkedPullRequest(&github.Issue{
+				Number:           github.Int(4),
+				PullRequestLinks: &github.PullRequestLinks{HTMLURL: github.String("https://github.com/owner/repo/pull/9")},
+			})
+			Expect(ok).To(BeTrue())
+			Expect(number).To(Equal(9))
+		})
+
+		It("Should report an issue without links", func() {
+			_, ok := LinkedPullRequest(&github.Issue{Number: github.Int(4)})
+			Expect(ok).To(BeFalse())
+		})
+	})
+
+	Context("When checking an issue for drift", func() {
+		issue := &github.Issue{
+			Title:     github.String("title"),
+			Body:      github.String("body"),
+			Assignees: []*github.User{{Login: github.String("octocat")}},
+		}
+
+		It("Should ignore the fields left unset", func() {
+			Expect(Drifted(issue, "title", "body", IssueFields{})).To(BeFalse())
+		})
+
+		It("Should report an edited title or body", func() {
+			Expect(Drifted(issue, "edited", "body", IssueFields{})).To(BeTrue())
+			Expect(Drifted(issue, "title", "edited", IssueFields{})).To(BeTrue())
+		})
+
+		It("Should report changed assignees, a missing label or another milestone", func() {
+			Expect(Drifted(issue, "title", "body", IssueFields{Assignees: []string{"octocat", "hubot"}})).To(BeTrue())
+			Expect(Drifted(issue, "title", "body", IssueFields{Labels: []string{"incident"}})).To(BeTrue())
+			Expect(Drifted(issue, "title", "body", IssueFields{Milestone: 1})).To(BeTrue())
+		})
+	})
+
+	Context("When summarizing how an issue differs", func() {
+		issue := &github.Issue{
+			Title:     github.String("title"),
+			Body:      github.String("first\nsecond\nthird"),
+			Labels:    []*github.Label{{Name: github.String("bug")}},
+			Assignees: []*github.User{{Login: github.String("octocat")}},
+		}
+
+		It("Should be empty for an issue that doesn't differ", func() {
+			Expect(DiffSummary(issue, "title", "first\nsecond\nthird", IssueFields{Labels: []string{"bug"}})).To(BeEmpty())
+		})
+
+		It("Should summarize the title and the lines of the body that changed", func() {
+			summary := DiffSummary(issue, "edited", "first\nchanged\nthird\nfourth\nfifth", IssueFields{})
+			Expect(summary).To(Equal("title changed; body +3/-1 lines"))
+		})
+
+		It("Should summarize the milestone, the missing labels and the assignees", func() {
+			summary := DiffSummary(issue, "title", "first\nsecond\nthird", IssueFields{
+				Milestone: 2,
+				Labels:    []string{"bug", "incident", "sev1"},
+				Assignees: []string{"hubot"},
+			})
+			Expect(summary).To(Equal("milestone set to 2; labels added: incident, sev1; assignees changed"))
+		})
+
+		It("Should bound the summary", func() {
+			labels := make([]string, 50)
+			for i := range labels {
+				labels[i] = fmt.Sprintf("label-%d", i)
+			}
+			summary := DiffSummary(issue, "title", "first\nsecond\nthird", IssueFields{Labels: labels})
+			Expect(utf8.RuneCountInString(summary)).To(Equal(MaxDiffSummaryLength))
+			Expect(summary).To(HaveSuffix("…"))
+		})
+	})
+
+	Context("When commenting on an issue", func() {
+		It("Should post the comment body", func() {
+			var request map[string]interface{}
+			mux.HandleFunc("/repos/owner/repo/issues/4/comments", func(w http.ResponseWriter, r *http.Request) {
+				Expect(r.Method).To(Equal(http.MethodPost))
+				Expect(json.NewDecoder(r.Body).Decode(&request)).To(Succeed())
+				fmt.Fprint(w, `{"id": 1}`)
+			})
+
+			Expect(g.CreateComment(context.Background(), "owner", "repo", 4, "auto-resolved")).To(Succeed())
+			Expect(request).To(Equal(map[string]interface{}{"body": "auto-resolved"}))
+		})
+	})
+
+	Context("When appending the description as a comment", func() {
+		var comments []string
+
+		BeforeEach(func() {
+			comments = nil
+			mux.HandleFunc("/repos/owner/repo/issues/4/comments", func(w http.ResponseWriter, r *http.Request) {
+				var request map[string]string
+				Expect(json.NewDecoder(r.Body).Decode(&request)).To(Succeed())
+				comments = append(comments, request["body"])
+				fmt.Fprint(w, `{"id": 1}`)
+			})
+		})
+
+		It("Should post a changed description once", func() {
+			issue := &github.Issue{Number: github.Int(4), Body: github.String("original")}
+
+			hash, err := g.AppendComment(context.Background(), "owner", "repo", issue, "update", "")
+			Expect(err).NotTo(HaveOccurred())
+			hash, err = g.AppendComment(context.Background(), "owner", "repo", issue, "update", hash)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = g.AppendComment(context.Background(), "owner", "repo", issue, "another update", hash)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(comments).To(Equal([]string{"update", "another update"}))
+		})
+
+		It("Should not repost the original body", func() {
+			issue := &github.Issue{Number: github.Int(4), Body: github.String("original")}
+
+			hash, err := g.AppendComment(context.Background(), "owner", "repo", issue, "original", "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(hash).NotTo(BeEmpty())
+			Expect(comments).To(BeEmpty())
+		})
+	})
+
+	Context("When locking an issue", func() {
+		It("Should send the lock reason", func() {
+			var request map[string]interface{}
+			mux.HandleFunc("/repos/owner/repo/issues/4/lock", func(w http.ResponseWriter, r *http.Request) {
+				Expect(r.Method).To(Equal(http.MethodPut))
+				Expect(json.NewDecoder(r.Body).Decode(&request)).To(Succeed())
+				w.WriteHeader(http.StatusNoContent)
+			})
+
+			Expect(g.LockIssue(context.Background(), "owner", "repo", 4, "resolved")).To(Succeed())
+			Expect(request).To(Equal(map[string]interface{}{"lock_reason": "resolved"}))
+		})
+
+		It("Should fail when GitHub refuses the lock", func() {
+			mux.HandleFunc("/repos/owner/repo/issues/4/lock", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusForbidden)
+			})
+
+			Expect(g.LockIssue(context.Background(), "owner", "repo", 4, "resolved")).To(HaveOccurred())
+		})
+	})
+
+	Context("When verifying a closed issue", func() {
+		It("Should retry until GitHub reports the issue closed", func() {
+			gets := 0
+			mux.HandleFunc("/repos/owner/repo/issues/6", func(w http.ResponseWriter, r *http.Request) {
+				gets++
+				if gets == 1 {
+					fmt.Fprint(w, `{"number": 6, "state": "open"}`)
+					return
+				}
+				fmt.Fprint(w, `{"number": 6, "state": "closed"}`)
+			})
+
+			Expect(g.VerifyClosed(context.Background(), "owner", "repo", 6, 3, time.Millisecond)).To(Succeed())
+			Expect(gets).To(Equal(2))
+		})
+
+		It("Should give up once the attempts are exhausted", func() {
+			gets := 0
+			mux.HandleFunc("/repos/owner/repo/issues/6", func(w http.ResponseWriter, r *http.Request) {
+				gets++
+				fmt.Fprint(w, `{"number": 6, "state": "open"}`)
+			})
+
+			err := g.VerifyClosed(context.Background(), "owner", "repo", 6, 3, time.Millisecond)
+			Expect(err).To(MatchError(ContainSubstring("still open after 3 attempts")))
+			Expect(gets).To(Equal(3))
+		})
+	})
+
+	Context("When syncing the tracking issue", func() {
+		var edits []string
+
+		BeforeEach(func() {
+			edits = nil
+			body := "Tracking:\n- [ ] #8"
+			mux.HandleFunc("/repos/owner/repo/issues/2", func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodPatch {
+					request := map[string]string{}
+					Expect(json.NewDecoder(r.Body).Decode(&request)).To(Succeed())
+					body = request["body"]
+					edits = append(edits, body)
+				}
+				Expect(json.NewEncoder(w).Encode(map[string]interface{}{"number": 2, "body": body})).To(Succeed())
+			})
+		})
+
+		It("Should add the item once and check it off on close", func() {
+			ctx := context.Background()
+			Expect(g.SyncTrackingIssue(ctx, "owner", "repo", 2, 9, false)).To(Succeed())
+			Expect(g.SyncTrackingIssue(ctx, "owner", "repo", 2, 9, false)).To(Succeed())
+			Expect(edits).To(Equal([]string{"Tracking:\n- [ ] #8\n- [ ] #9"}))
+
+			Expect(g.SyncTrackingIssue(ctx, "owner", "repo", 2, 9, true)).To(Succeed())
+			Expect(edits).To(HaveLen(2))
+			Expect(edits[1]).To(Equal("Tracking:\n- [ ] #8\n- [x] #9"))
+		})
+	})
+
+	Context("When checking blocking issues", func() {
+		BeforeEach(func() {
+			mux.HandleFunc("/repos/owner/repo/issues/1", func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, `{"number": 1, "state": "closed"}`)
+			})
+			mux.HandleFunc("/repos/owner/repo/issues/2", func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, `{"number": 2, "state": "open"}`)
+			})
+			mux.HandleFunc("/repos/owner/repo/issues/3", func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, `{"message": "Not Found"}`, http.StatusNotFound)
+			})
+		})
+
+		It("Should return only the open dependencies", func() {
+			open, err := g.OpenDependencies("owner", "repo", []int{1, 2})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(open).To(Equal([]int{2}))
+		})
+
+		It("Should return nothing once all dependencies are closed", func() {
+			open, err := g.OpenDependencies("owner", "repo", []int{1})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(open).To(BeEmpty())
+		})
+
+		It("Should fail when a dependency can't be read", func() {
+			_, err := g.OpenDependencies("owner", "repo", []int{1, 3})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("#3"))
+		})
+	})
+
+	Context("When managing labels", func() {
+		issue := &github.Issue{Number: github.Int(5)}
+
+		It("Should add the label to the issue", func() {
+			var added []string
+			mux.HandleFunc("/repos/owner/repo/issues/5/labels", func(w http.ResponseWriter, r *http.Request) {
+				Expect(r.Method).To(Equal(http.MethodPost))
+				added = append(added, BlockedLabel)
+				fmt.Fprint(w, `[{"name": "blocked"}]`)
+			})
+
+			Expect(g.AddLabel("owner", "repo", issue, BlockedLabel)).To(Succeed())
+			Expect(added).To(Equal([]string{BlockedLabel}))
+		})
+
+		It("Should ignore removing a label that is already gone", func() {
+			mux.HandleFunc("/repos/owner/repo/issues/5/labels/blocked", func(w http.ResponseWriter, r *http.Request) {
+				Expect(r.Method).To(Equal(http.MethodDelete))
+				http.Error(w, `{"message": "Label does not exist"}`, http.StatusNotFound)
+			})
+
+			Expect(g.RemoveLabel("owner", "repo", issue, BlockedLabel)).To(Succeed())
+		})
+
+		It("Should report whether the issue carries a label", func() {
+			labeled := &github.Issue{Labels: []*github.Label{{Name: github.String(BlockedLabel)}}}
+			Expect(HasLabel(labeled, BlockedLabel)).To(BeTrue())
+			Expect(HasLabel(issue, BlockedLabel)).To(BeFalse())
+		})
+	})
+})
@@ -0,0 +1,135 @@
+package resources
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v47/github"
+)
+
+// RequiredScopes are the OAuth scopes a classic token needs to manage issues
+var RequiredScopes = []string{"repo"}
+
+// responseRecorder is an http.RoundTripper keeping the rate limit and the token scopes
+// GitHub reported with the latest response
+type responseRecorder struct {
+	mu          sync.Mutex
+	base        http.RoundTripper
+	rate        github.Rate
+	rateKnown   bool
+	scopes      []string
+	scopesKnown bool
+}
+
+// RoundTrip sends the request, recording the rate limit and scopes headers of the response
+func (r *responseRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := r.base.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if rate, ok := parseRate(resp); ok {
+		r.rate = rate
+		r.rateKnown = true
+	}
+	if scopes, ok := parseScopes(resp); ok {
+		r.scopes = scopes
+		r.scopesKnown = true
+	}
+	return resp, nil
+}
+
+// Rate returns the rate limit GitHub reported with the latest response, ok is false
+// until a response carried the rate limit headers
+func (g *GithubClient) Rate() (rate github.Rate, ok bool) {
+	if g.recorder == nil {
+		return github.Rate{}, false
+	}
+	g.recorder.mu.Lock()
+	defer g.recorder.mu.Unlock()
+	return g.recorder.rate, g.recorder.rateKnown
+}
+
+// Scopes returns the OAuth scopes of the token GitHub reported with the latest response,
+// ok is false until a response carried them, which never happens for fine-grained tokens
+func (g *GithubClient) Scopes() (scopes []string, ok bool) {
+	if g.recorder == nil {
+		return nil, false
+	}
+	g.recorder.mu.Lock()
+	defer g.recorder.mu.Unlock()
+	return g.recorder.scopes, g.recorder.scopesKnown
+}
+
+// CheckScopes asks GitHub for the scopes of the token, through the rate limit endpoint
+// which doesn't count against the limit. It returns the required scopes the token lacks,
+// ok is false when GitHub doesn't report the scopes of the token.
+func (g *GithubClient) CheckScopes(ctx context.Context) (missing []string, ok bool, err error) {
+	if _, _, err := g.client.RateLimits(ctx); err != nil {
+		return nil, false, fmt.Errorf("failed to check the token scopes: %w", err)
+	}
+
+	scopes, ok := g.Scopes()
+	if !ok {
+		return nil, false, nil
+	}
+	return MissingScopes(scopes), true, nil
+}
+
+// MissingScopes returns the required scopes absent from the given ones
+func MissingScopes(scopes []string) []string {
+	granted := map[string]bool{}
+	for _, scope := range scopes {
+		granted[scope] = true
+	}
+
+	var missing []string
+	for _, scope := range RequiredScopes {
+		if !granted[scope] {
+			missing = append(missing, scope)
+		}
+	}
+	return missing
+}
+
+// parseRate reads the rate limit headers of the response
+func parseRate(resp *http.Response) (github.Rate, bool) {
+	remaining, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return github.Rate{}, false
+	}
+	reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return github.Rate{}, false
+	}
+	limit, _ := strconv.Atoi(resp.Header.Get("X-RateLimit-Limit"))
+	return github.Rate{
+		Limit:     limit,
+		Remaining: remaining,
+		Reset:     github.Timestamp{Time: time.Unix(reset, 0)},
+	}, true
+}
+
+// parseScopes reads the comma separated X-OAuth-Scopes header of the response, only sent
+// for classic tokens
+func parseScopes(resp *http.Response) ([]string, bool) {
+	values, found := resp.Header[http.CanonicalHeaderKey("X-OAuth-Scopes")]
+	if !found || len(values) == 0 {
+		return nil, false
+	}
+
+	scopes := []string{}
+	for _, scope := range strings.Split(values[0], ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes, true
+}
@@ -0,0 +1,128 @@
+package resources
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Response recorder", func() {
+	var (
+		mux    *http.ServeMux
+		server *httptest.Server
+		g      *GithubClient
+	)
+
+	BeforeEach(func() {
+		mux = http.NewServeMux()
+		server = httptest.NewServer(mux)
+		g = newTestGithubClient(server)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("Should be unknown before any response", func() {
+		_, ok := g.Rate()
+		Expect(ok).To(BeFalse())
+	})
+
+	It("Should record the rate limit of the latest response", func() {
+		remaining := 4999
+		mux.HandleFunc("/repos/owner/repo/issues/1", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-RateLimit-Limit", "5000")
+			w.Header().Set("X-RateLimit-Remaining", fmt.Sprint(remaining))
+			w.Header().Set("X-RateLimit-Reset", "1700000000")
+			remaining--
+			fmt.Fprint(w, `{"number": 1, "state": "open"}`)
+		})
+
+		_, err := g.GetIssue(context.Background(), "owner", "repo", 1)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = g.GetIssue(context.Background(), "owner", "repo", 1)
+		Expect(err).NotTo(HaveOccurred())
+
+		rate, ok := g.Rate()
+		Expect(ok).To(BeTrue())
+		Expect(rate.Limit).To(Equal(5000))
+		Expect(rate.Remaining).To(Equal(4998))
+		Expect(rate.Reset.Time).To(Equal(time.Unix(1700000000, 0)))
+	})
+
+	It("Should keep the last known rate when a response has no headers", func() {
+		mux.HandleFunc("/repos/owner/repo/issues/1", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-RateLimit-Remaining", "10")
+			w.Header().Set("X-RateLimit-Reset", "1700000000")
+			fmt.Fprint(w, `{"number": 1, "state": "open"}`)
+		})
+		mux.HandleFunc("/repos/owner/repo/issues/2", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"number": 2, "state": "open"}`)
+		})
+
+		_, err := g.GetIssue(context.Background(), "owner", "repo", 1)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = g.GetIssue(context.Background(), "owner", "repo", 2)
+		Expect(err).NotTo(HaveOccurred())
+
+		rate, ok := g.Rate()
+		Expect(ok).To(BeTrue())
+		Expect(rate.Remaining).To(Equal(10))
+	})
+
+	Context("When checking the token scopes", func() {
+		It("Should report the missing repo scope", func() {
+			mux.HandleFunc("/rate_limit", func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-OAuth-Scopes", "read:org, gist")
+				fmt.Fprint(w, `{"resources": {}}`)
+			})
+
+			missing, ok, err := g.CheckScopes(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(missing).To(Equal([]string{"repo"}))
+
+			scopes, _ := g.Scopes()
+			Expect(scopes).To(Equal([]string{"read:org", "gist"}))
+		})
+
+		It("Should report nothing missing for a token with the repo scope", func() {
+			mux.HandleFunc("/rate_limit", func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-OAuth-Scopes", "repo, read:org")
+				fmt.Fprint(w, `{"resources": {}}`)
+			})
+
+			missing, ok, err := g.CheckScopes(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(missing).To(BeEmpty())
+		})
+
+		It("Should treat an empty scopes header as no scopes", func() {
+			mux.HandleFunc("/rate_limit", func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-OAuth-Scopes", "")
+				fmt.Fprint(w, `{"resources": {}}`)
+			})
+
+			missing, ok, err := g.CheckScopes(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(missing).To(Equal([]string{"repo"}))
+		})
+
+		It("Should not know the scopes of a fine-grained token", func() {
+			mux.HandleFunc("/rate_limit", func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, `{"resources": {}}`)
+			})
+
+			_, ok, err := g.CheckScopes(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeFalse())
+		})
+	})
+})
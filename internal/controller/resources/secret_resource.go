@@ -3,29 +3,224 @@ package resources
 import (
 	"context"
 	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
 	issuev1 "github.com/oshribelay/github-issue-operator/api/v1"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-func CreateSecret(githubIssue *issuev1.GithubIssue, c client.Client, ctx context.Context) error {
+// DefaultTokenKey is the secret key the token is read from unless configured otherwise
+const DefaultTokenKey = "token"
+
+// TokensKey is the secret key holding the additional tokens of a token pool
+const TokensKey = "tokens"
+
+// TokenSecretLabel labels the token secrets the operator creates, selecting them for the
+// orphan sweep
+const TokenSecretLabel = "issue.core.github.io/token-secret"
+
+// TokenSecretIssueAnnotation names the GithubIssue a token secret was created for, it
+// outlives the owner reference, which an orphaning delete strips
+const TokenSecretIssueAnnotation = "issue.core.github.io/githubissue"
+
+// tokenPattern matches the formats of GitHub personal access and OAuth tokens
+var tokenPattern = regexp.MustCompile(`^(gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,}|[0-9a-f]{40})$`)
+
+// TokenFromSecret returns the token stored under key. When that key is missing or empty
+// it falls back to the first key, in alphabetical order, whose value looks like a GitHub
+// token, ignoring the tokens key of the pool. It returns the key the token was read
+// from, empty when the secret holds no token.
+func TokenFromSecret(secret *corev1.Secret, key string) ([]byte, string) {
+	if token := secret.Data[key]; len(token) > 0 {
+		return token, key
+	}
+
+	keys := make([]string, 0, len(secret.Data))
+	for k := range secret.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if k == key || k == TokensKey {
+			continue
+		}
+		if token := strings.TrimSpace(string(secret.Data[k])); tokenPattern.MatchString(token) {
+			return []byte(token), k
+		}
+	}
+
+	return nil, ""
+}
+
+// TokenSecretSuffix ends the name of the token secret of every GithubIssue
+const TokenSecretSuffix = "-token-secret"
+
+// DefaultTokenSecretPattern names the token secret of a GithubIssue unless configured otherwise
+const DefaultTokenSecretPattern = "{{ .Name }}" + TokenSecretSuffix
+
+// TokenSecretNames names the token secrets of GithubIssues after a pattern, a nil
+// TokenSecretNames follows DefaultTokenSecretPattern
+type TokenSecretNames struct {
+	tmpl *template.Template
+}
+
+// tokenSecretData is what token secret name patterns are rendered with
+type tokenSecretData struct {
+	Namespace string
+	Name      string
+}
+
+// ParseTokenSecretNames parses the pattern, e.g. "{{ .Name }}-gh". It must render valid,
+// distinct secret names for distinct GithubIssues, so it has to use the name of the
+// GithubIssue.
+func ParseTokenSecretNames(pattern string) (*TokenSecretNames, error) {
+	tmpl, err := template.New("secret").Option("missingkey=error").Parse(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse token secret pattern %q: %w", pattern, err)
+	}
+	n := &TokenSecretNames{tmpl: tmpl}
+
+	first, err := n.For("default", "first")
+	if err != nil {
+		return nil, err
+	}
+	second, err := n.For("default", "second")
+	if err != nil {
+		return nil, err
+	}
+	if first == second {
+		return nil, fmt.Errorf("token secret pattern %q must use {{ .Name }}, it names every token secret %q", pattern, first)
+	}
+	return n, nil
+}
+
+// For returns the name of the token secret of the GithubIssue
+func (n *TokenSecretNames) For(namespace, name string) (string, error) {
+	if n == nil {
+		return name + TokenSecretSuffix, nil
+	}
+	var out strings.Builder
+	if err := n.tmpl.Execute(&out, tokenSecretData{Namespace: namespace, Name: name}); err != nil {
+		return "", fmt.Errorf("failed to render token secret name: %w", err)
+	}
+	secretName := out.String()
+	if errs := validation.IsDNS1123Subdomain(secretName); len(errs) > 0 {
+		return "", fmt.Errorf("invalid token secret name %q: %s", secretName, strings.Join(errs, ", "))
+	}
+	return secretName, nil
+}
+
+// OwningIssue returns the name of the GithubIssue the token secret was created for, by its
+// owner reference or, once that's gone, its TokenSecretIssueAnnotation. ok is false for
+// secrets not named after the GithubIssue owning them, which are left to their users.
+func OwningIssue(secret *corev1.Secret, names *TokenSecretNames) (name string, ok bool) {
+	candidates := make([]string, 0, len(secret.OwnerReferences)+1)
+	for _, ref := range secret.OwnerReferences {
+		if ref.Kind == "GithubIssue" && ref.APIVersion == issuev1.GroupVersion.String() {
+			candidates = append(candidates, ref.Name)
+		}
+	}
+	candidates = append(candidates, secret.Annotations[TokenSecretIssueAnnotation])
+	for _, candidate := range candidates {
+		if candidate == "" {
+			continue
+		}
+		if secretName, err := names.For(secret.Namespace, candidate); err == nil && secretName == secret.Name {
+			return candidate, true
+		}
+	}
+	return "", false
+}
+
+// IsOrphanedTokenSecret reports whether the token secret was created for a GithubIssue
+// that no longer exists. A GithubIssue recreated under the same name takes the secret over,
+// whatever its UID, so its secret isn't orphaned.
+func IsOrphanedTokenSecret(ctx context.Context, c client.Client, secret *corev1.Secret, names *TokenSecretNames) (bool, error) {
+	name, ok := OwningIssue(secret, names)
+	if !ok {
+		return false, nil
+	}
+
+	githubIssue := &issuev1.GithubIssue{}
+	err := c.Get(ctx, client.ObjectKey{Namespace: secret.Namespace, Name: name}, githubIssue)
+	if apierrors.IsNotFound(err) {
+		return true, nil
+	}
+	return false, err
+}
+
+// ReownSecret moves the token secret over to the GithubIssue when it is still owned by a
+// deleted GithubIssue of the same name, so garbage collection doesn't take the token with it.
+// It reports whether the secret was re-owned.
+func ReownSecret(ctx context.Context, c client.Client, githubIssue *issuev1.GithubIssue, secret *corev1.Secret) (bool, error) {
+	stale := false
+	refs := make([]metav1.OwnerReference, 0, len(secret.OwnerReferences))
+	for _, ref := range secret.OwnerReferences {
+		if ref.Kind == "GithubIssue" && ref.Name == githubIssue.Name && ref.UID != githubIssue.UID {
+			stale = true
+			continue
+		}
+		refs = append(refs, ref)
+	}
+	if !stale {
+		return false, nil
+	}
+
+	secret.OwnerReferences = append(refs, *issueOwnerReference(githubIssue))
+	if err := c.Update(ctx, secret); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// LabelTokenSecret adds the TokenSecretLabel and TokenSecretIssueAnnotation to a token secret
+// created before the operator set them, so the orphan sweep selects it. It reports whether
+// the secret was labeled.
+func LabelTokenSecret(ctx context.Context, c client.Client, githubIssue *issuev1.GithubIssue, secret *corev1.Secret) (bool, error) {
+	if secret.Labels[TokenSecretLabel] == "true" && secret.Annotations[TokenSecretIssueAnnotation] == githubIssue.Name {
+		return false, nil
+	}
+
+	patch := client.MergeFrom(secret.DeepCopy())
+	metav1.SetMetaDataLabel(&secret.ObjectMeta, TokenSecretLabel, "true")
+	metav1.SetMetaDataAnnotation(&secret.ObjectMeta, TokenSecretIssueAnnotation, githubIssue.Name)
+	if err := c.Patch(ctx, secret, patch); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// issueOwnerReference returns the controller reference of the GithubIssue
+func issueOwnerReference(githubIssue *issuev1.GithubIssue) *metav1.OwnerReference {
+	return metav1.NewControllerRef(githubIssue, schema.GroupVersionKind{
+		Group:   "issue.core.github.io",
+		Version: "v1",
+		Kind:    "GithubIssue",
+	})
+}
+
+// CreateSecret creates the token secret of the given name owned by the GithubIssue,
+// pre-populated with the given token. An empty token leaves the secret blank for manual entry.
+func CreateSecret(githubIssue *issuev1.GithubIssue, name string, c client.Client, ctx context.Context, token string) error {
 	secret := corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      fmt.Sprintf("%s-token-secret", githubIssue.Name),
-			Namespace: githubIssue.Namespace,
-
-			OwnerReferences: []metav1.OwnerReference{
-				*metav1.NewControllerRef(githubIssue, schema.GroupVersionKind{
-					Group:   "issue.core.github.io",
-					Version: "v1",
-					Kind:    "GithubIssue",
-				}),
-			},
+			Name:        name,
+			Namespace:   githubIssue.Namespace,
+			Labels:      map[string]string{TokenSecretLabel: "true"},
+			Annotations: map[string]string{TokenSecretIssueAnnotation: githubIssue.Name},
+
+			OwnerReferences: []metav1.OwnerReference{*issueOwnerReference(githubIssue)},
 		},
 		StringData: map[string]string{
-			"token": "",
+			DefaultTokenKey: token,
 		},
 	}
 
@@ -0,0 +1,313 @@
+package resources
+
+import (
+	"context"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	issuev1 "github.com/oshribelay/github-issue-operator/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("CreateSecret", func() {
+	ctx := context.Background()
+	githubIssue := &issuev1.GithubIssue{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-resource",
+			Namespace: "default",
+			UID:       "test-uid",
+		},
+	}
+	secretName := types.NamespacedName{Name: "test-resource-token-secret", Namespace: "default"}
+
+	It("Should leave the token empty by default", func() {
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+		Expect(CreateSecret(githubIssue, secretName.Name, c, ctx, "")).To(Succeed())
+
+		secret := &corev1.Secret{}
+		Expect(c.Get(ctx, secretName, secret)).To(Succeed())
+		Expect(secret.StringData).To(HaveKeyWithValue("token", ""))
+		Expect(secret.OwnerReferences).To(HaveLen(1))
+		Expect(secret.OwnerReferences[0].Name).To(Equal(githubIssue.Name))
+		Expect(secret.Labels).To(HaveKeyWithValue(TokenSecretLabel, "true"))
+		Expect(secret.Annotations).To(HaveKeyWithValue(TokenSecretIssueAnnotation, githubIssue.Name))
+	})
+
+	It("Should seed the token when one is provided", func() {
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+		Expect(CreateSecret(githubIssue, secretName.Name, c, ctx, "seeded-token")).To(Succeed())
+
+		secret := &corev1.Secret{}
+		Expect(c.Get(ctx, secretName, secret)).To(Succeed())
+		Expect(secret.StringData).To(HaveKeyWithValue("token", "seeded-token"))
+	})
+})
+
+var _ = Describe("ReownSecret", func() {
+	ctx := context.Background()
+	githubIssue := &issuev1.GithubIssue{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-resource", Namespace: "default", UID: "new-uid"},
+	}
+	secretName := types.NamespacedName{Name: "test-resource-token-secret", Namespace: "default"}
+
+	// newSecret returns the token secret owned by the GithubIssue of the uid
+	newSecret := func(uid types.UID, refs ...metav1.OwnerReference) *corev1.Secret {
+		owner := githubIssue.DeepCopy()
+		owner.UID = uid
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            secretName.Name,
+				Namespace:       secretName.Namespace,
+				OwnerReferences: append(refs, *issueOwnerReference(owner)),
+			},
+			Data: map[string][]byte{"token": []byte("kept-token")},
+		}
+	}
+
+	It("Should re-own a secret owned by a deleted GithubIssue of the same name", func() {
+		other := metav1.OwnerReference{APIVersion: "v1", Kind: "ConfigMap", Name: "other", UID: "other-uid"}
+		secret := newSecret("old-uid", other)
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(secret).Build()
+
+		reowned, err := ReownSecret(ctx, c, githubIssue, secret)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reowned).To(BeTrue())
+
+		stored := &corev1.Secret{}
+		Expect(c.Get(ctx, secretName, stored)).To(Succeed())
+		Expect(stored.OwnerReferences).To(HaveLen(2))
+		Expect(stored.OwnerReferences[0].Name).To(Equal("other"))
+		Expect(stored.OwnerReferences[1].UID).To(Equal(githubIssue.UID))
+		Expect(stored.Data).To(HaveKeyWithValue("token", []byte("kept-token")))
+	})
+
+	It("Should leave a secret owned by the GithubIssue alone", func() {
+		secret := newSecret(githubIssue.UID)
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(secret).Build()
+
+		reowned, err := ReownSecret(ctx, c, githubIssue, secret)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reowned).To(BeFalse())
+	})
+})
+
+var _ = Describe("LabelTokenSecret", func() {
+	ctx := context.Background()
+	githubIssue := &issuev1.GithubIssue{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-resource", Namespace: "default", UID: "test-uid"},
+	}
+	secretName := types.NamespacedName{Name: "test-resource-token-secret", Namespace: "default"}
+
+	It("Should label a secret created before the operator labeled them", func() {
+		secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: secretName.Name, Namespace: secretName.Namespace}}
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(secret).Build()
+
+		labeled, err := LabelTokenSecret(ctx, c, githubIssue, secret)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(labeled).To(BeTrue())
+
+		stored := &corev1.Secret{}
+		Expect(c.Get(ctx, secretName, stored)).To(Succeed())
+		Expect(stored.Labels).To(HaveKeyWithValue(TokenSecretLabel, "true"))
+		Expect(stored.Annotations).To(HaveKeyWithValue(TokenSecretIssueAnnotation, githubIssue.Name))
+
+		labeled, err = LabelTokenSecret(ctx, c, githubIssue, stored)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(labeled).To(BeFalse())
+	})
+})
+
+var _ = Describe("TokenFromSecret", func() {
+	classicToken := "ghp_" + strings.Repeat("a", 36)
+
+	It("Should read the configured key", func() {
+		secret := &corev1.Secret{Data: map[string][]byte{
+			"token":  []byte("configured"),
+			"backup": []byte(classicToken),
+		}}
+		token, key := TokenFromSecret(secret, "token")
+		Expect(string(token)).To(Equal("configured"))
+		Expect(key).To(Equal("token"))
+	})
+
+	It("Should fall back to the first key that looks like a token", func() {
+		secret := &corev1.Secret{Data: map[string][]byte{
+			"username":     []byte("octocat"),
+			"github-token": []byte(classicToken + "\n"),
+			"z-token":      []byte("ghp_" + strings.Repeat("b", 36)),
+		}}
+		token, key := TokenFromSecret(secret, "token")
+		Expect(string(token)).To(Equal(classicToken))
+		Expect(key).To(Equal("github-token"))
+	})
+
+	It("Should fall back when the configured key is empty", func() {
+		secret := &corev1.Secret{Data: map[string][]byte{
+			"token": []byte(""),
+			"pat":   []byte("github_pat_" + strings.Repeat("c", 30)),
+		}}
+		_, key := TokenFromSecret(secret, "token")
+		Expect(key).To(Equal("pat"))
+	})
+
+	It("Should ignore the pool tokens and values that don't look like tokens", func() {
+		secret := &corev1.Secret{Data: map[string][]byte{
+			"tokens":   []byte(classicToken),
+			"password": []byte("hunter2"),
+		}}
+		token, key := TokenFromSecret(secret, "token")
+		Expect(token).To(BeNil())
+		Expect(key).To(BeEmpty())
+	})
+})
+
+var _ = Describe("IsOrphanedTokenSecret", func() {
+	ctx := context.Background()
+	var c client.Client
+
+	newSecret := func(name string, refs ...metav1.OwnerReference) *corev1.Secret {
+		return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       "default",
+			OwnerReferences: refs,
+		}}
+	}
+	ownerRef := func(name string, uid types.UID) metav1.OwnerReference {
+		return metav1.OwnerReference{
+			APIVersion: issuev1.GroupVersion.String(),
+			Kind:       "GithubIssue",
+			Name:       name,
+			UID:        uid,
+		}
+	}
+
+	BeforeEach(func() {
+		s := runtime.NewScheme()
+		Expect(issuev1.AddToScheme(s)).To(Succeed())
+		c = fake.NewClientBuilder().WithScheme(s).WithObjects(&issuev1.GithubIssue{
+			ObjectMeta: metav1.ObjectMeta{Name: "live", Namespace: "default", UID: "live-uid"},
+		}).Build()
+	})
+
+	It("Should detect the secret of a deleted GithubIssue", func() {
+		orphaned, err := IsOrphanedTokenSecret(ctx, c, newSecret("gone-token-secret", ownerRef("gone", "gone-uid")), nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(orphaned).To(BeTrue())
+	})
+
+	It("Should keep the secret of an existing GithubIssue", func() {
+		orphaned, err := IsOrphanedTokenSecret(ctx, c, newSecret("live-token-secret", ownerRef("live", "live-uid")), nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(orphaned).To(BeFalse())
+	})
+
+	It("Should keep the secret of a GithubIssue recreated under the same name", func() {
+		orphaned, err := IsOrphanedTokenSecret(ctx, c, newSecret("live-token-secret", ownerRef("live", "old-uid")), nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(orphaned).To(BeFalse())
+	})
+
+	It("Should detect the secret of a deleted GithubIssue by its annotation without an owner reference", func() {
+		secret := newSecret("gone-token-secret")
+		secret.Annotations = map[string]string{TokenSecretIssueAnnotation: "gone"}
+		orphaned, err := IsOrphanedTokenSecret(ctx, c, secret, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(orphaned).To(BeTrue())
+
+		secret.Annotations[TokenSecretIssueAnnotation] = "other"
+		orphaned, err = IsOrphanedTokenSecret(ctx, c, secret, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(orphaned).To(BeFalse())
+	})
+
+	It("Should leave user-managed secrets alone", func() {
+		for _, secret := range []*corev1.Secret{
+			newSecret("gone-token-secret"),
+			newSecret("gone-token-secret", metav1.OwnerReference{APIVersion: "v1", Kind: "ConfigMap", Name: "gone"}),
+			newSecret("gone-credentials", ownerRef("gone", "gone-uid")),
+		} {
+			orphaned, err := IsOrphanedTokenSecret(ctx, c, secret, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(orphaned).To(BeFalse(), secret.Name)
+		}
+	})
+})
+
+var _ = Describe("TokenSecretNames", func() {
+	ctx := context.Background()
+
+	It("Should default to the -token-secret suffix", func() {
+		var names *TokenSecretNames
+		Expect(names.For("default", "web")).To(Equal("web-token-secret"))
+
+		names, err := ParseTokenSecretNames(DefaultTokenSecretPattern)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(names.For("default", "web")).To(Equal("web-token-secret"))
+	})
+
+	It("Should render custom patterns", func() {
+		names, err := ParseTokenSecretNames("{{ .Name }}-gh")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(names.For("default", "web")).To(Equal("web-gh"))
+
+		names, err = ParseTokenSecretNames("github-{{ .Namespace }}-{{ .Name }}")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(names.For("team-a", "web")).To(Equal("github-team-a-web"))
+	})
+
+	It("Should reject patterns not producing valid, distinct secret names", func() {
+		for _, pattern := range []string{
+			"{{ .Name",
+			"{{ .Owner }}-gh",
+			"{{ .Name }}_GH",
+			"github-token",
+			"{{ .Namespace }}-gh",
+		} {
+			_, err := ParseTokenSecretNames(pattern)
+			Expect(err).To(HaveOccurred(), pattern)
+		}
+	})
+
+	It("Should round-trip a custom pattern through creation and the orphan sweep", func() {
+		names, err := ParseTokenSecretNames("{{ .Name }}-gh")
+		Expect(err).NotTo(HaveOccurred())
+
+		s := runtime.NewScheme()
+		Expect(scheme.AddToScheme(s)).To(Succeed())
+		Expect(issuev1.AddToScheme(s)).To(Succeed())
+		gone := &issuev1.GithubIssue{ObjectMeta: metav1.ObjectMeta{Name: "gone", Namespace: "default", UID: "gone-uid"}}
+		live := &issuev1.GithubIssue{ObjectMeta: metav1.ObjectMeta{Name: "live", Namespace: "default", UID: "live-uid"}}
+		c := fake.NewClientBuilder().WithScheme(s).WithObjects(live).Build()
+
+		for _, githubIssue := range []*issuev1.GithubIssue{gone, live} {
+			name, err := names.For(githubIssue.Namespace, githubIssue.Name)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(CreateSecret(githubIssue, name, c, ctx, "")).To(Succeed())
+		}
+
+		secret := &corev1.Secret{}
+		Expect(c.Get(ctx, types.NamespacedName{Name: "gone-gh", Namespace: "default"}, secret)).To(Succeed())
+		owner, ok := OwningIssue(secret, names)
+		Expect(ok).To(BeTrue())
+		Expect(owner).To(Equal("gone"))
+		orphaned, err := IsOrphanedTokenSecret(ctx, c, secret, names)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(orphaned).To(BeTrue())
+
+		Expect(c.Get(ctx, types.NamespacedName{Name: "live-gh", Namespace: "default"}, secret)).To(Succeed())
+		orphaned, err = IsOrphanedTokenSecret(ctx, c, secret, names)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(orphaned).To(BeFalse())
+
+		// the default pattern doesn't claim secrets named after another one
+		_, ok = OwningIssue(secret, nil)
+		Expect(ok).To(BeFalse())
+	})
+})
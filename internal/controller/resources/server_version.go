@@ -0,0 +1,76 @@
+package resources
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/util/version"
+)
+
+// Feature is a GitHub API feature missing from older GitHub Enterprise Server releases
+type Feature string
+
+const (
+	// FeatureStateReason is the state_reason of issues, recording why they were closed or reopened
+	FeatureStateReason Feature = "StateReason"
+)
+
+// featureMinVersions are the first GitHub Enterprise Server releases supporting the features
+var featureMinVersions = map[Feature]*version.Version{
+	FeatureStateReason: version.MustParseGeneric("3.6"),
+}
+
+// WithServerVersion gates the features the client uses by the GitHub Enterprise Server version,
+// empty for github.com or an unknown version
+func WithServerVersion(serverVersion string) Option {
+	return func(g *GithubClient) {
+		g.serverVersion = serverVersion
+	}
+}
+
+// ServerVersion asks the meta endpoint for the installed GitHub Enterprise Server version,
+// empty for github.com which doesn't report one
+func (g *GithubClient) ServerVersion(ctx context.Context) (string, error) {
+	req, err := g.client.NewRequest("GET", "meta", nil)
+	if err != nil {
+		return "", err
+	}
+
+	meta := struct {
+		InstalledVersion string `json:"installed_version"`
+	}{}
+	if _, err := g.client.Do(ctx, req, &meta); err != nil {
+		return "", fmt.Errorf("failed to get the server version: %w", err)
+	}
+	return meta.InstalledVersion, nil
+}
+
+// Supports reports whether the server the client talks to supports the feature
+func (g *GithubClient) Supports(feature Feature) bool {
+	return FeatureAvailable(g.serverVersion, feature)
+}
+
+// FeatureAvailable reports whether the GitHub Enterprise Server version supports the feature,
+// github.com and versions that can't be parsed are assumed to support everything
+func FeatureAvailable(serverVersion string, feature Feature) bool {
+	if serverVersion == "" {
+		return true
+	}
+	installed, err := version.ParseGeneric(serverVersion)
+	if err != nil {
+		return true
+	}
+	minVersion, found := featureMinVersions[feature]
+	return !found || installed.AtLeast(minVersion)
+}
+
+// UnavailableFeatures returns the features the GitHub Enterprise Server version lacks
+func UnavailableFeatures(serverVersion string, features ...Feature) []Feature {
+	var unavailable []Feature
+	for _, feature := range features {
+		if !FeatureAvailable(serverVersion, feature) {
+			unavailable = append(unavailable, feature)
+		}
+	}
+	return unavailable
+}
@@ -0,0 +1,115 @@
+package resources
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Server version", func() {
+	var (
+		mux    *http.ServeMux
+		server *httptest.Server
+	)
+
+	BeforeEach(func() {
+		mux = http.NewServeMux()
+		server = httptest.NewServer(mux)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	Context("When detecting the server version", func() {
+		It("Should return the installed GitHub Enterprise Server version", func() {
+			mux.HandleFunc("/meta", func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, `{"verifiable_password_authentication": true, "installed_version": "3.5.2"}`)
+			})
+
+			serverVersion, err := newTestGithubClient(server).ServerVersion(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(serverVersion).To(Equal("3.5.2"))
+		})
+
+		It("Should return no version for github.com", func() {
+			mux.HandleFunc("/meta", func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, `{"verifiable_password_authentication": true}`)
+			})
+
+			serverVersion, err := newTestGithubClient(server).ServerVersion(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(serverVersion).To(BeEmpty())
+		})
+
+		It("Should fail when the meta endpoint fails", func() {
+			mux.HandleFunc("/meta", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+			})
+
+			_, err := newTestGithubClient(server).ServerVersion(context.Background())
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("When gating features", func() {
+		It("Should enable every feature on github.com", func() {
+			Expect(FeatureAvailable("", FeatureStateReason)).To(BeTrue())
+		})
+
+		It("Should enable every feature for a version that can't be parsed", func() {
+			Expect(FeatureAvailable("next", FeatureStateReason)).To(BeTrue())
+		})
+
+		It("Should gate the features by the minimum version", func() {
+			Expect(FeatureAvailable("3.5.2", FeatureStateReason)).To(BeFalse())
+			Expect(FeatureAvailable("3.6.0", FeatureStateReason)).To(BeTrue())
+			Expect(FeatureAvailable("3.12.1", FeatureStateReason)).To(BeTrue())
+		})
+
+		It("Should return the unavailable features", func() {
+			Expect(UnavailableFeatures("3.4.0", FeatureStateReason)).To(ConsistOf(FeatureStateReason))
+			Expect(UnavailableFeatures("3.9.0", FeatureStateReason)).To(BeEmpty())
+		})
+	})
+
+	Context("When the server lacks state reasons", func() {
+		var request map[string]interface{}
+
+		BeforeEach(func() {
+			request = nil
+			mux.HandleFunc("/repos/owner/repo/issues/3", func(w http.ResponseWriter, r *http.Request) {
+				Expect(json.NewDecoder(r.Body).Decode(&request)).To(Succeed())
+				fmt.Fprint(w, `{"number": 3}`)
+			})
+		})
+
+		It("Should close a duplicate without a state reason", func() {
+			g := newTestGithubClient(server, WithServerVersion("3.5.2"))
+			_, err := g.CloseAsDuplicate(context.Background(), "owner", "repo", 3)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(request).To(HaveKeyWithValue("state", "closed"))
+			Expect(request).NotTo(HaveKey("state_reason"))
+		})
+
+		It("Should reopen without a state reason", func() {
+			g := newTestGithubClient(server, WithServerVersion("3.5.2"))
+			_, err := g.ReopenIssue(context.Background(), "owner", "repo", 3)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(request).To(HaveKeyWithValue("state", "open"))
+			Expect(request).NotTo(HaveKey("state_reason"))
+		})
+
+		It("Should send the state reason on newer servers", func() {
+			g := newTestGithubClient(server, WithServerVersion("3.6.0"))
+			_, err := g.CloseAsDuplicate(context.Background(), "owner", "repo", 3)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(request).To(HaveKeyWithValue("state_reason", "not_planned"))
+		})
+	})
+})
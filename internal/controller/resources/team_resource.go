@@ -0,0 +1,38 @@
+package resources
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v47/github"
+)
+
+// ParseTeam splits an "org/team-slug" team reference
+func ParseTeam(team string) (org, slug string, err error) {
+	org, slug, found := strings.Cut(team, "/")
+	if !found || org == "" || slug == "" || strings.Contains(slug, "/") {
+		return "", "", fmt.Errorf("team %q must be in the format 'org/team-slug'", team)
+	}
+	return org, slug, nil
+}
+
+// TeamMembers returns the logins of the members of the team, including the members of its
+// child teams. GitHub answers 404 for teams the token can't read without the read:org scope.
+func (g *GithubClient) TeamMembers(ctx context.Context, org, slug string) ([]string, error) {
+	opts := &github.TeamListTeamMembersOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	var logins []string
+	for {
+		members, resp, err := g.client.Teams.ListTeamMembersBySlug(ctx, org, slug, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list members of team %s/%s: %w", org, slug, err)
+		}
+		for _, member := range members {
+			logins = append(logins, member.GetLogin())
+		}
+		if resp.NextPage == 0 {
+			return logins, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
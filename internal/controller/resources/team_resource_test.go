@@ -0,0 +1,72 @@
+package resources
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Teams", func() {
+	var (
+		mux    *http.ServeMux
+		server *httptest.Server
+		g      *GithubClient
+	)
+
+	BeforeEach(func() {
+		mux = http.NewServeMux()
+		server = httptest.NewServer(mux)
+		g = newTestGithubClient(server)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("Should list the members of the team across pages", func() {
+		mux.HandleFunc("/orgs/org/teams/oncall/members", func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("page") == "2" {
+				fmt.Fprint(w, `[{"login": "hubot"}]`)
+				return
+			}
+			w.Header().Set("Link", fmt.Sprintf(`<%s/orgs/org/teams/oncall/members?page=2>; rel="next"`, server.URL))
+			fmt.Fprint(w, `[{"login": "octocat"}, {"login": "monalisa"}]`)
+		})
+
+		members, err := g.TeamMembers(context.Background(), "org", "oncall")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(members).To(Equal([]string{"octocat", "monalisa", "hubot"}))
+	})
+
+	It("Should return a team the token can't read as not found", func() {
+		mux.HandleFunc("/orgs/org/teams/oncall/members", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message": "Not Found"}`)
+		})
+
+		_, err := g.TeamMembers(context.Background(), "org", "oncall")
+		Expect(IsNotFound(err)).To(BeTrue())
+	})
+
+	DescribeTable("Should parse team references",
+		func(team, org, slug string, valid bool) {
+			gotOrg, gotSlug, err := ParseTeam(team)
+			if !valid {
+				Expect(err).To(HaveOccurred())
+				return
+			}
+			Expect(err).NotTo(HaveOccurred())
+			Expect(gotOrg).To(Equal(org))
+			Expect(gotSlug).To(Equal(slug))
+		},
+		Entry("org and slug", "my-org/on-call", "my-org", "on-call", true),
+		Entry("missing slug", "my-org/", "", "", false),
+		Entry("missing org", "/on-call", "", "", false),
+		Entry("no separator", "on-call", "", "", false),
+		Entry("extra segment", "my-org/on-call/x", "", "", false),
+	)
+})
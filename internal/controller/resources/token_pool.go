@@ -0,0 +1,176 @@
+package resources
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v47/github"
+)
+
+// tokenState tracks the rate limit GitHub last reported for a token
+type tokenState struct {
+	remaining int
+	reset     time.Time
+	known     bool
+}
+
+// TokenPool is an http.RoundTripper authenticating requests with one of several tokens,
+// failing over to the next token when GitHub reports the current one is rate limited
+type TokenPool struct {
+	mu     sync.Mutex
+	tokens []string
+	states map[string]*tokenState
+	base   http.RoundTripper
+	now    func() time.Time
+}
+
+// NewTokenPool creates a pool rotating between the given tokens
+func NewTokenPool(tokens []string) *TokenPool {
+	p := &TokenPool{
+		states: map[string]*tokenState{},
+		base:   http.DefaultTransport,
+		now:    time.Now,
+	}
+	p.SetTokens(tokens)
+	return p
+}
+
+// ParseTokens splits a list of tokens separated by newlines or commas
+func ParseTokens(s string) []string {
+	var tokens []string
+	for _, token := range strings.FieldsFunc(s, func(r rune) bool { return r == '\n' || r == ',' }) {
+		if token = strings.TrimSpace(token); token != "" {
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens
+}
+
+// SetTokens replaces the tokens of the pool, keeping the tracked limits of the tokens still in it
+func (p *TokenPool) SetTokens(tokens []string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	states := map[string]*tokenState{}
+	for _, token := range tokens {
+		if state, ok := p.states[token]; ok {
+			states[token] = state
+		} else {
+			states[token] = &tokenState{}
+		}
+	}
+	p.tokens = append([]string(nil), tokens...)
+	p.states = states
+}
+
+// Remaining returns the number of requests GitHub last reported left for the token,
+// and whether it reported any yet
+func (p *TokenPool) Remaining(token string) (int, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	state, ok := p.states[token]
+	if !ok || !state.known {
+		return 0, false
+	}
+	return state.remaining, true
+}
+
+// next returns the first token not known to be exhausted, skipping the tried ones, and whether
+// it is available. When every token is exhausted the one resetting first is returned
+func (p *TokenPool) next(tried map[string]bool) (string, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var fallback string
+	for _, token := range p.tokens {
+		if tried[token] {
+			continue
+		}
+		state := p.states[token]
+		if !state.known || state.remaining > 0 || !p.now().Before(state.reset) {
+			return token, true
+		}
+		if fallback == "" || state.reset.Before(p.states[fallback].reset) {
+			fallback = token
+		}
+	}
+	return fallback, false
+}
+
+// record updates the tracked limit of the token from the rate limit headers of the response
+func (p *TokenPool) record(token string, resp *http.Response) {
+	rate, ok := parseRate(resp)
+	if !ok {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if state, ok := p.states[token]; ok {
+		state.remaining = rate.Remaining
+		state.reset = rate.Reset.Time
+		state.known = true
+	}
+}
+
+// isRateLimited reports whether GitHub refused the request because the token ran out of requests
+func isRateLimited(resp *http.Response) bool {
+	return (resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests) &&
+		resp.Header.Get("X-RateLimit-Remaining") == "0"
+}
+
+// RoundTrip sends the request with a non-exhausted token, retrying with the next token
+// whenever GitHub reports the current one is rate limited
+func (p *TokenPool) RoundTrip(req *http.Request) (*http.Response, error) {
+	tried := map[string]bool{}
+	for {
+		token, _ := p.next(tried)
+		if token == "" {
+			return nil, fmt.Errorf("token pool is empty")
+		}
+		tried[token] = true
+
+		attempt := req.Clone(req.Context())
+		if len(tried) > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			attempt.Body = body
+		}
+		attempt.Header.Set("Authorization", "Bearer "+token)
+
+		resp, err := p.base.RoundTrip(attempt)
+		if err != nil {
+			return nil, err
+		}
+		p.record(token, resp)
+
+		// fail over only when there's another token to try and the body can be resent
+		if !isRateLimited(resp) || (req.Body != nil && req.GetBody == nil) {
+			return resp, nil
+		}
+		if _, available := p.next(tried); !available {
+			return resp, nil
+		}
+		resp.Body.Close()
+	}
+}
+
+// NewGithubClientWithPool initializes a new GitHub client authenticating with the tokens of the pool
+func NewGithubClientWithPool(pool *TokenPool, opts ...Option) *GithubClient {
+	recorder := &responseRecorder{base: pool}
+	client := github.NewClient(&http.Client{Transport: recorder})
+	client.UserAgent = UserAgent("")
+
+	g := &GithubClient{client: client, recorder: recorder}
+	for _, opt := range opts {
+		opt(g)
+	}
+
+	return g
+}
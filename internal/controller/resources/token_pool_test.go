@@ -0,0 +1,83 @@
+package resources
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TokenPool", func() {
+	var (
+		server *httptest.Server
+		pool   *TokenPool
+		g      *GithubClient
+		used   []string
+	)
+
+	BeforeEach(func() {
+		used = nil
+		reset := strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)
+		mux := http.NewServeMux()
+		mux.HandleFunc("/repos/owner/repo/issues/1", func(w http.ResponseWriter, r *http.Request) {
+			token := r.Header.Get("Authorization")
+			used = append(used, token)
+			w.Header().Set("X-RateLimit-Reset", reset)
+			if token == "Bearer exhausted" {
+				w.Header().Set("X-RateLimit-Remaining", "0")
+				w.WriteHeader(http.StatusForbidden)
+				fmt.Fprint(w, `{"message": "API rate limit exceeded"}`)
+				return
+			}
+			w.Header().Set("X-RateLimit-Remaining", "4999")
+			fmt.Fprint(w, `{"number": 1, "state": "open"}`)
+		})
+		server = httptest.NewServer(mux)
+
+		pool = NewTokenPool([]string{"exhausted", "fresh"})
+		g = NewGithubClientWithPool(pool)
+		g.client.BaseURL = testBaseURL(server)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("Should fail over to the next token when one is rate limited", func() {
+		open, err := g.OpenDependencies("owner", "repo", []int{1})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(open).To(Equal([]int{1}))
+		Expect(used).To(Equal([]string{"Bearer exhausted", "Bearer fresh"}))
+
+		remaining, known := pool.Remaining("exhausted")
+		Expect(known).To(BeTrue())
+		Expect(remaining).To(Equal(0))
+		remaining, _ = pool.Remaining("fresh")
+		Expect(remaining).To(Equal(4999))
+	})
+
+	It("Should skip an exhausted token until its limit resets", func() {
+		_, err := g.OpenDependencies("owner", "repo", []int{1})
+		Expect(err).NotTo(HaveOccurred())
+
+		used = nil
+		_, err = g.OpenDependencies("owner", "repo", []int{1})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(used).To(Equal([]string{"Bearer fresh"}))
+	})
+
+	It("Should surface the rate limit when every token is exhausted", func() {
+		pool.SetTokens([]string{"exhausted"})
+		_, err := g.OpenDependencies("owner", "repo", []int{1})
+		Expect(err).To(HaveOccurred())
+		Expect(used).To(Equal([]string{"Bearer exhausted"}))
+	})
+
+	It("Should parse tokens separated by newlines or commas", func() {
+		Expect(ParseTokens("a\nb, c\n\n")).To(Equal([]string{"a", "b", "c"}))
+	})
+})
@@ -2,34 +2,93 @@ package status
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/google/go-github/v47/github"
 	batchv1 "github.com/oshribelay/github-issue-operator/api/v1"
+	"github.com/oshribelay/github-issue-operator/internal/controller/finalizer"
 	"github.com/oshribelay/github-issue-operator/internal/controller/resources"
 	"github.com/oshribelay/github-issue-operator/internal/controller/utils"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"slices"
+	"strings"
+	"time"
 )
 
-func Update(ctx context.Context, c client.Client, githubIssue *batchv1.GithubIssue, issue *github.Issue) error {
+// closeVerifyAttempts and closeVerifyInterval bound the re-fetches confirming a closed issue
+const (
+	closeVerifyAttempts = 3
+	closeVerifyInterval = time.Second
+)
+
+// disabledConditions are the condition types Update leaves out of the status
+var disabledConditions = map[string]bool{}
+
+// stateConditions are the condition types the operator reads back as the state of the
+// GithubIssue, leaving them out of the status would lose that state
+var stateConditions = []string{
+	"IssueOpen",
+	ClosedAsDuplicateCondition,
+	ClosedByMilestoneCondition,
+	IssueNumberCollisionCondition,
+	"NotificationDelivered",
+	SLABreachedCondition,
+	RepoNotFoundCondition,
+	APIHostForbiddenCondition,
+	ConvertedToDiscussionCondition,
+	BodyTooLargeCondition,
+}
+
+// SetDisabledConditions configures the condition types Update leaves out of the status, e.g.
+// HasPR, it should be called before the manager starts. Update drops them from the whole
+// status, so the conditions read back as state, like IssueOpen or RepoNotFound, can't be
+// disabled.
+func SetDisabledConditions(conditionTypes []string) error {
+	for _, conditionType := range conditionTypes {
+		if slices.Contains(stateConditions, conditionType) {
+			return fmt.Errorf("the %s condition holds the state of the GithubIssue and can't be disabled", conditionType)
+		}
+	}
+	disabledConditions = map[string]bool{}
+	for _, conditionType := range conditionTypes {
+		disabledConditions[conditionType] = true
+	}
+	return nil
+}
+
+// Update sets the status conditions derived from the GitHub issue, followed by any extra
+// conditions computed by the controller, and writes the status of the GithubIssue CR
+func Update(ctx context.Context, c client.Client, githubIssue *batchv1.GithubIssue, issue *github.Issue, extra ...metav1.Condition) error {
 	conditions := []metav1.Condition{}
 
-	// check if the issue is open
-	if *issue.State == "open" {
+	// check if the issue is open, a partial issue without a state is reported as unknown
+	switch issue.GetState() {
+	case "open":
 		conditions = append(conditions, metav1.Condition{
 			Type:               "IssueOpen",
 			Status:             metav1.ConditionTrue,
 			LastTransitionTime: metav1.Now(),
 			Reason:             "IssueIsOpen",
-			Message:            fmt.Sprintf("Issue #%d is currently open", *issue.Number),
+			Message:            fmt.Sprintf("Issue #%d is currently open", issue.GetNumber()),
 		})
-	} else {
+	case "":
+		conditions = append(conditions, metav1.Condition{
+			Type:               "IssueOpen",
+			Status:             metav1.ConditionUnknown,
+			LastTransitionTime: metav1.Now(),
+			Reason:             "StateUnknown",
+			Message:            fmt.Sprintf("GitHub didn't report the state of issue #%d", issue.GetNumber()),
+		})
+	default:
 		conditions = append(conditions, metav1.Condition{
 			Type:               "IssueOpen",
 			Status:             metav1.ConditionFalse,
 			LastTransitionTime: metav1.Now(),
 			Reason:             "IssueIsClosed",
-			Message:            fmt.Sprintf("Issue #%d is closed", *issue.Number),
+			Message:            fmt.Sprintf("Issue #%d is closed", issue.GetNumber()),
 		})
 	}
 
@@ -52,9 +111,65 @@ func Update(ctx context.Context, c client.Client, githubIssue *batchv1.GithubIss
 		})
 	}
 
+	// reflect the lock state of the issue on GitHub
+	if issue.GetLocked() {
+		message := fmt.Sprintf("Issue #%d is locked", issue.GetNumber())
+		if reason := issue.GetActiveLockReason(); reason != "" {
+			message += " as " + reason
+		}
+		conditions = append(conditions, metav1.Condition{
+			Type:               "Locked",
+			Status:             metav1.ConditionTrue,
+			LastTransitionTime: metav1.Now(),
+			Reason:             "IssueLocked",
+			Message:            message,
+		})
+	} else {
+		conditions = append(conditions, metav1.Condition{
+			Type:               "Locked",
+			Status:             metav1.ConditionFalse,
+			LastTransitionTime: metav1.Now(),
+			Reason:             "IssueUnlocked",
+			Message:            fmt.Sprintf("Issue #%d is not locked", issue.GetNumber()),
+		})
+	}
+
+	conditions = append(conditions, FinalizerPresent(githubIssue))
+
+	// the extra conditions take precedence over the ones derived from the issue, e.g. the
+	// Locked condition reporting a failed lock
+	for _, condition := range extra {
+		replaced := false
+		for i := range conditions {
+			if conditions[i].Type == condition.Type {
+				conditions[i] = condition
+				replaced = true
+			}
+		}
+		if !replaced {
+			conditions = append(conditions, condition)
+		}
+	}
+
+	// leave the disabled conditions out
+	enabled := conditions[:0]
+	for _, condition := range conditions {
+		if !disabledConditions[condition.Type] {
+			enabled = append(enabled, condition)
+		}
+	}
+
 	// set the status fields to be updated
-	githubIssue.Status.Conditions = conditions
-	githubIssue.Status.IssueNumber = int32(*issue.Number)
+	githubIssue.Status.Conditions = enabled
+	// a partial issue without a number keeps the recorded one
+	if issue.GetNumber() > 0 {
+		githubIssue.Status.IssueNumber = int32(issue.GetNumber())
+	}
+	githubIssue.Status.CommentCount = int32(issue.GetComments())
+	githubIssue.Status.Assignees = nil
+	for _, assignee := range issue.Assignees {
+		githubIssue.Status.Assignees = append(githubIssue.Status.Assignees, assignee.GetLogin())
+	}
 	githubIssue.Status.LastUpdated = metav1.Now()
 
 	// update the status of the GithubIssue CR
@@ -65,25 +180,505 @@ func Update(ctx context.Context, c client.Client, githubIssue *batchv1.GithubIss
 	return nil
 }
 
-func Delete(ctx context.Context, c client.Client, gClient *resources.GithubClient, githubIssue *batchv1.GithubIssue) error {
+// SetRateLimit records the GitHub rate limit headroom in the status, it is written by the next status update
+func SetRateLimit(githubIssue *batchv1.GithubIssue, rate github.Rate) {
+	remaining := int32(rate.Remaining)
+	githubIssue.Status.RateLimitRemaining = &remaining
+	githubIssue.Status.RateLimitResetTime = &metav1.Time{Time: rate.Reset.Time}
+}
+
+// BodyTruncated returns the condition reporting whether the issue body had to be
+// truncated to fit GitHub's body size limit
+func BodyTruncated(truncated bool) metav1.Condition {
+	if truncated {
+		return metav1.Condition{
+			Type:               "BodyTruncated",
+			Status:             metav1.ConditionTrue,
+			LastTransitionTime: metav1.Now(),
+			Reason:             "BodyExceedsLimit",
+			Message:            "The issue body exceeded GitHub's size limit and was truncated",
+		}
+	}
+	return metav1.Condition{
+		Type:               "BodyTruncated",
+		Status:             metav1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             "BodyWithinLimit",
+		Message:            "The issue body fits within GitHub's size limit",
+	}
+}
+
+// TokenScopes returns the condition reporting whether the token has the scopes the
+// operator needs, missing lists the scopes it lacks
+func TokenScopes(missing []string) metav1.Condition {
+	if len(missing) > 0 {
+		return metav1.Condition{
+			Type:               "TokenScopesSufficient",
+			Status:             metav1.ConditionFalse,
+			LastTransitionTime: metav1.Now(),
+			Reason:             "ScopesMissing",
+			Message: fmt.Sprintf("The GitHub token lacks the %s scopes, GitHub will refuse requests with 403 "+
+				"until the token is regenerated with them", strings.Join(missing, ", ")),
+		}
+	}
+	return metav1.Condition{
+		Type:               "TokenScopesSufficient",
+		Status:             metav1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             "ScopesGranted",
+		Message:            "The GitHub token has the required scopes",
+	}
+}
+
+// TemplateApplied returns the condition reporting the label whose template rendered the body
+func TemplateApplied(label string) metav1.Condition {
+	return metav1.Condition{
+		Type:               "TemplateApplied",
+		Status:             metav1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             "LabelTemplate",
+		Message:            fmt.Sprintf("The body was rendered with the template of label %s", label),
+	}
+}
+
+// LinkedResourceHealthy returns the condition reporting the health of the linked resource
+func LinkedResourceHealthy(healthy bool) metav1.Condition {
+	if healthy {
+		return metav1.Condition{
+			Type:               "LinkedResourceHealthy",
+			Status:             metav1.ConditionTrue,
+			LastTransitionTime: metav1.Now(),
+			Reason:             "ResourceHealthy",
+			Message:            "The linked resource is healthy",
+		}
+	}
+	return metav1.Condition{
+		Type:               "LinkedResourceHealthy",
+		Status:             metav1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             "ResourceUnhealthy",
+		Message:            "The linked resource is unhealthy",
+	}
+}
+
+// LinkedResourceMissing returns the condition reporting the linked resource doesn't exist
+func LinkedResourceMissing() metav1.Condition {
+	return metav1.Condition{
+		Type:               "LinkedResourceHealthy",
+		Status:             metav1.ConditionUnknown,
+		LastTransitionTime: metav1.Now(),
+		Reason:             "ResourceNotFound",
+		Message:            "The linked resource doesn't exist, the issue state is left as is",
+	}
+}
+
+// LinkedResourceForbidden returns the condition reporting the linked resource can't be read,
+// being of a kind or in a namespace the operator doesn't allow
+func LinkedResourceForbidden(err error) metav1.Condition {
+	return metav1.Condition{
+		Type:               "LinkedResourceHealthy",
+		Status:             metav1.ConditionUnknown,
+		LastTransitionTime: metav1.Now(),
+		Reason:             "ResourceNotAllowed",
+		Message:            fmt.Sprintf("The linked resource isn't read, the issue state is left as is: %v", err),
+	}
+}
+
+// FeatureUnavailable returns the condition reporting the features the issue uses that the
+// GitHub Enterprise Server version lacks, the operator degrades them instead of failing
+func FeatureUnavailable(serverVersion string, unavailable []resources.Feature) metav1.Condition {
+	if len(unavailable) > 0 {
+		names := make([]string, 0, len(unavailable))
+		for _, feature := range unavailable {
+			names = append(names, string(feature))
+		}
+		return metav1.Condition{
+			Type:               "FeatureUnavailable",
+			Status:             metav1.ConditionTrue,
+			LastTransitionTime: metav1.Now(),
+			Reason:             "UnsupportedServerVersion",
+			Message: fmt.Sprintf("GitHub Enterprise Server %s lacks %s, the issue is managed without them",
+				serverVersion, strings.Join(names, ", ")),
+		}
+	}
+	return metav1.Condition{
+		Type:               "FeatureUnavailable",
+		Status:             metav1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             "FeaturesAvailable",
+		Message:            fmt.Sprintf("GitHub Enterprise Server %s supports the features the issue uses", serverVersion),
+	}
+}
+
+// ExternalEditDetected returns the condition reporting whether the issue body was edited on
+// GitHub, and whether the edit was overwritten or preserved by the policy
+func ExternalEditDetected(detected bool, policy batchv1.ExternalEditPolicy) metav1.Condition {
+	if !detected {
+		return metav1.Condition{
+			Type:               "ExternalEditDetected",
+			Status:             metav1.ConditionFalse,
+			LastTransitionTime: metav1.Now(),
+			Reason:             "BodyManaged",
+			Message:            "The issue body is the one the operator last wrote",
+		}
+	}
+	if policy == batchv1.ExternalEditPolicyPreserve {
+		return metav1.Condition{
+			Type:               "ExternalEditDetected",
+			Status:             metav1.ConditionTrue,
+			LastTransitionTime: metav1.Now(),
+			Reason:             "EditPreserved",
+			Message:            "The issue body was edited on GitHub, the edit is left in place",
+		}
+	}
+	return metav1.Condition{
+		Type:               "ExternalEditDetected",
+		Status:             metav1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             "EditOverwritten",
+		Message:            "The issue body was edited on GitHub, the description was restored over it",
+	}
+}
+
+// AssigneesOverloaded returns the condition warning about the assignees holding more open
+// operator-managed issues than the limit, it is advisory and doesn't block the assignment
+func AssigneesOverloaded(overloaded []string, limit int) metav1.Condition {
+	if len(overloaded) > 0 {
+		return metav1.Condition{
+			Type:               "AssigneesOverloaded",
+			Status:             metav1.ConditionTrue,
+			LastTransitionTime: metav1.Now(),
+			Reason:             "TooManyOpenIssues",
+			Message: fmt.Sprintf("%s would be assigned more than %d open issues",
+				strings.Join(overloaded, ", "), limit),
+		}
+	}
+	return metav1.Condition{
+		Type:               "AssigneesOverloaded",
+		Status:             metav1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             "WithinLimit",
+		Message:            fmt.Sprintf("Every assignee holds at most %d open issues", limit),
+	}
+}
+
+// TeamUnresolved returns the condition reporting the issue couldn't be assigned a member of
+// its team, either because the token can't read the team or because it has no members
+func TeamUnresolved(team string, err error) metav1.Condition {
+	condition := metav1.Condition{
+		Type:               "TeamUnresolved",
+		Status:             metav1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             "TeamNotAccessible",
+		Message:            fmt.Sprintf("The members of team %s can't be listed, the token may lack the read:org scope: %v", team, err),
+	}
+	if err == nil {
+		condition.Reason = "EmptyTeam"
+		condition.Message = fmt.Sprintf("Team %s has no members to assign the issue to", team)
+	}
+	return condition
+}
+
+// ClosedAsDuplicateCondition is the type of the condition reporting the issue was closed as a duplicate
+const ClosedAsDuplicateCondition = "ClosedAsDuplicate"
+
+// ClosedAsDuplicate returns the condition reporting whether the issue is closed as a
+// duplicate of another issue, zero reports it was reopened as no longer a duplicate
+func ClosedAsDuplicate(duplicateOf int) metav1.Condition {
+	if duplicateOf > 0 {
+		return metav1.Condition{
+			Type:               ClosedAsDuplicateCondition,
+			Status:             metav1.ConditionTrue,
+			LastTransitionTime: metav1.Now(),
+			Reason:             "DuplicateOf",
+			Message:            fmt.Sprintf("Closed as a duplicate of #%d", duplicateOf),
+		}
+	}
+	return metav1.Condition{
+		Type:               ClosedAsDuplicateCondition,
+		Status:             metav1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             "NoLongerDuplicate",
+		Message:            "Reopened as the issue is no longer a duplicate",
+	}
+}
+
+// NotificationDelivered returns the condition reporting whether the event was delivered to
+// the notify URL, a non-nil err reports the delivery failed
+func NotificationDelivered(event string, err error) metav1.Condition {
+	if err != nil {
+		return metav1.Condition{
+			Type:               "NotificationDelivered",
+			Status:             metav1.ConditionFalse,
+			LastTransitionTime: metav1.Now(),
+			Reason:             "DeliveryFailed",
+			Message:            fmt.Sprintf("Unable to notify of the issue being %s: %s", event, err),
+		}
+	}
+	return metav1.Condition{
+		Type:               "NotificationDelivered",
+		Status:             metav1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             "Delivered",
+		Message:            fmt.Sprintf("Notified of the issue being %s", event),
+	}
+}
+
+// SLABreachedCondition is the type of the condition reporting the open issue outlived its SLA
+const SLABreachedCondition = "SLABreached"
+
+// SLABreached returns the condition reporting whether the issue stayed open longer than maxAge
+func SLABreached(breached bool, maxAge time.Duration) metav1.Condition {
+	if breached {
+		return metav1.Condition{
+			Type:               SLABreachedCondition,
+			Status:             metav1.ConditionTrue,
+			LastTransitionTime: metav1.Now(),
+			Reason:             "MaxAgeExceeded",
+			Message:            fmt.Sprintf("The issue has been open for longer than %s", maxAge),
+		}
+	}
+	return metav1.Condition{
+		Type:               SLABreachedCondition,
+		Status:             metav1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             "WithinMaxAge",
+		Message:            fmt.Sprintf("The issue is closed or open for less than %s", maxAge),
+	}
+}
+
+// ClosedByMilestoneCondition is the type of the condition reporting the issue was closed as
+// its milestone is complete
+const ClosedByMilestoneCondition = "ClosedByMilestone"
+
+// ClosedByMilestone returns the condition reporting the issue was closed as its milestone is complete
+func ClosedByMilestone(milestone string) metav1.Condition {
+	return metav1.Condition{
+		Type:               ClosedByMilestoneCondition,
+		Status:             metav1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             "MilestoneCompleted",
+		Message:            fmt.Sprintf("Closed as milestone %q is complete", milestone),
+	}
+}
+
+// IssueNumberCollisionCondition is the type of the condition reporting other GithubIssues
+// manage the same issue
+const IssueNumberCollisionCondition = "IssueNumberCollision"
+
+// IssueNumberCollision returns the condition reporting the issue is also managed by the other
+// GithubIssues, keeping tells whether this GithubIssue keeps editing the issue
+func IssueNumberCollision(number int, others []string, keeping bool) metav1.Condition {
+	message := fmt.Sprintf("Issue #%d is also managed by %s, ", number, strings.Join(others, ", "))
+	if keeping {
+		message += "this GithubIssue is the oldest and keeps editing it"
+	} else {
+		message += "the oldest GithubIssue keeps editing it"
+	}
+	return metav1.Condition{
+		Type:               IssueNumberCollisionCondition,
+		Status:             metav1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             "SameIssueNumber",
+		Message:            message,
+	}
+}
+
+// ClosedByDeadline returns the condition reporting the issue was closed as its closeAt time passed
+func ClosedByDeadline(closeAt time.Time) metav1.Condition {
+	return metav1.Condition{
+		Type:               "ClosedByDeadline",
+		Status:             metav1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             "DeadlinePassed",
+		Message:            fmt.Sprintf("Closed as its deadline %s passed", closeAt.UTC().Format(time.RFC3339)),
+	}
+}
+
+// StateChangeThrottled returns the condition reporting the issue is held in its state as it
+// changed state too recently, until the wait is over
+func StateChangeThrottled(wait time.Duration) metav1.Condition {
+	return metav1.Condition{
+		Type:               "StateChangeThrottled",
+		Status:             metav1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             "ChangedTooRecently",
+		Message:            fmt.Sprintf("The issue changed state too recently, it is left as is for another %s", wait),
+	}
+}
+
+// Superseded returns the condition reporting the issue was closed as superseded by its successor
+func Superseded(successor string) metav1.Condition {
+	return metav1.Condition{
+		Type:               "Superseded",
+		Status:             metav1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             "SupersededBy",
+		Message:            fmt.Sprintf("Closed as superseded by %s", successor),
+	}
+}
+
+// Blocked returns the condition reporting whether the issue is blocked by open issues,
+// a non-nil err reports that the dependencies couldn't be read
+func Blocked(openDependencies []int, err error) metav1.Condition {
+	if err != nil {
+		return metav1.Condition{
+			Type:               "Blocked",
+			Status:             metav1.ConditionUnknown,
+			LastTransitionTime: metav1.Now(),
+			Reason:             "DependencyUnreadable",
+			Message:            fmt.Sprintf("Unable to read the blocking issues: %v", err),
+		}
+	}
+	if len(openDependencies) > 0 {
+		return metav1.Condition{
+			Type:               "Blocked",
+			Status:             metav1.ConditionTrue,
+			LastTransitionTime: metav1.Now(),
+			Reason:             "DependenciesOpen",
+			Message:            fmt.Sprintf("Blocked by open issues %v", openDependencies),
+		}
+	}
+	return metav1.Condition{
+		Type:               "Blocked",
+		Status:             metav1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             "DependenciesClosed",
+		Message:            "All blocking issues are closed",
+	}
+}
+
+// Locked returns the condition reporting whether the closed issue was locked, a non-nil
+// err reports the issue was closed but couldn't be locked
+func Locked(reason string, err error) metav1.Condition {
+	if err != nil {
+		return metav1.Condition{
+			Type:               "Locked",
+			Status:             metav1.ConditionFalse,
+			LastTransitionTime: metav1.Now(),
+			Reason:             "LockFailed",
+			Message:            fmt.Sprintf("The issue was closed but couldn't be locked: %v", err),
+		}
+	}
+	return metav1.Condition{
+		Type:               "Locked",
+		Status:             metav1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             "IssueLocked",
+		Message:            fmt.Sprintf("The issue is closed and locked as %s", reason),
+	}
+}
+
+// UpdateAdvisory records the security advisory created for the GithubIssue, a non-nil
+// createErr reports the advisory couldn't be created
+func UpdateAdvisory(ctx context.Context, c client.Client, githubIssue *batchv1.GithubIssue, advisory *resources.SecurityAdvisory, createErr error) error {
+	condition := metav1.Condition{
+		Type:               "AdvisoryCreated",
+		Status:             metav1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             "CreationFailed",
+		Message:            fmt.Sprintf("Unable to create the security advisory: %v", createErr),
+	}
+	if errors.Is(createErr, resources.ErrInsufficientScope) {
+		condition.Reason = "InsufficientScope"
+		condition.Message = "The token lacks the scope to create security advisories, " +
+			"it needs the repository security advisories write permission"
+	}
+	if createErr == nil {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "AdvisoryDrafted"
+		condition.Message = fmt.Sprintf("Security advisory %s was drafted", advisory.GHSAID)
+		githubIssue.Status.AdvisoryID = advisory.GHSAID
+	}
+
+	githubIssue.Status.Conditions = []metav1.Condition{condition}
+	githubIssue.Status.LastUpdated = metav1.Now()
+
+	if err := c.Status().Update(ctx, githubIssue); err != nil {
+		return fmt.Errorf("failed to update GithubIssue status: %w", err)
+	}
+
+	return nil
+}
+
+// Delete closes the GitHub issue, found by its title as sent to GitHub, and deletes the GithubIssue
+func Delete(ctx context.Context, c client.Client, gClient *resources.GithubClient, githubIssue *batchv1.GithubIssue, title string) error {
 	owner, repo, err := utils.ParseRepoUrl(githubIssue.Spec.Repo)
 	issueNumber := int(githubIssue.Status.IssueNumber)
 	if err != nil {
 		return fmt.Errorf("failed to parse repo url: %w", err)
 	}
 
-	// check if the issue exists
-	issue, err := gClient.CheckIssueExists(owner, repo, githubIssue.Spec.Title, issueNumber)
+	// security advisories replace the issue, close the draft instead
+	if githubIssue.Spec.SecurityAdvisory {
+		if githubIssue.Status.AdvisoryID != "" {
+			if err := gClient.CloseAdvisory(ctx, owner, repo, githubIssue.Status.AdvisoryID); err != nil {
+				return err
+			}
+		}
+		if err := c.Delete(ctx, githubIssue); err != nil {
+			return fmt.Errorf("failed to delete GithubIssue: %w", err)
+		}
+		return nil
+	}
+
+	// check if the issue exists, an issue whose title merely matches loosely isn't closed
+	issue, err := gClient.CheckIssueExistsExact(owner, repo, title, issueNumber, string(githubIssue.UID))
 	if err != nil {
 		return fmt.Errorf("failed to check if issue exists: %w", err)
 	}
 
+	// post the comment of an earlier state change a failed attempt didn't get to, even though
+	// the issue may be closed by now
+	if issue != nil {
+		if err := PostPendingComment(ctx, gClient, githubIssue, owner, repo, issue); err != nil {
+			return err
+		}
+	}
+
 	// close the issue if it exists and still open
-	if issue != nil && *issue.State == "open" {
+	if issue != nil && issue.GetState() == "open" {
+		// record what resolved the issue before closing it, a retry then finds the comment
+		// pending rather than losing it with the issue closed
+		if githubIssue.Spec.ClosedBy != "" {
+			if err := RecordPendingComment(ctx, c, githubIssue, utils.ClosingComment("", githubIssue.Spec.ClosedBy), "closed"); err != nil {
+				return err
+			}
+		}
+
 		err := gClient.CloseIssue(owner, repo, issue)
 		if err != nil {
 			return fmt.Errorf("failed to close issue: %w", err)
 		}
+		issue.State = github.String("closed")
+
+		if err := PostPendingComment(ctx, gClient, githubIssue, owner, repo, issue); err != nil {
+			return fmt.Errorf("failed to comment the closing reference: %w", err)
+		}
+
+		// make sure GitHub reflects the close before the finalizer is removed
+		if err := gClient.VerifyClosed(ctx, owner, repo, issue.GetNumber(), closeVerifyAttempts, closeVerifyInterval); err != nil {
+			return err
+		}
+	}
+
+	// lock the closed issue best-effort, a lock failure mustn't keep the GithubIssue from being
+	// deleted once its issue is closed
+	if issue != nil && githubIssue.Spec.LockOnClose && !issue.GetLocked() {
+		if err := gClient.LockIssue(ctx, owner, repo, issue.GetNumber(), githubIssue.Spec.LockReason); err != nil {
+			logger := log.FromContext(ctx)
+			logger.Error(err, "issue closed but unable to lock it, deleting the GithubIssue anyway")
+			if statusErr := Update(ctx, c, githubIssue, issue, Locked(githubIssue.Spec.LockReason, err)); statusErr != nil {
+				logger.Error(statusErr, "unable to report the failed lock")
+			}
+		}
+	}
+
+	// check the issue off in the tracking issue
+	if issue != nil && githubIssue.Spec.TrackingIssue > 0 {
+		if err := gClient.SyncTrackingIssue(ctx, owner, repo, githubIssue.Spec.TrackingIssue, issue.GetNumber(), true); err != nil {
+			return err
+		}
 	}
 
 	// remove the GithubIssue CR from the cluster
@@ -94,6 +689,366 @@ func Delete(ctx context.Context, c client.Client, gClient *resources.GithubClien
 	return nil
 }
 
+// RecordIssueNumber patches the number of the issue just created into the status of a
+// GithubIssue along with whether its initial comments are pending, so they're recorded even
+// when the status update that follows fails. The other status fields of the GithubIssue are
+// left as they are in memory.
+func RecordIssueNumber(ctx context.Context, c client.Client, githubIssue *batchv1.GithubIssue, number int) error {
+	recorded := githubIssue.DeepCopy()
+	patch := client.MergeFrom(recorded.DeepCopy())
+	recorded.Status.IssueNumber = int32(number)
+	// recorded along with the number, the issue found on a retry is the one just created
+	recorded.Status.InitialCommentsPending = len(githubIssue.Spec.InitialComments) > 0
+	if err := c.Status().Patch(ctx, recorded, patch); err != nil {
+		return fmt.Errorf("failed to record the issue number: %w", err)
+	}
+	githubIssue.ResourceVersion = recorded.ResourceVersion
+	githubIssue.Status.IssueNumber = recorded.Status.IssueNumber
+	githubIssue.Status.InitialCommentsPending = recorded.Status.InitialCommentsPending
+	return nil
+}
+
+// RecordPendingComment patches the comment to post along with changing the issue to the state
+// into the status of the GithubIssue before the state changes, tagged with a marker unique to
+// this change. A reconcile failing after the change then still finds the comment pending, and
+// the marker tells whether it was posted.
+func RecordPendingComment(ctx context.Context, c client.Client, githubIssue *batchv1.GithubIssue, comment, state string) error {
+	nonce := utils.ContentHash(fmt.Sprintf("%s\n%s\n%s", githubIssue.UID, comment, time.Now().Format(time.RFC3339Nano)))
+	recorded := githubIssue.DeepCopy()
+	patch := client.MergeFrom(recorded.DeepCopy())
+	recorded.Status.PendingComment = &batchv1.PendingComment{
+		Body:  comment + "\n\n" + utils.StateCommentMarker(nonce),
+		State: state,
+	}
+	if err := c.Status().Patch(ctx, recorded, patch); err != nil {
+		return fmt.Errorf("failed to record the pending comment: %w", err)
+	}
+	githubIssue.ResourceVersion = recorded.ResourceVersion
+	githubIssue.Status.PendingComment = recorded.Status.PendingComment
+	return nil
+}
+
+// PostPendingComment posts the comment recorded before a change of the issue state unless the
+// issue already has it, then clears it from the status in memory, the next status update
+// persisting that. The comment of a change that didn't happen, the issue not being in the
+// state it was changed to, is dropped as the reconcile redoes the change with a new one.
+func PostPendingComment(ctx context.Context, gClient *resources.GithubClient, githubIssue *batchv1.GithubIssue, owner, repo string, issue *github.Issue) error {
+	pending := githubIssue.Status.PendingComment
+	if pending == nil {
+		return nil
+	}
+	if issue.GetState() == pending.State {
+		if err := gClient.EnsureComment(ctx, owner, repo, issue.GetNumber(), pending.Body); err != nil {
+			return err
+		}
+	}
+	githubIssue.Status.PendingComment = nil
+	return nil
+}
+
+// RecordAdvisoryID patches the GHSA ID of the advisory just drafted into the status of the
+// GithubIssue, so a failing status update that follows doesn't have another advisory drafted
+func RecordAdvisoryID(ctx context.Context, c client.Client, githubIssue *batchv1.GithubIssue, ghsaID string) error {
+	recorded := githubIssue.DeepCopy()
+	patch := client.MergeFrom(recorded.DeepCopy())
+	recorded.Status.AdvisoryID = ghsaID
+	if err := c.Status().Patch(ctx, recorded, patch); err != nil {
+		return fmt.Errorf("failed to record the advisory ID: %w", err)
+	}
+	githubIssue.ResourceVersion = recorded.ResourceVersion
+	githubIssue.Status.AdvisoryID = recorded.Status.AdvisoryID
+	return nil
+}
+
+// RecordNotifiedState patches the state notified of into the status of the GithubIssue right
+// after the delivery, so that a later failing status update doesn't have the event sent again
+func RecordNotifiedState(ctx context.Context, c client.Client, githubIssue *batchv1.GithubIssue, state string) error {
+	recorded := githubIssue.DeepCopy()
+	patch := client.MergeFrom(recorded.DeepCopy())
+	recorded.Status.NotifiedState = state
+	if err := c.Status().Patch(ctx, recorded, patch); err != nil {
+		return fmt.Errorf("failed to record the notified state: %w", err)
+	}
+	githubIssue.ResourceVersion = recorded.ResourceVersion
+	githubIssue.Status.NotifiedState = recorded.Status.NotifiedState
+	return nil
+}
+
+// UpdateDeferredCreation writes the DeferredCreation condition to the status of a GithubIssue
+// whose issue isn't created until its create window opens at opensAt
+func UpdateDeferredCreation(ctx context.Context, c client.Client, githubIssue *batchv1.GithubIssue, opensAt time.Time) error {
+	meta.SetStatusCondition(&githubIssue.Status.Conditions, metav1.Condition{
+		Type:    "DeferredCreation",
+		Status:  metav1.ConditionTrue,
+		Reason:  "OutsideCreateWindow",
+		Message: fmt.Sprintf("The issue will be created once the create window opens at %s", opensAt.Format(time.RFC3339)),
+	})
+	return c.Status().Update(ctx, githubIssue)
+}
+
+// FinalizerPresent returns the condition reporting whether the GithubIssue holds the
+// finalizer closing its issue on deletion, telling why a GithubIssue stays Terminating
+func FinalizerPresent(githubIssue *batchv1.GithubIssue) metav1.Condition {
+	switch {
+	case !finalizer.Present(githubIssue):
+		return metav1.Condition{
+			Type:               "FinalizerPresent",
+			Status:             metav1.ConditionFalse,
+			LastTransitionTime: metav1.Now(),
+			Reason:             "FinalizerAbsent",
+			Message:            "Deleting the GithubIssue leaves its issue as is",
+		}
+	case !githubIssue.GetDeletionTimestamp().IsZero():
+		return metav1.Condition{
+			Type:               "FinalizerPresent",
+			Status:             metav1.ConditionTrue,
+			LastTransitionTime: metav1.Now(),
+			Reason:             "ClosingIssue",
+			Message:            "The GithubIssue is deleted once its issue is closed on GitHub",
+		}
+	default:
+		return metav1.Condition{
+			Type:               "FinalizerPresent",
+			Status:             metav1.ConditionTrue,
+			LastTransitionTime: metav1.Now(),
+			Reason:             "FinalizerAdded",
+			Message:            "Deleting the GithubIssue closes its issue first",
+		}
+	}
+}
+
+// UpdateFinalizerPresent writes the FinalizerPresent condition to the status of a GithubIssue,
+// e.g. one whose deletion is held by the finalizer failing to close its issue
+func UpdateFinalizerPresent(ctx context.Context, c client.Client, githubIssue *batchv1.GithubIssue) error {
+	meta.SetStatusCondition(&githubIssue.Status.Conditions, FinalizerPresent(githubIssue))
+	return c.Status().Update(ctx, githubIssue)
+}
+
+// UpdateFrozen writes the Frozen condition to the status of a GithubIssue whose GitHub edits
+// are held until the change freeze ends
+func UpdateFrozen(ctx context.Context, c client.Client, githubIssue *batchv1.GithubIssue, endsAt time.Time, reason string) error {
+	message := fmt.Sprintf("GitHub edits are held for a change freeze until %s", endsAt.Format(time.RFC3339))
+	if reason != "" {
+		message += ": " + reason
+	}
+	meta.SetStatusCondition(&githubIssue.Status.Conditions, metav1.Condition{
+		Type:    "Frozen",
+		Status:  metav1.ConditionTrue,
+		Reason:  "ChangeFreeze",
+		Message: message,
+	})
+	return c.Status().Update(ctx, githubIssue)
+}
+
+// UpdateCreationRateLimited writes the CreationRateLimited condition to the status of a
+// GithubIssue whose creation is held back by the operator's creation rate limit
+func UpdateCreationRateLimited(ctx context.Context, c client.Client, githubIssue *batchv1.GithubIssue, retryIn time.Duration) error {
+	meta.SetStatusCondition(&githubIssue.Status.Conditions, metav1.Condition{
+		Type:    "CreationRateLimited",
+		Status:  metav1.ConditionTrue,
+		Reason:  "TooManyCreations",
+		Message: fmt.Sprintf("The operator is creating issues at its maximum rate, retrying in %s", retryIn.Round(time.Second)),
+	})
+	return c.Status().Update(ctx, githubIssue)
+}
+
+// UpdateSecondaryRateLimited writes the SecondaryRateLimited condition to the status of a
+// GithubIssue whose reconcile hit GitHub's secondary rate limit
+func UpdateSecondaryRateLimited(ctx context.Context, c client.Client, githubIssue *batchv1.GithubIssue, retryAfter time.Duration) error {
+	meta.SetStatusCondition(&githubIssue.Status.Conditions, metav1.Condition{
+		Type:    "SecondaryRateLimited",
+		Status:  metav1.ConditionTrue,
+		Reason:  "AbuseDetection",
+		Message: fmt.Sprintf("GitHub's secondary rate limit was hit, reconciles are held back for %s", retryAfter),
+	})
+	return c.Status().Update(ctx, githubIssue)
+}
+
+// UpdateForbidden writes the condition telling apart why GitHub answered the reconcile with
+// 403 to the status of a GithubIssue, from the RateLimited, TokenScopeInsufficient and
+// AccessDenied conditions
+func UpdateForbidden(ctx context.Context, c client.Client, githubIssue *batchv1.GithubIssue, forbiddenErr error) error {
+	condition := metav1.Condition{Status: metav1.ConditionTrue}
+	var (
+		rateErr   *resources.RateLimitedError
+		scopeErr  *resources.ScopeInsufficientError
+		deniedErr *resources.AccessDeniedError
+	)
+	switch {
+	case errors.As(forbiddenErr, &rateErr):
+		condition.Type = "RateLimited"
+		condition.Reason = "RateLimitExhausted"
+		condition.Message = fmt.Sprintf("The rate limit of the token is exhausted until %s",
+			rateErr.Reset.UTC().Format(time.RFC3339))
+	case errors.As(forbiddenErr, &scopeErr) && len(scopeErr.Missing) > 0:
+		condition.Type = "TokenScopeInsufficient"
+		condition.Reason = "MissingScopes"
+		condition.Message = fmt.Sprintf("The token is missing scopes %s", strings.Join(scopeErr.Missing, ", "))
+	case errors.As(forbiddenErr, &scopeErr):
+		condition.Type = "TokenScopeInsufficient"
+		condition.Reason = "MissingPermissions"
+		condition.Message = "The token lacks the permissions to manage the issue"
+	case errors.As(forbiddenErr, &deniedErr):
+		condition.Type = "AccessDenied"
+		condition.Reason = "Forbidden"
+		condition.Message = fmt.Sprintf("GitHub denied access to the issue: %v", deniedErr.Err)
+	default:
+		return fmt.Errorf("not a 403 from GitHub: %w", forbiddenErr)
+	}
+	meta.SetStatusCondition(&githubIssue.Status.Conditions, condition)
+	return c.Status().Update(ctx, githubIssue)
+}
+
+// UpdateGitHubUnavailable writes the GitHubUnavailable condition to the status of a GithubIssue
+// whose reconcile found GitHub unavailable, e.g. during maintenance
+func UpdateGitHubUnavailable(ctx context.Context, c client.Client, githubIssue *batchv1.GithubIssue, retryAfter time.Duration) error {
+	meta.SetStatusCondition(&githubIssue.Status.Conditions, metav1.Condition{
+		Type:    "GitHubUnavailable",
+		Status:  metav1.ConditionTrue,
+		Reason:  "ServiceUnavailable",
+		Message: fmt.Sprintf("GitHub is unavailable, e.g. for maintenance, the issue is retried in %s", retryAfter),
+	})
+	return c.Status().Update(ctx, githubIssue)
+}
+
+// RepoNotFoundCondition is the type of the condition reporting the repository was deleted
+const RepoNotFoundCondition = "RepoNotFound"
+
+// UpdateRepoNotFound writes the RepoNotFound condition to the status of a GithubIssue whose
+// repository doesn't exist, for the generation of the spec naming it
+func UpdateRepoNotFound(ctx context.Context, c client.Client, githubIssue *batchv1.GithubIssue) error {
+	meta.SetStatusCondition(&githubIssue.Status.Conditions, metav1.Condition{
+		Type:               RepoNotFoundCondition,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: githubIssue.Generation,
+		Reason:             "RepositoryDeleted",
+		Message: fmt.Sprintf("The repository %s doesn't exist, the issue isn't retried until the spec changes",
+			githubIssue.Spec.Repo),
+	})
+	return c.Status().Update(ctx, githubIssue)
+}
+
+// APIHostForbiddenCondition is the type of the condition reporting that the API base URL of
+// the GithubIssue points at a host the operator doesn't allow
+const APIHostForbiddenCondition = "APIHostForbidden"
+
+// UpdateAPIHostForbidden reports that the host of the API base URL isn't allowed, the
+// GithubIssue isn't reconciled and no token is sent there
+func UpdateAPIHostForbidden(ctx context.Context, c client.Client, githubIssue *batchv1.GithubIssue) error {
+	meta.SetStatusCondition(&githubIssue.Status.Conditions, metav1.Condition{
+		Type:               APIHostForbiddenCondition,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: githubIssue.Generation,
+		Reason:             "HostNotAllowed",
+		Message: fmt.Sprintf("The host of the API base URL %s isn't allowed by the operator, the issue isn't reconciled until the spec changes",
+			githubIssue.Spec.APIBaseURL),
+	})
+	return c.Status().Update(ctx, githubIssue)
+}
+
+// RepoNotFound reports whether the repository of the current spec was found to be deleted
+func RepoNotFound(githubIssue *batchv1.GithubIssue) bool {
+	condition := meta.FindStatusCondition(githubIssue.Status.Conditions, RepoNotFoundCondition)
+	return condition != nil && condition.Status == metav1.ConditionTrue &&
+		condition.ObservedGeneration == githubIssue.Generation
+}
+
+// RepoReachableCondition is the type of the condition reporting whether the repository of the
+// GithubIssue answered the last probe
+const RepoReachableCondition = "RepoReachable"
+
+// RepoReachable returns the condition reporting whether the repository answered the last
+// probe, shared by the GithubIssues targeting it. err is why the probe failed, a repository
+// answering 404 is reported as not found since GitHub hides the repositories a token can't read.
+func RepoReachable(repo string, reachable bool, err error) metav1.Condition {
+	switch {
+	case err != nil:
+		return metav1.Condition{
+			Type:               RepoReachableCondition,
+			Status:             metav1.ConditionFalse,
+			LastTransitionTime: metav1.Now(),
+			Reason:             "ProbeFailed",
+			Message:            fmt.Sprintf("Probing the repository %s failed: %v", repo, err),
+		}
+	case !reachable:
+		return metav1.Condition{
+			Type:               RepoReachableCondition,
+			Status:             metav1.ConditionFalse,
+			LastTransitionTime: metav1.Now(),
+			Reason:             "RepositoryNotFound",
+			Message:            fmt.Sprintf("The repository %s doesn't exist or isn't accessible with the token", repo),
+		}
+	}
+	return metav1.Condition{
+		Type:               RepoReachableCondition,
+		Status:             metav1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             "RepositoryReachable",
+		Message:            fmt.Sprintf("The repository %s answered the last probe", repo),
+	}
+}
+
+// UpdateRepoReachable writes the RepoReachable condition on its own, so an unreachable
+// repository is reported although the reconcile can't get further. An unchanged condition
+// isn't written again.
+func UpdateRepoReachable(ctx context.Context, c client.Client, githubIssue *batchv1.GithubIssue, condition metav1.Condition) error {
+	if !meta.SetStatusCondition(&githubIssue.Status.Conditions, condition) {
+		return nil
+	}
+	return c.Status().Update(ctx, githubIssue)
+}
+
+// ConvertedToDiscussionCondition is the type of the condition reporting the issue was
+// converted to a discussion
+const ConvertedToDiscussionCondition = "IssueConvertedToDiscussion"
+
+// UpdateConvertedToDiscussion writes the IssueConvertedToDiscussion condition and the URL of
+// the discussion to the status of a GithubIssue whose issue was converted to a discussion, for
+// the generation of the spec naming it
+func UpdateConvertedToDiscussion(ctx context.Context, c client.Client, githubIssue *batchv1.GithubIssue, discussionURL string) error {
+	githubIssue.Status.DiscussionURL = discussionURL
+	meta.SetStatusCondition(&githubIssue.Status.Conditions, metav1.Condition{
+		Type:               ConvertedToDiscussionCondition,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: githubIssue.Generation,
+		Reason:             "ConvertedToDiscussion",
+		Message: fmt.Sprintf("Issue #%d was converted to the discussion %s, it isn't edited until the spec changes",
+			githubIssue.Status.IssueNumber, discussionURL),
+	})
+	return c.Status().Update(ctx, githubIssue)
+}
+
+// ConvertedToDiscussion reports whether the issue of the current spec was found to be
+// converted to a discussion
+func ConvertedToDiscussion(githubIssue *batchv1.GithubIssue) bool {
+	condition := meta.FindStatusCondition(githubIssue.Status.Conditions, ConvertedToDiscussionCondition)
+	return condition != nil && condition.Status == metav1.ConditionTrue &&
+		condition.ObservedGeneration == githubIssue.Generation
+}
+
+// BodyTooLargeCondition is the type of the condition reporting GitHub refused the body as too long
+const BodyTooLargeCondition = "BodyTooLarge"
+
+// UpdateBodyTooLarge writes the BodyTooLarge condition to the status of a GithubIssue whose
+// body GitHub refused as too long, for the generation of the spec rendering it
+func UpdateBodyTooLarge(ctx context.Context, c client.Client, githubIssue *batchv1.GithubIssue) error {
+	meta.SetStatusCondition(&githubIssue.Status.Conditions, metav1.Condition{
+		Type:               BodyTooLargeCondition,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: githubIssue.Generation,
+		Reason:             "BodyRefused",
+		Message:            "GitHub refused the issue body as too long, the issue isn't retried until the spec changes",
+	})
+	return c.Status().Update(ctx, githubIssue)
+}
+
+// BodyTooLarge reports whether GitHub refused the body rendered from the current spec as too long
+func BodyTooLarge(githubIssue *batchv1.GithubIssue) bool {
+	condition := meta.FindStatusCondition(githubIssue.Status.Conditions, BodyTooLargeCondition)
+	return condition != nil && condition.Status == metav1.ConditionTrue &&
+		condition.ObservedGeneration == githubIssue.Generation
+}
+
 func UpdateTokenRequired(ctx context.Context, c client.Client, githubIssue *batchv1.GithubIssue, required bool) error {
 	githubIssue.Status.TokenRequired = required
 	return c.Status().Update(ctx, githubIssue)
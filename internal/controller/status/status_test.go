@@ -0,0 +1,402 @@
+package status
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"time"
+
+	"github.com/google/go-github/v47/github"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	batchv1 "github.com/oshribelay/github-issue-operator/api/v1"
+	"github.com/oshribelay/github-issue-operator/internal/controller/finalizer"
+	"github.com/oshribelay/github-issue-operator/internal/controller/resources"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Update", func() {
+	ctx := context.Background()
+
+	var (
+		c           client.Client
+		githubIssue *batchv1.GithubIssue
+		issue       *github.Issue
+	)
+
+	BeforeEach(func() {
+		s := runtime.NewScheme()
+		Expect(batchv1.AddToScheme(s)).To(Succeed())
+		githubIssue = &batchv1.GithubIssue{
+			ObjectMeta: metav1.ObjectMeta{Name: "status-resource", Namespace: "default"},
+		}
+		c = fake.NewClientBuilder().WithScheme(s).
+			WithObjects(githubIssue).
+			WithStatusSubresource(githubIssue).
+			Build()
+		issue = &github.Issue{Number: github.Int(1), State: github.String("open")}
+	})
+
+	AfterEach(func() {
+		Expect(SetDisabledConditions(nil)).To(Succeed())
+	})
+
+	// stored returns the GithubIssue as written
+	stored := func() *batchv1.GithubIssue {
+		written := &batchv1.GithubIssue{}
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(githubIssue), written)).To(Succeed())
+		return written
+	}
+
+	It("Should write every condition by default", func() {
+		Expect(Update(ctx, c, githubIssue, issue, BodyTruncated(false))).To(Succeed())
+
+		conditions := stored().Status.Conditions
+		Expect(meta.FindStatusCondition(conditions, "IssueOpen")).NotTo(BeNil())
+		Expect(meta.FindStatusCondition(conditions, "HasPR")).NotTo(BeNil())
+		Expect(meta.FindStatusCondition(conditions, "BodyTruncated")).NotTo(BeNil())
+	})
+
+	It("Should leave the disabled conditions out", func() {
+		Expect(SetDisabledConditions([]string{"HasPR", "BodyTruncated"})).To(Succeed())
+		Expect(Update(ctx, c, githubIssue, issue, BodyTruncated(false))).To(Succeed())
+
+		conditions := stored().Status.Conditions
+		Expect(meta.FindStatusCondition(conditions, "IssueOpen")).NotTo(BeNil())
+		Expect(meta.FindStatusCondition(conditions, "HasPR")).To(BeNil())
+		Expect(meta.FindStatusCondition(conditions, "BodyTruncated")).To(BeNil())
+	})
+
+	It("Should refuse to disable the conditions read back as state", func() {
+		for _, conditionType := range []string{"IssueOpen", "ClosedAsDuplicate", "IssueNumberCollision", "NotificationDelivered", "SLABreached"} {
+			Expect(SetDisabledConditions([]string{"HasPR", conditionType})).To(MatchError(ContainSubstring(conditionType)))
+		}
+		Expect(Update(ctx, c, githubIssue, issue)).To(Succeed())
+		Expect(meta.FindStatusCondition(stored().Status.Conditions, "HasPR")).NotTo(BeNil())
+	})
+
+	It("Should record the comment count of the issue", func() {
+		issue.Comments = github.Int(12)
+		Expect(Update(ctx, c, githubIssue, issue)).To(Succeed())
+		Expect(stored().Status.CommentCount).To(Equal(int32(12)))
+	})
+
+	It("Should record a token out of requests", func() {
+		SetRateLimit(githubIssue, github.Rate{Remaining: 0, Reset: github.Timestamp{Time: time.Now()}})
+		Expect(Update(ctx, c, githubIssue, issue)).To(Succeed())
+		Expect(stored().Status.RateLimitRemaining).To(HaveValue(BeZero()))
+		// a zero left must not be dropped from the serialized status
+		serialized, err := json.Marshal(stored().Status)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(serialized)).To(ContainSubstring(`"rateLimitRemaining":0`))
+	})
+
+	It("Should report an unknown state for a partial issue", func() {
+		githubIssue.Status.IssueNumber = 1
+		Expect(Update(ctx, c, githubIssue, &github.Issue{})).To(Succeed())
+
+		written := stored()
+		Expect(written.Status.IssueNumber).To(Equal(int32(1)))
+		issueOpen := meta.FindStatusCondition(written.Status.Conditions, "IssueOpen")
+		Expect(issueOpen.Status).To(Equal(metav1.ConditionUnknown))
+		Expect(issueOpen.Reason).To(Equal("StateUnknown"))
+	})
+
+	It("Should reflect the lock state of the issue", func() {
+		Expect(Update(ctx, c, githubIssue, issue)).To(Succeed())
+		Expect(meta.IsStatusConditionFalse(stored().Status.Conditions, "Locked")).To(BeTrue())
+
+		issue.Locked = github.Bool(true)
+		issue.ActiveLockReason = github.String("too heated")
+		Expect(Update(ctx, c, githubIssue, issue)).To(Succeed())
+		locked := meta.FindStatusCondition(stored().Status.Conditions, "Locked")
+		Expect(locked.Status).To(Equal(metav1.ConditionTrue))
+		Expect(locked.Message).To(ContainSubstring("too heated"))
+	})
+
+	It("Should let an extra condition of the same type take precedence", func() {
+		issue.State = github.String("closed")
+		Expect(Update(ctx, c, githubIssue, issue, Locked("resolved", errors.New("forbidden")))).To(Succeed())
+
+		conditions := stored().Status.Conditions
+		locked := meta.FindStatusCondition(conditions, "Locked")
+		Expect(locked.Reason).To(Equal("LockFailed"))
+		count := 0
+		for _, condition := range conditions {
+			if condition.Type == "Locked" {
+				count++
+			}
+		}
+		Expect(count).To(Equal(1))
+	})
+})
+
+var _ = Describe("FinalizerPresent", func() {
+	ctx := context.Background()
+
+	It("Should follow the finalizer through the lifecycle of the GithubIssue", func() {
+		s := runtime.NewScheme()
+		Expect(batchv1.AddToScheme(s)).To(Succeed())
+		githubIssue := &batchv1.GithubIssue{ObjectMeta: metav1.ObjectMeta{Name: "finalizer-resource", Namespace: "default"}}
+		c := fake.NewClientBuilder().WithScheme(s).
+			WithObjects(githubIssue).
+			WithStatusSubresource(githubIssue).
+			Build()
+		key := client.ObjectKeyFromObject(githubIssue)
+		issue := &github.Issue{Number: github.Int(1), State: github.String("open")}
+		condition := func() *metav1.Condition {
+			stored := &batchv1.GithubIssue{}
+			Expect(c.Get(ctx, key, stored)).To(Succeed())
+			return meta.FindStatusCondition(stored.Status.Conditions, "FinalizerPresent")
+		}
+
+		Expect(finalizer.EnsureFinalizer(ctx, c, githubIssue)).To(Succeed())
+		Expect(Update(ctx, c, githubIssue, issue)).To(Succeed())
+		Expect(condition().Status).To(Equal(metav1.ConditionTrue))
+		Expect(condition().Reason).To(Equal("FinalizerAdded"))
+
+		// skipping the finalizer removes it
+		githubIssue.Spec.SkipFinalizer = true
+		Expect(finalizer.EnsureFinalizer(ctx, c, githubIssue)).To(Succeed())
+		Expect(Update(ctx, c, githubIssue, issue)).To(Succeed())
+		Expect(condition().Status).To(Equal(metav1.ConditionFalse))
+
+		githubIssue.Spec.SkipFinalizer = false
+		Expect(finalizer.EnsureFinalizer(ctx, c, githubIssue)).To(Succeed())
+		Expect(Update(ctx, c, githubIssue, issue)).To(Succeed())
+		Expect(condition().Status).To(Equal(metav1.ConditionTrue))
+
+		// a deletion held by the finalizer failing to close the issue
+		Expect(c.Delete(ctx, githubIssue)).To(Succeed())
+		Expect(c.Get(ctx, key, githubIssue)).To(Succeed())
+		Expect(UpdateFinalizerPresent(ctx, c, githubIssue)).To(Succeed())
+		Expect(condition().Status).To(Equal(metav1.ConditionTrue))
+		Expect(condition().Reason).To(Equal("ClosingIssue"))
+
+		Expect(finalizer.RemoveFinalizer(ctx, c, githubIssue)).To(Succeed())
+		Expect(finalizer.Present(githubIssue)).To(BeFalse())
+		Expect(FinalizerPresent(githubIssue).Status).To(Equal(metav1.ConditionFalse))
+	})
+})
+
+var _ = Describe("Delete", func() {
+	ctx := context.Background()
+
+	It("Should delete the GithubIssue of a partial issue without a state", func() {
+		edited := false
+		mux := http.NewServeMux()
+		mux.HandleFunc("/repos/owner/repo/issues", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `[{"number": 1, "title": "Test Issue"}]`)
+		})
+		mux.HandleFunc("/repos/owner/repo/issues/1", func(w http.ResponseWriter, r *http.Request) {
+			edited = true
+		})
+		server := httptest.NewServer(mux)
+		defer server.Close()
+		baseURL, err := url.Parse(server.URL + "/")
+		Expect(err).NotTo(HaveOccurred())
+
+		s := runtime.NewScheme()
+		Expect(batchv1.AddToScheme(s)).To(Succeed())
+		githubIssue := &batchv1.GithubIssue{
+			ObjectMeta: metav1.ObjectMeta{Name: "delete-resource", Namespace: "default"},
+			Spec:       batchv1.GithubIssueSpec{Repo: "https://github.com/owner/repo", Title: "Test Issue"},
+		}
+		c := fake.NewClientBuilder().WithScheme(s).WithObjects(githubIssue).Build()
+
+		gClient := resources.NewGithubClient("token", resources.WithBaseURL(baseURL))
+		Expect(Delete(ctx, c, gClient, githubIssue, "Test Issue")).To(Succeed())
+		Expect(edited).To(BeFalse())
+
+		err = c.Get(ctx, client.ObjectKeyFromObject(githubIssue), &batchv1.GithubIssue{})
+		Expect(err).To(HaveOccurred())
+	})
+	It("Should not close an open issue whose title only matches loosely", func() {
+		edited := false
+		mux := http.NewServeMux()
+		mux.HandleFunc("/repos/owner/repo/issues", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `[{"number": 1, "title": "Test Issue (unrelated)", "state": "open"}]`)
+		})
+		mux.HandleFunc("/repos/owner/repo/issues/1", func(w http.ResponseWriter, r *http.Request) {
+			edited = true
+		})
+		server := httptest.NewServer(mux)
+		defer server.Close()
+		baseURL, err := url.Parse(server.URL + "/")
+		Expect(err).NotTo(HaveOccurred())
+
+		s := runtime.NewScheme()
+		Expect(batchv1.AddToScheme(s)).To(Succeed())
+		githubIssue := &batchv1.GithubIssue{
+			ObjectMeta: metav1.ObjectMeta{Name: "delete-resource", Namespace: "default"},
+			Spec:       batchv1.GithubIssueSpec{Repo: "https://github.com/owner/repo", Title: "Test Issue"},
+		}
+		c := fake.NewClientBuilder().WithScheme(s).WithObjects(githubIssue).Build()
+
+		gClient := resources.NewGithubClient("token", resources.WithBaseURL(baseURL),
+			resources.WithTitleMatch(resources.TitleMatchPrefix))
+		Expect(Delete(ctx, c, gClient, githubIssue, "Test Issue")).To(Succeed())
+		Expect(edited).To(BeFalse())
+	})
+	It("Should still comment the closing reference on the closed issue when commenting failed", func() {
+		state := "open"
+		var comments []string
+		failComments := 1
+		mux := http.NewServeMux()
+		mux.HandleFunc("/repos/owner/repo/issues", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `[{"number": 1, "title": "Test Issue", "state": %q}]`, state)
+		})
+		mux.HandleFunc("/repos/owner/repo/issues/1", func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodPatch {
+				state = "closed"
+			}
+			fmt.Fprintf(w, `{"number": 1, "title": "Test Issue", "state": %q}`, state)
+		})
+		mux.HandleFunc("/repos/owner/repo/issues/1/comments", func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet {
+				listed := []map[string]string{}
+				for _, body := range comments {
+					listed = append(listed, map[string]string{"body": body})
+				}
+				Expect(json.NewEncoder(w).Encode(listed)).To(Succeed())
+				return
+			}
+			if failComments > 0 {
+				failComments--
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			var comment map[string]string
+			Expect(json.NewDecoder(r.Body).Decode(&comment)).To(Succeed())
+			comments = append(comments, comment["body"])
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"id": 1}`)
+		})
+		server := httptest.NewServer(mux)
+		defer server.Close()
+		baseURL, err := url.Parse(server.URL + "/")
+		Expect(err).NotTo(HaveOccurred())
+
+		s := runtime.NewScheme()
+		Expect(batchv1.AddToScheme(s)).To(Succeed())
+		githubIssue := &batchv1.GithubIssue{
+			ObjectMeta: metav1.ObjectMeta{Name: "delete-resource", Namespace: "default"},
+			Spec: batchv1.GithubIssueSpec{
+				Repo:     "https://github.com/owner/repo",
+				Title:    "Test Issue",
+				ClosedBy: "1a2b3c4d",
+			},
+			Status: batchv1.GithubIssueStatus{IssueNumber: 1},
+		}
+		c := fake.NewClientBuilder().WithScheme(s).
+			WithObjects(githubIssue).
+			WithStatusSubresource(githubIssue).
+			Build()
+		gClient := resources.NewGithubClient("token", resources.WithBaseURL(baseURL))
+
+		Expect(Delete(ctx, c, gClient, githubIssue.DeepCopy(), "Test Issue")).NotTo(Succeed())
+		Expect(state).To(Equal("closed"))
+		Expect(comments).To(BeEmpty())
+
+		By("posting the pending comment on the retry finding the issue closed")
+		stored := &batchv1.GithubIssue{}
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(githubIssue), stored)).To(Succeed())
+		Expect(stored.Status.PendingComment).NotTo(BeNil())
+		Expect(Delete(ctx, c, gClient, stored, "Test Issue")).To(Succeed())
+		Expect(comments).To(HaveLen(1))
+		Expect(comments[0]).To(HavePrefix("Closed by 1a2b3c4d"))
+
+		err = c.Get(ctx, client.ObjectKeyFromObject(githubIssue), &batchv1.GithubIssue{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+	Context("When the closed issue can't be locked", func() {
+		var (
+			state      string
+			trackingUp string
+			server     *httptest.Server
+			gClient    *resources.GithubClient
+		)
+
+		BeforeEach(func() {
+			state = "open"
+			trackingUp = ""
+			mux := http.NewServeMux()
+			mux.HandleFunc("/repos/owner/repo/issues", func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprintf(w, `[{"number": 1, "title": "Test Issue", "state": %q}]`, state)
+			})
+			mux.HandleFunc("/repos/owner/repo/issues/1", func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodPatch {
+					state = "closed"
+				}
+				fmt.Fprintf(w, `{"number": 1, "title": "Test Issue", "state": %q}`, state)
+			})
+			mux.HandleFunc("/repos/owner/repo/issues/1/lock", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusForbidden)
+				fmt.Fprint(w, `{"message": "Must have admin rights to Repository."}`)
+			})
+			mux.HandleFunc("/repos/owner/repo/issues/9", func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodPatch {
+					var request map[string]string
+					Expect(json.NewDecoder(r.Body).Decode(&request)).To(Succeed())
+					trackingUp = request["body"]
+				}
+				fmt.Fprint(w, `{"number": 9, "body": "- [ ] #1"}`)
+			})
+			server = httptest.NewServer(mux)
+			baseURL, err := url.Parse(server.URL + "/")
+			Expect(err).NotTo(HaveOccurred())
+			gClient = resources.NewGithubClient("token", resources.WithBaseURL(baseURL))
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		deleteLocked := func() client.Client {
+			s := runtime.NewScheme()
+			Expect(batchv1.AddToScheme(s)).To(Succeed())
+			githubIssue := &batchv1.GithubIssue{
+				ObjectMeta: metav1.ObjectMeta{Name: "delete-resource", Namespace: "default"},
+				Spec: batchv1.GithubIssueSpec{
+					Repo:          "https://github.com/owner/repo",
+					Title:         "Test Issue",
+					LockOnClose:   true,
+					TrackingIssue: 9,
+				},
+				Status: batchv1.GithubIssueStatus{IssueNumber: 1},
+			}
+			c := fake.NewClientBuilder().WithScheme(s).
+				WithObjects(githubIssue).
+				WithStatusSubresource(githubIssue).
+				Build()
+			Expect(Delete(ctx, c, gClient, githubIssue, "Test Issue")).To(Succeed())
+			return c
+		}
+
+		It("Should still check the issue off and delete the GithubIssue once it's closed", func() {
+			c := deleteLocked()
+			Expect(state).To(Equal("closed"))
+			Expect(trackingUp).To(Equal("- [x] #1"))
+
+			err := c.Get(ctx, client.ObjectKey{Name: "delete-resource", Namespace: "default"}, &batchv1.GithubIssue{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("Should delete the GithubIssue of an issue closed before whose lock keeps failing", func() {
+			state = "closed"
+			c := deleteLocked()
+
+			err := c.Get(ctx, client.ObjectKey{Name: "delete-resource", Namespace: "default"}, &batchv1.GithubIssue{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+	})
+})
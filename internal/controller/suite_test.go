@@ -18,18 +18,25 @@ package controller
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"github.com/google/go-github/v47/github"
 	"github.com/joho/godotenv"
-	"github.com/oshribelay/github-issue-operator/internal/controller/resources"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"path/filepath"
 	"runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"strings"
 	"testing"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -38,6 +45,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
 	issuev1 "github.com/oshribelay/github-issue-operator/api/v1"
+	"github.com/oshribelay/github-issue-operator/internal/controller/resources"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -56,6 +64,85 @@ func TestControllers(t *testing.T) {
 	RunSpecs(t, "Controller Suite")
 }
 
+// newFakeClient returns a fake client holding the objects, serving the status subresource of each
+func newFakeClient(objs ...client.Object) client.WithWatch {
+	s := k8sruntime.NewScheme()
+	Expect(scheme.AddToScheme(s)).To(Succeed())
+	Expect(issuev1.AddToScheme(s)).To(Succeed())
+	return fake.NewClientBuilder().WithScheme(s).WithObjects(objs...).WithStatusSubresource(objs...).Build()
+}
+
+// newGithubServer serves the handler as a fake GitHub until the spec ends, returning a GitHub
+// client talking to it
+func newGithubServer(handler http.Handler, opts ...resources.Option) *resources.GithubClient {
+	server := httptest.NewServer(handler)
+	DeferCleanup(server.Close)
+	baseURL, err := url.Parse(server.URL + "/")
+	Expect(err).NotTo(HaveOccurred())
+	return resources.NewGithubClient("token", append([]resources.Option{resources.WithBaseURL(baseURL)}, opts...)...)
+}
+
+// newGithubTLSServer serves the handler as a fake GitHub over https until the spec ends, for
+// the API base URLs of GithubIssues, which must be https. The GitHub clients trust its
+// certificate meanwhile.
+func newGithubTLSServer(handler http.Handler) *httptest.Server {
+	server := httptest.NewTLSServer(handler)
+	transport := http.DefaultTransport
+	http.DefaultTransport = server.Client().Transport
+	DeferCleanup(func() {
+		http.DefaultTransport = transport
+		server.Close()
+	})
+	return server
+}
+
+// recordLabels serves the labels of the issue of owner/repo with the number on the fake
+// GitHub, appending the labels added to added and those removed to removed
+func recordLabels(mux *http.ServeMux, number int, added, removed *[]string) {
+	path := fmt.Sprintf("/repos/owner/repo/issues/%d/labels", number)
+	mux.HandleFunc(path, func(w http.ResponseWriter, req *http.Request) {
+		var labels []string
+		Expect(json.NewDecoder(req.Body).Decode(&labels)).To(Succeed())
+		*added = append(*added, labels...)
+		fmt.Fprint(w, `[]`)
+	})
+	mux.HandleFunc(path+"/", func(w http.ResponseWriter, req *http.Request) {
+		label, err := url.PathUnescape(strings.TrimPrefix(req.URL.EscapedPath(), path+"/"))
+		Expect(err).NotTo(HaveOccurred())
+		*removed = append(*removed, label)
+		fmt.Fprint(w, `[]`)
+	})
+}
+
+// commentsHandler serves the comments of an issue on the fake GitHub, listing the comments
+// posted so far and appending the new ones to posted
+func commentsHandler(posted *[]string) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodGet {
+			comments := []*github.IssueComment{}
+			for _, body := range *posted {
+				comments = append(comments, &github.IssueComment{Body: github.String(body)})
+			}
+			Expect(json.NewEncoder(w).Encode(comments)).To(Succeed())
+			return
+		}
+		var comment github.IssueComment
+		Expect(json.NewDecoder(req.Body).Decode(&comment)).To(Succeed())
+		*posted = append(*posted, comment.GetBody())
+		w.WriteHeader(http.StatusCreated)
+		Expect(json.NewEncoder(w).Encode(&comment)).To(Succeed())
+	}
+}
+
+// commentTexts returns the comments without the invisible markers the operator tags them with
+func commentTexts(comments []string) []string {
+	texts := make([]string, len(comments))
+	for i, comment := range comments {
+		texts[i], _, _ = strings.Cut(comment, "\n\n<!-- github-issue-operator:")
+	}
+	return texts
+}
+
 var _ = BeforeSuite(func() {
 	// Set up logging with development config and high verbosity
 	opts := zap.Options{
@@ -119,10 +206,9 @@ var _ = BeforeSuite(func() {
 
 	// create the reconciler
 	err = (&GithubIssueReconciler{
-		Client:       k8sManager.GetClient(),
-		GithubClient: resources.NewGithubClient("Z2hwX1AyUElSSXVFZ0U5TXJhMm9vMDM3b2xaTzRsM2lSMjBPdnhwTA=="),
-		Scheme:       k8sManager.GetScheme(),
-		Log:          logger,
+		Client: k8sManager.GetClient(),
+		Scheme: k8sManager.GetScheme(),
+		Log:    logger,
 	}).SetupWithManager(k8sManager)
 	Expect(err).ToNot(HaveOccurred())
 
@@ -0,0 +1,212 @@
+package summary
+
+import (
+	"slices"
+	"sort"
+	"strings"
+
+	v1 "github.com/oshribelay/github-issue-operator/api/v1"
+	"github.com/oshribelay/github-issue-operator/internal/controller/utils"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// State returns the state of the GitHub issue managed by the GithubIssue, based on its IssueOpen condition
+func State(githubIssue *v1.GithubIssue) string {
+	if githubIssue.Status.IssueNumber == 0 {
+		return v1.IssueStatePending
+	}
+
+	condition := meta.FindStatusCondition(githubIssue.Status.Conditions, "IssueOpen")
+	switch {
+	case condition == nil:
+		return v1.IssueStatePending
+	case condition.Status == metav1.ConditionTrue:
+		return v1.IssueStateOpen
+	default:
+		return v1.IssueStateClosed
+	}
+}
+
+// Build aggregates the GithubIssues matching the spec per repository and state. The summary is
+// rebuilt from scratch so deleted GithubIssues, and repositories left without any, are pruned
+// from it. Every matching issue is counted but at most maxIssues of them are listed, in repo,
+// namespace and name order, so the summary's size doesn't grow with the cluster.
+func Build(spec v1.GithubIssueSummarySpec, githubIssues []v1.GithubIssue) v1.GithubIssueSummaryStatus {
+	summaryStatus := v1.GithubIssueSummaryStatus{}
+	repos := map[string]*v1.RepoSummary{}
+
+	for i := range githubIssues {
+		githubIssue := &githubIssues[i]
+		// issues being deleted are on their way out of the summary
+		if !githubIssue.GetDeletionTimestamp().IsZero() {
+			continue
+		}
+		state := State(githubIssue)
+		if !matches(spec, githubIssue, state) {
+			continue
+		}
+
+		repo, ok := repos[githubIssue.Spec.Repo]
+		if !ok {
+			repo = &v1.RepoSummary{Repo: githubIssue.Spec.Repo}
+			repos[githubIssue.Spec.Repo] = repo
+		}
+
+		switch state {
+		case v1.IssueStateOpen:
+			repo.Open++
+			summaryStatus.Open++
+		case v1.IssueStateClosed:
+			repo.Closed++
+			summaryStatus.Closed++
+		default:
+			repo.Pending++
+			summaryStatus.Pending++
+		}
+		summaryStatus.Total++
+
+		repo.Issues = append(repo.Issues, v1.ManagedIssue{
+			Namespace:   githubIssue.Namespace,
+			Name:        githubIssue.Name,
+			IssueNumber: githubIssue.Status.IssueNumber,
+			State:       state,
+		})
+	}
+
+	// keep the output stable so the status only changes when the issues do
+	for _, repo := range repos {
+		sort.Slice(repo.Issues, func(i, j int) bool {
+			if repo.Issues[i].Namespace != repo.Issues[j].Namespace {
+				return repo.Issues[i].Namespace < repo.Issues[j].Namespace
+			}
+			return repo.Issues[i].Name < repo.Issues[j].Name
+		})
+		summaryStatus.Repos = append(summaryStatus.Repos, *repo)
+	}
+	sort.Slice(summaryStatus.Repos, func(i, j int) bool {
+		return summaryStatus.Repos[i].Repo < summaryStatus.Repos[j].Repo
+	})
+
+	listed := int32(v1.DefaultSummaryMaxIssues)
+	if spec.MaxIssues != nil {
+		listed = max(*spec.MaxIssues, 0)
+	}
+	for i := range summaryStatus.Repos {
+		repo := &summaryStatus.Repos[i]
+		if int32(len(repo.Issues)) > listed {
+			summaryStatus.Omitted += int32(len(repo.Issues)) - listed
+			repo.Issues = repo.Issues[:listed]
+			repo.Truncated = true
+		}
+		listed -= int32(len(repo.Issues))
+		if len(repo.Issues) == 0 {
+			repo.Issues = nil
+		}
+	}
+
+	return summaryStatus
+}
+
+// matches reports whether the GithubIssue, with its issue in the given state, is counted by
+// the summary of the spec
+func matches(spec v1.GithubIssueSummarySpec, githubIssue *v1.GithubIssue, state string) bool {
+	if len(spec.Namespaces) > 0 && !slices.Contains(spec.Namespaces, githubIssue.Namespace) {
+		return false
+	}
+	if len(spec.Repos) > 0 && !slices.Contains(spec.Repos, githubIssue.Spec.Repo) {
+		return false
+	}
+	return len(spec.States) == 0 || slices.Contains(spec.States, state)
+}
+
+// Affects reports whether updating the GithubIssue from old to new changes what the summary
+// reports about it, other updates don't need the summary rebuilt
+func Affects(old, new *v1.GithubIssue) bool {
+	return old.Spec.Repo != new.Spec.Repo ||
+		old.Status.IssueNumber != new.Status.IssueNumber ||
+		State(old) != State(new) ||
+		old.GetDeletionTimestamp().IsZero() != new.GetDeletionTimestamp().IsZero()
+}
+
+// OpenAssigned counts the open issues assigned to every login, skipping the GithubIssue
+// with the given key so an issue isn't counted against its own assignees. GitHub logins
+// are case-insensitive, the counts are keyed by the lowercased login.
+func OpenAssigned(githubIssues []v1.GithubIssue, skip types.NamespacedName) map[string]int {
+	counts := map[string]int{}
+	for i := range githubIssues {
+		githubIssue := &githubIssues[i]
+		if githubIssue.Namespace == skip.Namespace && githubIssue.Name == skip.Name {
+			continue
+		}
+		if !githubIssue.GetDeletionTimestamp().IsZero() || State(githubIssue) != v1.IssueStateOpen {
+			continue
+		}
+		for _, assignee := range githubIssue.Status.Assignees {
+			counts[strings.ToLower(assignee)]++
+		}
+	}
+	return counts
+}
+
+// LeastLoaded returns the login holding the fewest open issues, the first in alphabetical
+// order among the ones holding as few, whatever the case of the logins. It returns an empty
+// string without logins.
+func LeastLoaded(counts map[string]int, logins []string) string {
+	var least string
+	for _, login := range logins {
+		lower, leastLower := strings.ToLower(login), strings.ToLower(least)
+		if least == "" || counts[lower] < counts[leastLower] || (counts[lower] == counts[leastLower] && lower < leastLower) {
+			least = login
+		}
+	}
+	return least
+}
+
+// Colliding returns the other GithubIssues whose status holds the issue number in the same
+// repository, sorted from the oldest GithubIssue, which is the one to keep managing the issue
+func Colliding(githubIssues []v1.GithubIssue, self types.NamespacedName, owner, repo string, number int) []v1.GithubIssue {
+	var colliding []v1.GithubIssue
+	for _, githubIssue := range githubIssues {
+		if githubIssue.Namespace == self.Namespace && githubIssue.Name == self.Name {
+			continue
+		}
+		if !githubIssue.GetDeletionTimestamp().IsZero() || int(githubIssue.Status.IssueNumber) != number {
+			continue
+		}
+		otherOwner, otherRepo, err := utils.ParseRepoUrl(githubIssue.Spec.Repo)
+		if err != nil || !strings.EqualFold(otherOwner, owner) || !strings.EqualFold(otherRepo, repo) {
+			continue
+		}
+		colliding = append(colliding, githubIssue)
+	}
+	sort.Slice(colliding, func(i, j int) bool {
+		return Older(&colliding[i], &colliding[j])
+	})
+	return colliding
+}
+
+// Older reports whether the GithubIssue a was created before b, telling apart GithubIssues
+// created in the same second by namespace and name
+func Older(a, b *v1.GithubIssue) bool {
+	if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+		return a.CreationTimestamp.Before(&b.CreationTimestamp)
+	}
+	if a.Namespace != b.Namespace {
+		return a.Namespace < b.Namespace
+	}
+	return a.Name < b.Name
+}
+
+// Overloaded returns the assignees that would hold more than limit open issues with one more,
+// whatever the case of their login
+func Overloaded(counts map[string]int, assignees []string, limit int) []string {
+	var overloaded []string
+	for _, assignee := range assignees {
+		if counts[strings.ToLower(assignee)]+1 > limit {
+			overloaded = append(overloaded, assignee)
+		}
+	}
+	return overloaded
+}
@@ -0,0 +1,235 @@
+package summary
+
+import (
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "github.com/oshribelay/github-issue-operator/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func newGithubIssue(namespace, name, repo string, number int32, open metav1.ConditionStatus) v1.GithubIssue {
+	githubIssue := v1.GithubIssue{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Spec:       v1.GithubIssueSpec{Repo: repo},
+	}
+	if number > 0 {
+		githubIssue.Status.IssueNumber = number
+		githubIssue.Status.Conditions = []metav1.Condition{{Type: "IssueOpen", Status: open}}
+	}
+	return githubIssue
+}
+
+var _ = Describe("Summary", func() {
+	const (
+		repoA = "https://github.com/owner/a"
+		repoB = "https://github.com/owner/b"
+	)
+
+	It("Should count the issues per repo and state", func() {
+		summaryStatus := Build(v1.GithubIssueSummarySpec{}, []v1.GithubIssue{
+			newGithubIssue("team-b", "first", repoA, 1, metav1.ConditionTrue),
+			newGithubIssue("team-a", "second", repoA, 2, metav1.ConditionFalse),
+			newGithubIssue("team-a", "third", repoB, 0, ""),
+		})
+
+		Expect(summaryStatus.Total).To(Equal(int32(3)))
+		Expect(summaryStatus.Open).To(Equal(int32(1)))
+		Expect(summaryStatus.Closed).To(Equal(int32(1)))
+		Expect(summaryStatus.Pending).To(Equal(int32(1)))
+		Expect(summaryStatus.Repos).To(HaveLen(2))
+
+		Expect(summaryStatus.Repos[0].Repo).To(Equal(repoA))
+		Expect(summaryStatus.Repos[0].Open).To(Equal(int32(1)))
+		Expect(summaryStatus.Repos[0].Closed).To(Equal(int32(1)))
+		Expect(summaryStatus.Repos[0].Issues).To(Equal([]v1.ManagedIssue{
+			{Namespace: "team-a", Name: "second", IssueNumber: 2, State: v1.IssueStateClosed},
+			{Namespace: "team-b", Name: "first", IssueNumber: 1, State: v1.IssueStateOpen},
+		}))
+
+		Expect(summaryStatus.Repos[1].Repo).To(Equal(repoB))
+		Expect(summaryStatus.Repos[1].Pending).To(Equal(int32(1)))
+	})
+
+	It("Should prune deleted issues and repos left without issues", func() {
+		summaryStatus := Build(v1.GithubIssueSummarySpec{}, []v1.GithubIssue{
+			newGithubIssue("default", "first", repoA, 1, metav1.ConditionTrue),
+			newGithubIssue("default", "second", repoB, 2, metav1.ConditionTrue),
+		})
+		Expect(summaryStatus.Repos).To(HaveLen(2))
+
+		deleting := newGithubIssue("default", "second", repoB, 2, metav1.ConditionTrue)
+		now := metav1.Now()
+		deleting.DeletionTimestamp = &now
+		summaryStatus = Build(v1.GithubIssueSummarySpec{}, []v1.GithubIssue{
+			newGithubIssue("default", "first", repoA, 1, metav1.ConditionTrue),
+			deleting,
+		})
+		Expect(summaryStatus.Total).To(Equal(int32(1)))
+		Expect(summaryStatus.Repos).To(HaveLen(1))
+		Expect(summaryStatus.Repos[0].Repo).To(Equal(repoA))
+
+		Expect(Build(v1.GithubIssueSummarySpec{}, nil).Repos).To(BeEmpty())
+	})
+
+	It("Should only count the issues of the selected namespaces, repos and states", func() {
+		githubIssues := []v1.GithubIssue{
+			newGithubIssue("team-a", "open-a", repoA, 1, metav1.ConditionTrue),
+			newGithubIssue("team-a", "closed-a", repoA, 2, metav1.ConditionFalse),
+			newGithubIssue("team-a", "open-b", repoB, 3, metav1.ConditionTrue),
+			newGithubIssue("team-b", "open-a", repoA, 4, metav1.ConditionTrue),
+		}
+
+		summaryStatus := Build(v1.GithubIssueSummarySpec{
+			Namespaces: []string{"team-a"},
+			Repos:      []string{repoA},
+			States:     []string{v1.IssueStateOpen},
+		}, githubIssues)
+		Expect(summaryStatus.Total).To(Equal(int32(1)))
+		Expect(summaryStatus.Repos).To(HaveLen(1))
+		Expect(summaryStatus.Repos[0].Issues).To(Equal([]v1.ManagedIssue{
+			{Namespace: "team-a", Name: "open-a", IssueNumber: 1, State: v1.IssueStateOpen},
+		}))
+
+		summaryStatus = Build(v1.GithubIssueSummarySpec{Namespaces: []string{"team-a"}}, githubIssues)
+		Expect(summaryStatus.Total).To(Equal(int32(3)))
+		Expect(summaryStatus.Repos).To(HaveLen(2))
+	})
+
+	It("Should list at most maxIssues issues while still counting them all", func() {
+		var githubIssues []v1.GithubIssue
+		for i := range 150 {
+			githubIssues = append(githubIssues, newGithubIssue("default", fmt.Sprintf("issue-%03d", i), repoA, int32(i+1), metav1.ConditionTrue))
+		}
+		githubIssues = append(githubIssues, newGithubIssue("default", "other", repoB, 1, metav1.ConditionTrue))
+
+		summaryStatus := Build(v1.GithubIssueSummarySpec{}, githubIssues)
+		Expect(summaryStatus.Total).To(Equal(int32(151)))
+		Expect(summaryStatus.Repos[0].Open).To(Equal(int32(150)))
+		Expect(summaryStatus.Repos[0].Issues).To(HaveLen(v1.DefaultSummaryMaxIssues))
+		Expect(summaryStatus.Repos[0].Truncated).To(BeTrue())
+		Expect(summaryStatus.Repos[1].Issues).To(BeEmpty())
+		Expect(summaryStatus.Repos[1].Truncated).To(BeTrue())
+		Expect(summaryStatus.Omitted).To(Equal(int32(51)))
+
+		maxIssues := int32(151)
+		summaryStatus = Build(v1.GithubIssueSummarySpec{MaxIssues: &maxIssues}, githubIssues)
+		Expect(summaryStatus.Repos[0].Truncated).To(BeFalse())
+		Expect(summaryStatus.Repos[1].Truncated).To(BeFalse())
+		Expect(summaryStatus.Omitted).To(BeZero())
+
+		maxIssues = 0
+		summaryStatus = Build(v1.GithubIssueSummarySpec{MaxIssues: &maxIssues}, githubIssues)
+		Expect(summaryStatus.Repos[0].Issues).To(BeEmpty())
+		Expect(summaryStatus.Omitted).To(Equal(int32(151)))
+	})
+
+	It("Should only report the updates that change what the summary reports", func() {
+		old := newGithubIssue("default", "first", repoA, 0, "")
+		updated := old.DeepCopy()
+		updated.Spec.Title = "edited"
+		updated.Status.Assignees = []string{"octocat"}
+		Expect(Affects(&old, updated)).To(BeFalse())
+
+		opened := newGithubIssue("default", "first", repoA, 1, metav1.ConditionTrue)
+		Expect(Affects(&old, &opened)).To(BeTrue())
+		closed := newGithubIssue("default", "first", repoA, 1, metav1.ConditionFalse)
+		Expect(Affects(&opened, &closed)).To(BeTrue())
+	})
+})
+
+var _ = Describe("Assigned issues", func() {
+	const repo = "https://github.com/owner/a"
+
+	// assigned returns a GithubIssue assigned to the logins
+	assigned := func(name string, open metav1.ConditionStatus, assignees ...string) v1.GithubIssue {
+		githubIssue := newGithubIssue("default", name, repo, 1, open)
+		githubIssue.Status.Assignees = assignees
+		return githubIssue
+	}
+
+	githubIssues := []v1.GithubIssue{
+		assigned("first", metav1.ConditionTrue, "octocat", "hubot"),
+		assigned("second", metav1.ConditionTrue, "octocat"),
+		assigned("closed", metav1.ConditionFalse, "octocat", "hubot"),
+		assigned("current", metav1.ConditionTrue, "octocat", "hubot"),
+	}
+	current := types.NamespacedName{Namespace: "default", Name: "current"}
+
+	It("Should count the open issues of every assignee, except the current one", func() {
+		Expect(OpenAssigned(githubIssues, current)).To(Equal(map[string]int{"octocat": 2, "hubot": 1}))
+	})
+
+	It("Should report the assignees above the limit with the current issue", func() {
+		counts := OpenAssigned(githubIssues, current)
+		Expect(Overloaded(counts, []string{"octocat", "hubot"}, 2)).To(Equal([]string{"octocat"}))
+	})
+
+	It("Should not report assignees at the limit", func() {
+		counts := OpenAssigned(githubIssues, current)
+		Expect(Overloaded(counts, []string{"octocat", "hubot"}, 3)).To(BeEmpty())
+	})
+
+	It("Should match the assignees whatever the case of their login", func() {
+		counts := OpenAssigned([]v1.GithubIssue{
+			assigned("first", metav1.ConditionTrue, "OctoCat"),
+			assigned("second", metav1.ConditionTrue, "octocat"),
+		}, current)
+		Expect(counts).To(Equal(map[string]int{"octocat": 2}))
+		Expect(Overloaded(counts, []string{"OCTOCAT"}, 2)).To(Equal([]string{"OCTOCAT"}))
+	})
+
+	It("Should not report assignees without other open issues", func() {
+		counts := OpenAssigned(githubIssues, current)
+		Expect(Overloaded(counts, []string{"newcomer"}, 1)).To(BeEmpty())
+	})
+
+	It("Should pick the login holding the fewest open issues, alphabetically among ties", func() {
+		counts := OpenAssigned(githubIssues, current)
+		Expect(LeastLoaded(counts, []string{"octocat", "hubot"})).To(Equal("hubot"))
+		Expect(LeastLoaded(counts, []string{"octocat", "zed", "newcomer"})).To(Equal("newcomer"))
+		Expect(LeastLoaded(counts, nil)).To(BeEmpty())
+	})
+
+	It("Should pick the least loaded login whatever the case of the members", func() {
+		counts := OpenAssigned(githubIssues, current)
+		Expect(LeastLoaded(counts, []string{"OctoCat", "zed"})).To(Equal("zed"))
+		Expect(LeastLoaded(counts, []string{"Zed", "newcomer"})).To(Equal("newcomer"))
+	})
+})
+
+var _ = Describe("Colliding", func() {
+	const repo = "https://github.com/owner/repo"
+	self := types.NamespacedName{Namespace: "team-a", Name: "self"}
+
+	created := func(githubIssue v1.GithubIssue, at time.Time) v1.GithubIssue {
+		githubIssue.CreationTimestamp = metav1.NewTime(at)
+		return githubIssue
+	}
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	It("Should return the other GithubIssues holding the issue, oldest first", func() {
+		colliding := Colliding([]v1.GithubIssue{
+			newGithubIssue("team-a", "self", repo, 7, metav1.ConditionTrue),
+			created(newGithubIssue("team-b", "newer", repo, 7, metav1.ConditionTrue), now),
+			created(newGithubIssue("team-b", "older", "https://github.com/Owner/Repo", 7, metav1.ConditionTrue), now.Add(-time.Hour)),
+			newGithubIssue("team-b", "other-number", repo, 8, metav1.ConditionTrue),
+			newGithubIssue("team-b", "other-repo", "https://github.com/owner/other", 7, metav1.ConditionTrue),
+			newGithubIssue("team-b", "pending", repo, 0, ""),
+		}, self, "owner", "repo", 7)
+
+		Expect(colliding).To(HaveLen(2))
+		Expect(colliding[0].Name).To(Equal("older"))
+		Expect(colliding[1].Name).To(Equal("newer"))
+	})
+
+	It("Should tell apart GithubIssues created at once by their key", func() {
+		a := created(newGithubIssue("team-a", "a", repo, 7, metav1.ConditionTrue), now)
+		b := created(newGithubIssue("team-a", "b", repo, 7, metav1.ConditionTrue), now)
+		Expect(Older(&a, &b)).To(BeTrue())
+		Expect(Older(&b, &a)).To(BeFalse())
+	})
+})
@@ -0,0 +1,99 @@
+package templates
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"text/template"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Template is the body template used for the issues carrying its label
+type Template struct {
+	// Label selects the template
+	Label string `json:"label"`
+	// Priority picks the template when the labels of an issue match several, highest first
+	Priority int `json:"priority,omitempty"`
+	// Body is the Go template rendering the issue body
+	Body string `json:"template"`
+
+	tmpl *template.Template
+}
+
+// Data is what the body templates are rendered with
+type Data struct {
+	Repo        string
+	Title       string
+	Description string
+	Category    string
+	Labels      []string
+}
+
+// Set is the set of body templates configured by label
+type Set struct {
+	templates []*Template
+}
+
+// Load parses a YAML list of templates, each with its label, priority and template keys
+func Load(data []byte) (*Set, error) {
+	var templates []*Template
+	if err := yaml.UnmarshalStrict(data, &templates); err != nil {
+		return nil, fmt.Errorf("invalid label templates: %w", err)
+	}
+
+	seen := map[string]bool{}
+	for _, t := range templates {
+		if t.Label == "" {
+			return nil, fmt.Errorf("invalid label templates: template without a label")
+		}
+		if seen[t.Label] {
+			return nil, fmt.Errorf("invalid label templates: label %s has several templates", t.Label)
+		}
+		seen[t.Label] = true
+
+		tmpl, err := template.New(t.Label).Option("missingkey=error").Parse(t.Body)
+		if err != nil {
+			return nil, fmt.Errorf("invalid template for label %s: %w", t.Label, err)
+		}
+		t.tmpl = tmpl
+	}
+
+	// keep the templates ordered by priority so the first match wins, ties by label
+	sort.SliceStable(templates, func(i, j int) bool {
+		if templates[i].Priority != templates[j].Priority {
+			return templates[i].Priority > templates[j].Priority
+		}
+		return templates[i].Label < templates[j].Label
+	})
+
+	return &Set{templates: templates}, nil
+}
+
+// Select returns the highest priority template whose label is among the given ones,
+// nil when none matches
+func (s *Set) Select(labels []string) *Template {
+	if s == nil {
+		return nil
+	}
+
+	has := map[string]bool{}
+	for _, label := range labels {
+		has[label] = true
+	}
+	for _, t := range s.templates {
+		if has[t.Label] {
+			return t
+		}
+	}
+	return nil
+}
+
+// Render renders the issue body with the template
+func (t *Template) Render(data Data) (string, error) {
+	var body bytes.Buffer
+	if err := t.tmpl.Execute(&body, data); err != nil {
+		return "", fmt.Errorf("failed to render template for label %s: %w", t.Label, err)
+	}
+	return body.String(), nil
+}
@@ -0,0 +1,69 @@
+package templates
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+const config = `
+- label: bug
+  template: "Bug in {{ .Repo }}: {{ .Description }}"
+- label: incident
+  priority: 10
+  template: |
+    ## Incident
+    {{ .Description }}
+- label: alert
+  priority: 10
+  template: "Alert: {{ .Title }}"
+`
+
+var _ = Describe("Set", func() {
+	var set *Set
+
+	BeforeEach(func() {
+		var err error
+		set, err = Load([]byte(config))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("Should select the template of the matching label", func() {
+		t := set.Select([]string{"bug", "good first issue"})
+		Expect(t).NotTo(BeNil())
+		Expect(t.Label).To(Equal("bug"))
+
+		body, err := t.Render(Data{Repo: "owner/repo", Description: "it crashes"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(body).To(Equal("Bug in owner/repo: it crashes"))
+	})
+
+	It("Should pick the highest priority when several labels match", func() {
+		Expect(set.Select([]string{"bug", "incident"}).Label).To(Equal("incident"))
+	})
+
+	It("Should break priority ties by label", func() {
+		Expect(set.Select([]string{"incident", "alert", "bug"}).Label).To(Equal("alert"))
+	})
+
+	It("Should select nothing without a matching label", func() {
+		Expect(set.Select([]string{"question"})).To(BeNil())
+		Expect((*Set)(nil).Select([]string{"bug"})).To(BeNil())
+	})
+
+	It("Should reject templates that don't parse", func() {
+		_, err := Load([]byte(`[{label: bug, template: "{{ .Description"}]`))
+		Expect(err).To(MatchError(ContainSubstring("invalid template for label bug")))
+	})
+
+	It("Should reject a label with several templates", func() {
+		_, err := Load([]byte(`[{label: bug, template: a}, {label: bug, template: b}]`))
+		Expect(err).To(MatchError(ContainSubstring("several templates")))
+	})
+
+	It("Should fail rendering an unknown field", func() {
+		set, err := Load([]byte(`[{label: bug, template: "{{ .Unknown }}"}]`))
+		Expect(err).NotTo(HaveOccurred())
+		_, err = set.Select([]string{"bug"}).Render(Data{})
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,85 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/oshribelay/github-issue-operator/internal/controller/resources"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// TokenSecretSweeper periodically deletes the token secrets left behind by GithubIssues
+// that no longer exist, e.g. force-deleted bypassing their finalizer or deleted orphaning
+// their dependents. Only the secrets carrying resources.TokenSecretLabel are looked at.
+type TokenSecretSweeper struct {
+	Client   client.Client
+	Log      logr.Logger
+	Interval time.Duration
+
+	// Names names the token secrets, "<name>-token-secret" when nil
+	Names *resources.TokenSecretNames
+}
+
+// Start sweeps the token secrets every Interval until the context is done, it implements
+// manager.Runnable
+func (s *TokenSecretSweeper) Start(ctx context.Context) error {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if _, err := s.Sweep(ctx); err != nil {
+				s.Log.Error(err, "unable to sweep orphaned token secrets")
+			}
+		}
+	}
+}
+
+// Sweep deletes the orphaned token secrets, returning the keys of the deleted secrets
+func (s *TokenSecretSweeper) Sweep(ctx context.Context) ([]client.ObjectKey, error) {
+	secrets := &corev1.SecretList{}
+	if err := s.Client.List(ctx, secrets, client.MatchingLabels{resources.TokenSecretLabel: "true"}); err != nil {
+		return nil, err
+	}
+
+	var deleted []client.ObjectKey
+	for i := range secrets.Items {
+		secret := &secrets.Items[i]
+		orphaned, err := resources.IsOrphanedTokenSecret(ctx, s.Client, secret, s.Names)
+		if err != nil {
+			return deleted, err
+		}
+		if !orphaned {
+			continue
+		}
+
+		s.Log.Info("deleting orphaned token secret", "secret", client.ObjectKeyFromObject(secret))
+		if err := s.Client.Delete(ctx, secret); client.IgnoreNotFound(err) != nil {
+			return deleted, err
+		}
+		deleted = append(deleted, client.ObjectKeyFromObject(secret))
+	}
+
+	return deleted, nil
+}
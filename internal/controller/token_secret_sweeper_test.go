@@ -0,0 +1,118 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	issuev1 "github.com/oshribelay/github-issue-operator/api/v1"
+	"github.com/oshribelay/github-issue-operator/internal/controller/resources"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("TokenSecretSweeper", func() {
+	ctx := context.Background()
+
+	tokenSecret := func(issueName, uid string) *corev1.Secret {
+		return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+			Name:        issueName + "-token-secret",
+			Namespace:   "default",
+			Labels:      map[string]string{resources.TokenSecretLabel: "true"},
+			Annotations: map[string]string{resources.TokenSecretIssueAnnotation: issueName},
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: issuev1.GroupVersion.String(),
+				Kind:       "GithubIssue",
+				Name:       issueName,
+				UID:        types.UID("uid-" + uid),
+			}},
+		}}
+	}
+
+	It("Should delete only the secrets of GithubIssues that no longer exist", func() {
+		s := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(s)).To(Succeed())
+		Expect(issuev1.AddToScheme(s)).To(Succeed())
+
+		live := &issuev1.GithubIssue{ObjectMeta: metav1.ObjectMeta{Name: "live", Namespace: "default", UID: "uid-live"}}
+		userManaged := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "gone-token-secret", Namespace: "other"}}
+		c := fake.NewClientBuilder().WithScheme(s).
+			WithObjects(live, tokenSecret("live", "live"), tokenSecret("gone", "gone"), userManaged).
+			Build()
+
+		sweeper := &TokenSecretSweeper{Client: c, Log: logr.Discard()}
+		deleted, err := sweeper.Sweep(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(deleted).To(ConsistOf(client.ObjectKey{Namespace: "default", Name: "gone-token-secret"}))
+
+		secrets := &corev1.SecretList{}
+		Expect(c.List(ctx, secrets)).To(Succeed())
+		Expect(secrets.Items).To(HaveLen(2))
+	})
+
+	It("Should keep the secret of a GithubIssue recreated under the same name", func() {
+		s := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(s)).To(Succeed())
+		Expect(issuev1.AddToScheme(s)).To(Succeed())
+
+		// the secret still points at the deleted GithubIssue until the new one re-owns it
+		recreated := &issuev1.GithubIssue{ObjectMeta: metav1.ObjectMeta{Name: "recreated", Namespace: "default", UID: "uid-new"}}
+		c := fake.NewClientBuilder().WithScheme(s).
+			WithObjects(recreated, tokenSecret("recreated", "old")).
+			Build()
+
+		sweeper := &TokenSecretSweeper{Client: c, Log: logr.Discard()}
+		deleted, err := sweeper.Sweep(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(deleted).To(BeEmpty())
+
+		secret := &corev1.Secret{}
+		Expect(c.Get(ctx, client.ObjectKey{Namespace: "default", Name: "recreated-token-secret"}, secret)).To(Succeed())
+	})
+
+	It("Should delete the orphaned secrets whose owner reference was removed", func() {
+		s := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(s)).To(Succeed())
+		Expect(issuev1.AddToScheme(s)).To(Succeed())
+
+		// deleting a GithubIssue orphaning its dependents strips the owner reference
+		orphaned := tokenSecret("orphaned", "orphaned")
+		orphaned.OwnerReferences = nil
+		// the secrets the operator didn't create aren't selected
+		unlabeled := tokenSecret("unlabeled", "unlabeled")
+		unlabeled.Labels = nil
+		c := fake.NewClientBuilder().WithScheme(s).
+			WithObjects(orphaned, unlabeled).
+			Build()
+
+		sweeper := &TokenSecretSweeper{Client: c, Log: logr.Discard()}
+		deleted, err := sweeper.Sweep(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(deleted).To(ConsistOf(client.ObjectKey{Namespace: "default", Name: "orphaned-token-secret"}))
+
+		secret := &corev1.Secret{}
+		Expect(c.Get(ctx, client.ObjectKey{Namespace: "default", Name: "unlabeled-token-secret"}, secret)).To(Succeed())
+	})
+})
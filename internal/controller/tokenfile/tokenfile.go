@@ -0,0 +1,91 @@
+package tokenfile
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/go-logr/logr"
+)
+
+// Source is a GitHub token read from a file, e.g. a mounted secret, and read again whenever
+// the file changes
+type Source struct {
+	Path string
+	Log  logr.Logger
+
+	mu    sync.RWMutex
+	token string
+}
+
+// Load reads the token of the file at path
+func Load(path string, log logr.Logger) (*Source, error) {
+	s := &Source{Path: path, Log: log}
+	if err := s.Reload(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Token returns the token last read from the file
+func (s *Source) Token() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.token
+}
+
+// Reload reads the token of the file again, the token read before is kept when it fails
+func (s *Source) Reload() error {
+	data, err := os.ReadFile(s.Path)
+	if err != nil {
+		return fmt.Errorf("failed to read the token file: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.token = strings.TrimSpace(string(data))
+	return nil
+}
+
+// Start reloads the token whenever the file changes until the context is done, it implements
+// manager.Runnable. The directory of the file is watched, since mounted secrets are updated
+// by swapping a symlink rather than writing the file.
+func (s *Source) Start(ctx context.Context) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to watch the token file: %w", err)
+	}
+	defer watcher.Close()
+	if err := watcher.Add(filepath.Dir(s.Path)); err != nil {
+		return fmt.Errorf("failed to watch the token file: %w", err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case _, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			previous := s.Token()
+			if err := s.Reload(); err != nil {
+				// the file is briefly missing while it's replaced, the next event reloads it
+				s.Log.V(1).Info("unable to reload the token file", "error", err.Error())
+				continue
+			}
+			if s.Token() != previous {
+				s.Log.Info("reloaded the GitHub token from its file", "path", s.Path)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			s.Log.Error(err, "error watching the token file")
+		}
+	}
+}
@@ -0,0 +1,14 @@
+package tokenfile
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestTokenFile(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecs(t, "Token File Suite")
+}
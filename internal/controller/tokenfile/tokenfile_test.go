@@ -0,0 +1,86 @@
+package tokenfile
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Source", func() {
+	var path string
+
+	BeforeEach(func() {
+		path = filepath.Join(GinkgoT().TempDir(), "token")
+	})
+
+	It("Should read the token from the file, trimming the trailing newline", func() {
+		Expect(os.WriteFile(path, []byte("ghp_first\n"), 0o600)).To(Succeed())
+
+		s, err := Load(path, logr.Discard())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(s.Token()).To(Equal("ghp_first"))
+	})
+
+	It("Should fail to load a missing file", func() {
+		_, err := Load(path, logr.Discard())
+		Expect(err).To(MatchError(ContainSubstring("failed to read the token file")))
+	})
+
+	It("Should keep the token when the file can't be read again", func() {
+		Expect(os.WriteFile(path, []byte("ghp_first"), 0o600)).To(Succeed())
+		s, err := Load(path, logr.Discard())
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(os.Remove(path)).To(Succeed())
+		Expect(s.Reload()).NotTo(Succeed())
+		Expect(s.Token()).To(Equal("ghp_first"))
+	})
+
+	Context("When watching the file", func() {
+		var (
+			s      *Source
+			cancel context.CancelFunc
+			done   chan error
+		)
+
+		BeforeEach(func() {
+			Expect(os.WriteFile(path, []byte("ghp_first"), 0o600)).To(Succeed())
+			var err error
+			s, err = Load(path, logr.Discard())
+			Expect(err).NotTo(HaveOccurred())
+
+			var ctx context.Context
+			ctx, cancel = context.WithCancel(context.Background())
+			done = make(chan error, 1)
+			go func() { done <- s.Start(ctx) }()
+			// give the watcher time to start before changing the file
+			time.Sleep(100 * time.Millisecond)
+		})
+
+		AfterEach(func() {
+			cancel()
+			Eventually(done).Should(Receive(BeNil()))
+		})
+
+		It("Should reload the token written to the file", func() {
+			Expect(os.WriteFile(path, []byte("ghp_second"), 0o600)).To(Succeed())
+			Eventually(s.Token).Should(Equal("ghp_second"))
+		})
+
+		It("Should reload the token of a file swapped like a mounted secret", func() {
+			dir := filepath.Dir(path)
+			data := filepath.Join(dir, "..data")
+			Expect(os.WriteFile(data, []byte("ghp_second"), 0o600)).To(Succeed())
+			next := filepath.Join(dir, "token.next")
+			Expect(os.Symlink(data, next)).To(Succeed())
+			Expect(os.Rename(next, path)).To(Succeed())
+
+			Eventually(s.Token).Should(Equal("ghp_second"))
+		})
+	})
+})
@@ -1,16 +1,451 @@
 package utils
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	v1 "github.com/oshribelay/github-issue-operator/api/v1"
+	"github.com/oshribelay/github-issue-operator/internal/footer"
+	"github.com/oshribelay/github-issue-operator/internal/repourl"
+	"regexp"
+	"sort"
 	"strings"
+	"text/template"
+	"time"
+	"unicode/utf8"
 )
 
+// TitleHashAnnotation records the hash of the repo and title the issue number was resolved for
+const TitleHashAnnotation = "issue.core.github.io/title-hash"
+
+// ApprovedAnnotation marks a GithubIssue as approved when set to "true", lifting its approval label
+const ApprovedAnnotation = "issue.core.github.io/approved"
+
+// GithubBodyLimit is the maximum number of characters GitHub accepts in an issue body
+const GithubBodyLimit = 65536
+
+// truncatedNotice is appended to bodies that were cut down to GithubBodyLimit
+const truncatedNotice = "...(truncated)"
+
+// ParseRepoUrl extracts the owner and repo from a 'https://github.com/{owner}/{repo}' URL,
+// accepting exactly the URLs the webhook admits
 func ParseRepoUrl(repoUrl string) (string, string, error) {
-	repoUrl = strings.TrimPrefix(repoUrl, "https://github.com/")
-	parts := strings.Split(repoUrl, "/")
-	if len(parts) < 2 {
-		return "", "", fmt.Errorf("invalid repo url: %s", repoUrl)
+	owner, repo, err := repourl.Parse(repoUrl)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid repo url %s: %w", repoUrl, err)
+	}
+	return owner, repo, nil
+}
+
+// OperatorMarker is the invisible marker tagging the body of issues created by the operator
+const OperatorMarker = "<!-- created-by: github-issue-operator -->"
+
+// AddOperatorMarker appends the operator marker to the body, unless it carries it already
+func AddOperatorMarker(body string) string {
+	if HasOperatorMarker(body) {
+		return body
+	}
+	return body + "\n\n" + OperatorMarker
+}
+
+// HasOperatorMarker reports whether the body carries the operator marker
+func HasOperatorMarker(body string) bool {
+	return strings.Contains(body, OperatorMarker)
+}
+
+// DedupeMarker returns the invisible marker tying an issue to the GithubIssue with the UID,
+// so an issue created before a failed status update is found instead of created again
+func DedupeMarker(uid string) string {
+	return fmt.Sprintf("<!-- github-issue-operator:uid=%s -->", uid)
+}
+
+// AddDedupeMarker appends the dedupe marker of the UID to the body, unless it carries it already
+func AddDedupeMarker(body, uid string) string {
+	marker := DedupeMarker(uid)
+	if strings.Contains(body, marker) {
+		return body
+	}
+	return body + "\n\n" + marker
+}
+
+// InitialCommentMarker returns the invisible marker tagging the initial comment at the index,
+// so the comments posted before a failed reconcile aren't posted again
+func InitialCommentMarker(index int) string {
+	return fmt.Sprintf("<!-- github-issue-operator:initial-comment=%d -->", index)
+}
+
+// StateCommentMarker returns the invisible marker tagging the comment posted along with a
+// change of the issue state, the nonce telling it apart from the same comment posted along
+// with an earlier change
+func StateCommentMarker(nonce string) string {
+	return fmt.Sprintf("<!-- github-issue-operator:state-comment=%s -->", nonce)
+}
+
+// IssueHash returns a stable hash identifying the repo and title of an issue
+func IssueHash(repo, title string) string {
+	sum := sha256.Sum256([]byte(repo + "\n" + title))
+	return hex.EncodeToString(sum[:8])
+}
+
+// ContentHash returns a stable hash of the content posted to an issue
+func ContentHash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:8])
+}
+
+// AtRisk reports whether a milestone due at dueOn is within the given window of now, a
+// milestone past its due date is at risk too. While it isn't at risk, recheckIn is how
+// long until it will be.
+func AtRisk(dueOn, now time.Time, within time.Duration) (atRisk bool, recheckIn time.Duration) {
+	boundary := dueOn.Add(-within)
+	if !now.Before(boundary) {
+		return true, 0
+	}
+	return false, boundary.Sub(now)
+}
+
+// InCreateWindow reports whether now is within the daily create window. Outside the
+// window, opensIn is how long until it opens next.
+func InCreateWindow(window *v1.CreateWindow, now time.Time) (in bool, opensIn time.Duration, err error) {
+	loc, err := time.LoadLocation(window.TimeZone)
+	if err != nil {
+		return false, 0, fmt.Errorf("invalid create window time zone: %w", err)
+	}
+	start, err := time.Parse("15:04", window.Start)
+	if err != nil {
+		return false, 0, fmt.Errorf("invalid create window start: %w", err)
+	}
+	end, err := time.Parse("15:04", window.End)
+	if err != nil {
+		return false, 0, fmt.Errorf("invalid create window end: %w", err)
+	}
+
+	now = now.In(loc)
+	startsAt := time.Date(now.Year(), now.Month(), now.Day(), start.Hour(), start.Minute(), 0, 0, loc)
+	endsAt := time.Date(now.Year(), now.Month(), now.Day(), end.Hour(), end.Minute(), 0, 0, loc)
+	if endsAt.After(startsAt) {
+		in = !now.Before(startsAt) && now.Before(endsAt)
+	} else {
+		// the window wraps past midnight
+		in = !now.Before(startsAt) || now.Before(endsAt)
+	}
+	if in {
+		return true, 0, nil
+	}
+
+	if !startsAt.After(now) {
+		startsAt = time.Date(now.Year(), now.Month(), now.Day()+1, start.Hour(), start.Minute(), 0, 0, loc)
+	}
+	return false, startsAt.Sub(now), nil
+}
+
+// TitlePrefixData is what the title prefix template is rendered with
+type TitlePrefixData struct {
+	Cluster   string
+	Namespace string
+}
+
+// PrefixTitle prepends the title prefix template, rendered with the cluster and the
+// namespace of the GithubIssue, to the title. A nil prefix leaves the title as is.
+func PrefixTitle(prefix *template.Template, data TitlePrefixData, title string) (string, error) {
+	if prefix == nil {
+		return title, nil
+	}
+
+	var rendered strings.Builder
+	if err := prefix.Execute(&rendered, data); err != nil {
+		return "", fmt.Errorf("failed to render title prefix: %w", err)
+	}
+	return rendered.String() + title, nil
+}
+
+// PrefixEmoji prepends the emoji to the title, an empty emoji leaves the title as is
+func PrefixEmoji(emoji, title string) string {
+	if emoji == "" {
+		return title
+	}
+	return emoji + " " + title
+}
+
+// TrimEmoji removes the first of the emojis PrefixEmoji prepended to the title
+func TrimEmoji(title string, emojis []string) string {
+	for _, emoji := range emojis {
+		if emoji == "" {
+			continue
+		}
+		if trimmed, ok := strings.CutPrefix(title, emoji+" "); ok {
+			return trimmed
+		}
+	}
+	return title
+}
+
+// CanSkipScan reports whether the recorded issue number still belongs to the GithubIssue, so
+// the repository doesn't need to be scanned for it. A rescan is needed until a number is
+// recorded and whenever the repo or title were edited since.
+func CanSkipScan(githubIssue *v1.GithubIssue) bool {
+	return githubIssue.Status.IssueNumber > 0 &&
+		githubIssue.Annotations[TitleHashAnnotation] == IssueHash(githubIssue.Spec.Repo, githubIssue.Spec.Title)
+}
+
+// ParseKeyValues parses a comma separated list of key=value pairs, as used by the operator flags
+func ParseKeyValues(s string) (map[string]string, error) {
+	values := map[string]string{}
+	if strings.TrimSpace(s) == "" {
+		return values, nil
+	}
+
+	for _, pair := range strings.Split(s, ",") {
+		key, value, found := strings.Cut(pair, "=")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if !found || key == "" || value == "" {
+			return nil, fmt.Errorf("invalid key=value pair: %q", pair)
+		}
+		values[key] = value
+	}
+
+	return values, nil
+}
+
+// ParseList parses a comma separated list, as used by the operator flags
+func ParseList(s string) []string {
+	var values []string
+	for _, value := range strings.Split(s, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// NormalizeLineEndings converts CRLF line endings to the LF GitHub stores bodies with, so
+// a body written on Windows doesn't differ from the stored one on every reconcile
+func NormalizeLineEndings(body string) string {
+	return strings.ReplaceAll(body, "\r\n", "\n")
+}
+
+// backtickRun matches the runs of backticks in a body
+var backtickRun = regexp.MustCompile("`+")
+
+// FenceBody wraps the body in a code fence so GitHub renders it as plain text, the fence
+// is longer than any run of backticks in the body so it can't be closed early
+func FenceBody(body string) string {
+	fence := "```"
+	for _, run := range backtickRun.FindAllString(body, -1) {
+		if len(run) >= len(fence) {
+			fence = strings.Repeat("`", len(run)+1)
+		}
+	}
+	return fence + "text\n" + strings.TrimSuffix(body, "\n") + "\n" + fence
+}
+
+// attachmentsStart and attachmentsEnd delimit the attachments section of the issue body
+const (
+	attachmentsStart = "<!-- github-issue-operator:attachments -->"
+	attachmentsEnd   = "<!-- /github-issue-operator:attachments -->"
+)
+
+// RenderAttachments renders a link for every attachment in a section at the end of the body.
+// A section the body carries already is replaced in place, and dropped without attachments.
+func RenderAttachments(body string, attachments []v1.Attachment) string {
+	var section string
+	if len(attachments) > 0 {
+		lines := []string{attachmentsStart, "**Attachments**", ""}
+		for _, attachment := range attachments {
+			title := strings.NewReplacer("[", "\\[", "]", "\\]").Replace(attachment.Title)
+			lines = append(lines, fmt.Sprintf("- [%s](%s)", title, attachment.URL))
+		}
+		lines = append(lines, attachmentsEnd)
+		section = "\n\n" + strings.Join(lines, "\n")
+	}
+	return replaceSection(body, attachmentsStart, attachmentsEnd, section)
+}
+
+// trackedByPRStart and trackedByPREnd delimit the line of the issue body naming its pull request
+const (
+	trackedByPRStart = "<!-- github-issue-operator:tracked-by-pr -->"
+	trackedByPREnd   = "<!-- /github-issue-operator:tracked-by-pr -->"
+)
+
+// RenderTrackedByPR renders a "Tracked by PR #N" line at the end of the body, replacing the
+// line the body carries already. Zero drops the line.
+func RenderTrackedByPR(body string, number int) string {
+	var section string
+	if number > 0 {
+		section = fmt.Sprintf("\n\n%sTracked by PR #%d%s", trackedByPRStart, number, trackedByPREnd)
+	}
+	return replaceSection(body, trackedByPRStart, trackedByPREnd, section)
+}
+
+// replaceSection replaces the section of the body between the start and end markers, along
+// with the blank lines before it, appending the section when the body has none
+func replaceSection(body, start, end, section string) string {
+	if i := strings.Index(body, start); i >= 0 {
+		if j := strings.Index(body[i:], end); j >= 0 {
+			return strings.TrimRight(body[:i], "\n") + section + body[i+j+len(end):]
+		}
+	}
+	return body + section
+}
+
+// managedSectionStart and managedSectionEnd delimit the section of the issue body the operator
+// owns when the GithubIssue manages a section only
+const (
+	managedSectionStart = "<!-- operator:start -->"
+	managedSectionEnd   = "<!-- operator:end -->"
+)
+
+// RenderManagedSection replaces the content between the managed section markers of the body,
+// leaving what surrounds them intact. A body without the markers gets the section appended.
+func RenderManagedSection(body, content string) string {
+	if start := strings.Index(body, managedSectionStart); start >= 0 {
+		if end := strings.Index(body[start:], managedSectionEnd); end >= 0 {
+			return body[:start] + managedSectionStart + "\n" + content + "\n" + body[start+end:]
+		}
+	}
+	section := managedSectionStart + "\n" + content + "\n" + managedSectionEnd
+	if body == "" {
+		return section
+	}
+	return body + "\n\n" + section
+}
+
+// footerStart and footerEnd delimit the footer of the issue body
+const (
+	footerStart = "<!-- github-issue-operator:footer -->"
+	footerEnd   = "<!-- /github-issue-operator:footer -->"
+)
+
+// RenderFooter renders the footer template at the end of the body. A footer the body carries
+// already is replaced in place, so rendering it again yields the same body.
+func RenderFooter(body, footerTemplate string, data footer.Data) (string, error) {
+	rendered, err := footer.Render(footerTemplate, data)
+	if err != nil {
+		return "", err
+	}
+	section := "\n\n" + footerStart + "\n" + rendered + "\n" + footerEnd
+	return replaceSection(body, footerStart, footerEnd, section), nil
+}
+
+// localesStart and localesEnd delimit the bodies of the other locales in the issue body
+const (
+	localesStart = "<!-- github-issue-operator:locales -->"
+	localesEnd   = "<!-- /github-issue-operator:locales -->"
+)
+
+// RenderLocales appends the bodies of the locales other than defaultLocale to the body, each
+// in a collapsible section, ordered by locale. The sections the body carries already are
+// replaced, so rendering them again yields the same body.
+func RenderLocales(body string, bodies map[string]string, defaultLocale string) string {
+	locales := make([]string, 0, len(bodies))
+	for locale := range bodies {
+		if locale != defaultLocale {
+			locales = append(locales, locale)
+		}
+	}
+	if len(locales) == 0 {
+		return replaceSection(body, localesStart, localesEnd, "")
 	}
+	sort.Strings(locales)
 
-	return parts[0], parts[1], nil
+	var section strings.Builder
+	section.WriteString("\n\n" + localesStart)
+	for _, locale := range locales {
+		fmt.Fprintf(&section, "\n<details>\n<summary>%s</summary>\n\n%s\n\n</details>",
+			locale, strings.TrimSpace(bodies[locale]))
+	}
+	section.WriteString("\n" + localesEnd)
+	return replaceSection(body, localesStart, localesEnd, section.String())
+}
+
+// RenderBlockedBy appends a "Blocked by #N" line to the body for every blocking issue
+func RenderBlockedBy(body string, blockedBy []int) string {
+	if len(blockedBy) == 0 {
+		return body
+	}
+
+	lines := make([]string, 0, len(blockedBy))
+	for _, number := range blockedBy {
+		lines = append(lines, fmt.Sprintf("Blocked by #%d", number))
+	}
+
+	return body + "\n\n" + strings.Join(lines, "\n")
+}
+
+// trackingItem matches the checklist item of an issue in a tracking issue body
+func trackingItem(number int) *regexp.Regexp {
+	return regexp.MustCompile(fmt.Sprintf(`(?m)^(\s*[-*] )\[([ xX])\] #%d(\D|$)`, number))
+}
+
+// SetTrackingItem makes sure the tracking issue body holds a checklist item for the issue,
+// checked when the issue is closed. It reports whether the body changed
+func SetTrackingItem(body string, number int, closed bool) (string, bool) {
+	mark := " "
+	if closed {
+		mark = "x"
+	}
+
+	re := trackingItem(number)
+	match := re.FindStringSubmatchIndex(body)
+	if match == nil {
+		item := fmt.Sprintf("- [%s] #%d", mark, number)
+		if body == "" {
+			return item, true
+		}
+		return strings.TrimRight(body, "\n") + "\n" + item, true
+	}
+
+	// the checkbox mark is the second group, keep the rest of the line as is
+	current := body[match[4]:match[5]]
+	if strings.EqualFold(current, mark) {
+		return body, false
+	}
+	return body[:match[4]] + mark + body[match[5]:], true
+}
+
+// TruncateBody cuts body down to at most limit characters, replacing the tail with a
+// truncation notice. It reports whether the body had to be truncated.
+func TruncateBody(body string, limit int) (string, bool) {
+	runes := []rune(body)
+	if len(runes) <= limit {
+		return body, false
+	}
+
+	notice := []rune(truncatedNotice)
+	if limit <= len(notice) {
+		return string(notice[:limit]), true
+	}
+
+	return string(runes[:limit-len(notice)]) + truncatedNotice, true
+}
+
+// TruncateBytes cuts s down to at most limit bytes on a character boundary, replacing the tail
+// with a truncation notice. It reports whether s had to be truncated.
+func TruncateBytes(s string, limit int) (string, bool) {
+	if len(s) <= limit {
+		return s, false
+	}
+	if limit <= len(truncatedNotice) {
+		return truncatedNotice[:limit], true
+	}
+
+	cut := limit - len(truncatedNotice)
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + truncatedNotice, true
+}
+
+// ClosingComment appends the closing reference to the comment the issue is closed with,
+// GitHub links both commit SHAs and pull request URLs. It returns the comment as is
+// without a closing reference.
+func ClosingComment(comment, closedBy string) string {
+	if closedBy == "" {
+		return comment
+	}
+	reference := "Closed by " + closedBy
+	if comment == "" {
+		return reference
+	}
+	return comment + "\n\n" + reference
 }
@@ -0,0 +1,507 @@
+package utils
+
+import (
+	"strings"
+	"text/template"
+	"time"
+	"unicode/utf8"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "github.com/oshribelay/github-issue-operator/api/v1"
+	"github.com/oshribelay/github-issue-operator/internal/footer"
+)
+
+var _ = Describe("ParseRepoUrl", func() {
+	It("Should extract the owner and repo", func() {
+		owner, repo, err := ParseRepoUrl("https://github.com/owner/repo")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(owner).To(Equal("owner"))
+		Expect(repo).To(Equal("repo"))
+	})
+
+	DescribeTable("Should reject URLs the webhook rejects",
+		func(repoUrl string) {
+			_, _, err := ParseRepoUrl(repoUrl)
+			Expect(err).To(HaveOccurred())
+		},
+		Entry("extra path segment", "https://github.com/owner/repo/extra"),
+		Entry("trailing slash", "https://github.com/owner/repo/"),
+		Entry("missing repo", "https://github.com/owner"),
+		Entry("empty owner", "https://github.com//repo"),
+		Entry("other host", "https://gitlab.com/owner/repo"),
+	)
+})
+
+var _ = Describe("TruncateBody", func() {
+	It("Should keep a body exactly at the limit untouched", func() {
+		body := strings.Repeat("a", GithubBodyLimit)
+		truncated, ok := TruncateBody(body, GithubBodyLimit)
+		Expect(ok).To(BeFalse())
+		Expect(truncated).To(Equal(body))
+	})
+
+	It("Should truncate a body one character over the limit", func() {
+		body := strings.Repeat("a", GithubBodyLimit+1)
+		truncated, ok := TruncateBody(body, GithubBodyLimit)
+		Expect(ok).To(BeTrue())
+		Expect(utf8.RuneCountInString(truncated)).To(Equal(GithubBodyLimit))
+		Expect(truncated).To(HaveSuffix("...(truncated)"))
+	})
+
+	It("Should count multi-byte characters as single characters", func() {
+		body := strings.Repeat("é", 20)
+		truncated, ok := TruncateBody(body, 20)
+		Expect(ok).To(BeFalse())
+		Expect(truncated).To(Equal(body))
+
+		truncated, ok = TruncateBody(body, 19)
+		Expect(ok).To(BeTrue())
+		Expect(utf8.RuneCountInString(truncated)).To(Equal(19))
+		Expect(utf8.ValidString(truncated)).To(BeTrue())
+	})
+
+	It("Should not exceed a limit shorter than the notice", func() {
+		truncated, ok := TruncateBody("a long enough body", 5)
+		Expect(ok).To(BeTrue())
+		Expect(truncated).To(HaveLen(5))
+	})
+})
+
+var _ = Describe("TruncateBytes", func() {
+	It("Should keep a string exactly at the limit untouched", func() {
+		truncated, ok := TruncateBytes("abcdef", 6)
+		Expect(ok).To(BeFalse())
+		Expect(truncated).To(Equal("abcdef"))
+	})
+
+	It("Should count bytes and cut on a character boundary", func() {
+		s := strings.Repeat("é", 20)
+		truncated, ok := TruncateBytes(s, 25)
+		Expect(ok).To(BeTrue())
+		Expect(len(truncated)).To(BeNumerically("<=", 25))
+		Expect(utf8.ValidString(truncated)).To(BeTrue())
+		Expect(truncated).To(HaveSuffix("...(truncated)"))
+	})
+
+	It("Should not exceed a limit shorter than the notice", func() {
+		truncated, ok := TruncateBytes("a long enough body", 5)
+		Expect(ok).To(BeTrue())
+		Expect(truncated).To(HaveLen(5))
+	})
+})
+
+var _ = Describe("RenderBlockedBy", func() {
+	It("Should leave the body untouched without blocking issues", func() {
+		Expect(RenderBlockedBy("body", nil)).To(Equal("body"))
+	})
+
+	It("Should append a line for every blocking issue", func() {
+		Expect(RenderBlockedBy("body", []int{3, 7})).To(Equal("body\n\nBlocked by #3\nBlocked by #7"))
+	})
+})
+
+var _ = Describe("RenderAttachments", func() {
+	attachments := []v1.Attachment{
+		{Title: "Grafana", URL: "https://grafana.example.com/d/api"},
+		{Title: "Runbook [prod]", URL: "https://runbooks.example.com/api"},
+	}
+	section := "<!-- github-issue-operator:attachments -->\n**Attachments**\n\n" +
+		"- [Grafana](https://grafana.example.com/d/api)\n" +
+		"- [Runbook \\[prod\\]](https://runbooks.example.com/api)\n" +
+		"<!-- /github-issue-operator:attachments -->"
+
+	It("Should leave the body untouched without attachments", func() {
+		Expect(RenderAttachments("body", nil)).To(Equal("body"))
+	})
+
+	It("Should append a link for every attachment", func() {
+		Expect(RenderAttachments("body", attachments)).To(Equal("body\n\n" + section))
+	})
+
+	It("Should not duplicate the section when rendered again", func() {
+		once := RenderAttachments("body", attachments)
+		Expect(RenderAttachments(once, attachments)).To(Equal(once))
+	})
+
+	It("Should replace the section when the attachments change", func() {
+		rendered := RenderAttachments(RenderAttachments("body", attachments), attachments[:1])
+		Expect(rendered).To(ContainSubstring("Grafana"))
+		Expect(rendered).NotTo(ContainSubstring("Runbook"))
+		Expect(strings.Count(rendered, "<!-- github-issue-operator:attachments -->")).To(Equal(1))
+	})
+
+	It("Should drop the section once the attachments are removed", func() {
+		Expect(RenderAttachments(RenderAttachments("body", attachments), nil)).To(Equal("body"))
+	})
+
+	It("Should keep what follows the section", func() {
+		body := RenderAttachments("body", attachments) + "\n\n" + OperatorMarker
+		Expect(RenderAttachments(body, attachments)).To(Equal(body))
+	})
+})
+
+var _ = Describe("ParseKeyValues", func() {
+	It("Should parse the pairs", func() {
+		values, err := ParseKeyValues("bug=kind/bug, feature = kind/feature")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(values).To(Equal(map[string]string{"bug": "kind/bug", "feature": "kind/feature"}))
+	})
+
+	It("Should return an empty map for an empty string", func() {
+		values, err := ParseKeyValues("")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(values).To(BeEmpty())
+	})
+
+	It("Should reject malformed pairs", func() {
+		_, err := ParseKeyValues("bug=kind/bug,feature")
+		Expect(err).To(HaveOccurred())
+		_, err = ParseKeyValues("=kind/bug")
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("AddDedupeMarker", func() {
+	It("Should append the marker of the UID once", func() {
+		body := AddDedupeMarker("body", "1234")
+		Expect(body).To(Equal("body\n\n<!-- github-issue-operator:uid=1234 -->"))
+		Expect(AddDedupeMarker(body, "1234")).To(Equal(body))
+	})
+})
+
+var _ = Describe("ParseList", func() {
+	It("Should parse the values, dropping the empty ones", func() {
+		Expect(ParseList(" HasPR, ,IssueOpen ")).To(Equal([]string{"HasPR", "IssueOpen"}))
+	})
+
+	It("Should return no values for an empty string", func() {
+		Expect(ParseList("")).To(BeEmpty())
+	})
+})
+
+var _ = Describe("CanSkipScan", func() {
+	var githubIssue *v1.GithubIssue
+
+	BeforeEach(func() {
+		githubIssue = &v1.GithubIssue{
+			Spec: v1.GithubIssueSpec{Repo: "https://github.com/owner/repo", Title: "title"},
+		}
+	})
+
+	It("Should scan until an issue number is recorded", func() {
+		Expect(CanSkipScan(githubIssue)).To(BeFalse())
+	})
+
+	It("Should skip the scan while the hash is unchanged", func() {
+		githubIssue.Status.IssueNumber = 3
+		githubIssue.Annotations = map[string]string{TitleHashAnnotation: IssueHash(githubIssue.Spec.Repo, githubIssue.Spec.Title)}
+		Expect(CanSkipScan(githubIssue)).To(BeTrue())
+	})
+
+	It("Should rescan once the title is edited", func() {
+		githubIssue.Status.IssueNumber = 3
+		githubIssue.Annotations = map[string]string{TitleHashAnnotation: IssueHash(githubIssue.Spec.Repo, githubIssue.Spec.Title)}
+		githubIssue.Spec.Title = "edited title"
+		Expect(CanSkipScan(githubIssue)).To(BeFalse())
+	})
+
+	It("Should hash the repo and title separately", func() {
+		Expect(IssueHash("a", "bc")).NotTo(Equal(IssueHash("ab", "c")))
+	})
+
+	It("Should hash the same content the same way", func() {
+		Expect(ContentHash("body")).To(Equal(ContentHash("body")))
+		Expect(ContentHash("body")).NotTo(Equal(ContentHash("edited body")))
+	})
+})
+
+var _ = Describe("SetTrackingItem", func() {
+	It("Should append an unchecked item for an open issue", func() {
+		body, changed := SetTrackingItem("Tracking:\n- [ ] #1\n", 12, false)
+		Expect(changed).To(BeTrue())
+		Expect(body).To(Equal("Tracking:\n- [ ] #1\n- [ ] #12"))
+	})
+
+	It("Should be idempotent", func() {
+		body, changed := SetTrackingItem("- [ ] #12 the issue", 12, false)
+		Expect(changed).To(BeFalse())
+		Expect(body).To(Equal("- [ ] #12 the issue"))
+	})
+
+	It("Should check off the item of a closed issue", func() {
+		body, changed := SetTrackingItem("- [ ] #1\n- [ ] #12 the issue\n- [ ] #123", 12, true)
+		Expect(changed).To(BeTrue())
+		Expect(body).To(Equal("- [ ] #1\n- [x] #12 the issue\n- [ ] #123"))
+
+		_, changed = SetTrackingItem(body, 12, true)
+		Expect(changed).To(BeFalse())
+	})
+
+	It("Should not confuse issues sharing a number prefix", func() {
+		body, changed := SetTrackingItem("- [ ] #123", 12, false)
+		Expect(changed).To(BeTrue())
+		Expect(body).To(Equal("- [ ] #123\n- [ ] #12"))
+	})
+
+	It("Should start an empty body with the item", func() {
+		body, _ := SetTrackingItem("", 3, true)
+		Expect(body).To(Equal("- [x] #3"))
+	})
+})
+
+var _ = Describe("Operator marker", func() {
+	It("Should append the marker once", func() {
+		body := AddOperatorMarker("body")
+		Expect(HasOperatorMarker(body)).To(BeTrue())
+		Expect(AddOperatorMarker(body)).To(Equal(body))
+		Expect(HasOperatorMarker("body")).To(BeFalse())
+	})
+})
+
+var _ = Describe("AtRisk", func() {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	It("Should wait until the milestone is within the window", func() {
+		atRisk, recheckIn := AtRisk(now.Add(10*24*time.Hour), now, 7*24*time.Hour)
+		Expect(atRisk).To(BeFalse())
+		Expect(recheckIn).To(Equal(3 * 24 * time.Hour))
+	})
+
+	It("Should flag a milestone due within the window", func() {
+		atRisk, recheckIn := AtRisk(now.Add(2*24*time.Hour), now, 7*24*time.Hour)
+		Expect(atRisk).To(BeTrue())
+		Expect(recheckIn).To(BeZero())
+	})
+
+	It("Should flag a milestone at the window boundary and past its due date", func() {
+		atRisk, _ := AtRisk(now.Add(7*24*time.Hour), now, 7*24*time.Hour)
+		Expect(atRisk).To(BeTrue())
+
+		atRisk, _ = AtRisk(now.Add(-time.Hour), now, 7*24*time.Hour)
+		Expect(atRisk).To(BeTrue())
+	})
+})
+
+var _ = Describe("RenderManagedSection", func() {
+	It("Should wrap the content of a new body in the markers", func() {
+		Expect(RenderManagedSection("", "Status: investigating")).To(Equal(
+			"<!-- operator:start -->\nStatus: investigating\n<!-- operator:end -->"))
+	})
+
+	It("Should keep the human edits around the section when replacing it", func() {
+		live := "Summary written by a human\n\n<!-- operator:start -->\nStatus: investigating\n<!-- operator:end -->\n\n## Notes\n- checked the logs"
+		Expect(RenderManagedSection(live, "Status: mitigated")).To(Equal(
+			"Summary written by a human\n\n<!-- operator:start -->\nStatus: mitigated\n<!-- operator:end -->\n\n## Notes\n- checked the logs"))
+	})
+
+	It("Should render the same body again once the section is up to date", func() {
+		live := RenderManagedSection("Summary written by a human", "Status: mitigated")
+		Expect(RenderManagedSection(live, "Status: mitigated")).To(Equal(live))
+	})
+
+	It("Should append the section to a body whose markers were removed", func() {
+		Expect(RenderManagedSection("Summary written by a human", "Status: mitigated")).To(Equal(
+			"Summary written by a human\n\n<!-- operator:start -->\nStatus: mitigated\n<!-- operator:end -->"))
+	})
+})
+
+var _ = Describe("RenderTrackedByPR", func() {
+	It("Should append the line naming the pull request", func() {
+		Expect(RenderTrackedByPR("body", 9)).To(Equal("body\n\n" + trackedByPRStart + "Tracked by PR #9" + trackedByPREnd))
+	})
+
+	It("Should replace the line when the pull request changes", func() {
+		Expect(RenderTrackedByPR(RenderTrackedByPR("body", 9), 12)).To(Equal(RenderTrackedByPR("body", 12)))
+	})
+
+	It("Should drop the line without a pull request", func() {
+		Expect(RenderTrackedByPR(RenderTrackedByPR("body", 9), 0)).To(Equal("body"))
+	})
+})
+
+var _ = Describe("RenderFooter", func() {
+	data := footer.Data{IssueNumber: 7, IssueURL: "https://github.com/owner/repo/issues/7"}
+
+	It("Should append the rendered footer between markers", func() {
+		Expect(RenderFooter("body", "Tracked as #{{ .IssueNumber }}", data)).To(Equal(
+			"body\n\n" + footerStart + "\nTracked as #7\n" + footerEnd))
+	})
+
+	It("Should replace the footer the body carries", func() {
+		body, err := RenderFooter("body", "See {{ .IssueURL }}", footer.Data{IssueNumber: 3, IssueURL: "https://old"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(RenderFooter(body, "See {{ .IssueURL }}", data)).To(Equal(
+			"body\n\n" + footerStart + "\nSee https://github.com/owner/repo/issues/7\n" + footerEnd))
+	})
+
+	It("Should fail for an unknown field", func() {
+		_, err := RenderFooter("body", "{{ .Nope }}", data)
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("RenderLocales", func() {
+	bodies := map[string]string{
+		"en":    "Disk full",
+		"pt-BR": "Disco cheio\n",
+		"de":    "Festplatte voll",
+	}
+	rendered := "Disk full\n\n" + localesStart +
+		"\n<details>\n<summary>de</summary>\n\nFestplatte voll\n\n</details>" +
+		"\n<details>\n<summary>pt-BR</summary>\n\nDisco cheio\n\n</details>\n" + localesEnd
+
+	It("Should append the other locales in collapsible sections, ordered by locale", func() {
+		Expect(RenderLocales("Disk full", bodies, "en")).To(Equal(rendered))
+	})
+
+	It("Should replace the sections the body carries", func() {
+		Expect(RenderLocales(rendered, bodies, "en")).To(Equal(rendered))
+
+		stale := RenderLocales("Disk full", map[string]string{"en": "Disk full", "fr": "Disque plein"}, "en")
+		Expect(RenderLocales(stale, bodies, "en")).To(Equal(rendered))
+	})
+
+	It("Should drop the sections once there are no other locales", func() {
+		Expect(RenderLocales(rendered, map[string]string{"en": "Disk full"}, "en")).To(Equal("Disk full"))
+		Expect(RenderLocales("Disk full", map[string]string{"en": "Disk full"}, "en")).To(Equal("Disk full"))
+	})
+})
+
+var _ = Describe("NormalizeLineEndings", func() {
+	It("Should convert CRLF line endings to LF", func() {
+		Expect(NormalizeLineEndings("first\r\nsecond\r\n")).To(Equal("first\nsecond\n"))
+	})
+
+	It("Should leave LF line endings alone", func() {
+		Expect(NormalizeLineEndings("first\nsecond")).To(Equal("first\nsecond"))
+	})
+})
+
+var _ = Describe("FenceBody", func() {
+	It("Should wrap the body in a text code fence", func() {
+		Expect(FenceBody("- [ ] not a checklist\n# not a heading\n")).To(Equal(
+			"```text\n- [ ] not a checklist\n# not a heading\n```"))
+	})
+
+	It("Should use a fence longer than the backtick runs of the body", func() {
+		Expect(FenceBody("before\n````\ncode\n````")).To(Equal(
+			"`````text\nbefore\n````\ncode\n````\n`````"))
+	})
+})
+
+var _ = Describe("PrefixEmoji", func() {
+	It("Should prepend the emoji to the title", func() {
+		Expect(PrefixEmoji("🔥", "Disk full")).To(Equal("🔥 Disk full"))
+	})
+
+	It("Should leave the title as is without an emoji", func() {
+		Expect(PrefixEmoji("", "Disk full")).To(Equal("Disk full"))
+	})
+})
+
+var _ = Describe("TrimEmoji", func() {
+	emojis := []string{"🔥", "⚠️"}
+
+	It("Should remove a configured emoji", func() {
+		Expect(TrimEmoji("⚠️ Disk full", emojis)).To(Equal("Disk full"))
+	})
+
+	It("Should keep an emoji that isn't configured", func() {
+		Expect(TrimEmoji("🐛 Disk full", emojis)).To(Equal("🐛 Disk full"))
+	})
+
+	It("Should only remove the emoji in front of the title", func() {
+		Expect(TrimEmoji("Disk 🔥 full", emojis)).To(Equal("Disk 🔥 full"))
+	})
+})
+
+var _ = Describe("PrefixTitle", func() {
+	data := TitlePrefixData{Cluster: "staging", Namespace: "payments"}
+
+	It("Should leave the title as is without a prefix", func() {
+		Expect(PrefixTitle(nil, data, "Disk full")).To(Equal("Disk full"))
+	})
+
+	It("Should prepend the rendered prefix", func() {
+		prefix := template.Must(template.New("prefix").Parse("[{{ .Cluster }}/{{ .Namespace }}] "))
+		Expect(PrefixTitle(prefix, data, "Disk full")).To(Equal("[staging/payments] Disk full"))
+	})
+
+	It("Should fail on a prefix that doesn't render", func() {
+		prefix := template.Must(template.New("prefix").Option("missingkey=error").Parse("{{ .Region }}"))
+		_, err := PrefixTitle(prefix, data, "Disk full")
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("InCreateWindow", func() {
+	businessHours := &v1.CreateWindow{Start: "09:00", End: "17:00", TimeZone: "UTC"}
+
+	It("Should be in a window that is open", func() {
+		in, opensIn, err := InCreateWindow(businessHours, time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(in).To(BeTrue())
+		Expect(opensIn).To(BeZero())
+	})
+
+	It("Should wait for the window to open later the same day", func() {
+		in, opensIn, err := InCreateWindow(businessHours, time.Date(2024, 5, 1, 7, 30, 0, 0, time.UTC))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(in).To(BeFalse())
+		Expect(opensIn).To(Equal(90 * time.Minute))
+	})
+
+	It("Should wait for the window to open the next day once it closed", func() {
+		in, opensIn, err := InCreateWindow(businessHours, time.Date(2024, 5, 1, 17, 0, 0, 0, time.UTC))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(in).To(BeFalse())
+		Expect(opensIn).To(Equal(16 * time.Hour))
+	})
+
+	It("Should handle a window wrapping past midnight", func() {
+		night := &v1.CreateWindow{Start: "22:00", End: "06:00", TimeZone: "UTC"}
+
+		in, _, err := InCreateWindow(night, time.Date(2024, 5, 1, 23, 0, 0, 0, time.UTC))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(in).To(BeTrue())
+
+		in, _, err = InCreateWindow(night, time.Date(2024, 5, 1, 5, 0, 0, 0, time.UTC))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(in).To(BeTrue())
+
+		in, opensIn, err := InCreateWindow(night, time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(in).To(BeFalse())
+		Expect(opensIn).To(Equal(10 * time.Hour))
+	})
+
+	It("Should read the window in its time zone", func() {
+		tokyo := &v1.CreateWindow{Start: "09:00", End: "17:00", TimeZone: "Asia/Tokyo"}
+
+		// 01:00 UTC is 10:00 in Tokyo
+		in, _, err := InCreateWindow(tokyo, time.Date(2024, 5, 1, 1, 0, 0, 0, time.UTC))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(in).To(BeTrue())
+	})
+
+	It("Should fail on an unknown time zone", func() {
+		_, _, err := InCreateWindow(&v1.CreateWindow{Start: "09:00", End: "17:00", TimeZone: "Mars/Olympus"}, time.Now())
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("ClosingComment", func() {
+	It("Should append the closing reference to the comment", func() {
+		Expect(ClosingComment("auto-resolved", "1a2b3c4")).To(Equal("auto-resolved\n\nClosed by 1a2b3c4"))
+	})
+
+	It("Should comment only the closing reference without a comment", func() {
+		Expect(ClosingComment("", "https://github.com/owner/repo/pull/7")).To(
+			Equal("Closed by https://github.com/owner/repo/pull/7"))
+	})
+
+	It("Should keep the comment as is without a closing reference", func() {
+		Expect(ClosingComment("auto-resolved", "")).To(Equal("auto-resolved"))
+	})
+})
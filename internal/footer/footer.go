@@ -0,0 +1,36 @@
+package footer
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// Data is what the footer template is rendered with
+type Data struct {
+	IssueNumber int
+	IssueURL    string
+}
+
+// sample is the data the webhook renders footers with, before the issue exists
+var sample = Data{IssueNumber: 1, IssueURL: "https://github.com/owner/repo/issues/1"}
+
+// Render renders the footer template with the data of the issue. It is shared by the webhook
+// and the controller, so a footer the webhook admits renders the same in the controller.
+func Render(footer string, data Data) (string, error) {
+	tmpl, err := template.New("footer").Option("missingkey=error").Parse(footer)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse footer: %w", err)
+	}
+	var rendered strings.Builder
+	if err := tmpl.Execute(&rendered, data); err != nil {
+		return "", fmt.Errorf("failed to render footer: %w", err)
+	}
+	return rendered.String(), nil
+}
+
+// Validate checks that the footer renders, with sample data standing in for the issue
+func Validate(footer string) error {
+	_, err := Render(footer, sample)
+	return err
+}
@@ -0,0 +1,14 @@
+package footer
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestFooter(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecs(t, "Footer Suite")
+}
@@ -0,0 +1,31 @@
+package footer
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Render", func() {
+	data := Data{IssueNumber: 7, IssueURL: "https://github.com/owner/repo/issues/7"}
+
+	It("Should render the fields of the issue", func() {
+		Expect(Render("Tracked as #{{ .IssueNumber }} at {{ .IssueURL }}", data)).To(
+			Equal("Tracked as #7 at https://github.com/owner/repo/issues/7"))
+	})
+
+	It("Should fail for an unknown field", func() {
+		_, err := Render("{{ .Foo }}", data)
+		Expect(err).To(MatchError(ContainSubstring("failed to render footer")))
+	})
+})
+
+var _ = Describe("Validate", func() {
+	It("Should admit a footer using the fields of the issue", func() {
+		Expect(Validate("Tracked as #{{ .IssueNumber }}")).To(Succeed())
+	})
+
+	It("Should reject a footer that doesn't parse or render", func() {
+		Expect(Validate("Tracked as #{{ .IssueNumber }")).To(MatchError(ContainSubstring("failed to parse footer")))
+		Expect(Validate("{{ .Foo }}")).To(MatchError(ContainSubstring("can't evaluate field Foo")))
+	})
+})
@@ -0,0 +1,47 @@
+package frontmatter
+
+import (
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// delimiter opens and closes the front-matter block
+const delimiter = "---"
+
+// Metadata holds the front-matter keys mapped onto the GitHub issue, other keys are left
+// to the tooling that reads them
+type Metadata struct {
+	Labels    []string `json:"labels,omitempty"`
+	Assignees []string `json:"assignees,omitempty"`
+	Milestone int      `json:"milestone,omitempty"`
+}
+
+// Parse extracts the YAML front-matter at the start of the description, returning its
+// metadata and the description without it. A description without front-matter is
+// returned as is.
+func Parse(description string) (Metadata, string, error) {
+	var metadata Metadata
+
+	first, rest, found := strings.Cut(description, "\n")
+	if !found || strings.TrimRight(first, " \r") != delimiter {
+		return metadata, description, nil
+	}
+
+	// look for the closing delimiter on a line of its own
+	var block []string
+	lines := strings.SplitAfter(rest, "\n")
+	for i, line := range lines {
+		if strings.TrimRight(line, " \r\n") == delimiter {
+			if err := yaml.Unmarshal([]byte(strings.Join(block, "")), &metadata); err != nil {
+				return Metadata{}, description, fmt.Errorf("invalid front-matter: %w", err)
+			}
+			body := strings.TrimLeft(strings.Join(lines[i+1:], ""), "\r\n")
+			return metadata, body, nil
+		}
+		block = append(block, line)
+	}
+
+	return Metadata{}, description, fmt.Errorf("invalid front-matter: missing closing %q", delimiter)
+}
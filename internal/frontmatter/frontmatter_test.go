@@ -0,0 +1,48 @@
+package frontmatter
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Parse", func() {
+	It("Should return a description without front-matter as is", func() {
+		metadata, body, err := Parse("just a body\n---\nwith a rule")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(metadata).To(Equal(Metadata{}))
+		Expect(body).To(Equal("just a body\n---\nwith a rule"))
+	})
+
+	It("Should extract the recognized keys and strip the front-matter", func() {
+		metadata, body, err := Parse("---\nlabels: [incident, sev2]\nassignees:\n  - octocat\nmilestone: 3\nowner: team-a\n---\n\nThe body\n---\nkept")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(metadata).To(Equal(Metadata{
+			Labels:    []string{"incident", "sev2"},
+			Assignees: []string{"octocat"},
+			Milestone: 3,
+		}))
+		Expect(body).To(Equal("The body\n---\nkept"))
+	})
+
+	It("Should accept empty front-matter", func() {
+		metadata, body, err := Parse("---\n---\nThe body")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(metadata).To(Equal(Metadata{}))
+		Expect(body).To(Equal("The body"))
+	})
+
+	It("Should reject front-matter that isn't closed", func() {
+		_, _, err := Parse("---\nlabels: [incident]\nThe body")
+		Expect(err).To(MatchError(ContainSubstring("missing closing")))
+	})
+
+	It("Should reject malformed YAML", func() {
+		_, _, err := Parse("---\nlabels: [incident\n---\nThe body")
+		Expect(err).To(MatchError(ContainSubstring("invalid front-matter")))
+	})
+
+	It("Should reject keys of the wrong type", func() {
+		_, _, err := Parse("---\nmilestone: next\n---\nThe body")
+		Expect(err).To(HaveOccurred())
+	})
+})
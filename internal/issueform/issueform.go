@@ -0,0 +1,144 @@
+package issueform
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Dir is where a repository keeps its issue forms
+const Dir = ".github/ISSUE_TEMPLATE"
+
+// NoResponse is what GitHub fills a form section left empty with
+const NoResponse = "_No response_"
+
+// Form is a GitHub issue form, only the parts needed to tell its required fields
+type Form struct {
+	Name string    `json:"name"`
+	Body []Element `json:"body"`
+}
+
+// Element is a field of an issue form
+type Element struct {
+	Type       string `json:"type"`
+	ID         string `json:"id,omitempty"`
+	Attributes struct {
+		Label string `json:"label,omitempty"`
+	} `json:"attributes"`
+	Validations struct {
+		Required bool `json:"required,omitempty"`
+	} `json:"validations"`
+}
+
+// Parse reads an issue form definition
+func Parse(data []byte) (*Form, error) {
+	form := &Form{}
+	if err := yaml.Unmarshal(data, form); err != nil {
+		return nil, fmt.Errorf("invalid issue form: %w", err)
+	}
+	return form, nil
+}
+
+// RequiredLabels returns the labels of the required fields of the form, in their order.
+// Markdown elements only hold text and are never required.
+func (f *Form) RequiredLabels() []string {
+	var labels []string
+	for _, element := range f.Body {
+		if element.Type == "markdown" || !element.Validations.Required || element.Attributes.Label == "" {
+			continue
+		}
+		labels = append(labels, element.Attributes.Label)
+	}
+	return labels
+}
+
+// Missing returns the labels the body lacks a filled in section for. A form's answers are
+// rendered as "### <label>" headings followed by the answer, like GitHub renders them.
+func Missing(body string, labels []string) []string {
+	sections := map[string]string{}
+	var heading string
+	var content strings.Builder
+	flush := func() {
+		if heading != "" {
+			sections[heading] = strings.TrimSpace(content.String())
+		}
+		content.Reset()
+	}
+	for _, line := range strings.Split(body, "\n") {
+		if title, ok := strings.CutPrefix(strings.TrimRight(line, "\r"), "### "); ok {
+			flush()
+			heading = strings.TrimSpace(title)
+			continue
+		}
+		content.WriteString(line)
+		content.WriteString("\n")
+	}
+	flush()
+
+	var missing []string
+	for _, label := range labels {
+		if answer := sections[label]; answer == "" || answer == NoResponse {
+			missing = append(missing, label)
+		}
+	}
+	return missing
+}
+
+// Fetcher fetches the definition of an issue form of a repository, nil when the repository
+// has no such form
+type Fetcher interface {
+	IssueForm(ctx context.Context, owner, repo, name string) ([]byte, error)
+}
+
+// cached is a form fetched at some point, nil when the repository has no such form
+type cached struct {
+	form      *Form
+	fetchedAt time.Time
+}
+
+// Cache keeps the issue forms fetched for TTL, so admission doesn't wait for GitHub every
+// time. Forms a repository lacks are cached too, errors aren't.
+type Cache struct {
+	fetcher Fetcher
+	ttl     time.Duration
+	now     func() time.Time
+
+	mu    sync.Mutex
+	forms map[string]cached
+}
+
+// NewCache returns a cache of the forms the fetcher fetches
+func NewCache(fetcher Fetcher, ttl time.Duration) *Cache {
+	return &Cache{fetcher: fetcher, ttl: ttl, now: time.Now, forms: map[string]cached{}}
+}
+
+// Form returns the issue form of the repository, nil when it has no such form
+func (c *Cache) Form(ctx context.Context, owner, repo, name string) (*Form, error) {
+	key := owner + "/" + repo + "/" + name
+	c.mu.Lock()
+	entry, ok := c.forms[key]
+	c.mu.Unlock()
+	if ok && c.now().Sub(entry.fetchedAt) < c.ttl {
+		return entry.form, nil
+	}
+
+	data, err := c.fetcher.IssueForm(ctx, owner, repo, name)
+	if err != nil {
+		return nil, err
+	}
+	var form *Form
+	if data != nil {
+		if form, err = Parse(data); err != nil {
+			return nil, err
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.forms[key] = cached{form: form, fetchedAt: c.now()}
+	return form, nil
+}
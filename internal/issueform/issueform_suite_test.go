@@ -0,0 +1,14 @@
+package issueform
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestIssueForm(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecs(t, "Issue Form Suite")
+}
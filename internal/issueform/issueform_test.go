@@ -0,0 +1,145 @@
+package issueform
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+const bugReport = `
+name: Bug report
+description: File a bug report
+body:
+  - type: markdown
+    attributes:
+      value: Thanks for taking the time to fill out this bug report!
+  - type: textarea
+    id: what-happened
+    attributes:
+      label: What happened?
+    validations:
+      required: true
+  - type: dropdown
+    id: version
+    attributes:
+      label: Version
+      options: ["1.0", "2.0"]
+    validations:
+      required: true
+  - type: textarea
+    id: logs
+    attributes:
+      label: Relevant log output
+`
+
+// stubFetcher serves the forms it holds, counting the fetches
+type stubFetcher struct {
+	forms   map[string]string
+	err     error
+	fetches int
+}
+
+func (f *stubFetcher) IssueForm(_ context.Context, owner, repo, name string) ([]byte, error) {
+	f.fetches++
+	if f.err != nil {
+		return nil, f.err
+	}
+	form, ok := f.forms[owner+"/"+repo+"/"+name]
+	if !ok {
+		return nil, nil
+	}
+	return []byte(form), nil
+}
+
+var _ = Describe("Issue forms", func() {
+	Context("When reading the required fields", func() {
+		It("Should list the labels of the required fields only", func() {
+			form, err := Parse([]byte(bugReport))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(form.Name).To(Equal("Bug report"))
+			Expect(form.RequiredLabels()).To(Equal([]string{"What happened?", "Version"}))
+		})
+
+		It("Should reject a malformed form", func() {
+			_, err := Parse([]byte("body: {"))
+			Expect(err).To(MatchError(ContainSubstring("invalid issue form")))
+		})
+	})
+
+	Context("When checking a body against the required fields", func() {
+		labels := []string{"What happened?", "Version"}
+
+		It("Should find nothing missing when every section is filled in", func() {
+			body := "### What happened?\n\nThe pod crashed.\n\n### Version\n\n2.0\n"
+			Expect(Missing(body, labels)).To(BeEmpty())
+		})
+
+		It("Should report absent sections", func() {
+			Expect(Missing("### Version\n\n2.0", labels)).To(Equal([]string{"What happened?"}))
+			Expect(Missing("The pod crashed.", labels)).To(Equal(labels))
+		})
+
+		It("Should report empty sections and sections without a response", func() {
+			body := "### What happened?\n\n### Version\n\n_No response_\n"
+			Expect(Missing(body, labels)).To(Equal(labels))
+		})
+
+		It("Should read CRLF bodies", func() {
+			body := "### What happened?\r\n\r\nThe pod crashed.\r\n### Version\r\n\r\n2.0"
+			Expect(Missing(body, labels)).To(BeEmpty())
+		})
+	})
+
+	Context("When caching forms", func() {
+		var (
+			fetcher *stubFetcher
+			cache   *Cache
+			now     time.Time
+		)
+
+		BeforeEach(func() {
+			fetcher = &stubFetcher{forms: map[string]string{"owner/repo/bug.yml": bugReport}}
+			cache = NewCache(fetcher, 10*time.Minute)
+			now = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+			cache.now = func() time.Time { return now }
+		})
+
+		It("Should fetch a form once within the TTL", func() {
+			for range 3 {
+				form, err := cache.Form(context.Background(), "owner", "repo", "bug.yml")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(form.RequiredLabels()).To(HaveLen(2))
+			}
+			Expect(fetcher.fetches).To(Equal(1))
+
+			now = now.Add(10 * time.Minute)
+			_, err := cache.Form(context.Background(), "owner", "repo", "bug.yml")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fetcher.fetches).To(Equal(2))
+		})
+
+		It("Should cache a form the repository lacks", func() {
+			for range 2 {
+				form, err := cache.Form(context.Background(), "owner", "repo", "missing.yml")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(form).To(BeNil())
+			}
+			Expect(fetcher.fetches).To(Equal(1))
+		})
+
+		It("Should not cache errors", func() {
+			fetcher.err = errors.New("GitHub unavailable")
+			_, err := cache.Form(context.Background(), "owner", "repo", "bug.yml")
+			Expect(err).To(HaveOccurred())
+
+			fetcher.err = nil
+			form, err := cache.Form(context.Background(), "owner", "repo", "bug.yml")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(form).NotTo(BeNil())
+			Expect(fetcher.fetches).To(Equal(2))
+		})
+	})
+})
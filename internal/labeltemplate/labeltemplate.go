@@ -0,0 +1,63 @@
+package labeltemplate
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"unicode/utf8"
+)
+
+// MaxLength is the longest label name GitHub accepts
+const MaxLength = 50
+
+// Data is what label templates are rendered with
+type Data struct {
+	Namespace string
+	Name      string
+}
+
+// Render renders the labels as templates with the metadata of the GithubIssue, e.g.
+// "ns/{{ .Namespace }}". Labels without a template are kept as is. It is shared by the webhook
+// and the controller, so labels admitted are labels that can be attached. The rendered labels
+// are deduplicated, keeping their order, and an error is returned for labels GitHub would
+// reject.
+func Render(labels []string, data Data) ([]string, error) {
+	if len(labels) == 0 {
+		return labels, nil
+	}
+
+	rendered := make([]string, 0, len(labels))
+	seen := make(map[string]bool, len(labels))
+	for _, label := range labels {
+		if strings.Contains(label, "{{") {
+			tmpl, err := template.New("label").Option("missingkey=error").Parse(label)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse label %q: %w", label, err)
+			}
+			var out strings.Builder
+			if err := tmpl.Execute(&out, data); err != nil {
+				return nil, fmt.Errorf("failed to render label %q: %w", label, err)
+			}
+			label = strings.TrimSpace(out.String())
+		}
+		if err := validate(label); err != nil {
+			return nil, err
+		}
+		if !seen[label] {
+			seen[label] = true
+			rendered = append(rendered, label)
+		}
+	}
+	return rendered, nil
+}
+
+// validate checks that GitHub accepts the label name
+func validate(label string) error {
+	if label == "" {
+		return fmt.Errorf("label must not be empty")
+	}
+	if n := utf8.RuneCountInString(label); n > MaxLength {
+		return fmt.Errorf("label %q is %d characters long, GitHub accepts up to %d", label, n, MaxLength)
+	}
+	return nil
+}
@@ -0,0 +1,14 @@
+package labeltemplate
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestLabelTemplate(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecs(t, "LabelTemplate Suite")
+}
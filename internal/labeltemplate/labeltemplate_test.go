@@ -0,0 +1,41 @@
+package labeltemplate
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Render", func() {
+	data := Data{Namespace: "payments", Name: "db-down"}
+
+	It("Should render the metadata into templated labels", func() {
+		labels, err := Render([]string{"bug", "ns/{{ .Namespace }}", "cr/{{.Name}}"}, data)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(labels).To(Equal([]string{"bug", "ns/payments", "cr/db-down"}))
+	})
+
+	It("Should dedupe the rendered labels, keeping their order", func() {
+		labels, err := Render([]string{"ns/payments", "bug", "ns/{{ .Namespace }}", "bug"}, data)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(labels).To(Equal([]string{"ns/payments", "bug"}))
+	})
+
+	It("Should leave no labels as is", func() {
+		labels, err := Render(nil, data)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(labels).To(BeNil())
+	})
+
+	DescribeTable("Should reject labels GitHub wouldn't accept",
+		func(label string, message string) {
+			_, err := Render([]string{label}, data)
+			Expect(err).To(MatchError(ContainSubstring(message)))
+		},
+		Entry("unparsable template", "ns/{{ .Namespace", "failed to parse"),
+		Entry("unknown field", "{{ .Cluster }}", "failed to render"),
+		Entry("empty rendering", "{{ if false }}x{{ end }}", "must not be empty"),
+		Entry("too long rendering", "{{ .Namespace }}-"+strings.Repeat("x", 45), "GitHub accepts up to 50"),
+	)
+})
@@ -0,0 +1,132 @@
+package mdlint
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// fencePattern matches the line opening or closing a fenced code block
+var fencePattern = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})(.*)$")
+
+// delimiterCellPattern matches a cell of the delimiter row of a table, e.g. ":---:"
+var delimiterCellPattern = regexp.MustCompile(`^:?-+:?$`)
+
+// Problem is a structural error of the markdown, which GitHub renders other than intended
+type Problem struct {
+	// Line is the 1-based line the problem was found on
+	Line    int
+	Message string
+}
+
+// String formats the problem for an admission warning
+func (p Problem) String() string {
+	return fmt.Sprintf("line %d: %s", p.Line, p.Message)
+}
+
+// Check returns the common structural errors of the markdown: code fences that are never
+// closed, which swallow the rest of the body, and tables whose rows don't line up with
+// their header. It doesn't look inside code blocks.
+func Check(markdown string) []Problem {
+	var problems []Problem
+	lines := strings.Split(strings.ReplaceAll(markdown, "\r\n", "\n"), "\n")
+
+	fence, fenceLine := "", 0
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if m := fencePattern.FindStringSubmatch(line); m != nil {
+			switch {
+			case fence == "":
+				// backtick fences can't have backticks in their info string
+				if m[1][0] == '`' && strings.Contains(m[2], "`") {
+					break
+				}
+				fence, fenceLine = m[1], i+1
+				continue
+			case m[1][0] == fence[0] && len(m[1]) >= len(fence) && strings.TrimSpace(m[2]) == "":
+				fence = ""
+				continue
+			}
+		}
+		if fence != "" {
+			continue
+		}
+
+		if i+1 < len(lines) && isDelimiterRow(lines[i+1]) && strings.Contains(line, "|") {
+			i = checkTable(lines, i, &problems)
+		}
+	}
+
+	if fence != "" {
+		problems = append(problems, Problem{
+			Line:    fenceLine,
+			Message: fmt.Sprintf("code fence %s is never closed, the rest of the description renders as code", fence),
+		})
+	}
+	return problems
+}
+
+// checkTable checks the table whose header is on line start, returning the index of its
+// last row
+func checkTable(lines []string, start int, problems *[]Problem) int {
+	columns := len(cells(lines[start]))
+	if n := len(cells(lines[start+1])); n != columns {
+		*problems = append(*problems, Problem{
+			Line: start + 2,
+			Message: fmt.Sprintf("table delimiter row has %d columns but its header has %d, "+
+				"it renders as text rather than a table", n, columns),
+		})
+		return start + 1
+	}
+
+	end := start + 1
+	for end+1 < len(lines) && strings.TrimSpace(lines[end+1]) != "" && strings.Contains(lines[end+1], "|") {
+		end++
+		if n := len(cells(lines[end])); n > columns {
+			*problems = append(*problems, Problem{
+				Line:    end + 1,
+				Message: fmt.Sprintf("table row has %d cells but the header has %d, the extra cells are dropped", n, columns),
+			})
+		}
+	}
+	return end
+}
+
+// isDelimiterRow reports whether the line is the delimiter row of a table, e.g. "| --- | :-: |"
+func isDelimiterRow(line string) bool {
+	if !strings.Contains(line, "|") {
+		return false
+	}
+	row := cells(line)
+	for _, cell := range row {
+		if !delimiterCellPattern.MatchString(cell) {
+			return false
+		}
+	}
+	return len(row) > 0
+}
+
+// cells splits the table row on its unescaped pipes, ignoring the leading and trailing pipe
+func cells(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, `\|`) {
+		line = line[:len(line)-1]
+	}
+
+	var row []string
+	var cell strings.Builder
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '\\' && i+1 < len(line) && line[i+1] == '|':
+			cell.WriteString(`\|`)
+			i++
+		case line[i] == '|':
+			row = append(row, strings.TrimSpace(cell.String()))
+			cell.Reset()
+		default:
+			cell.WriteByte(line[i])
+		}
+	}
+	return append(row, strings.TrimSpace(cell.String()))
+}
@@ -0,0 +1,14 @@
+package mdlint
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestMdlint(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecs(t, "Mdlint Suite")
+}
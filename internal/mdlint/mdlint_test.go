@@ -0,0 +1,51 @@
+package mdlint
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Check", func() {
+	It("Should find nothing wrong with clean markdown", func() {
+		Expect(Check("## Summary\n\nThe job failed.\n\n" +
+			"```go\nfmt.Println(\"|a|b|\")\n```\n\n" +
+			"| Pod | Restarts |\n| :-- | --: |\n| web-1 | 3 |\n| web-2 |\n\n" +
+			"~~~~\n```\n~~~~\n")).To(BeEmpty())
+	})
+
+	It("Should find a code fence that is never closed", func() {
+		problems := Check("## Logs\n\n```\npanic: boom\n\n## Next steps\n")
+		Expect(problems).To(Equal([]Problem{{
+			Line:    3,
+			Message: "code fence ``` is never closed, the rest of the description renders as code",
+		}}))
+		Expect(problems[0].String()).To(HavePrefix("line 3: code fence"))
+	})
+
+	It("Should not close a fence with a shorter or different one", func() {
+		Expect(Check("````\n```\n````")).To(BeEmpty())
+		Expect(Check("````\n```\n")).To(HaveLen(1))
+		Expect(Check("~~~\n```\n")).To(HaveLen(1))
+	})
+
+	It("Should find a table whose delimiter row doesn't match its header", func() {
+		problems := Check("| Pod | Node | Restarts |\n| --- | --- |\n| web-1 | a | 3 |")
+		Expect(problems).To(HaveLen(1))
+		Expect(problems[0].Line).To(Equal(2))
+		Expect(problems[0].Message).To(ContainSubstring("has 2 columns but its header has 3"))
+	})
+
+	It("Should find table rows with more cells than the header", func() {
+		problems := Check("Pods\n\n| Pod | Restarts |\n|---|---|\n| web-1 | 3 |\n| web-2 | 4 | extra |\n")
+		Expect(problems).To(HaveLen(1))
+		Expect(problems[0].Line).To(Equal(6))
+	})
+
+	It("Should not count escaped pipes as cells", func() {
+		Expect(Check("| Expr | Value |\n| --- | --- |\n| a \\| b | 1 |")).To(BeEmpty())
+	})
+
+	It("Should not check tables inside code blocks", func() {
+		Expect(Check("```\n| a | b |\n| --- |\n```")).To(BeEmpty())
+	})
+})
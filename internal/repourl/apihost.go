@@ -0,0 +1,50 @@
+package repourl
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// GitHubAPIHost is the host of GitHub's API, always allowed to receive the operator's tokens
+const GitHubAPIHost = "api.github.com"
+
+// ParseHosts parses a comma-separated list of hosts, each optionally with a port
+func ParseHosts(list string) ([]string, error) {
+	var hosts []string
+	for _, host := range strings.Split(list, ",") {
+		host = strings.TrimSpace(host)
+		if host == "" {
+			continue
+		}
+		if strings.ContainsAny(host, "/?#@") {
+			return nil, fmt.Errorf("%q is not a host, give it without a scheme or path", host)
+		}
+		hosts = append(hosts, strings.ToLower(host))
+	}
+	return hosts, nil
+}
+
+// APIHostAllowed reports whether the GitHub API at the base URL may be sent the operator's
+// tokens: api.github.com always is, other hosts only when allowed, matched with or without
+// their port
+func APIHostAllowed(apiBaseURL string, allowed []string) bool {
+	return HostAllowed(apiBaseURL, append([]string{GitHubAPIHost}, allowed...))
+}
+
+// HostAllowed reports whether the host of the URL is one of the allowed hosts, matched with
+// or without its port
+func HostAllowed(rawURL string, allowed []string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	host := strings.ToLower(u.Host)
+	hostname := strings.ToLower(u.Hostname())
+	for _, allowedHost := range allowed {
+		if allowedHost == host || allowedHost == hostname {
+			return true
+		}
+	}
+	return false
+}
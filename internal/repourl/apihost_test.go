@@ -0,0 +1,47 @@
+package repourl
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("APIHostAllowed", func() {
+	allowed := []string{"github.example.com", "gateway.example.com:8443"}
+
+	DescribeTable("Should allow api.github.com and the allowed hosts",
+		func(apiBaseURL string) {
+			Expect(APIHostAllowed(apiBaseURL, allowed)).To(BeTrue())
+		},
+		Entry("GitHub", "https://api.github.com/"),
+		Entry("allowed host", "https://GitHub.example.com/api/v3/"),
+		Entry("allowed host with its port", "https://gateway.example.com:8443/github/"),
+	)
+
+	DescribeTable("Should deny other hosts",
+		func(apiBaseURL string) {
+			Expect(APIHostAllowed(apiBaseURL, allowed)).To(BeFalse())
+		},
+		Entry("unknown host", "https://attacker.example.net/api/v3/"),
+		Entry("suffix of an allowed host", "https://github.example.com.attacker.example.net/"),
+		Entry("allowed host on another port", "https://gateway.example.com:9443/github/"),
+		Entry("no host", "https:///api/v3/"),
+	)
+
+	It("Should deny every other host without an allowlist", func() {
+		Expect(APIHostAllowed("https://github.example.com/api/v3/", nil)).To(BeFalse())
+		Expect(APIHostAllowed("https://api.github.com/", nil)).To(BeTrue())
+	})
+})
+
+var _ = Describe("ParseHosts", func() {
+	It("Should parse the hosts, lowercased", func() {
+		hosts, err := ParseHosts(" GitHub.example.com, gateway.example.com:8443,")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(hosts).To(Equal([]string{"github.example.com", "gateway.example.com:8443"}))
+	})
+
+	It("Should reject URLs", func() {
+		_, err := ParseHosts("https://github.example.com")
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,38 @@
+package repourl
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// prefix is the only form of repository URL accepted
+const prefix = "https://github.com/"
+
+var (
+	// ownerPattern matches GitHub user and organization names
+	ownerPattern = regexp.MustCompile(`^[A-Za-z0-9](?:[A-Za-z0-9-]*[A-Za-z0-9])?$`)
+	// repoPattern matches GitHub repository names
+	repoPattern = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+)
+
+// Parse extracts the owner and repo from a 'https://github.com/{owner}/{repo}' URL. It is
+// shared by the webhook and the controller, so a URL admitted is a URL that can be reconciled.
+func Parse(repoURL string) (owner, repo string, err error) {
+	path, found := strings.CutPrefix(repoURL, prefix)
+	if !found {
+		return "", "", fmt.Errorf("repository URL must start with %s", prefix)
+	}
+	parts := strings.Split(path, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("repository URL must be in the format '%s{owner}/{repo}'", prefix)
+	}
+	if !ownerPattern.MatchString(parts[0]) {
+		return "", "", fmt.Errorf("%q is not a valid GitHub owner", parts[0])
+	}
+	if !repoPattern.MatchString(parts[1]) {
+		return "", "", fmt.Errorf("%q is not a valid GitHub repository name", parts[1])
+	}
+
+	return parts[0], parts[1], nil
+}
@@ -0,0 +1,14 @@
+package repourl
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestRepoURL(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecs(t, "RepoURL Suite")
+}
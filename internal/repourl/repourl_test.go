@@ -0,0 +1,28 @@
+package repourl
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Parse", func() {
+	It("Should extract the owner and repo", func() {
+		owner, repo, err := Parse("https://github.com/my-org/my.repo_2")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(owner).To(Equal("my-org"))
+		Expect(repo).To(Equal("my.repo_2"))
+	})
+
+	DescribeTable("Should reject URLs the owner and repo can't be extracted from",
+		func(repoURL string) {
+			_, _, err := Parse(repoURL)
+			Expect(err).To(HaveOccurred())
+		},
+		Entry("credentials", "https://user@github.com/owner/repo"),
+		Entry("query", "https://github.com/owner/repo?tab=issues"),
+		Entry("fragment", "https://github.com/owner/repo#readme"),
+		Entry("owner starting with a hyphen", "https://github.com/-owner/repo"),
+		Entry("extra path segment", "https://github.com/owner/repo/issues"),
+		Entry("other host", "https://gitlab.com/owner/repo"),
+	)
+})